@@ -0,0 +1,198 @@
+package genz
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"runtime/pprof"
+	"strings"
+	"text/template"
+
+	"github.com/leorolland/genz/internal/bench"
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/parser"
+	"github.com/leorolland/genz/internal/render"
+	"github.com/leorolland/genz/pkg/models"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	benchUsage = `Usage of genz bench:
+	genz bench [flags] -type T -template foo.tmpl [directory]
+Measures the generation of the type, e.g. to find out which template slows down a go:generate run: the package is
+loaded once, then the model is parsed, the template parsed, rendered and formatted -n times, each phase reported with
+its duration and allocations. Nothing is written. With -cpuprofile, the runs are profiled, see go tool pprof.
+Flags:`
+)
+
+type benchCommand struct {
+}
+
+var (
+	benchCmd               = flag.NewFlagSet("bench", flag.ExitOnError)
+	benchTypeName          = benchCmd.String("type", "", "name of the type to parse; must be set, unless -functions or -values is")
+	benchTemplate          = benchCmd.String("template", "", "go-template local file, URL or module file (e.g. github.com/org/templates/builder.tmpl@v1.2.0); with -template-dir, the entrypoint in the directory (default main.tmpl)")
+	benchTemplateDir       = benchCmd.String("template-dir", "", "directory of go-templates sharing their {{ define }} blocks, local or of a module (e.g. github.com/org/templates/api@v1.2.0)")
+	benchRuns              = benchCmd.Int("n", 100, "number of runs of each phase")
+	benchCPUProfile        = benchCmd.String("cpuprofile", "", "write a CPU profile of the runs to this file")
+	benchRaw               = benchCmd.Bool("raw", false, "do not measure the formatting, e.g. for a template generating another language than Go")
+	benchBuildTags         = benchCmd.String("tags", "", "comma-separated list of build tags to apply")
+	benchBuildFlags        = benchCmd.String("build-flags", "", "space-separated list of flags of the build system, e.g. -mod=vendor")
+	benchTests             = benchCmd.Bool("tests", false, "include the _test.go files of the package, to parse the types declared in tests")
+	benchAllowErrors       = benchCmd.Bool("allow-errors", false, "generate from a package having compile errors, e.g. referencing the code not generated yet")
+	benchFlattenEmbedded   = benchCmd.Bool("flatten-embedded", false, "replace embedded structs by their promoted attributes")
+	benchRecursive         = benchCmd.Bool("recursive", false, "parse the struct types of the attributes declared in the same module")
+	benchFunctions         = benchCmd.Bool("functions", false, "parse the top-level functions of the package")
+	benchValues            = benchCmd.Bool("values", false, "parse the exported constants and variables of the package")
+	benchIncludeTags       = stringList{}
+	benchExcludeTags       = stringList{}
+	benchFuncs             = stringList{}
+	benchVars              = keyValues{}
+	benchExcludeUnexported = benchCmd.Bool("exclude-unexported", false, "remove the unexported attributes")
+)
+
+func init() {
+	benchCmd.Var(&benchIncludeTags, "include-tag", "keep only the attributes having one of these comma-separated tags: a key (e.g. json) or a key and a name (e.g. db:id)")
+	benchCmd.Var(&benchExcludeTags, "exclude-tag", "remove the attributes having one of these comma-separated tags, e.g. 'genz:\"-\"'; see -include-tag")
+	benchCmd.Var(benchVars, "var", "variable given to the template as .Vars, as key=value; can be repeated")
+	benchCmd.Var(&benchFuncs, "funcs", "comma-separated files of custom template functions, see genz -h")
+	benchCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", benchUsage)
+		benchCmd.PrintDefaults()
+	}
+	command.RegisterCommand("bench", benchCommand{})
+}
+
+func (b benchCommand) FlagSet() *flag.FlagSet {
+	return benchCmd
+}
+
+func (b benchCommand) ValidateArgs() error {
+	if len(*benchTypeName) == 0 && !*benchFunctions && !*benchValues {
+		benchCmd.Usage()
+		return fmt.Errorf("missing 'type' argument")
+	}
+	if len(*benchTemplate) == 0 && len(*benchTemplateDir) == 0 {
+		benchCmd.Usage()
+		return fmt.Errorf("missing 'template' argument")
+	}
+	if *benchRuns < 1 {
+		return fmt.Errorf("-n must be positive, got %d", *benchRuns)
+	}
+	return nil
+}
+
+// Run loads the package, then measures each phase of the generation of the type separately, from the result of the
+// previous phase, and prints the results on the standard output.
+func (b benchCommand) Run() error {
+	target := config.Target{
+		Template:    *benchTemplate,
+		TemplateDir: *benchTemplateDir,
+		Tests:       *benchTests,
+		AllowErrors: *benchAllowErrors,
+		Funcs:       benchFuncs,
+		Inputs:      benchCmd.Args(),
+	}
+	if len(*benchBuildTags) > 0 {
+		target.Tags = strings.Split(*benchBuildTags, ",")
+	}
+	target.BuildFlags = strings.Fields(*benchBuildFlags)
+	if len(target.Inputs) == 0 {
+		target.Inputs = []string{"."}
+	}
+
+	var (
+		content  []byte
+		partials []generator.Partial
+		err      error
+	)
+	if target.TemplateDir != "" {
+		content, partials, err = render.ReadTemplateDir(target.TemplateDir, target.Template)
+	} else {
+		content, err = render.ReadTemplate(target.Template)
+	}
+	if err != nil {
+		return failure.New(failure.KindTemplate, err)
+	}
+	funcs, err := generator.LoadFuncs(target.Funcs)
+	if err != nil {
+		return failure.New(failure.KindTemplate, err)
+	}
+	parse := parser.WithOptions(parser.Options{
+		FlattenEmbedded:   *benchFlattenEmbedded,
+		Recursive:         *benchRecursive,
+		Functions:         *benchFunctions,
+		Values:            *benchValues,
+		IncludeTags:       benchIncludeTags,
+		ExcludeTags:       benchExcludeTags,
+		ExcludeUnexported: *benchExcludeUnexported,
+	})
+
+	// The package is loaded once: the type checking dominates, and does not depend on the template.
+	var pkg *packages.Package
+	load, err := bench.Measure("load", 1, func() error {
+		pkg, err = loadPackage(target)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if *benchCPUProfile != "" {
+		f, err := os.Create(*benchCPUProfile)
+		if err != nil {
+			return &failure.Error{Kind: failure.KindWrite, File: *benchCPUProfile, Err: err}
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	var element models.ParsedElement
+	parsed, err := bench.Measure("parse", *benchRuns, func() error {
+		element, err = parse(pkg, *benchTypeName)
+		return err
+	})
+	if err != nil {
+		return failure.New(failure.KindParse, err)
+	}
+	element.Vars = benchVars
+
+	var tmpl *template.Template
+	templated, err := bench.Measure("template", *benchRuns, func() error {
+		tmpl, err = generator.ParseWithFuncs(string(content), funcs, partials...)
+		return err
+	})
+	if err != nil {
+		return render.TemplateFileError(target, render.TemplateFile(target), err)
+	}
+
+	var buf bytes.Buffer
+	rendered, err := bench.Measure("render", *benchRuns, func() error {
+		buf.Reset()
+		return tmpl.Execute(&buf, element)
+	})
+	if err != nil {
+		return failure.New(failure.KindTemplate, err)
+	}
+
+	results := []bench.Result{load, parsed, templated, rendered}
+	if !*benchRaw {
+		formatted, err := bench.Measure("format", *benchRuns, func() error {
+			_, err := generator.Format(buf)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		results = append(results, formatted)
+	}
+	return bench.Write(os.Stdout, results)
+}
@@ -0,0 +1,160 @@
+package genz
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leorolland/genz/internal/builtin"
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/config"
+)
+
+const (
+	builtinUsage = `Usage of genz builtin:
+	genz builtin <generator> [flags] -type T [directory]
+	genz builtin <generator> [flags] -type T files... # Must be a single package
+	genz builtin providers [flags] [directory] # Rendered from the functions of the package, without type
+Generators:
+	%s
+Flags:
+`
+)
+
+type builtinCommand struct {
+}
+
+var (
+	builtinCmd               = flag.NewFlagSet("builtin", flag.ExitOnError)
+	builtinTypeNames         = stringList{}
+	builtinOutput            = builtinCmd.String("output", "", "output file name; default srcdir/<type>_<generator>.gen.go, or the extension of a non-Go generator (e.g. .ts)")
+	builtinBuildTags         = builtinCmd.String("tags", "", "comma-separated list of build tags to apply")
+	builtinBuildFlags        = builtinCmd.String("build-flags", "", "space-separated list of flags of the build system, e.g. -mod=vendor")
+	builtinTests             = builtinCmd.Bool("tests", false, "include the _test.go files of the package, to parse the types declared in tests")
+	builtinAllowErrors       = builtinCmd.Bool("allow-errors", false, "generate from a package having compile errors, e.g. referencing the code not generated yet")
+	builtinCombine           = builtinCmd.Bool("combine", false, "render all the types into a single output file")
+	builtinWithTests         = builtinCmd.Bool("with-tests", false, "also generate the tests of the generated code, for the generators shipping them (clone, equal)")
+	builtinDryRun            = builtinCmd.Bool("dry-run", false, "do not write the outputs, and fail if some of them are out of date")
+	builtinDiff              = builtinCmd.Bool("diff", false, "print the unified diff of the outputs which are out of date, and write them unless -dry-run")
+	builtinIncludeTags       = stringList{}
+	builtinVars              = keyValues{}
+	builtinExcludeTags       = stringList{}
+	builtinExcludeUnexported = builtinCmd.Bool("exclude-unexported", false, "remove the unexported attributes")
+	builtinSuffix            = builtinCmd.String("suffix", "", "suffix of the default output names, before the extension, e.g. _gen for <type>_<generator>_gen.go; default .gen")
+	builtinBuild             = builtinCmd.String("build", "", "//go:build constraint expression added to the Go outputs, e.g. 'linux && amd64'")
+	builtinProtect           = builtinCmd.Bool("protect", false, "refuse to overwrite the existing outputs not marked as generated by a \"Code generated ... DO NOT EDIT.\" comment")
+	builtinWrite             = builtinCmd.String("write", "", "strategy of writing the outputs: overwrite (default), skip-if-exists to scaffold files edited by hand, or region to only rewrite the regions between the genz:begin and genz:end markers")
+	builtinDialect           = builtinCmd.String("dialect", "postgres", "SQL dialect of the sql generator: postgres, mysql or sqlite")
+	builtinInterface         = builtinCmd.String("interface-name", "", "name of the interface of the interface generator; default <type>Interface")
+	builtinMethodPrefix      = builtinCmd.String("method-prefix", "", "keep only the methods starting with this prefix, for the interface generator")
+	builtinMethodTag         = builtinCmd.String("method-tag", "", "keep only the methods marked with the //genz:<tag> directive, for the interface generator")
+	builtinTo                = builtinCmd.String("to", "", "type the mapper generator converts to, qualified with the path or the directory of its package when declared in another one (e.g. ./domain.User)")
+	builtinUnmapped          = builtinCmd.String("unmapped", "error", "attributes the mapper generator cannot map: error, or todo to leave a TODO comment")
+	builtinProtoPackage      = builtinCmd.String("proto-package", "", "package of the .proto file of the grpc generator; default the Go package name")
+	builtinPBPackage         = builtinCmd.String("pb-package", "", "import path of the Go package generated by protoc from the .proto file of the grpc generator; default the package of the interface")
+	builtinRouter            = builtinCmd.String("router", "http", "router of the http generator: http (the net/http ServeMux of Go 1.22), chi or echo")
+	builtinDelimiter         = builtinCmd.String("delimiter", ",", "field delimiter of the csv generator, e.g. ';' or '\\t'")
+	builtinTimeFormat        = builtinCmd.String("time-format", "RFC3339", "layout of the time.Time attributes of the csv generator: a constant of the time package (e.g. DateOnly) or a layout (e.g. 02/01/2006)")
+	builtinInjector          = builtinCmd.String("injector", "wire", "dependency injection framework of the providers generator: wire or fx")
+	builtinHelpers           = builtinCmd.String("helpers", "filter,map,sort,group,index", "comma-separated helpers of the slice generator: filter, map, sort, group and index")
+	builtinBy                = builtinCmd.String("by", "", "comma-separated attributes of the SortByX, GroupByX and IndexByX helpers of the slice generator; default all the ones of a basic type (or time.Time to sort)")
+	builtinMapTo             = builtinCmd.String("map-to", "", "comma-separated types of the MapToX helpers of the slice generator, e.g. string,dto.User")
+	builtinGeneratorArg      string
+)
+
+func init() {
+	builtinCmd.Var(&builtinTypeNames, "type", "comma-separated list of type names or patterns (e.g. '*DTO'); must be set, except for the generators rendered from the functions of the package (providers)")
+	builtinCmd.Var(&builtinTypeNames, "from", "same as -type, e.g. genz builtin mapper -from User -to ./domain.User")
+	builtinCmd.Var(builtinVars, "var", "variable given to the template as .Vars, as key=value; can be repeated, and overrides the flags of the generators, e.g. -var dialect=mysql")
+	builtinCmd.Var(&builtinIncludeTags, "include-tag", "keep only the attributes having one of these comma-separated tags: a key (e.g. json) or a key and a name (e.g. db:id)")
+	builtinCmd.Var(&builtinExcludeTags, "exclude-tag", "remove the attributes having one of these comma-separated tags, e.g. 'genz:\"-\"'; see -include-tag")
+	builtinCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, builtinUsage, strings.Join(builtin.Names(), "\n\t"))
+		builtinCmd.PrintDefaults()
+	}
+	command.RegisterCommand("builtin", builtinCommand{})
+}
+
+func (b builtinCommand) FlagSet() *flag.FlagSet {
+	return builtinCmd
+}
+
+// ValidateArgs reads the generator name, then parses the flags following it.
+func (b builtinCommand) ValidateArgs() error {
+	if builtinCmd.NArg() == 0 {
+		builtinCmd.Usage()
+		return fmt.Errorf("missing generator argument")
+	}
+	builtinGeneratorArg = builtinCmd.Arg(0)
+	if _, err := builtin.Template(builtinGeneratorArg); err != nil {
+		builtinCmd.Usage()
+		return err
+	}
+	if err := builtinCmd.Parse(builtinCmd.Args()[1:]); err != nil {
+		return err
+	}
+	if len(builtinTypeNames) == 0 && !builtin.ParsesFunctions(builtinGeneratorArg) {
+		builtinCmd.Usage()
+		return fmt.Errorf("missing 'type' argument")
+	}
+	if builtinGeneratorArg == "mapper" && *builtinTo == "" {
+		builtinCmd.Usage()
+		return fmt.Errorf("missing 'to' argument of the mapper generator")
+	}
+	return config.CheckWrite(*builtinWrite)
+}
+
+func (b builtinCommand) Run() error {
+	target := config.Target{
+		Types:       builtinTypeNames,
+		Tests:       *builtinTests,
+		AllowErrors: *builtinAllowErrors,
+		Template:    builtin.Location(builtinGeneratorArg),
+		Output:      *builtinOutput,
+		Combine:     *builtinCombine,
+		WithTests:   *builtinWithTests,
+		DryRun:      *builtinDryRun,
+		Diff:        *builtinDiff,
+		Protect:     *builtinProtect,
+		Write:       *builtinWrite,
+		Suffix:      *builtinSuffix,
+		Build:       *builtinBuild,
+		Vars: map[string]string{
+			"dialect":       *builtinDialect,
+			"interface":     *builtinInterface,
+			"prefix":        *builtinMethodPrefix,
+			"tag":           *builtinMethodTag,
+			"unmapped":      *builtinUnmapped,
+			"proto_package": *builtinProtoPackage,
+			"pb_package":    *builtinPBPackage,
+			"router":        *builtinRouter,
+			"delimiter":     *builtinDelimiter,
+			"time_format":   *builtinTimeFormat,
+			"injector":      *builtinInjector,
+			"helpers":       *builtinHelpers,
+			"by":            *builtinBy,
+			"map_to":        *builtinMapTo,
+		},
+		To:                *builtinTo,
+		Inputs:            builtinCmd.Args(),
+		Recursive:         true,
+		Functions:         builtin.ParsesFunctions(builtinGeneratorArg),
+		Implementations:   builtin.ParsesImplementations(builtinGeneratorArg),
+		IncludeTags:       builtinIncludeTags,
+		ExcludeTags:       builtinExcludeTags,
+		ExcludeUnexported: *builtinExcludeUnexported,
+	}
+	for key, value := range builtinVars {
+		target.Vars[key] = value
+	}
+	if len(*builtinBuildTags) > 0 {
+		target.Tags = strings.Split(*builtinBuildTags, ",")
+	}
+	target.BuildFlags = strings.Fields(*builtinBuildFlags)
+	if len(target.Inputs) == 0 {
+		target.Inputs = []string{"."}
+	}
+	_, err := runTarget(target)
+	return err
+}
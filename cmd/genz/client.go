@@ -0,0 +1,93 @@
+package genz
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/daemon"
+	"github.com/leorolland/genz/internal/failure"
+)
+
+const (
+	clientUsage = `Usage of genz client:
+	genz client [-socket path] [genz flags] [directory] # Runs the generation in genz daemon
+Runs genz with the given flags and inputs in the daemon started with genz daemon, from the current directory, printing
+its logs and its outputs, and exiting with its exit code. The packages loaded by the previous requests are reused
+while their files do not change. The standard input is not forwarded, and -watch cannot be used.
+Flags:`
+)
+
+type clientCommand struct {
+}
+
+var (
+	clientCmd    *flag.FlagSet
+	clientSocket = new(string)
+)
+
+func init() {
+	command.RegisterCommand("client", clientCommand{})
+}
+
+// FlagSet returns the flags of genz generate, plus -socket: the arguments are checked before being sent to the daemon.
+// It is created on first use, once the flags of genz generate are all defined.
+func (c clientCommand) FlagSet() *flag.FlagSet {
+	if clientCmd == nil {
+		clientCmd = generateFlags("client", flag.ExitOnError)
+		clientCmd.StringVar(clientSocket, "socket", daemon.DefaultSocket(), "unix socket of the daemon; default $"+daemon.SocketEnv+", or genz.sock in $XDG_RUNTIME_DIR or in a genz-<uid> temporary directory")
+		clientCmd.Usage = func() {
+			fmt.Fprintf(os.Stderr, "%s\n", clientUsage)
+			clientCmd.PrintDefaults()
+		}
+	}
+	return clientCmd
+}
+
+func (c clientCommand) ValidateArgs() error {
+	if *watchMode {
+		return fmt.Errorf("-watch cannot be used with genz client")
+	}
+	return nil
+}
+
+// Run sends the arguments of the command line, -socket aside, to the daemon, and prints its response.
+func (c clientCommand) Run() error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	response, err := daemon.Call(*clientSocket, daemon.Request{Dir: dir, Args: withoutFlag(os.Args[2:], "socket")})
+	if err != nil {
+		return failure.New(failure.KindUsage, err)
+	}
+	fmt.Fprint(os.Stdout, response.Stdout)
+	fmt.Fprint(os.Stderr, response.Stderr)
+	if response.ExitCode != 0 {
+		return reportedError(response.ExitCode)
+	}
+	return nil
+}
+
+// withoutFlag returns the given arguments without the given flag and its value, e.g. "-socket /tmp/genz.sock" or
+// "--socket=/tmp/genz.sock".
+func withoutFlag(args []string, name string) []string {
+	var result []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(result, args[i:]...)
+		}
+		flagName := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		switch {
+		case arg != flagName && flagName == name:
+			i++ // The value is the next argument.
+		case arg != flagName && strings.HasPrefix(flagName, name+"="):
+		default:
+			result = append(result, arg)
+		}
+	}
+	return result
+}
@@ -0,0 +1,173 @@
+package genz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/daemon"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/logging"
+	"github.com/leorolland/genz/internal/utils"
+)
+
+const (
+	daemonUsage = `Usage of genz daemon:
+	genz daemon [flags] # Serves the generations requested with genz client until interrupted
+Keeps the type-checked packages in memory between the generations requested with genz client, e.g. by an editor or a
+watch tool, and loads again only the packages whose files changed since. The requests are run one at a time.
+Flags:`
+)
+
+type daemonCommand struct {
+}
+
+var (
+	daemonCmd    = flag.NewFlagSet("daemon", flag.ExitOnError)
+	daemonSocket = daemonCmd.String("socket", daemon.DefaultSocket(), "unix socket to listen on; default $"+daemon.SocketEnv+", or genz.sock in $XDG_RUNTIME_DIR or in a genz-<uid> temporary directory")
+)
+
+func init() {
+	daemonCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", daemonUsage)
+		daemonCmd.PrintDefaults()
+	}
+	command.RegisterCommand("daemon", daemonCommand{})
+}
+
+func (d daemonCommand) FlagSet() *flag.FlagSet {
+	return daemonCmd
+}
+
+func (d daemonCommand) ValidateArgs() error {
+	if daemonCmd.NArg() > 0 {
+		daemonCmd.Usage()
+		return fmt.Errorf("unexpected arguments %v", daemonCmd.Args())
+	}
+	return nil
+}
+
+func (d daemonCommand) Run() error {
+	listener, err := daemon.Listen(*daemonSocket)
+	if err != nil {
+		return failure.New(failure.KindUsage, err)
+	}
+	// The packages are kept loaded between the requests, until they change.
+	sharedLoader = utils.NewLoader()
+	defer func() { sharedLoader = nil }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	logging.Info("listening", "socket", *daemonSocket)
+	return daemon.Serve(ctx, listener, serveRequest)
+}
+
+// serveRequest runs the generation request as genz generate would from the directory of the client, and returns what
+// it printed and its exit code. The standard input is empty: the client does not forward it.
+func serveRequest(request daemon.Request) (response daemon.Response) {
+	start := time.Now()
+	logging.Info("running", "dir", request.Dir, "args", request.Args)
+	var stdout, stderr bytes.Buffer
+	restore, err := redirectStdio(&stdout, &stderr)
+	if err != nil {
+		return daemon.Response{Stderr: fmt.Sprintf("genz: error: %v\n", err), ExitCode: int(failure.KindOther)}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			logging.Error(fmt.Sprintf("panic: %v", r))
+			response.ExitCode = int(failure.KindOther)
+		}
+		restore()
+		// The logs of the daemon are configured by its own flags again.
+		_ = addLogFlags(daemonCmd).configure()
+		response.Stdout, response.Stderr = stdout.String(), stderr.String()
+		logging.Info("ran", "dir", request.Dir, "exit-code", response.ExitCode, "duration", time.Since(start))
+	}()
+
+	if err := runRequest(request); err != nil {
+		response.ExitCode = Report(err)
+	}
+	return response
+}
+
+// runRequest parses the arguments of the request with the flags of genz generate, without exiting the daemon on an
+// invalid flag, then runs it from the directory of the request.
+func runRequest(request daemon.Request) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(request.Dir); err != nil {
+		return failure.New(failure.KindUsage, err)
+	}
+	defer os.Chdir(wd)
+	sharedLoader.Refresh()
+
+	addLogFlags(generateCmd)
+	flagSet := generateFlags("genz", flag.ContinueOnError)
+	flagSet.SetOutput(os.Stderr)
+	flagSet.Usage = generateCmd.Usage
+	resetFlags(generateCmd)
+	if err := flagSet.Parse(request.Args); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return reportedError(failure.KindUsage) // Reported by the flag set.
+	}
+	if *watchMode {
+		return failure.New(failure.KindUsage, fmt.Errorf("-watch cannot be used with genz client"))
+	}
+
+	// The header of the outputs lists the arguments of the request, see commandLine.
+	args := os.Args
+	os.Args = append([]string{"genz"}, request.Args...)
+	defer func() { os.Args = args }()
+	resetFlags(generateCmd)
+	return execute(command.RootCommand(), request.Args)
+}
+
+// redirectStdio redirects the standard input, output and error of the process, and the logs, until the returned
+// function is called: the input is empty, and the output and the error are copied into the given buffers.
+func redirectStdio(stdout, stderr *bytes.Buffer) (func(), error) {
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		return nil, err
+	}
+	outReader, outWriter, err := os.Pipe()
+	if err != nil {
+		stdin.Close()
+		return nil, err
+	}
+	errReader, errWriter, err := os.Pipe()
+	if err != nil {
+		stdin.Close()
+		outReader.Close()
+		outWriter.Close()
+		return nil, err
+	}
+	copied := make(chan struct{}, 2)
+	go func() { _, _ = io.Copy(stdout, outReader); copied <- struct{}{} }()
+	go func() { _, _ = io.Copy(stderr, errReader); copied <- struct{}{} }()
+
+	previousIn, previousOut, previousErr := os.Stdin, os.Stdout, os.Stderr
+	os.Stdin, os.Stdout, os.Stderr = stdin, outWriter, errWriter
+	previousLogs := logging.SetOutput(errWriter)
+	return func() {
+		os.Stdin, os.Stdout, os.Stderr = previousIn, previousOut, previousErr
+		logging.SetOutput(previousLogs)
+		outWriter.Close()
+		errWriter.Close()
+		<-copied
+		<-copied
+		stdin.Close()
+		outReader.Close()
+		errReader.Close()
+	}, nil
+}
@@ -0,0 +1,191 @@
+package genz
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/inspect"
+	"github.com/leorolland/genz/internal/parser"
+	"github.com/leorolland/genz/internal/render"
+	"github.com/leorolland/genz/pkg/models"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	debugUsage = `Usage of genz debug:
+	genz debug [flags] -type T [-template foo.tmpl] [directory]
+Prints the model given to the template for the type, renders the template, showing the template lines of a failing
+action, then evaluates the expressions read from the standard input, e.g. ".Type.Name" or
+"{{ range .Attributes }}{{ .Name }} {{ end }}". Commands:
+	:model   prints the model again
+	:render  reads the template again and renders it
+	:quit    exits (or end of input)
+Flags:`
+)
+
+type debugCommand struct {
+}
+
+var (
+	debugCmd               = flag.NewFlagSet("debug", flag.ExitOnError)
+	debugTypeName          = debugCmd.String("type", "", "name of the type to parse; must be set")
+	debugTemplate          = debugCmd.String("template", "", "go-template local file, URL or module file (e.g. github.com/org/templates/builder.tmpl@v1.2.0); with -template-dir, the entrypoint in the directory (default main.tmpl)")
+	debugTemplateDir       = debugCmd.String("template-dir", "", "directory of go-templates sharing their {{ define }} blocks, local or of a module (e.g. github.com/org/templates/api@v1.2.0)")
+	debugBuildTags         = debugCmd.String("tags", "", "comma-separated list of build tags to apply")
+	debugBuildFlags        = debugCmd.String("build-flags", "", "space-separated list of flags of the build system, e.g. -mod=vendor")
+	debugTests             = debugCmd.Bool("tests", false, "include the _test.go files of the package, to parse the types declared in tests")
+	debugAllowErrors       = debugCmd.Bool("allow-errors", false, "generate from a package having compile errors, e.g. referencing the code not generated yet")
+	debugFlattenEmbedded   = debugCmd.Bool("flatten-embedded", false, "replace embedded structs by their promoted attributes")
+	debugRecursive         = debugCmd.Bool("recursive", false, "parse the struct types of the attributes declared in the same module")
+	debugFunctions         = debugCmd.Bool("functions", false, "parse the top-level functions of the package")
+	debugValues            = debugCmd.Bool("values", false, "parse the exported constants and variables of the package")
+	debugPositions         = debugCmd.Bool("positions", false, "fill the source positions of the types, attributes and methods")
+	debugIncludeTags       = stringList{}
+	debugExcludeTags       = stringList{}
+	debugFuncs             = stringList{}
+	debugVars              = keyValues{}
+	debugExcludeUnexported = debugCmd.Bool("exclude-unexported", false, "remove the unexported attributes")
+)
+
+func init() {
+	debugCmd.Var(&debugIncludeTags, "include-tag", "keep only the attributes having one of these comma-separated tags: a key (e.g. json) or a key and a name (e.g. db:id)")
+	debugCmd.Var(&debugExcludeTags, "exclude-tag", "remove the attributes having one of these comma-separated tags, e.g. 'genz:\"-\"'; see -include-tag")
+	debugCmd.Var(debugVars, "var", "variable given to the template as .Vars, as key=value; can be repeated")
+	debugCmd.Var(&debugFuncs, "funcs", "comma-separated files of custom template functions, see genz -h")
+	debugCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", debugUsage)
+		debugCmd.PrintDefaults()
+	}
+	command.RegisterCommand("debug", debugCommand{})
+}
+
+func (d debugCommand) FlagSet() *flag.FlagSet {
+	return debugCmd
+}
+
+func (d debugCommand) ValidateArgs() error {
+	if len(*debugTypeName) == 0 && !*debugFunctions && !*debugValues {
+		debugCmd.Usage()
+		return fmt.Errorf("missing 'type' argument")
+	}
+	return nil
+}
+
+func (d debugCommand) Run() error {
+	target := config.Target{
+		Template:    *debugTemplate,
+		Tests:       *debugTests,
+		AllowErrors: *debugAllowErrors,
+		TemplateDir: *debugTemplateDir,
+		Funcs:       debugFuncs,
+		Inputs:      debugCmd.Args(),
+	}
+	if len(*debugBuildTags) > 0 {
+		target.Tags = strings.Split(*debugBuildTags, ",")
+	}
+	target.BuildFlags = strings.Fields(*debugBuildFlags)
+	if len(target.Inputs) == 0 {
+		target.Inputs = []string{"."}
+	}
+	pkg, err := loadPackage(target)
+	if err != nil {
+		return err
+	}
+	parseWithOptions := parser.WithOptions(parser.Options{
+		FlattenEmbedded:   *debugFlattenEmbedded,
+		Recursive:         *debugRecursive,
+		Functions:         *debugFunctions,
+		Values:            *debugValues,
+		Positions:         *debugPositions,
+		IncludeTags:       debugIncludeTags,
+		ExcludeTags:       debugExcludeTags,
+		ExcludeUnexported: *debugExcludeUnexported,
+	})
+	parse := func(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+		parsedElement, err := parseWithOptions(pkg, typeName)
+		if len(debugVars) > 0 {
+			parsedElement.Vars = debugVars
+		}
+		return parsedElement, err
+	}
+	element, err := parse(pkg, *debugTypeName)
+	if err != nil {
+		return err
+	}
+	if err := inspect.Encode(os.Stdout, []models.ParsedElement{element}, "yaml"); err != nil {
+		return err
+	}
+	tmpl := debugRender(target, pkg, parse)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for fmt.Fprint(os.Stderr, "genz> "); scanner.Scan(); fmt.Fprint(os.Stderr, "genz> ") {
+		switch line := strings.TrimSpace(scanner.Text()); line {
+		case "":
+		case ":quit":
+			return nil
+		case ":model":
+			if err := inspect.Encode(os.Stdout, []models.ParsedElement{element}, "yaml"); err != nil {
+				return err
+			}
+		case ":render":
+			tmpl = debugRender(target, pkg, parse)
+		default:
+			result, err := generator.Evaluate(tmpl, line, element)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				continue
+			}
+			fmt.Println(result)
+		}
+	}
+	fmt.Fprintln(os.Stderr)
+	return scanner.Err()
+}
+
+// debugRender reads and renders the template of the target, if any, and prints the result, or the error with the
+// failing lines of the template. It returns the parsed template, so that the expressions can use its {{ define }}
+// blocks, or nil.
+func debugRender(target config.Target, pkg *packages.Package, parse func(*packages.Package, string) (models.ParsedElement, error)) *template.Template {
+	if target.Template == "" && target.TemplateDir == "" {
+		return nil
+	}
+	var (
+		content  []byte
+		partials []generator.Partial
+		err      error
+	)
+	if target.TemplateDir != "" {
+		content, partials, err = render.ReadTemplateDir(target.TemplateDir, target.Template)
+	} else {
+		content, err = render.ReadTemplate(target.Template)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return nil
+	}
+	funcs, err := generator.LoadFuncs(target.Funcs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return nil
+	}
+	tmpl, err := generator.ParseWithFuncs(string(content), funcs, partials...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", render.TemplateFileError(target, render.TemplateFile(target), err))
+		return nil
+	}
+	buf, err := generator.GenerateWithFuncs(pkg, string(content), *debugTypeName, parse, funcs, partials...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", render.TemplateFileError(target, render.TemplateFile(target), err))
+		return tmpl
+	}
+	fmt.Print(buf.String())
+	return tmpl
+}
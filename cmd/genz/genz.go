@@ -1,21 +1,26 @@
 package genz
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/leorolland/genz/internal/parser"
-
+	"github.com/leorolland/genz/internal/builtin"
 	"github.com/leorolland/genz/internal/command"
-	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/logging"
+	"github.com/leorolland/genz/internal/remote"
+	"github.com/leorolland/genz/internal/render"
 	"github.com/leorolland/genz/internal/utils"
+	"github.com/leorolland/genz/internal/watch"
 )
 
 type generateCommand struct {
@@ -25,20 +30,80 @@ const (
 	generateCommandUsage = `Usage of genz:
 	genz [flags] -type T -template foo.tmpl [directory]
 	genz [flags] -type T -template foo.tmpl files... # Must be a single package
+	genz [flags] -type-regex '.*Event$' -template foo.tmpl ./... # Every matching package
+	genz [flags] -type T -template foo.tmpl - # Reads a Go file from stdin, writes to stdout
+	genz [flags] # Runs the targets of the closest genz.yaml
 Flags:`
 )
 
 var (
-	generateCmd      = flag.NewFlagSet("", flag.ExitOnError)
-	typeName         = generateCmd.String("type", "", "name of the type to parse")
-	templateLocation = generateCmd.String("template", "", "go-template local or remote file")
-	output           = generateCmd.String("output", "", "output file name; default srcdir/<type>.gen.go")
-	buildTags        = generateCmd.String("tags", "", "comma-separated list of build tags to apply")
+	generateCmd       = flag.NewFlagSet("", flag.ExitOnError)
+	typeNames         = stringList{}
+	typeRegex         = generateCmd.String("type-regex", "", "regular expression selecting the types to parse, in addition to -type")
+	templateLocation  = generateCmd.String("template", "", "go-template local file, URL or module file (e.g. github.com/org/templates/builder.tmpl@v1.2.0); with -template-dir, the entrypoint in the directory (default main.tmpl)")
+	templateDir       = generateCmd.String("template-dir", "", "directory of go-templates sharing their {{ define }} blocks, local or of a module (e.g. github.com/org/templates/api@v1.2.0)")
+	output            = generateCmd.String("output", "", "output file name, of any extension (only .go files are gofmt-ed), or - for stdout, or a template rendered for each type (e.g. '{{ .Type | snakecase }}_gen.go'); default srcdir/<type>.gen.go")
+	buildTags         = generateCmd.String("tags", "", "comma-separated list of build tags to apply")
+	buildFlags        = generateCmd.String("build-flags", "", "space-separated list of flags of the build system, e.g. -mod=vendor")
+	tests             = generateCmd.Bool("tests", false, "include the _test.go files of the package, to parse the types declared in tests")
+	allowErrors       = generateCmd.Bool("allow-errors", false, "generate from a package having compile errors, e.g. referencing the code not generated yet")
+	flattenEmbedded   = generateCmd.Bool("flatten-embedded", false, "replace embedded structs by their promoted attributes")
+	recursive         = generateCmd.Bool("recursive", false, "parse the struct types of the attributes declared in the same module")
+	watchMode         = generateCmd.Bool("watch", false, "watch the package and the template for changes and regenerate the output")
+	configFile        = generateCmd.String("config", "", "configuration file declaring the targets; default closest genz.yaml")
+	combine           = generateCmd.Bool("combine", false, "render all the types into a single output file")
+	functions         = generateCmd.Bool("functions", false, "parse the top-level functions of the package; -type is then optional")
+	values            = generateCmd.Bool("values", false, "parse the exported constants and variables of the package; -type is then optional")
+	positions         = generateCmd.Bool("positions", false, "fill the source positions of the types, attributes and methods (.File, .Line, .Column)")
+	implementations   = generateCmd.Bool("implementations", false, "list the structs of the package implementing an interface into .Implementations")
+	includeTags       = stringList{}
+	excludeTags       = stringList{}
+	funcs             = stringList{}
+	interfaces        = stringList{}
+	vars              = keyValues{}
+	excludeUnexported = generateCmd.Bool("exclude-unexported", false, "remove the unexported attributes")
+	withTests         = generateCmd.Bool("with-tests", false, "also render the test template (e.g. foo.tmpl_test) into the _test.go file of each output")
+	dryRun            = generateCmd.Bool("dry-run", false, "do not write the outputs, and fail if some of them are out of date")
+	showDiff          = generateCmd.Bool("diff", false, "print the unified diff of the outputs which are out of date, and write them unless -dry-run")
+	cacheDir          = generateCmd.String("cache", "", "directory of the incremental generation cache (e.g. .genz-cache); skip the targets whose sources and templates did not change")
+	raw               = generateCmd.Bool("raw", false, "write the rendered template as is, without gofmt nor imports fix")
+	noImportsFix      = generateCmd.Bool("no-imports-fix", false, "keep the imports as rendered, instead of adding the missing ones and removing the unused ones")
+	header            = generateCmd.String("header", "", "template of the comment prepended to the Go outputs not marked as generated, given .Version and .Command, or none; default \"Code generated by genz {{ .Version }}. DO NOT EDIT.\" followed by the command")
+	suffix            = generateCmd.String("suffix", "", "suffix of the default output names, before the extension, e.g. _gen for <type>_gen.go or _gen_linux; default .gen")
+	buildConstraint   = generateCmd.String("build", "", "//go:build constraint expression added to the Go outputs, e.g. 'linux && amd64'")
+	write             = generateCmd.String("write", "", "strategy of writing the outputs: overwrite (default), skip-if-exists to scaffold files edited by hand, or region to only rewrite the regions between the genz:begin and genz:end markers")
+	protect           = generateCmd.Bool("protect", false, "refuse to overwrite the existing outputs not marked as generated by a \"Code generated ... DO NOT EDIT.\" comment")
 )
 
+// stringList is a flag accepting a comma-separated list of values, that can also be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+func (l *stringList) reset() {
+	*l = nil
+}
+
 func init() {
 	log.SetFlags(0)
 	log.SetPrefix("genz: ")
+	generateCmd.Var(&typeNames, "type", "comma-separated list of type names or patterns (e.g. '*DTO'); must be set; two types, one of them qualified with its package (e.g. ./v1.User,./v2.User), are compared into .Left and .Right")
+	generateCmd.Var(&includeTags, "include-tag", "keep only the attributes having one of these comma-separated tags: a key (e.g. json) or a key and a name (e.g. db:id)")
+	generateCmd.Var(&excludeTags, "exclude-tag", "remove the attributes having one of these comma-separated tags, e.g. 'genz:\"-\"'; see -include-tag")
+	generateCmd.Var(vars, "var", "variable given to the template as .Vars, as key=value, e.g. -var table=users; can be repeated, and overrides the vars of genz.yaml")
+	generateCmd.Var(&interfaces, "interfaces", "comma-separated interfaces parsed into .Interfaces, e.g. to generate a facade combining them; qualified with the path or the directory of their package when declared in another one (e.g. io.Closer); -type is then optional")
+	generateCmd.Var(&funcs, "funcs", "comma-separated files of custom template functions: templates whose {{ define \"name\" }} blocks are functions, or Go plugins (.so) exporting a Funcs map")
 	generateCmd.Usage = func() {
 		fmt.Fprintf(os.Stderr, "%s\n", generateCommandUsage)
 		generateCmd.PrintDefaults()
@@ -48,15 +113,26 @@ func init() {
 }
 
 func (c generateCommand) ValidateArgs() error {
-	if len(*typeName) == 0 {
+	if len(*configFile) > 0 {
+		return nil
+	}
+	if len(typeNames) == 0 && len(*typeRegex) == 0 && !*functions && !*values && len(interfaces) == 0 && len(*templateLocation) == 0 && len(*templateDir) == 0 {
+		if _, err := config.Find("."); err == nil {
+			return nil // The targets are declared in genz.yaml.
+		}
+	}
+	if len(typeNames) == 0 && len(*typeRegex) == 0 && !*functions && !*values && len(interfaces) == 0 {
 		generateCmd.Usage()
 		return fmt.Errorf("missing 'type' argument")
 	}
-	if len(*templateLocation) == 0 {
+	if *watchMode && (generateCmd.Arg(0) == config.Stdio || *output == config.Stdio) {
+		return fmt.Errorf("-watch cannot be used with the standard input or output")
+	}
+	if len(*templateLocation) == 0 && len(*templateDir) == 0 {
 		generateCmd.Usage()
 		return fmt.Errorf("missing 'template' argument")
 	}
-	return nil
+	return config.CheckWrite(*write)
 }
 
 func (c generateCommand) FlagSet() *flag.FlagSet {
@@ -64,69 +140,194 @@ func (c generateCommand) FlagSet() *flag.FlagSet {
 }
 
 func (c generateCommand) Run() error {
-	var tags []string
-	if len(*buildTags) > 0 {
-		tags = strings.Split(*buildTags, ",")
+	targets, err := targetsFromArgs()
+	if err != nil {
+		return err
 	}
 
-	var template []byte
-	if url, _ := url.ParseRequestURI(*templateLocation); url != nil {
-		response, err := http.Get(*templateLocation)
+	if !*watchMode {
+		_, err := runTargets(targets)
+		return err
+	}
+
+	// The written files are ignored by the watch, otherwise each generation would trigger the next one. The files first
+	// written by a later generation, e.g. once the sources compile again, are ignored from then on.
+	ignored, err := runTargets(targets)
+	if err != nil {
+		logging.Error("generation failed", "error", err)
+	}
+	var watched []string
+	for _, target := range targets {
+		inputs, err := watchedInputs(target)
 		if err != nil {
-			return fmt.Errorf("failed to make a request to %s: %v", *templateLocation, err)
+			return err
 		}
-		body, err := io.ReadAll(response.Body)
-		if err != nil {
-			return fmt.Errorf("could not read body of remote template %s: %v", *templateLocation, err)
+		watched = append(watched, inputs...)
+		if target.TemplateDir != "" && !remote.IsModule(target.TemplateDir) {
+			files, err := render.TemplateFiles(target.TemplateDir)
+			if err != nil {
+				return err
+			}
+			watched = append(watched, files...)
+		} else if _, isBuiltin := builtin.FromLocation(target.Template); target.TemplateDir == "" && target.Template != "" && !isBuiltin && !utils.IsRemote(target.Template) && !remote.IsModule(target.Template) {
+			watched = append(watched, target.Template)
 		}
-		template = body
-	} else {
-		file, err := os.ReadFile(*templateLocation)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	logging.Info("watching for changes", "files", strings.Join(watched, " "))
+	return watch.Watch(ctx, watched, ignored, func() ([]string, error) {
+		logging.Info("change detected, regenerating")
+		return runTargets(targets)
+	})
+}
+
+// watchedInputs returns the inputs of the target, with a package pattern (e.g. "./...") expanded into the directories
+// of the matching packages.
+func watchedInputs(target config.Target) ([]string, error) {
+	if len(target.Inputs) != 1 || !utils.IsPackagePattern(target.Inputs[0]) {
+		return target.Inputs, nil
+	}
+	pkgs, err := utils.LoadPackages(target.Inputs, render.LoadOptions(target))
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) != 0 {
+			dirs = append(dirs, filepath.Dir(pkg.GoFiles[0]))
+		}
+	}
+	return dirs, nil
+}
+
+// targetsFromArgs returns the targets to generate: either the single target described by the flags,
+// or the targets declared in the configuration file.
+func targetsFromArgs() ([]config.Target, error) {
+	if len(typeNames) == 0 && len(*typeRegex) == 0 && !*functions && !*values && len(interfaces) == 0 {
+		path := *configFile
+		if path == "" {
+			found, err := config.Find(".")
+			if err != nil {
+				return nil, failure.New(failure.KindUsage, err)
+			}
+			path = found
+		}
+		cfg, err := config.Load(path)
 		if err != nil {
-			return fmt.Errorf("failed to read template file %s: %v", *templateLocation, err)
+			return nil, failure.New(failure.KindUsage, err)
+		}
+		logging.Info("loaded the targets", "config", path, "count", len(cfg.Targets))
+		for i := range cfg.Targets {
+			cfg.Targets[i].DryRun = *dryRun
+			cfg.Targets[i].Diff = *showDiff
+			cfg.Targets[i].Cache = *cacheDir
+			cfg.Targets[i].Protect = cfg.Targets[i].Protect || *protect
+			if len(vars) > 0 && cfg.Targets[i].Vars == nil {
+				cfg.Targets[i].Vars = map[string]string{}
+			}
+			for key, value := range vars {
+				cfg.Targets[i].Vars[key] = value
+			}
 		}
-		template = file
+		return cfg.Targets, nil
 	}
 
-	// We accept either one directory or a list of files. Which do we have?
-	args := generateCmd.Args()
-	if len(args) == 0 {
+	target := config.Target{
+		Types:             typeNames,
+		Tests:             *tests,
+		AllowErrors:       *allowErrors,
+		TypeRegex:         *typeRegex,
+		Combine:           *combine,
+		Template:          *templateLocation,
+		TemplateDir:       *templateDir,
+		Output:            *output,
+		Inputs:            generateCmd.Args(),
+		FlattenEmbedded:   *flattenEmbedded,
+		Recursive:         *recursive,
+		Functions:         *functions,
+		Values:            *values,
+		Positions:         *positions,
+		Implementations:   *implementations,
+		IncludeTags:       includeTags,
+		ExcludeTags:       excludeTags,
+		ExcludeUnexported: *excludeUnexported,
+		Funcs:             funcs,
+		Interfaces:        interfaces,
+		Vars:              vars,
+		WithTests:         *withTests,
+		DryRun:            *dryRun,
+		Diff:              *showDiff,
+		Cache:             *cacheDir,
+		Raw:               *raw,
+		NoImportsFix:      *noImportsFix,
+		Header:            *header,
+		Suffix:            *suffix,
+		Build:             *buildConstraint,
+		Protect:           *protect,
+		Write:             *write,
+	}
+	if len(*buildTags) > 0 {
+		target.Tags = strings.Split(*buildTags, ",")
+	}
+	target.BuildFlags = strings.Fields(*buildFlags)
+	if len(target.Inputs) == 0 {
 		// Default: process whole package in current directory.
-		args = []string{"."}
-	}
-	buf, err := generator.Generate(
-		utils.LoadPackage(args, tags),
-		string(template),
-		*typeName,
-		parser.Parser,
-	)
-	if err != nil {
-		return err
+		target.Inputs = []string{"."}
 	}
+	return []config.Target{target}, nil
+}
 
-	src := generator.Format(buf)
+// generateFlags returns a flag set defining the flags of genz generate, sharing their values, e.g. to parse the
+// arguments forwarded by genz client without exiting on an error.
+func generateFlags(name string, errorHandling flag.ErrorHandling) *flag.FlagSet {
+	flagSet := flag.NewFlagSet(name, errorHandling)
+	generateCmd.VisitAll(func(f *flag.Flag) {
+		flagSet.Var(f.Value, f.Name, f.Usage)
+	})
+	return flagSet
+}
 
-	var dir string
-	if len(args) == 1 && utils.IsDirectory(args[0]) {
-		dir = args[0]
-	} else {
-		if len(tags) != 0 {
-			return fmt.Errorf("-tags option applies only to directories, not when files are specified")
+// resetFlags sets the flags of the given set back to their default values, e.g. between the requests of genz daemon
+// parsing them again.
+func resetFlags(flagSet *flag.FlagSet) {
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if list, ok := f.Value.(interface{ reset() }); ok {
+			list.reset()
+			return
 		}
-		dir = filepath.Dir(args[0])
-	}
+		_ = f.Value.Set(f.DefValue)
+	})
+}
 
-	// Write to file.
-	outputName := *output
-	if outputName == "" {
-		baseName := fmt.Sprintf("%s.gen.go", *typeName)
-		outputName = filepath.Join(dir, strings.ToLower(baseName))
+// runTargets generates every target and returns the written files.
+// A failing target does not prevent the others from being generated.
+// The packages of the targets are loaded at once, when they share their options, see utils.Loader, or kept loaded by
+// genz daemon. The targets using a cache are left out, so that they load nothing when they are up to date.
+func runTargets(targets []config.Target) ([]string, error) {
+	if sharedLoader == nil && len(targets) > 1 {
+		sharedLoader = utils.NewLoader()
+		defer func() { sharedLoader = nil }()
 	}
-
-	if err := os.WriteFile(outputName, src, 0644); err != nil {
-		return fmt.Errorf("writing output: %s", err)
+	for _, target := range targets {
+		if len(target.Inputs) == 1 && target.Cache == "" {
+			sharedLoader.Add(target.Inputs[0], render.LoadOptions(target))
+		}
 	}
-
-	log.Printf("wrote %s (%d bytes)", outputName, len(src))
-	return nil
+	var written []string
+	var errs error
+	for _, target := range targets {
+		logging.Debug("running the target", "target", target)
+		start := time.Now()
+		outputNames, err := runTarget(target)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", target, err))
+		} else {
+			logging.Info("generated", "target", target, "files", len(outputNames), "duration", time.Since(start))
+		}
+		// The next targets parse the code generated by this one.
+		sharedLoader.Invalidate(outputNames)
+		written = append(written, outputNames...)
+	}
+	return written, errs
 }
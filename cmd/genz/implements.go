@@ -0,0 +1,165 @@
+package genz
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/parser"
+	"github.com/leorolland/genz/internal/render"
+	"github.com/leorolland/genz/internal/utils"
+	"github.com/leorolland/genz/pkg/models"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	implementsUsage = `Usage of genz implements:
+	genz implements [flags] -interface Repository [directory]
+	genz implements [flags] -interface Repository ./... # Every package of the module
+	genz implements [flags] -interface Repository -template registry.tmpl ./...
+Lists the structs implementing the interface, declared in the loaded packages. With a template, renders it for the
+interface, the implementations being given to the template in .Implementations, e.g. to generate a registry.
+Flags:`
+)
+
+type implementsCommand struct {
+}
+
+var (
+	implementsCmd             = flag.NewFlagSet("implements", flag.ExitOnError)
+	implementsInterface       = implementsCmd.String("interface", "", "name of the interface, optionally qualified with its package path (e.g. example.com/store.Repository); must be set")
+	implementsTemplate        = implementsCmd.String("template", "", "go-template local file, URL or module file (e.g. github.com/org/templates/builder.tmpl@v1.2.0); with -template-dir, the entrypoint in the directory (default main.tmpl)")
+	implementsTemplateDir     = implementsCmd.String("template-dir", "", "directory of go-templates sharing their {{ define }} blocks, local or of a module (e.g. github.com/org/templates/api@v1.2.0)")
+	implementsOutput          = implementsCmd.String("output", "", "output file name, or - for stdout; default <interface directory>/<interface>_implementations.gen.go")
+	implementsBuildTags       = implementsCmd.String("tags", "", "comma-separated list of build tags to apply")
+	implementsBuildFlags      = implementsCmd.String("build-flags", "", "space-separated list of flags of the build system, e.g. -mod=vendor")
+	implementsTests           = implementsCmd.Bool("tests", false, "include the _test.go files of the package, to parse the types declared in tests")
+	implementsAllowErrors     = implementsCmd.Bool("allow-errors", false, "generate from a package having compile errors, e.g. referencing the code not generated yet")
+	implementsFlattenEmbedded = implementsCmd.Bool("flatten-embedded", false, "replace embedded structs by their promoted attributes")
+	implementsRecursive       = implementsCmd.Bool("recursive", false, "parse the struct types of the attributes declared in the same module")
+	implementsPositions       = implementsCmd.Bool("positions", false, "fill the source positions of the types, attributes and methods")
+	implementsDryRun          = implementsCmd.Bool("dry-run", false, "do not write the output, and fail if it is out of date")
+	implementsDiff            = implementsCmd.Bool("diff", false, "print the unified diff of the output if it is out of date, and write it unless -dry-run")
+)
+
+func init() {
+	implementsCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", implementsUsage)
+		implementsCmd.PrintDefaults()
+	}
+	command.RegisterCommand("implements", implementsCommand{})
+}
+
+func (i implementsCommand) FlagSet() *flag.FlagSet {
+	return implementsCmd
+}
+
+func (i implementsCommand) ValidateArgs() error {
+	if len(*implementsInterface) == 0 {
+		implementsCmd.Usage()
+		return fmt.Errorf("missing 'interface' argument")
+	}
+	return nil
+}
+
+// Run loads the packages, looks up the interface and its implementations, then lists them or renders the template.
+func (i implementsCommand) Run() error {
+	target := config.Target{
+		Template:        *implementsTemplate,
+		Tests:           *implementsTests,
+		AllowErrors:     *implementsAllowErrors,
+		TemplateDir:     *implementsTemplateDir,
+		Output:          *implementsOutput,
+		Inputs:          implementsCmd.Args(),
+		FlattenEmbedded: *implementsFlattenEmbedded,
+		Recursive:       *implementsRecursive,
+		Positions:       *implementsPositions,
+		DryRun:          *implementsDryRun,
+		Diff:            *implementsDiff,
+	}
+	if len(*implementsBuildTags) > 0 {
+		target.Tags = strings.Split(*implementsBuildTags, ",")
+	}
+	target.BuildFlags = strings.Fields(*implementsBuildFlags)
+	if len(target.Inputs) == 0 {
+		target.Inputs = []string{"."}
+	}
+
+	pkgs, err := utils.LoadPackages(target.Inputs, render.LoadOptions(target))
+	if err != nil {
+		return err
+	}
+	pkg, interfaceName, err := parser.LookupInterface(pkgs, *implementsInterface)
+	if err != nil {
+		return err
+	}
+	options := parser.Options{
+		FlattenEmbedded:   target.FlattenEmbedded,
+		Recursive:         target.Recursive,
+		Positions:         target.Positions,
+		IncludeTags:       target.IncludeTags,
+		ExcludeTags:       target.ExcludeTags,
+		ExcludeUnexported: target.ExcludeUnexported,
+	}
+	implementations, err := parser.ParseImplementations(pkgs, pkg, interfaceName, options)
+	if err != nil {
+		return err
+	}
+	if target.Template == "" && target.TemplateDir == "" {
+		for _, implementation := range implementations {
+			pointer := ""
+			if implementation.PointerReceiver {
+				pointer = "*"
+			}
+			fmt.Printf("%s%s.%s\n", pointer, implementation.Type.PkgPath, implementation.Type.InternalName)
+		}
+		return nil
+	}
+
+	written, err := renderImplementations(target, pkg, interfaceName, implementations, options)
+	if err == nil && target.DryRun && len(written) != 0 {
+		err = fmt.Errorf("generated file out of date: %s", strings.Join(written, ", "))
+	}
+	return err
+}
+
+// renderImplementations renders the template of the target for the given interface of the given package,
+// with its implementations. It returns the written files, see writeOutput.
+func renderImplementations(
+	target config.Target,
+	pkg *packages.Package,
+	interfaceName string,
+	implementations []models.Implementation,
+	options parser.Options,
+) ([]string, error) {
+	var (
+		template []byte
+		partials []generator.Partial
+		err      error
+	)
+	if target.TemplateDir != "" {
+		template, partials, err = render.ReadTemplateDir(target.TemplateDir, target.Template)
+	} else {
+		template, err = render.ReadTemplate(target.Template)
+	}
+	if err != nil {
+		return nil, err
+	}
+	outputName := target.Output
+	if outputName == "" {
+		outputName = filepath.Join(relativeDir(pkg.GoFiles[0]), strings.ToLower(interfaceName)+"_implementations.gen.go")
+	}
+	parseWithOptions := parser.WithOptions(options)
+	parse := func(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+		parsedElement, err := parseWithOptions(pkg, typeName)
+		parsedElement.Implementations = implementations
+		return parsedElement, err
+	}
+	return renderTemplate(target, pkg, render.TemplateFile(target), string(template), partials, []string{interfaceName}, []string{outputName}, parse)
+}
@@ -0,0 +1,121 @@
+package genz
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/inspect"
+	"github.com/leorolland/genz/internal/parser"
+	"github.com/leorolland/genz/pkg/models"
+)
+
+const (
+	inspectUsage = `Usage of genz inspect:
+	genz inspect [flags] -type T [directory]
+	genz inspect [flags] -type T files... # Must be a single package
+	genz inspect [flags] -type T - # Reads a Go file from stdin
+Prints the model given to the templates for each type, e.g. to debug a template or to use the parser from other tools.
+Flags:`
+)
+
+type inspectCommand struct {
+}
+
+var (
+	inspectCmd               = flag.NewFlagSet("inspect", flag.ExitOnError)
+	inspectTypeNames         = stringList{}
+	inspectTypeRegex         = inspectCmd.String("type-regex", "", "regular expression selecting the types to parse, in addition to -type")
+	inspectFormat            = inspectCmd.String("format", "json", "output format: json or yaml")
+	inspectBuildTags         = inspectCmd.String("tags", "", "comma-separated list of build tags to apply")
+	inspectBuildFlags        = inspectCmd.String("build-flags", "", "space-separated list of flags of the build system, e.g. -mod=vendor")
+	inspectTests             = inspectCmd.Bool("tests", false, "include the _test.go files of the package, to parse the types declared in tests")
+	inspectAllowErrors       = inspectCmd.Bool("allow-errors", false, "generate from a package having compile errors, e.g. referencing the code not generated yet")
+	inspectFlattenEmbedded   = inspectCmd.Bool("flatten-embedded", false, "replace embedded structs by their promoted attributes")
+	inspectRecursive         = inspectCmd.Bool("recursive", false, "parse the struct types of the attributes declared in the same module")
+	inspectFunctions         = inspectCmd.Bool("functions", false, "parse the top-level functions of the package")
+	inspectValues            = inspectCmd.Bool("values", false, "parse the exported constants and variables of the package")
+	inspectPositions         = inspectCmd.Bool("positions", false, "fill the source positions of the types, attributes and methods")
+	inspectIncludeTags       = stringList{}
+	inspectExcludeTags       = stringList{}
+	inspectExcludeUnexported = inspectCmd.Bool("exclude-unexported", false, "remove the unexported attributes")
+)
+
+func init() {
+	inspectCmd.Var(&inspectTypeNames, "type", "comma-separated list of type names or patterns (e.g. '*DTO'); optional with -functions or -values")
+	inspectCmd.Var(&inspectIncludeTags, "include-tag", "keep only the attributes having one of these comma-separated tags: a key (e.g. json) or a key and a name (e.g. db:id)")
+	inspectCmd.Var(&inspectExcludeTags, "exclude-tag", "remove the attributes having one of these comma-separated tags, e.g. 'genz:\"-\"'; see -include-tag")
+	inspectCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", inspectUsage)
+		inspectCmd.PrintDefaults()
+	}
+	command.RegisterCommand("inspect", inspectCommand{})
+}
+
+func (i inspectCommand) FlagSet() *flag.FlagSet {
+	return inspectCmd
+}
+
+func (i inspectCommand) ValidateArgs() error {
+	if len(inspectTypeNames) == 0 && len(*inspectTypeRegex) == 0 && !*inspectFunctions && !*inspectValues {
+		inspectCmd.Usage()
+		return fmt.Errorf("missing 'type' argument")
+	}
+	for _, format := range inspect.Formats {
+		if *inspectFormat == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %s, expected one of %v", *inspectFormat, inspect.Formats)
+}
+
+// Run parses the selected types as genz generate does, and prints them on the standard output.
+func (i inspectCommand) Run() error {
+	target := config.Target{
+		Types:       inspectTypeNames,
+		Tests:       *inspectTests,
+		AllowErrors: *inspectAllowErrors,
+		TypeRegex:   *inspectTypeRegex,
+		Inputs:      inspectCmd.Args(),
+	}
+	if len(*inspectBuildTags) > 0 {
+		target.Tags = strings.Split(*inspectBuildTags, ",")
+	}
+	target.BuildFlags = strings.Fields(*inspectBuildFlags)
+	if len(target.Inputs) == 0 {
+		target.Inputs = []string{"."}
+	}
+
+	pkg, err := loadPackage(target)
+	if err != nil {
+		return err
+	}
+	typeNames := []string{""} // Package only: its functions or its values are printed once.
+	if len(target.Types) != 0 || target.TypeRegex != "" {
+		typeNames, err = parser.SelectTypes(pkg, target.Types, target.TypeRegex)
+		if err != nil {
+			return err
+		}
+	}
+	parse := parser.WithOptions(parser.Options{
+		FlattenEmbedded:   *inspectFlattenEmbedded,
+		Recursive:         *inspectRecursive,
+		Functions:         *inspectFunctions,
+		Values:            *inspectValues,
+		Positions:         *inspectPositions,
+		IncludeTags:       inspectIncludeTags,
+		ExcludeTags:       inspectExcludeTags,
+		ExcludeUnexported: *inspectExcludeUnexported,
+	})
+	elements := make([]models.ParsedElement, len(typeNames))
+	for i, typeName := range typeNames {
+		elements[i], err = parse(pkg, typeName)
+		if err != nil {
+			return err
+		}
+	}
+	return inspect.Encode(os.Stdout, elements, *inspectFormat)
+}
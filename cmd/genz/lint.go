@@ -0,0 +1,123 @@
+package genz
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/lint"
+	"github.com/leorolland/genz/internal/render"
+)
+
+const (
+	lintUsage = `Usage of genz lint-template:
+	genz lint-template [flags] foo.tmpl... # Checks each template
+	genz lint-template [flags] -template-dir dir [-template main.tmpl] # Checks the templates of the directory together
+Checks the templates against the models given to them, without rendering them: the unknown fields and functions, the
+templates invoked without being defined, and, as warnings, the fields read through a pointer which may be nil (e.g.
+.Target.Name outside of {{ with .Target }}) and the slice elements read without checking the slice. Fails if there are
+errors. With -format json, the diagnostics are printed as {"diagnostics":[{"severity":"error","rule":"unknown-field",
+"file":"foo.tmpl","line":3,"column":9,"message":"..."}]}.
+Flags:`
+)
+
+type lintCommand struct {
+}
+
+var (
+	lintCmd         = flag.NewFlagSet("lint-template", flag.ExitOnError)
+	lintTemplate    = lintCmd.String("template", "", "with -template-dir, the entrypoint in the directory (default main.tmpl)")
+	lintTemplateDir = lintCmd.String("template-dir", "", "directory of go-templates sharing their {{ define }} blocks, local or of a module (e.g. github.com/org/templates/api@v1.2.0)")
+	lintFormat      = lintCmd.String("format", "text", "output format: text or json")
+	lintFuncs       = stringList{}
+)
+
+func init() {
+	lintCmd.Var(&lintFuncs, "funcs", "comma-separated files of custom template functions, see genz -h")
+	lintCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", lintUsage)
+		lintCmd.PrintDefaults()
+	}
+	command.RegisterCommand("lint-template", lintCommand{})
+}
+
+func (l lintCommand) FlagSet() *flag.FlagSet {
+	return lintCmd
+}
+
+func (l lintCommand) ValidateArgs() error {
+	if *lintTemplateDir == "" && lintCmd.NArg() == 0 {
+		lintCmd.Usage()
+		return fmt.Errorf("missing template")
+	}
+	if *lintTemplateDir != "" && lintCmd.NArg() > 0 {
+		return fmt.Errorf("-template-dir cannot be set with template files %v", lintCmd.Args())
+	}
+	if *lintFormat != "text" && *lintFormat != "json" {
+		return fmt.Errorf("unknown format %s, expected text or json", *lintFormat)
+	}
+	return nil
+}
+
+// Run checks the templates, and prints their diagnostics on the standard output, located in the template files.
+func (l lintCommand) Run() error {
+	funcs, err := generator.LoadFuncs(lintFuncs)
+	if err != nil {
+		return failure.New(failure.KindTemplate, err)
+	}
+	var diagnostics []lint.Diagnostic
+	if *lintTemplateDir != "" {
+		template, partials, err := render.ReadTemplateDir(*lintTemplateDir, *lintTemplate)
+		if err != nil {
+			return failure.New(failure.KindTemplate, err)
+		}
+		entrypoint := *lintTemplate
+		if entrypoint == "" {
+			entrypoint = "main.tmpl"
+		}
+		for _, diagnostic := range lint.Lint(string(template), partials, funcs) {
+			if diagnostic.File == generator.MainTemplate {
+				diagnostic.File = entrypoint
+			}
+			diagnostic.File = filepath.Join(*lintTemplateDir, filepath.FromSlash(diagnostic.File))
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	} else {
+		for _, location := range lintCmd.Args() {
+			template, err := render.ReadTemplate(location)
+			if err != nil {
+				return failure.New(failure.KindTemplate, err)
+			}
+			for _, diagnostic := range lint.Lint(string(template), nil, funcs) {
+				diagnostic.File = location
+				diagnostics = append(diagnostics, diagnostic)
+			}
+		}
+	}
+
+	if *lintFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false) // e.g. the "{{ with .Target }}" of the messages
+		if diagnostics == nil {
+			diagnostics = []lint.Diagnostic{}
+		}
+		if err := encoder.Encode(struct {
+			Diagnostics []lint.Diagnostic `json:"diagnostics"`
+		}{diagnostics}); err != nil {
+			return err
+		}
+	} else {
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintln(os.Stdout, diagnostic)
+		}
+	}
+	if lint.HasErrors(diagnostics) {
+		return failure.New(failure.KindTemplate, fmt.Errorf("the templates have errors"))
+	}
+	return nil
+}
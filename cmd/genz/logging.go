@@ -0,0 +1,55 @@
+package genz
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/leorolland/genz/internal/logging"
+)
+
+// errorFormat is the format of the error reported when a command fails, see Report.
+var errorFormat = "text"
+
+// logFlags are the flags configuring the logs and the reported error, accepted by every command.
+type logFlags struct {
+	flagSet *flag.FlagSet
+}
+
+// addLogFlags defines the flags configuring the logs and the reported error on the given flag set. A flag the command already defines,
+// e.g. the -verbose of genz test, is shared.
+func addLogFlags(flagSet *flag.FlagSet) logFlags {
+	if flagSet.Lookup("verbose") == nil {
+		flagSet.Bool("verbose", false, "also log the steps of the generation, e.g. the rendering and the formatting of each output")
+	}
+	if flagSet.Lookup("quiet") == nil {
+		flagSet.Bool("quiet", false, "log the errors only")
+	}
+	if flagSet.Lookup("log-format") == nil {
+		flagSet.String("log-format", "text", "format of the logs: "+strings.Join(logging.Formats, " or "))
+	}
+	if flagSet.Lookup("error-format") == nil {
+		flagSet.String("error-format", "text", "format of the error of a failure: text, or json listing the errors with their kind and position")
+	}
+	return logFlags{flagSet: flagSet}
+}
+
+// configure configures the logs and the reported error from the parsed flags.
+func (l logFlags) configure() error {
+	errorFormat = l.flagSet.Lookup("error-format").Value.String()
+	if errorFormat != "text" && errorFormat != "json" {
+		return fmt.Errorf("invalid error format %q, expected text or json", errorFormat)
+	}
+	verbose := l.flagSet.Lookup("verbose").Value.String() == "true"
+	quiet := l.flagSet.Lookup("quiet").Value.String() == "true"
+	if verbose && quiet {
+		return fmt.Errorf("-verbose and -quiet cannot be set together")
+	}
+	level := logging.LevelInfo
+	if verbose {
+		level = logging.LevelDebug
+	} else if quiet {
+		level = logging.LevelError
+	}
+	return logging.Configure(level, l.flagSet.Lookup("log-format").Value.String())
+}
@@ -0,0 +1,384 @@
+package genz
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/jsonrpc"
+	"github.com/leorolland/genz/internal/logging"
+	"github.com/leorolland/genz/internal/remote"
+	"github.com/leorolland/genz/internal/render"
+	"github.com/leorolland/genz/internal/utils"
+	"github.com/leorolland/genz/pkg/models"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	lspUsage = `Usage of genz lsp:
+	genz lsp # Serves the requests of an editor on the standard input and output
+Serves the JSON-RPC requests of an editor plugin, framed as the messages of the Language Server Protocol, to preview
+the code generated by a template as it is edited. The packages stay loaded between the requests, until their files
+change. The requests are run one at a time, and the logs are written on the standard error. Methods:
+	genz/listTargets    lists the targets selected by "args"
+	genz/parseElement   returns the models given to the template of the target
+	genz/renderPreview  renders the outputs of the target, with the unsaved "content" of the template "file", without
+	                    writing them
+Their params: {"dir": "/src/shop", "args": ["-type", "User", "-template", "getters.tmpl"], "target": 0,
+"file": "getters.tmpl", "content": "..."}. The arguments are the ones of genz generate, resolved from the directory:
+without -type, the targets of the closest genz.yaml. The target is the index of the one to run among them, default the
+first one rendering the file. The failures of genz are listed in the "errors" of the result, as with -error-format json.
+Flags:`
+)
+
+type lspCommand struct {
+}
+
+var lspCmd = flag.NewFlagSet("lsp", flag.ExitOnError)
+
+func init() {
+	lspCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", lspUsage)
+		lspCmd.PrintDefaults()
+	}
+	command.RegisterCommand("lsp", lspCommand{})
+}
+
+func (l lspCommand) FlagSet() *flag.FlagSet {
+	return lspCmd
+}
+
+func (l lspCommand) ValidateArgs() error {
+	if lspCmd.NArg() > 0 {
+		lspCmd.Usage()
+		return fmt.Errorf("unexpected arguments %v", lspCmd.Args())
+	}
+	return nil
+}
+
+// Run serves the requests read on the standard input until it is closed or the editor sends "exit".
+// The standard output is reserved to the responses: what the generation would print there goes to the standard error.
+func (l lspCommand) Run() error {
+	// The packages are kept loaded between the requests, until they change.
+	sharedLoader = utils.NewLoader()
+	defer func() { sharedLoader = nil }()
+
+	responses := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = responses }()
+	logging.Info("serving the requests on the standard input")
+	return jsonrpc.Serve(os.Stdin, responses, handleLSP)
+}
+
+// lspParams are the params of the methods of genz lsp.
+type lspParams struct {
+	// Dir is the directory from which the arguments are resolved, e.g. the root of the workspace. Default: the working
+	// directory of genz lsp.
+	Dir string `json:"dir"`
+	// Args are the flags and the inputs of genz generate selecting the targets, e.g. ["-type", "User", "-template",
+	// "getters.tmpl"], or none for the targets of the closest genz.yaml.
+	Args []string `json:"args"`
+	// Target is the index of the target among the selected ones. Default: the first one rendering File, see rendersFile.
+	Target *int `json:"target"`
+	// File is the template file edited, absolute or relative to Dir: the main template of the target, or one of its
+	// template directory.
+	File string `json:"file"`
+	// Content is the unsaved content of File, rendered instead of the one on disk.
+	Content *string `json:"content"`
+}
+
+// lspTarget describes a target listed by genz/listTargets.
+type lspTarget struct {
+	Index       int      `json:"index"`
+	Name        string   `json:"name"`
+	Template    string   `json:"template,omitempty"`
+	TemplateDir string   `json:"templateDir,omitempty"`
+	Plugin      string   `json:"plugin,omitempty"`
+	Inputs      []string `json:"inputs"`
+	Output      string   `json:"output,omitempty"`
+}
+
+// lspOutput is an output rendered by genz/renderPreview: post-processed as it would be written, or as rendered if
+// the post-processing failed.
+type lspOutput struct {
+	File    string `json:"file"`
+	Content string `json:"content"`
+}
+
+// lspResult is the result of the methods of genz lsp: what they return, and the failures of genz.
+type lspResult struct {
+	Targets  []lspTarget            `json:"targets,omitempty"`
+	Elements []models.ParsedElement `json:"elements,omitempty"`
+	Outputs  []lspOutput            `json:"outputs,omitempty"`
+	Errors   []failure.Entry        `json:"errors,omitempty"`
+}
+
+// handleLSP handles the requests of genz lsp, and the lifecycle messages of the Language Server Protocol, so that the
+// generic LSP clients of the editors can start and stop it. A panic fails the request, not genz lsp.
+func handleLSP(method string, rawParams json.RawMessage) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.Error(fmt.Sprintf("panic: %v", r))
+			result, err = nil, &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: fmt.Sprintf("panic: %v", r)}
+		}
+	}()
+	switch method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{},
+			"serverInfo":   map[string]string{"name": "genz", "version": Version},
+		}, nil
+	case "initialized", "shutdown":
+		return nil, nil
+	case "exit":
+		return nil, jsonrpc.ErrExit
+	case "genz/listTargets", "genz/parseElement", "genz/renderPreview":
+	default:
+		return nil, &jsonrpc.Error{Code: jsonrpc.CodeMethodNotFound, Message: "method not found: " + method}
+	}
+
+	var params lspParams
+	if len(rawParams) != 0 {
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: err.Error()}
+		}
+	}
+	if params.Dir != "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chdir(params.Dir); err != nil {
+			return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: err.Error()}
+		}
+		defer os.Chdir(wd)
+	}
+	sharedLoader.Refresh()
+	// The header of the Go outputs lists the arguments, as if given to genz, see commandLine.
+	args := os.Args
+	os.Args = append([]string{"genz"}, params.Args...)
+	defer func() { os.Args = args }()
+
+	var response lspResult
+	err = parseLSPArgs(params.Args)
+	if err == nil {
+		switch method {
+		case "genz/listTargets":
+			response.Targets, err = listTargets()
+		case "genz/parseElement":
+			response.Elements, err = parseElements(params)
+		case "genz/renderPreview":
+			response.Outputs, err = renderPreview(params)
+		}
+	}
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		return nil, rpcErr
+	}
+	if err != nil {
+		response.Errors = failure.Entries(err)
+	}
+	return response, nil
+}
+
+// parseLSPArgs parses the given arguments with the flags of genz generate, without exiting genz lsp on an invalid flag.
+func parseLSPArgs(args []string) error {
+	addLogFlags(generateCmd)
+	flagSet := generateFlags("genz", flag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	resetFlags(generateCmd)
+	if err := flagSet.Parse(args); err != nil {
+		return &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: fmt.Sprintf("invalid args: %v", err)}
+	}
+	resetFlags(generateCmd)
+	return generateCmd.Parse(args)
+}
+
+// listTargets returns the targets selected by the arguments.
+func listTargets() ([]lspTarget, error) {
+	targets, err := targetsFromArgs()
+	if err != nil {
+		return nil, err
+	}
+	listed := make([]lspTarget, len(targets))
+	for i, target := range targets {
+		listed[i] = lspTarget{
+			Index:       i,
+			Name:        target.String(),
+			Template:    target.Template,
+			TemplateDir: target.TemplateDir,
+			Plugin:      target.Plugin,
+			Inputs:      target.Inputs,
+			Output:      target.Output,
+		}
+	}
+	return listed, nil
+}
+
+// parseElements returns the models of the types of the target, as given to its template.
+func parseElements(params lspParams) ([]models.ParsedElement, error) {
+	target, err := selectTarget(params)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := loadLSPPackage(target)
+	if err != nil {
+		return nil, err
+	}
+	typeNames, _, parse, err := render.Prepare(target, pkg)
+	if err != nil {
+		return nil, failure.New(failure.KindParse, err)
+	}
+	elements := make([]models.ParsedElement, len(typeNames))
+	for i, typeName := range typeNames {
+		if elements[i], err = parse(pkg, typeName); err != nil {
+			return nil, failure.New(failure.KindParse, err)
+		}
+	}
+	return elements, nil
+}
+
+// renderPreview renders the outputs of the target, with the unsaved content of the edited template, and post-processes
+// them as they would be written. A failing post-processing, e.g. a syntax error in the generated code, leaves the
+// output as rendered, and is listed in the errors. The test template of WithTests is not rendered.
+func renderPreview(params lspParams) ([]lspOutput, error) {
+	target, err := selectTarget(params)
+	if err != nil {
+		return nil, err
+	}
+	if target.Plugin != "" {
+		return nil, failure.New(failure.KindUsage, fmt.Errorf("the outputs of the plugin %s cannot be previewed", target.Plugin))
+	}
+	template, partials, err := lspTemplate(target, params)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := loadLSPPackage(target)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := render.Package(target, pkg, template, partials, headerData())
+	previews := make([]lspOutput, len(outputs))
+	for i, output := range outputs {
+		previews[i] = lspOutput{File: output.Name, Content: string(output.Content)}
+	}
+	return previews, err
+}
+
+// selectTarget returns the target selected by the params among the ones of the arguments: the one at their index, or
+// the first one rendering their file, or the first one.
+func selectTarget(params lspParams) (config.Target, error) {
+	targets, err := targetsFromArgs()
+	if err != nil {
+		return config.Target{}, err
+	}
+	if params.Target != nil {
+		if *params.Target < 0 || *params.Target >= len(targets) {
+			return config.Target{}, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: fmt.Sprintf("no target %d among the %d targets", *params.Target, len(targets))}
+		}
+		return targets[*params.Target], nil
+	}
+	if len(targets) == 0 {
+		return config.Target{}, failure.New(failure.KindUsage, fmt.Errorf("no target"))
+	}
+	if params.File != "" {
+		for _, target := range targets {
+			if _, rendered := rendersFile(target, params.File); rendered {
+				return target, nil
+			}
+		}
+	}
+	return targets[0], nil
+}
+
+// rendersFile returns true if the given file is a local template of the target, and its name: "" for the main
+// template, or its name in the template directory, e.g. "partials/header.tmpl".
+func rendersFile(target config.Target, file string) (string, bool) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	if main, err := filepath.Abs(render.TemplateFile(target)); err == nil && main == file {
+		return "", true
+	}
+	if target.TemplateDir == "" || remote.IsModule(target.TemplateDir) {
+		return "", false
+	}
+	dir, err := filepath.Abs(target.TemplateDir)
+	if err != nil {
+		return "", false
+	}
+	name, err := filepath.Rel(dir, file)
+	if err != nil || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(name), true
+}
+
+// lspTemplate returns the template of the target and its partials, the edited file having its unsaved content.
+func lspTemplate(target config.Target, params lspParams) ([]byte, []generator.Partial, error) {
+	edited := "" // The main template, unless a file of the template directory is edited.
+	if params.Content != nil && params.File != "" {
+		var rendered bool
+		if edited, rendered = rendersFile(target, params.File); !rendered {
+			return nil, nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: fmt.Sprintf("%s is not a template of the target %s", params.File, target)}
+		}
+	}
+	if params.Content != nil && target.TemplateDir == "" {
+		return []byte(*params.Content), nil, nil // Possibly not saved yet.
+	}
+
+	var (
+		template []byte
+		partials []generator.Partial
+		err      error
+	)
+	if target.TemplateDir != "" {
+		template, partials, err = render.ReadTemplateDir(target.TemplateDir, target.Template)
+	} else {
+		template, err = render.ReadTemplate(target.Template)
+	}
+	if err != nil {
+		return nil, nil, failure.New(failure.KindTemplate, err)
+	}
+	if params.Content == nil {
+		return template, partials, nil
+	}
+	if edited == "" {
+		return []byte(*params.Content), partials, nil
+	}
+	for i, partial := range partials {
+		if partial.Name == edited {
+			partials[i].Content = *params.Content
+			return template, partials, nil
+		}
+	}
+	// A partial not saved yet.
+	return template, append(partials, generator.Partial{Name: edited, Content: *params.Content}), nil
+}
+
+// loadLSPPackage loads the package of the target, kept loaded for the next requests. The standard input is reserved to
+// the requests.
+func loadLSPPackage(target config.Target) (*packages.Package, error) {
+	if len(target.Inputs) != 1 {
+		return loadPackage(target)
+	}
+	switch input := target.Inputs[0]; {
+	case input == config.Stdio:
+		return nil, failure.New(failure.KindUsage, fmt.Errorf("genz lsp cannot read the standard input"))
+	case utils.IsPackagePattern(input):
+		return nil, failure.New(failure.KindUsage, fmt.Errorf("the package pattern %s cannot be previewed, select a directory", input))
+	}
+	sharedLoader.Add(target.Inputs[0], render.LoadOptions(target))
+	return loadPackage(target)
+}
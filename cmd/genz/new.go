@@ -0,0 +1,128 @@
+package genz
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/logging"
+	"github.com/leorolland/genz/internal/render"
+	"github.com/leorolland/genz/internal/scaffold"
+)
+
+const (
+	newUsage = `Usage of genz new:
+	genz new <template> <name> [flags] # Scaffolds the new package <name> from the template bundle <template>
+The template bundle is a directory of templates, local or of a module (e.g. github.com/org/templates/service@v1.2.0):
+each of its files, and its path, is rendered into the directory <name>, given .Name, .Package, .Module, .ImportPath and
+.Vars. Its optional scaffold.yaml file declares the variables, asked for unless given with -var.
+Flags:`
+)
+
+type newCommand struct {
+}
+
+var (
+	newCmd      = flag.NewFlagSet("new", flag.ExitOnError)
+	newVars     = keyValues{}
+	newDir      = newCmd.String("dir", ".", "parent directory of the directory of the new package")
+	newModule   = newCmd.String("module", "", "module path of the new package, e.g. for a bundle scaffolding a module; default the one of the closest go.mod")
+	newNoInput  = newCmd.Bool("no-input", false, "do not ask for the variables: use their default values")
+	newForce    = newCmd.Bool("force", false, "overwrite the existing files; by default, they are kept")
+	newTemplate string
+	newName     string
+)
+
+func init() {
+	newCmd.Var(newVars, "var", "variable of the template bundle, as key=value; can be repeated")
+	newCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", newUsage)
+		newCmd.PrintDefaults()
+	}
+	command.RegisterCommand("new", newCommand{})
+}
+
+func (n newCommand) FlagSet() *flag.FlagSet {
+	return newCmd
+}
+
+// ValidateArgs reads the template and the name, then parses the flags following them.
+func (n newCommand) ValidateArgs() error {
+	if newCmd.NArg() < 2 {
+		newCmd.Usage()
+		return fmt.Errorf("missing template or name argument")
+	}
+	newTemplate, newName = newCmd.Arg(0), newCmd.Arg(1)
+	if err := newCmd.Parse(newCmd.Args()[2:]); err != nil {
+		return err
+	}
+	if newCmd.NArg() > 0 {
+		newCmd.Usage()
+		return fmt.Errorf("unexpected arguments %v", newCmd.Args())
+	}
+	return nil
+}
+
+func (n newCommand) Run() error {
+	bundle, err := render.LocalTemplateDir(newTemplate)
+	if err != nil {
+		return failure.New(failure.KindTemplate, err)
+	}
+	if info, err := os.Stat(bundle); err != nil || !info.IsDir() {
+		return failure.New(failure.KindUsage, fmt.Errorf("template bundle %s is not a directory", newTemplate))
+	}
+	manifest, err := scaffold.LoadManifest(bundle)
+	if err != nil {
+		return failure.New(failure.KindTemplate, err)
+	}
+	dir := filepath.Join(*newDir, filepath.FromSlash(newName))
+	data, err := scaffold.NewData(newName, dir, *newModule)
+	if err != nil {
+		return failure.New(failure.KindUsage, err)
+	}
+
+	// The variables are asked for on a terminal only, e.g. not in a CI job.
+	var in io.Reader
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 && !*newNoInput {
+		in = os.Stdin
+		if manifest.Description != "" {
+			fmt.Fprintln(os.Stderr, manifest.Description)
+		}
+	}
+	if err := scaffold.Ask(manifest, &data, newVars, in, os.Stderr); err != nil {
+		return failure.New(failure.KindUsage, err)
+	}
+
+	files, err := scaffold.Render(bundle, data)
+	if err != nil {
+		return failure.New(failure.KindTemplate, err)
+	}
+	// The scaffolded files are edited by hand from then on: they are not marked as generated, nor overwritten.
+	// Their imports are kept as rendered, as they may import the packages scaffolded along with them.
+	target := config.Target{Header: "none", Write: config.WriteSkipIfExists, NoImportsFix: true}
+	if *newForce {
+		target.Write = config.WriteOverwrite
+	}
+	written := 0
+	for _, file := range files {
+		name := filepath.Join(dir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return &failure.Error{Kind: failure.KindWrite, File: name, Err: err}
+		}
+		changed, err := writeOutput(target, name, *bytes.NewBuffer(file.Content))
+		if err != nil {
+			return err
+		}
+		if changed {
+			written++
+		}
+	}
+	logging.Info("scaffolded", "dir", dir, "package", data.ImportPath, "files", written)
+	return nil
+}
@@ -0,0 +1,209 @@
+package genz
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/parser"
+	"github.com/leorolland/genz/internal/render"
+	"github.com/leorolland/genz/internal/utils"
+	"github.com/leorolland/genz/pkg/models"
+	"github.com/leorolland/genz/pkg/plugin"
+)
+
+const (
+	pluginUsage = `Usage of genz plugin:
+	genz plugin <plugin> [flags] -type T [directory]
+	genz plugin <plugin> [flags] -type T files... # Must be a single package
+The plugin is the genz-plugin-<plugin> executable of the PATH, or the path of an executable (e.g. ./bin/foo).
+It reads the parsed types in JSON on its standard input, and writes the generated files in JSON on its standard output,
+see the documentation of the github.com/leorolland/genz/pkg/plugin package.
+Flags:`
+)
+
+type pluginCommand struct {
+}
+
+var (
+	pluginCmd               = flag.NewFlagSet("plugin", flag.ExitOnError)
+	pluginTypeNames         = stringList{}
+	pluginVars              = keyValues{}
+	pluginTypeRegex         = pluginCmd.String("type-regex", "", "regular expression selecting the types to parse, in addition to -type")
+	pluginBuildTags         = pluginCmd.String("tags", "", "comma-separated list of build tags to apply")
+	pluginBuildFlags        = pluginCmd.String("build-flags", "", "space-separated list of flags of the build system, e.g. -mod=vendor")
+	pluginTests             = pluginCmd.Bool("tests", false, "include the _test.go files of the package, to parse the types declared in tests")
+	pluginAllowErrors       = pluginCmd.Bool("allow-errors", false, "generate from a package having compile errors, e.g. referencing the code not generated yet")
+	pluginFlattenEmbedded   = pluginCmd.Bool("flatten-embedded", false, "replace embedded structs by their promoted attributes")
+	pluginRecursive         = pluginCmd.Bool("recursive", false, "parse the struct types of the attributes declared in the same module")
+	pluginPositions         = pluginCmd.Bool("positions", false, "fill the source positions of the elements, attributes and methods")
+	pluginIncludeTags       = stringList{}
+	pluginExcludeTags       = stringList{}
+	pluginExcludeUnexported = pluginCmd.Bool("exclude-unexported", false, "remove the unexported attributes")
+	pluginDryRun            = pluginCmd.Bool("dry-run", false, "do not write the outputs, and fail if some of them are out of date")
+	pluginDiff              = pluginCmd.Bool("diff", false, "print the unified diff of the outputs which are out of date, and write them unless -dry-run")
+	pluginArg               string
+)
+
+// keyValues is a flag accepting a key=value pair, that can be repeated.
+type keyValues map[string]string
+
+func (k keyValues) String() string {
+	pairs := make([]string, 0, len(k))
+	for key, value := range k {
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (k keyValues) Set(value string) error {
+	key, val, found := strings.Cut(value, "=")
+	if !found || key == "" {
+		return fmt.Errorf("invalid %q, expected key=value", value)
+	}
+	k[key] = val
+	return nil
+}
+
+func (k keyValues) reset() {
+	for key := range k {
+		delete(k, key)
+	}
+}
+
+func init() {
+	pluginCmd.Var(&pluginTypeNames, "type", "comma-separated list of type names or patterns (e.g. '*DTO'); must be set")
+	pluginCmd.Var(pluginVars, "var", "variable given to the plugin, as key=value; can be repeated")
+	pluginCmd.Var(&pluginIncludeTags, "include-tag", "keep only the attributes having one of these comma-separated tags: a key (e.g. json) or a key and a name (e.g. db:id)")
+	pluginCmd.Var(&pluginExcludeTags, "exclude-tag", "remove the attributes having one of these comma-separated tags, e.g. 'genz:\"-\"'; see -include-tag")
+	pluginCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", pluginUsage)
+		pluginCmd.PrintDefaults()
+	}
+	command.RegisterCommand("plugin", pluginCommand{})
+}
+
+func (p pluginCommand) FlagSet() *flag.FlagSet {
+	return pluginCmd
+}
+
+// ValidateArgs reads the plugin name, then parses the flags following it.
+func (p pluginCommand) ValidateArgs() error {
+	if pluginCmd.NArg() == 0 {
+		pluginCmd.Usage()
+		return fmt.Errorf("missing plugin argument")
+	}
+	pluginArg = pluginCmd.Arg(0)
+	if err := pluginCmd.Parse(pluginCmd.Args()[1:]); err != nil {
+		return err
+	}
+	if len(pluginTypeNames) == 0 && *pluginTypeRegex == "" {
+		pluginCmd.Usage()
+		return fmt.Errorf("missing 'type' argument")
+	}
+	return nil
+}
+
+func (p pluginCommand) Run() error {
+	target := config.Target{
+		Types:             pluginTypeNames,
+		Tests:             *pluginTests,
+		AllowErrors:       *pluginAllowErrors,
+		TypeRegex:         *pluginTypeRegex,
+		Plugin:            pluginArg,
+		FlattenEmbedded:   *pluginFlattenEmbedded,
+		Recursive:         *pluginRecursive,
+		Positions:         *pluginPositions,
+		IncludeTags:       pluginIncludeTags,
+		ExcludeTags:       pluginExcludeTags,
+		ExcludeUnexported: *pluginExcludeUnexported,
+		DryRun:            *pluginDryRun,
+		Diff:              *pluginDiff,
+		Vars:              pluginVars,
+		Inputs:            pluginCmd.Args(),
+	}
+	if len(*pluginBuildTags) > 0 {
+		target.Tags = strings.Split(*pluginBuildTags, ",")
+	}
+	target.BuildFlags = strings.Fields(*pluginBuildFlags)
+	if len(target.Inputs) == 0 {
+		target.Inputs = []string{"."}
+	}
+	_, err := runTarget(target)
+	return err
+}
+
+// runPlugin parses the types of the target and gives them to its plugin at once, then writes the files it generated
+// relative to the directory of the package. It returns the written files, see writeOutput.
+func runPlugin(target config.Target) ([]string, error) {
+	if usesStdio(target) || (len(target.Inputs) == 1 && utils.IsPackagePattern(target.Inputs[0])) {
+		return nil, fmt.Errorf("plugin %s: the input must be a single package directory or its files", target.Plugin)
+	}
+	executable, err := plugin.Lookup(target.Plugin)
+	if err != nil {
+		return nil, failure.New(failure.KindUsage, err)
+	}
+	pkg, err := loadPackage(target)
+	if err != nil {
+		return nil, err
+	}
+	typeNames, err := parser.SelectTypes(pkg, target.Types, target.TypeRegex)
+	if err != nil {
+		return nil, err
+	}
+	parse := parser.WithOptions(parser.Options{
+		FlattenEmbedded:   target.FlattenEmbedded,
+		Recursive:         target.Recursive,
+		Positions:         target.Positions,
+		IncludeTags:       target.IncludeTags,
+		ExcludeTags:       target.ExcludeTags,
+		ExcludeUnexported: target.ExcludeUnexported,
+	})
+	var to *models.Element
+	if target.To != "" {
+		element, err := render.ParseTo(target, pkg)
+		if err != nil {
+			return nil, err
+		}
+		to = &element
+	}
+	request := plugin.Request{Vars: target.Vars}
+	for _, typeName := range typeNames {
+		parsedElement, err := parse(pkg, typeName)
+		if err != nil {
+			return nil, err
+		}
+		parsedElement.Target = to
+		parsedElement.Vars = target.Vars
+		request.Elements = append(request.Elements, parsedElement)
+	}
+
+	files, err := plugin.Call(executable, request)
+	if err != nil {
+		return nil, failure.New(failure.KindTemplate, err)
+	}
+	dir := relativeDir(pkg.GoFiles[0])
+	var written []string
+	for _, file := range files {
+		name := file.Name
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		var buf bytes.Buffer
+		buf.WriteString(file.Content)
+		changed, err := writeOutput(target, name, buf)
+		if err != nil {
+			return written, err
+		}
+		if changed {
+			written = append(written, name)
+		}
+	}
+	return written, nil
+}
@@ -2,9 +2,14 @@
 package genz
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 
 	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/logging"
 )
 
 const (
@@ -19,20 +24,50 @@ func Execute() error {
 	}
 	for name, cmd := range command.Commands() {
 		if name == os.Args[1] {
-			if err := cmd.FlagSet().Parse(os.Args[2:]); err != nil {
-				return err
-			}
-			if err := cmd.ValidateArgs(); err != nil {
-				return err
-			}
-			return cmd.Run()
+			return execute(cmd, os.Args[2:])
 		}
 	}
-	if err := command.RootCommand().FlagSet().Parse(os.Args[1:]); err != nil {
+	return execute(command.RootCommand(), os.Args[1:])
+}
+
+// execute parses the given arguments of the command, including the flags configuring the logs, then runs it.
+func execute(cmd command.Command, args []string) error {
+	logs := addLogFlags(cmd.FlagSet())
+	if err := cmd.FlagSet().Parse(args); err != nil {
 		return err
 	}
-	if err := command.RootCommand().ValidateArgs(); err != nil {
-		return err
+	if err := cmd.ValidateArgs(); err != nil {
+		return failure.New(failure.KindUsage, err)
 	}
-	return command.RootCommand().Run()
+	if err := logs.configure(); err != nil {
+		return failure.New(failure.KindUsage, err)
+	}
+	return cmd.Run()
+}
+
+// Report reports the error of a failed command on the standard error, with -error-format, and returns the exit code
+// of its kind, see failure.Kind. With -error-format json, the errors are listed in a single line, e.g.
+// {"errors":[{"kind":"template","message":"...","file":"getters.tmpl","line":4,"column":3}]}
+func Report(err error) int {
+	var reported reportedError
+	if errors.As(err, &reported) {
+		return int(reported)
+	}
+	if errorFormat == "json" {
+		encoder := json.NewEncoder(os.Stderr)
+		encoder.SetEscapeHTML(false) // e.g. the failing action "<.Foo>" of a template
+		_ = encoder.Encode(struct {
+			Errors []failure.Entry `json:"errors"`
+		}{failure.Entries(err)})
+	} else {
+		logging.Error(err.Error())
+	}
+	return failure.ExitCode(err)
+}
+
+// reportedError is the exit code of a failure already reported, e.g. by genz daemon for genz client.
+type reportedError int
+
+func (r reportedError) Error() string {
+	return fmt.Sprintf("exit code %d", int(r))
 }
@@ -0,0 +1,125 @@
+package genz
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/directives"
+	"github.com/leorolland/genz/internal/logging"
+	"github.com/leorolland/genz/internal/utils"
+)
+
+const (
+	scanUsage = `Usage of genz scan-directives:
+	genz scan-directives [flags] [packages] # default ./...
+Runs the genz commands declared next to the types by //genz:generate comments, e.g.
+	//genz:generate builtin getters -type $GOFILE
+from the directory of their file, as go generate does with //go:generate comments.
+Flags:`
+)
+
+type scanCommand struct {
+}
+
+var (
+	scanCmd        = flag.NewFlagSet("scan-directives", flag.ExitOnError)
+	scanParallel   = scanCmd.Int("parallel", runtime.NumCPU(), "maximum number of commands run at once")
+	scanBuildTags  = scanCmd.String("tags", "", "comma-separated list of build tags to apply")
+	scanBuildFlags = scanCmd.String("build-flags", "", "space-separated list of flags of the build system, e.g. -mod=vendor")
+	scanPrintOnly  = scanCmd.Bool("n", false, "print the commands without running them")
+)
+
+func init() {
+	scanCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", scanUsage)
+		scanCmd.PrintDefaults()
+	}
+	command.RegisterCommand("scan-directives", scanCommand{})
+}
+
+func (s scanCommand) FlagSet() *flag.FlagSet {
+	return scanCmd
+}
+
+func (s scanCommand) ValidateArgs() error {
+	if *scanParallel < 1 {
+		return fmt.Errorf("-parallel must be at least 1")
+	}
+	return nil
+}
+
+// Run scans the files of the packages for //genz:generate directives, and runs them in parallel.
+// The errors of the failed commands are reported together, once all the commands ran.
+func (s scanCommand) Run() error {
+	patterns := scanCmd.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	var tags []string
+	if len(*scanBuildTags) > 0 {
+		tags = strings.Split(*scanBuildTags, ",")
+	}
+	pkgs, err := utils.ListPackages(patterns, utils.LoadOptions{Tags: tags, BuildFlags: strings.Fields(*scanBuildFlags)})
+	if err != nil {
+		return err
+	}
+	var found []directives.Directive
+	for _, pkg := range pkgs {
+		for _, file := range pkg.GoFiles {
+			fileDirectives, err := directives.Scan(relativeFile(file), pkg.Name)
+			if err != nil {
+				return err
+			}
+			found = append(found, fileDirectives...)
+		}
+	}
+	if len(found) == 0 {
+		logging.Warn("no //genz:generate directive found", "patterns", strings.Join(patterns, " "))
+		return nil
+	}
+	if *scanPrintOnly {
+		for _, directive := range found {
+			fmt.Println(directive)
+		}
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the genz executable: %w", err)
+	}
+	var outputLock sync.Mutex
+	err = directives.Run(found, *scanParallel, func(directive directives.Directive) error {
+		var output bytes.Buffer
+		cmd := exec.Command(executable, directive.Args...)
+		cmd.Dir = filepath.Dir(directive.File)
+		cmd.Env = append(os.Environ(), directive.Env()...)
+		cmd.Stdout, cmd.Stderr = &output, &output
+		err := cmd.Run()
+
+		// The outputs of the commands run at once are printed whole, one after the other.
+		outputLock.Lock()
+		defer outputLock.Unlock()
+		log.Printf("%s", directive)
+		os.Stderr.Write(output.Bytes())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("some //genz:generate directives failed:\n%w", err)
+	}
+	return nil
+}
+
+// relativeFile returns the given file relative to the current directory when it is below it, see relativeDir.
+func relativeFile(file string) string {
+	return filepath.Join(relativeDir(file), filepath.Base(file))
+}
@@ -0,0 +1,571 @@
+package genz
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/leorolland/genz/internal/cache"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/diff"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/hooks"
+	"github.com/leorolland/genz/internal/logging"
+	"github.com/leorolland/genz/internal/parser"
+	"github.com/leorolland/genz/internal/render"
+	"github.com/leorolland/genz/internal/utils"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// runTarget loads the package of the target once, renders its template, or runs its plugin, for each of its types
+// and writes the results into the output files. It returns the written files.
+// With DryRun, nothing is written: it returns the files which are out of date, and fails if there are some.
+func runTarget(target config.Target) ([]string, error) {
+	var (
+		written []string
+		err     error
+	)
+	if target.Plugin != "" {
+		written, err = runPlugin(target)
+	} else {
+		written, err = runTemplate(target)
+	}
+	if err == nil && target.DryRun && len(written) != 0 {
+		err = outOfDateError(written)
+	}
+	if err == nil && !target.DryRun && len(written) != 0 {
+		err = runPost(target, written)
+	}
+	return written, err
+}
+
+// outOfDateError lists the outputs which are out of date with DryRun.
+type outOfDateError []string
+
+func (o outOfDateError) Error() string {
+	return fmt.Sprintf("%d generated file(s) out of date: %s", len(o), strings.Join(o, ", "))
+}
+
+// Unwrap returns an error located in each output, classified as failure.KindOutOfDate.
+func (o outOfDateError) Unwrap() []error {
+	errs := make([]error, len(o))
+	for i, name := range o {
+		errs[i] = &failure.Error{Kind: failure.KindOutOfDate, File: name, Err: fmt.Errorf("%s is out of date", name)}
+	}
+	return errs
+}
+
+// runPost runs the Post commands of the target, once its outputs are written. The output of each command is printed
+// once it exits, and the first failing command fails the target.
+func runPost(target config.Target, written []string) error {
+	for _, command := range target.Post {
+		logging.Info("running", "command", command)
+		output, err := hooks.Run(command, target.Dir, written)
+		os.Stderr.Write(output)
+		if err != nil {
+			return fmt.Errorf("post-generation command failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// runTemplate renders the template of the target for each of its types. It returns the written files.
+// With a package pattern input (e.g. "./..."), each matching package is generated, see runPackages.
+// With Cache, nothing is loaded nor written if neither the sources nor the templates changed since the last run.
+func runTemplate(target config.Target) ([]string, error) {
+	var (
+		template []byte
+		partials []generator.Partial
+		written  []string
+		err      error
+	)
+	if target.TemplateDir != "" {
+		template, partials, err = render.ReadTemplateDir(target.TemplateDir, target.Template)
+	} else {
+		template, err = render.ReadTemplate(target.Template)
+	}
+	if err != nil {
+		return nil, failure.New(failure.KindTemplate, err)
+	}
+
+	if len(target.Inputs) == 1 && utils.IsPackagePattern(target.Inputs[0]) {
+		written, err = runPackages(target, template, partials)
+	} else {
+		useCache := target.Cache != "" && !target.DryRun && !target.Diff && !usesStdio(target)
+		var key string
+		if useCache {
+			key, err = cacheKey(target, template, partials)
+			if err != nil {
+				return nil, err
+			}
+			if entry, found := cache.New(target.Cache).Load(cacheID(target)); found && entry.Fresh(key) {
+				logging.Info("up to date, cached", "target", target)
+				return nil, nil
+			}
+		}
+
+		var pkg *packages.Package
+		pkg, err = loadPackage(target)
+		if err != nil {
+			return nil, err
+		}
+		written, err = generatePackage(target, pkg, template, partials)
+		if err == nil && useCache {
+			err = storeCache(target, key, pkg, written)
+		}
+	}
+	return written, err
+}
+
+// sharedLoader shares the loading of the packages between the targets run together, see runTargets.
+var sharedLoader *utils.Loader
+
+// loadPackage loads the package of the target with the shared loader, see render.LoadPackage.
+func loadPackage(target config.Target) (*packages.Package, error) {
+	return render.LoadPackage(sharedLoader, target)
+}
+
+// headerData returns the data given to the header of the Go outputs: the version of genz and its command line.
+func headerData() generator.HeaderData {
+	return generator.HeaderData{Version: Version, Command: commandLine()}
+}
+
+// usesStdio returns true if the target reads its input from the standard input or writes to the standard output.
+func usesStdio(target config.Target) bool {
+	return target.Output == config.Stdio || (len(target.Inputs) == 1 && target.Inputs[0] == config.Stdio)
+}
+
+// runPackages generates the target for each package matching its input pattern, e.g. "./...", loaded at once.
+// The outputs are written next to the sources of each package. The packages declaring none of the selected types
+// are skipped, and a package declaring only some of them is generated for those.
+func runPackages(target config.Target, template []byte, partials []generator.Partial) ([]string, error) {
+	if target.Output != "" {
+		return nil, fmt.Errorf("-output cannot be set with the package pattern %s", target.Inputs[0])
+	}
+	if target.Compares() {
+		return nil, fmt.Errorf("types qualified with their package cannot be compared in the package pattern %s", target.Inputs[0])
+	}
+	pkgs, err := utils.LoadPackages(target.Inputs, render.LoadOptions(target))
+	if err != nil {
+		return nil, err
+	}
+	var written []string
+	generated := 0
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) == 0 || pkg.Types == nil {
+			continue
+		}
+		pkgTarget := target
+		pkgTarget.Inputs = []string{relativeDir(pkg.GoFiles[0])}
+		if len(target.Types) != 0 || target.TypeRegex != "" {
+			pkgTarget.Types, pkgTarget.TypeRegex = declaredTypes(pkg, target), ""
+			if len(pkgTarget.Types) == 0 {
+				continue
+			}
+		}
+		generated++
+		pkgWritten, err := generatePackage(pkgTarget, pkg, template, partials)
+		written = append(written, pkgWritten...)
+		if err != nil {
+			return written, fmt.Errorf("%s: %w", pkg.PkgPath, err)
+		}
+	}
+	if generated == 0 {
+		return nil, fmt.Errorf("no package matching %s declares the selected types", target.Inputs[0])
+	}
+	return written, nil
+}
+
+// relativeDir returns the directory of the given file, relative to the current directory when it is below it,
+// so that the outputs are logged as "models/user.gen.go" rather than with their absolute path.
+func relativeDir(file string) string {
+	dir := filepath.Dir(file)
+	wd, err := os.Getwd()
+	if err != nil {
+		return dir
+	}
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return dir
+	}
+	return rel
+}
+
+// declaredTypes returns the types selected by the target which are declared in the given package.
+func declaredTypes(pkg *packages.Package, target config.Target) []string {
+	var declared []string
+	for _, name := range target.Types {
+		if typeNames, err := parser.SelectTypes(pkg, []string{name}, ""); err == nil {
+			declared = append(declared, typeNames...)
+		}
+	}
+	if target.TypeRegex != "" {
+		if typeNames, err := parser.SelectTypes(pkg, nil, target.TypeRegex); err == nil {
+			declared = append(declared, typeNames...)
+		}
+	}
+	var selected []string
+	seen := map[string]bool{}
+	for _, name := range declared {
+		if _, isTypeName := pkg.Types.Scope().Lookup(name).(*types.TypeName); isTypeName && !seen[name] {
+			seen[name] = true
+			selected = append(selected, name)
+		}
+	}
+	return selected
+}
+
+// generatePackage renders the template of the target for each of its types of the given package,
+// and its test template with WithTests. It returns the written files, see writeOutput.
+func generatePackage(target config.Target, pkg *packages.Package, template []byte, partials []generator.Partial) ([]string, error) {
+	typeNames, outputNames, parse, err := render.Prepare(target, pkg)
+	if err != nil {
+		return nil, err
+	}
+	written, err := renderTemplate(target, pkg, render.TemplateFile(target), string(template), partials, typeNames, outputNames, parse)
+	if err == nil && target.WithTests {
+		var testWritten []string
+		testWritten, err = renderTests(target, pkg, partials, typeNames, outputNames, parse)
+		written = append(written, testWritten...)
+	}
+	return written, err
+}
+
+// cacheID identifies the target in the cache: its options, without the ones which do not change the outputs.
+func cacheID(target config.Target) string {
+	target.DryRun, target.Diff, target.Cache = false, false, ""
+	id, _ := json.Marshal(target)
+	return string(id)
+}
+
+// cacheKey returns the hash of the version of genz and of the templates and the custom functions of the target.
+// The source files are checked by the cache entry itself, see cache.Entry.Fresh.
+func cacheKey(target config.Target, template []byte, partials []generator.Partial) (string, error) {
+	parts := [][]byte{[]byte(Version), template}
+	for _, partial := range partials {
+		parts = append(parts, []byte(partial.Name), []byte(partial.Content))
+	}
+	for _, path := range target.Funcs {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, []byte(path), content)
+	}
+	if target.WithTests {
+		testTemplate, err := render.ReadTestTemplate(target)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, testTemplate)
+	}
+	return cache.Hash(parts...), nil
+}
+
+// storeCache saves the state of the target after a successful generation of the given outputs:
+// the hashes of the outputs and of the directories of the package and of the module packages it imports.
+func storeCache(target config.Target, key string, pkg *packages.Package, outputNames []string) error {
+	entry := cache.Entry{Key: key, Inputs: map[string]string{}, Outputs: map[string]string{}}
+	for _, outputName := range outputNames {
+		path, err := filepath.Abs(outputName)
+		if err != nil {
+			return err
+		}
+		hash, err := cache.HashFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to hash output: %w", err)
+		}
+		entry.Outputs[path] = hash
+	}
+	for _, dir := range utils.InputDirs(pkg) {
+		hash, err := cache.HashDir(dir, entry.Outputs)
+		if err != nil {
+			return fmt.Errorf("failed to hash input: %w", err)
+		}
+		entry.Inputs[dir] = hash
+	}
+	return cache.New(target.Cache).Store(cacheID(target), entry)
+}
+
+// renderTests renders the test template of the target into the companion _test files of the outputs.
+func renderTests(
+	target config.Target,
+	pkg *packages.Package,
+	partials []generator.Partial,
+	typeNames []string,
+	outputNames []string,
+	parse render.ParseFunc,
+) ([]string, error) {
+	testTemplate, err := render.ReadTestTemplate(target)
+	if err != nil {
+		return nil, err
+	}
+	testNames := make([]string, len(outputNames))
+	for i, outputName := range outputNames {
+		if outputName == config.Stdio {
+			testNames[i] = config.Stdio
+			continue
+		}
+		extension := filepath.Ext(outputName)
+		testNames[i] = strings.TrimSuffix(outputName, extension) + "_test" + extension
+	}
+	return renderTemplate(target, pkg, render.TemplateFile(target)+"_test", string(testTemplate), partials, typeNames, testNames, parse)
+}
+
+// renderTemplate renders the template for each of the types and writes the results into the output files,
+// along with the additional files emitted by the template. It returns the written files, see writeOutput.
+// The template errors are located in the given template file, see render.TemplateFileError.
+func renderTemplate(
+	target config.Target,
+	pkg *packages.Package,
+	templateName string,
+	template string,
+	partials []generator.Partial,
+	typeNames []string,
+	outputNames []string,
+	parse render.ParseFunc,
+) ([]string, error) {
+	funcs, err := generator.LoadFuncs(target.Funcs)
+	if err != nil {
+		return nil, failure.New(failure.KindTemplate, err)
+	}
+	if streams(target, outputNames) {
+		tmpl, err := generator.ParseWithFuncs(template, funcs, partials...)
+		if err != nil {
+			return nil, render.TemplateFileError(target, templateName, err)
+		}
+		if !generator.UsesFiles(tmpl) {
+			return streamTemplate(target, pkg, templateName, template, partials, funcs, typeNames, outputNames, parse)
+		}
+	}
+	outputs, err := render.Outputs(target, pkg, templateName, template, partials, funcs, typeNames, outputNames, parse)
+	if err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, output := range outputs {
+		changed, err := writeOutput(target, output.Name, output.Buf)
+		if err != nil {
+			return written, err
+		}
+		if changed {
+			written = append(written, output.Name)
+		}
+	}
+	return written, nil
+}
+
+// streams returns true if the outputs of the target can be rendered directly into their files, without being held in
+// memory: they are written as rendered (not gofmt-ed, e.g. -raw or non-Go outputs), and overwritten without being
+// compared to their current content. The template must not emit additional files either, see generator.UsesFiles.
+func streams(target config.Target, outputNames []string) bool {
+	if target.Combine || target.DryRun || target.Diff || (target.Write != "" && target.Write != config.WriteOverwrite) {
+		return false
+	}
+	for _, outputName := range outputNames {
+		if outputName == config.Stdio || (!target.Raw && render.OutputExtension(target, outputName) == ".go") {
+			return false
+		}
+	}
+	return true
+}
+
+// streamTemplate renders the template for each of the types directly into their output files, through a buffered
+// writer, e.g. for the multi-megabyte tables of a large enum or embedded data. It returns the written files.
+func streamTemplate(
+	target config.Target,
+	pkg *packages.Package,
+	templateName string,
+	template string,
+	partials []generator.Partial,
+	funcs texttemplate.FuncMap,
+	typeNames []string,
+	outputNames []string,
+	parse render.ParseFunc,
+) ([]string, error) {
+	var written []string
+	for i, typeName := range typeNames {
+		err := streamOutput(target, outputNames[i], func(w io.Writer) error {
+			err := generator.GenerateTo(w, pkg, template, typeName, parse, funcs, partials...)
+			return render.TemplateFileError(target, templateName, err)
+		})
+		if err != nil {
+			return written, err
+		}
+		written = append(written, outputNames[i])
+	}
+	return written, nil
+}
+
+// streamOutput writes the given output file with the content rendered by render into a buffered writer.
+// The content is written into a temporary file of the same directory, renamed once complete: a failing render leaves
+// the current file unchanged.
+func streamOutput(target config.Target, outputName string, render func(w io.Writer) error) error {
+	if err := checkProtected(target, outputName); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(outputName), "."+filepath.Base(outputName)+".*")
+	if err != nil {
+		return &failure.Error{Kind: failure.KindWrite, File: outputName, Err: fmt.Errorf("writing output: %s", err)}
+	}
+	defer os.Remove(f.Name()) // No-op once renamed.
+	w := bufio.NewWriterSize(f, 64*1024)
+	if err := render(w); err != nil {
+		f.Close()
+		return err
+	}
+	var size int64
+	err = w.Flush()
+	if err == nil {
+		size, err = f.Seek(0, io.SeekCurrent)
+	}
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), outputName)
+	}
+	if err != nil {
+		return &failure.Error{Kind: failure.KindWrite, File: outputName, Err: fmt.Errorf("writing output: %s", err)}
+	}
+	logging.Info("wrote", "file", outputName, "bytes", size)
+	return nil
+}
+
+// writeOutput post-processes the generated buffer and writes it into the given output file.
+// With DryRun, the file is not written.
+// With Diff, the unified diff from the current content of the file is printed on the standard output, and the file is
+// still written unless DryRun. With DryRun or Diff, a file already up to date is not written, and it returns false.
+// The Stdio output is printed on the standard output, and never reported as written.
+// With the WriteSkipIfExists strategy, an existing file is never written, and with WriteRegion only its generated
+// regions are, see mergeRegions.
+func writeOutput(target config.Target, outputName string, buf bytes.Buffer) (bool, error) {
+	if target.Write == config.WriteRegion {
+		// The file is edited by hand outside of its regions: it is not marked as generated.
+		target.Header = "none"
+	}
+	src, err := render.PostProcess(target, outputName, buf, headerData())
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", outputName, err)
+	}
+	if outputName == config.Stdio {
+		_, err := os.Stdout.Write(src)
+		return false, err
+	}
+	switch target.Write {
+	case config.WriteSkipIfExists:
+		if _, err := os.Stat(outputName); err == nil {
+			logging.Info("exists, skipped", "file", outputName)
+			return false, nil
+		}
+	case config.WriteRegion:
+		if src, err = mergeRegions(target, outputName, src); err != nil {
+			return false, &failure.Error{Kind: failure.KindWrite, File: outputName, Err: err}
+		}
+	}
+	if target.Write != config.WriteRegion {
+		if err := checkProtected(target, outputName); err != nil {
+			return false, err
+		}
+	}
+	if target.DryRun || target.Diff {
+		current, err := os.ReadFile(outputName)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return false, fmt.Errorf("reading output: %s", err)
+		}
+		if bytes.Equal(current, src) {
+			return false, nil
+		}
+		if target.Diff {
+			fmt.Print(diff.Unified("a/"+filepath.ToSlash(outputName), "b/"+filepath.ToSlash(outputName), current, src))
+		}
+		if target.DryRun {
+			logging.Info("out of date", "file", outputName)
+			return true, nil
+		}
+	}
+	if err := os.WriteFile(outputName, src, 0644); err != nil {
+		return false, &failure.Error{Kind: failure.KindWrite, File: outputName, Err: fmt.Errorf("writing output: %s", err)}
+	}
+	logging.Info("wrote", "file", outputName, "bytes", len(src))
+	return true, nil
+}
+
+// checkProtected returns an error if the target protects the files not marked as generated and the given output file
+// exists without being marked.
+func checkProtected(target config.Target, outputName string) error {
+	if !target.Protect {
+		return nil
+	}
+	if current, err := os.ReadFile(outputName); err == nil && !generator.IsGenerated(current) {
+		err := fmt.Errorf("%s is not marked as generated (no \"Code generated ... DO NOT EDIT.\" comment), refusing to overwrite it", outputName)
+		return &failure.Error{Kind: failure.KindWrite, File: outputName, Err: err}
+	}
+	return nil
+}
+
+// mergeRegions returns the current content of the given output file with its regions replaced by the generated ones,
+// see generator.MergeRegions, gofmt-ed and with its imports fixed for the Go files, unless disabled by the target.
+// It returns the generated source as is if the file does not exist yet.
+func mergeRegions(target config.Target, outputName string, src []byte) ([]byte, error) {
+	current, err := os.ReadFile(outputName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return src, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading output: %s", err)
+	}
+	merged, err := generator.MergeRegions(current, src)
+	if err != nil {
+		return nil, err
+	}
+	if target.Raw || render.OutputExtension(target, outputName) != ".go" {
+		return merged, nil
+	}
+	merged, err = generator.Format(*bytes.NewBuffer(merged))
+	if err != nil || target.NoImportsFix {
+		return merged, err
+	}
+	return generator.FixImports(outputName, merged)
+}
+
+// commandLine returns the genz command line, e.g. "genz -type Car -template getters.tmpl", for the header of the outputs.
+// The flags changing how the outputs are written but not their content are left out, so that the outputs checked with
+// -dry-run are the same as the ones written without it.
+func commandLine() string {
+	args := []string{"genz"}
+	for i := 1; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if strings.HasPrefix(arg, "-") {
+			switch name {
+			case "dry-run", "diff", "watch", "protect":
+				continue
+			case "cache":
+				if !hasValue {
+					i++
+				}
+				continue
+			}
+		}
+		if arg == "" || strings.ContainsAny(arg, " \t*?[]'\"$|&;<>()") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		args = append(args, arg)
+	}
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,97 @@
+package genz
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/leorolland/genz/internal/builtin"
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/logging"
+)
+
+const (
+	templatesUsage = `Usage of genz templates:
+	genz templates list # Lists the built-in templates
+	genz templates show <template> # Prints the source of a built-in template
+	genz templates eject [flags] <template> [directory] # Copies a built-in template into a directory, "." by default
+An ejected template can be customized, then used with -template, e.g. -template ./templates/builder.tmpl.
+Flags:`
+)
+
+type templatesCommand struct {
+}
+
+var (
+	templatesCmd        = flag.NewFlagSet("templates", flag.ExitOnError)
+	templatesForce      = templatesCmd.Bool("force", false, "overwrite the existing files when ejecting a template")
+	templatesSubcommand string
+)
+
+func init() {
+	templatesCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", templatesUsage)
+		templatesCmd.PrintDefaults()
+	}
+	command.RegisterCommand("templates", templatesCommand{})
+}
+
+func (t templatesCommand) FlagSet() *flag.FlagSet {
+	return templatesCmd
+}
+
+// ValidateArgs reads the subcommand, then parses the flags following it.
+func (t templatesCommand) ValidateArgs() error {
+	if templatesCmd.NArg() == 0 {
+		templatesCmd.Usage()
+		return fmt.Errorf("missing subcommand: list, show or eject")
+	}
+	templatesSubcommand = templatesCmd.Arg(0)
+	if err := templatesCmd.Parse(templatesCmd.Args()[1:]); err != nil {
+		return err
+	}
+	switch templatesSubcommand {
+	case "list":
+		return nil
+	case "show", "eject":
+		if templatesCmd.NArg() == 0 {
+			templatesCmd.Usage()
+			return fmt.Errorf("missing template argument")
+		}
+		_, err := builtin.Template(templatesCmd.Arg(0))
+		return err
+	default:
+		templatesCmd.Usage()
+		return fmt.Errorf("unknown subcommand %s, expected list, show or eject", templatesSubcommand)
+	}
+}
+
+func (t templatesCommand) Run() error {
+	switch templatesSubcommand {
+	case "show":
+		content, _ := builtin.Template(templatesCmd.Arg(0)) // checked by ValidateArgs
+		_, err := os.Stdout.Write(content)
+		return err
+	case "eject":
+		dir := "."
+		if templatesCmd.NArg() > 1 {
+			dir = templatesCmd.Arg(1)
+		}
+		written, err := builtin.Eject(templatesCmd.Arg(0), dir, *templatesForce)
+		for _, file := range written {
+			logging.Info("wrote", "file", file)
+		}
+		return err
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, name := range builtin.Names() {
+			tests := ""
+			if _, err := builtin.TemplateTests(name); err == nil {
+				tests = "with tests"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, builtin.Extension(name), tests, builtin.Description(name))
+		}
+		return w.Flush()
+	}
+}
@@ -0,0 +1,127 @@
+package genz
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leorolland/genz/internal/command"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/httpapi"
+	"github.com/leorolland/genz/internal/logging"
+	"github.com/leorolland/genz/internal/parser"
+)
+
+const (
+	verifyUsage = `Usage of genz verify:
+	genz verify [flags] -type Users -openapi openapi.yaml [directory]
+Verifies the HTTP routes of the interfaces, declared by their methods marked with //genz:http as for the http built-in
+generator, against an OpenAPI 3 document in YAML or JSON: reports the routes missing from the document, the operations
+served by no route, and the parameters and the request bodies which differ.
+Flags:`
+)
+
+type verifyCommand struct {
+}
+
+var (
+	verifyCmd         = flag.NewFlagSet("verify", flag.ExitOnError)
+	verifyTypeNames   = stringList{}
+	verifyOpenAPI     = verifyCmd.String("openapi", "", "OpenAPI document the routes are verified against; must be set")
+	verifyBuildTags   = verifyCmd.String("tags", "", "comma-separated list of build tags to apply")
+	verifyBuildFlags  = verifyCmd.String("build-flags", "", "space-separated list of flags of the build system, e.g. -mod=vendor")
+	verifyTests       = verifyCmd.Bool("tests", false, "include the _test.go files of the package, to parse the types declared in tests")
+	verifyAllowErrors = verifyCmd.Bool("allow-errors", false, "verify a package having compile errors, e.g. referencing the code not generated yet")
+)
+
+func init() {
+	verifyCmd.Var(&verifyTypeNames, "type", "comma-separated list of interface names or patterns (e.g. '*API') serving the document together; must be set")
+	verifyCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", verifyUsage)
+		verifyCmd.PrintDefaults()
+	}
+	command.RegisterCommand("verify", verifyCommand{})
+}
+
+func (v verifyCommand) FlagSet() *flag.FlagSet {
+	return verifyCmd
+}
+
+func (v verifyCommand) ValidateArgs() error {
+	if len(verifyTypeNames) == 0 {
+		verifyCmd.Usage()
+		return fmt.Errorf("missing 'type' argument")
+	}
+	if *verifyOpenAPI == "" {
+		verifyCmd.Usage()
+		return fmt.Errorf("missing 'openapi' argument")
+	}
+	return nil
+}
+
+// Run reads the routes of the interfaces and the OpenAPI document, and fails with a located error per drift.
+func (v verifyCommand) Run() error {
+	target := config.Target{
+		Types:       verifyTypeNames,
+		Tests:       *verifyTests,
+		AllowErrors: *verifyAllowErrors,
+		Inputs:      verifyCmd.Args(),
+	}
+	if len(*verifyBuildTags) > 0 {
+		target.Tags = strings.Split(*verifyBuildTags, ",")
+	}
+	target.BuildFlags = strings.Fields(*verifyBuildFlags)
+	if len(target.Inputs) == 0 {
+		target.Inputs = []string{"."}
+	}
+
+	content, err := os.ReadFile(*verifyOpenAPI)
+	if err != nil {
+		return failure.New(failure.KindUsage, err)
+	}
+	document, err := httpapi.ReadDocument(content)
+	if err != nil {
+		return &failure.Error{Kind: failure.KindParse, File: *verifyOpenAPI, Err: err}
+	}
+	pkg, err := loadPackage(target)
+	if err != nil {
+		return err
+	}
+	typeNames, err := parser.SelectTypes(pkg, target.Types, "")
+	if err != nil {
+		return err
+	}
+	parse := parser.WithOptions(parser.Options{Positions: true})
+	var routes []httpapi.Route
+	for _, typeName := range typeNames {
+		element, err := parse(pkg, typeName)
+		if err != nil {
+			return err
+		}
+		typeRoutes, err := httpapi.Routes(element)
+		if err != nil {
+			return failure.New(failure.KindParse, err)
+		}
+		routes = append(routes, typeRoutes...)
+	}
+	drifts, err := httpapi.Verify(routes, document)
+	if err != nil {
+		return &failure.Error{Kind: failure.KindParse, File: *verifyOpenAPI, Err: err}
+	}
+	if len(drifts) == 0 {
+		logging.Info("verified", "routes", len(routes), "openapi", *verifyOpenAPI)
+		return nil
+	}
+	errs := make([]error, len(drifts))
+	for i, drift := range drifts {
+		drifted := &failure.Error{Kind: failure.KindOutOfDate, File: *verifyOpenAPI, Err: errors.New(drift.Message)}
+		if drift.Route != nil && drift.Route.Position.File != "" {
+			drifted.File, drifted.Line, drifted.Column = drift.Route.Position.File, drift.Route.Position.Line, drift.Route.Position.Column
+		}
+		errs[i] = drifted
+	}
+	return errors.Join(errs...)
+}
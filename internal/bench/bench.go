@@ -0,0 +1,83 @@
+// Package bench measures the phases of a generation for genz bench: their duration and their memory allocations.
+package bench
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"text/tabwriter"
+	"time"
+)
+
+// Result is the measure of a phase of the generation, run N times.
+type Result struct {
+	// Name of the phase, e.g. "render".
+	Name string
+	// N is the number of runs of the phase.
+	N int
+	// Duration is the total duration of the runs.
+	Duration time.Duration
+	// Allocs is the total number of heap allocations of the runs.
+	Allocs uint64
+	// Bytes is the total number of bytes allocated by the runs.
+	Bytes uint64
+}
+
+// PerOp returns the average duration of a run.
+func (r Result) PerOp() time.Duration {
+	if r.N == 0 {
+		return 0
+	}
+	return r.Duration / time.Duration(r.N)
+}
+
+// AllocsPerOp returns the average number of heap allocations of a run.
+func (r Result) AllocsPerOp() uint64 {
+	if r.N == 0 {
+		return 0
+	}
+	return r.Allocs / uint64(r.N)
+}
+
+// BytesPerOp returns the average number of bytes allocated by a run.
+func (r Result) BytesPerOp() uint64 {
+	if r.N == 0 {
+		return 0
+	}
+	return r.Bytes / uint64(r.N)
+}
+
+// Measure runs the given phase n times, and returns its measure. It stops at the first error.
+func Measure(name string, n int, run func() error) (Result, error) {
+	var before, after runtime.MemStats
+	runtime.GC() // The garbage of the previous phases is not collected during this one.
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		if err := run(); err != nil {
+			return Result{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return Result{
+		Name:     name,
+		N:        n,
+		Duration: duration,
+		Allocs:   after.Mallocs - before.Mallocs,
+		Bytes:    after.TotalAlloc - before.TotalAlloc,
+	}, nil
+}
+
+// Write writes the given results as a table, one phase per line, e.g.
+//
+//	phase   runs  total    per run   allocs/run  bytes/run
+//	render  1000  120ms    120µs     1520        98304
+func Write(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "phase\truns\ttotal\tper run\tallocs/run\tbytes/run")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d\t%d\n", r.Name, r.N, r.Duration.Round(time.Microsecond), r.PerOp(), r.AllocsPerOp(), r.BytesPerOp())
+	}
+	return tw.Flush()
+}
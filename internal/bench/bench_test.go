@@ -0,0 +1,57 @@
+package bench
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+var sink []byte
+
+func TestMeasure(t *testing.T) {
+	runs := 0
+	result, err := Measure("render", 10, func() error {
+		runs++
+		sink = make([]byte, 1024)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if runs != 10 || result.N != 10 || result.Name != "render" {
+		t.Errorf("expected 10 runs of render, got %d runs and %+v", runs, result)
+	}
+	if result.AllocsPerOp() < 1 || result.BytesPerOp() < 1024 {
+		t.Errorf("expected at least an allocation of 1024 bytes per run, got %d and %d", result.AllocsPerOp(), result.BytesPerOp())
+	}
+}
+
+func TestMeasureError(t *testing.T) {
+	runs := 0
+	_, err := Measure("parse", 10, func() error {
+		runs++
+		return errors.New("invalid template")
+	})
+	if err == nil || err.Error() != "parse: invalid template" || runs != 1 {
+		t.Errorf("expected the error of the first run, got %v after %d runs", err, runs)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, []Result{
+		{Name: "parse", N: 4, Duration: 4 * time.Millisecond, Allocs: 40, Bytes: 4096},
+		{Name: "render", N: 0},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `phase   runs  total  per run  allocs/run  bytes/run
+parse   4     4ms    1ms      10          1024
+render  0     0s     0s       0           0
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,179 @@
+package builtin
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Prefix is the prefix of the template locations referencing a built-in template, e.g. "builtin:getters".
+const Prefix = "builtin:"
+
+//go:embed templates/*.tmpl templates/*.tmpl_test
+var templates embed.FS
+
+// extensions are the output file extensions of the built-in templates which do not generate Go code.
+var extensions = map[string]string{
+	"graphql":    ".graphql",
+	"jsonschema": ".json",
+	"openapi":    ".yaml",
+	"proto":      ".proto",
+	"sql":        ".sql",
+	"typescript": ".ts",
+}
+
+// functions are the built-in templates rendered from the top-level functions of the package rather than from a type.
+var functions = map[string]bool{
+	"providers": true,
+}
+
+// implementations are the built-in templates rendered for an interface with the structs of its package implementing it.
+var implementations = map[string]bool{
+	"sealed": true,
+}
+
+// descriptions are the one-line descriptions of the built-in templates, listed by genz templates list.
+var descriptions = map[string]string{
+	"builder":    "fluent NewXBuilder().WithY(...).Build() builder of a struct",
+	"cache":      "cache key builder, TTL and JSON or msgpack codec of a struct marked with //genz:cache",
+	"clone":      "deep copy Clone() method of a struct",
+	"config":     "LoadX function setting a struct from its default, env and flag tags",
+	"csv":        "ToCSVRow(), FromCSVRow() and the CSV header of a struct, with functions reading and writing CSV",
+	"deepcopy":   "Kubernetes DeepCopy methods of a struct, and its CRD manifest if marked with //genz:crd",
+	"diff":       "DiffX(a, b) []FieldChange function listing the changed attributes of a struct, e.g. for an audit log",
+	"enum":       "String(), XFromString(), MarshalText() and UnmarshalText() of the constants of a type",
+	"equal":      "Equal(b T) bool method of a struct",
+	"fieldmask":  "ApplyXFieldMask(dst, src, paths) function copying the fields of a FieldMask, named after their json names",
+	"fixture":    "NewXFixture(overrides...) factory of a struct filled with fake values, for the tests",
+	"flags":      "Has(), Set(), Clear(), String(), ParseX() and JSON methods of the power-of-two flag constants of an integer type",
+	"getters":    "GetX() and SetX() methods of the unexported attributes of a struct",
+	"graphql":    "GraphQL types of a struct, or GraphQL interface of an interface",
+	"grpc":       "proto3 service of an interface, and the adapter serving the interface with gRPC",
+	"http":       "net/http, chi or echo handlers of the methods of an interface marked with //genz:http",
+	"immutable":  "read-only XView of a struct, with getters returning copies, and its Freeze() method",
+	"interface":  "interface of the exported methods of a struct",
+	"jsonschema": "JSON Schema of a struct, with its validate tags as constraints",
+	"mapper":     "conversion function from a struct to another one (-to)",
+	"mock":       "XMock implementation of an interface, with call recording",
+	"openapi":    "OpenAPI 3.1 component schemas of structs",
+	"options":    "NewX constructor with a functional option per attribute of a struct",
+	"proto":      "proto3 messages of a struct",
+	"providers":  "wire.ProviderSet or fx.Module of the New* constructors of a package, binding the interfaces they implement",
+	"scan":       "ScanX functions scanning the columns of a struct from SQL rows",
+	"sealed":     "exhaustive SwitchX function and XVisitor of the implementations of an interface in its package",
+	"slice":      "XSlice type of a struct, with Filter, Map, SortBy, GroupBy and IndexBy helpers",
+	"sql":        "CREATE TABLE statement of a struct",
+	"stringer":   "String() method of the constants of a type",
+	"typescript": "TypeScript interfaces of a struct",
+}
+
+// Names returns the names of the built-in templates, sorted.
+func Names() []string {
+	entries, _ := templates.ReadDir("templates") // cannot fail, the directory is embedded
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if name, isTemplate := strings.CutSuffix(entry.Name(), ".tmpl"); isTemplate {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Template returns the content of the built-in template with the given name.
+func Template(name string) ([]byte, error) {
+	content, err := templates.ReadFile(path.Join("templates", name+".tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown built-in template %s, available: %s", name, strings.Join(Names(), ", "))
+	}
+	return content, nil
+}
+
+// TemplateTests returns the content of the test template of the built-in template with the given name,
+// rendered into the companion _test.go file of the output with -with-tests.
+func TemplateTests(name string) ([]byte, error) {
+	content, err := templates.ReadFile(path.Join("templates", name+".tmpl_test"))
+	if err != nil {
+		return nil, fmt.Errorf("built-in template %s has no test template", name)
+	}
+	return content, nil
+}
+
+// Description returns the one-line description of the built-in template with the given name.
+func Description(name string) string {
+	return descriptions[name]
+}
+
+// Eject copies the built-in template with the given name, and its test template if it has one, into the given directory,
+// e.g. to customize them. It fails rather than overwriting an existing file, unless force is true.
+// It returns the written files.
+func Eject(name, dir string, force bool) ([]string, error) {
+	files := map[string][]byte{}
+	content, err := Template(name)
+	if err != nil {
+		return nil, err
+	}
+	files[name+".tmpl"] = content
+	if content, err := TemplateTests(name); err == nil {
+		files[name+".tmpl_test"] = content
+	}
+	fileNames := make([]string, 0, len(files))
+	for fileName := range files {
+		fileNames = append(fileNames, filepath.Join(dir, fileName))
+	}
+	sort.Strings(fileNames)
+	if !force {
+		for _, fileName := range fileNames {
+			if _, err := os.Stat(fileName); err == nil {
+				return nil, fmt.Errorf("%s already exists", fileName)
+			}
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	for _, fileName := range fileNames {
+		if err := os.WriteFile(fileName, files[filepath.Base(fileName)], 0644); err != nil {
+			return nil, err
+		}
+	}
+	return fileNames, nil
+}
+
+// Extension returns the output file extension of the built-in template with the given name, e.g. ".go" or ".ts".
+func Extension(name string) string {
+	if extension, ok := extensions[name]; ok {
+		return extension
+	}
+	return ".go"
+}
+
+// ParsesFunctions returns true if the built-in template with the given name is rendered from the top-level functions
+// of the package, in which case the types are optional.
+func ParsesFunctions(name string) bool {
+	return functions[name]
+}
+
+// ParsesImplementations returns true if the built-in template with the given name is rendered with the structs of the
+// package implementing the interfaces, see parser.Options.
+func ParsesImplementations(name string) bool {
+	return implementations[name]
+}
+
+// Location returns the template location referencing the built-in template with the given name.
+func Location(name string) string {
+	return Prefix + name
+}
+
+// FromLocation returns the name of the built-in template referenced by the given template location,
+// and false if the location does not reference a built-in template.
+func FromLocation(location string) (string, bool) {
+	if !strings.HasPrefix(location, Prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(location, Prefix), true
+}
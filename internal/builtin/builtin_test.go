@@ -0,0 +1,2572 @@
+package builtin
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/format"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/parser"
+	"github.com/leorolland/genz/internal/testutils"
+	"github.com/leorolland/genz/pkg/models"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestTemplate(t *testing.T) {
+	for _, name := range Names() {
+		if _, err := Template(name); err != nil {
+			t.Errorf("unexpected error for %s: %v", name, err)
+		}
+	}
+	if _, err := Template("unknown"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestTemplateTests(t *testing.T) {
+	for _, name := range []string{"clone", "equal"} {
+		if _, err := TemplateTests(name); err != nil {
+			t.Errorf("unexpected error for %s: %v", name, err)
+		}
+	}
+	if _, err := TemplateTests("getters"); err == nil {
+		t.Error("expected error, got nil")
+	}
+	for _, name := range Names() {
+		if strings.Contains(name, ".") {
+			t.Errorf("unexpected template name %s", name)
+		}
+	}
+}
+
+func TestFromLocation(t *testing.T) {
+	name, isBuiltin := FromLocation(Location("getters"))
+	if !isBuiltin || name != "getters" {
+		t.Fatalf("expected getters, got %s (%t)", name, isBuiltin)
+	}
+	if _, isBuiltin := FromLocation("./getters.tmpl"); isBuiltin {
+		t.Fatal("expected a local template not to be built-in")
+	}
+}
+
+func TestDescription(t *testing.T) {
+	for _, name := range Names() {
+		if Description(name) == "" {
+			t.Errorf("missing description of %s", name)
+		}
+	}
+}
+
+func TestEject(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "templates")
+	written, err := Eject("clone", dir, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "clone.tmpl"), filepath.Join(dir, "clone.tmpl_test")}
+	if diff := cmp.Diff(expected, written); diff != "" {
+		t.Errorf("written files don't match expected:\n%s", diff)
+	}
+	content, err := os.ReadFile(expected[0])
+	if template, _ := Template("clone"); err != nil || string(content) != string(template) {
+		t.Errorf("unexpected ejected template: %v", err)
+	}
+
+	if _, err := Eject("clone", dir, false); err == nil {
+		t.Error("expected an error for existing files")
+	}
+	if _, err := Eject("clone", dir, true); err != nil {
+		t.Errorf("unexpected error with force: %v", err)
+	}
+	if _, err := Eject("unknown", dir, false); err == nil {
+		t.Error("expected an error for an unknown template")
+	}
+}
+
+// deterministicCode declares one type of each kind with imports, tags, directives, methods and nested structs,
+// so that every map or set used by the parser and the templates is exercised.
+const deterministicCode = `package main
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+//genz:table users
+//genz:schema public
+//genz:cache ttl=5m key=ID
+//genz:crd group=example.com
+type User struct {
+	Base
+	ID        int64             ` + "`json:\"id\" db:\"id,primary\" validate:\"min=1\" genz:\"1\"`" + `
+	Name      string            ` + "`json:\"name\" db:\"name,unique\" validate:\"max=64\" genz:\"2\"`" + `
+	Labels    map[string]string ` + "`json:\"labels\" genz:\"3\"`" + `
+	Addresses []*Address        ` + "`json:\"addresses\" genz:\"4\"`" + `
+	Homepage  *url.URL          ` + "`json:\"homepage\" genz:\"5\"`" + `
+	CreatedAt time.Time         ` + "`json:\"created_at\" genz:\"6\"`" + `
+}
+
+type Base struct {
+	Version int ` + "`json:\"version\"`" + `
+}
+
+type Address struct {
+	Street string ` + "`json:\"street\"`" + `
+	City   string ` + "`json:\"city\"`" + `
+}
+
+func (u User) Age(now time.Time) time.Duration { return now.Sub(u.CreatedAt) }
+func (u *User) Rename(name string)             { u.Name = name }
+
+func NewUser(name string) (*User, error) { return &User{Name: name}, nil }
+
+type Store interface {
+	//genz:http PUT /users
+	Save(ctx context.Context, user *User) error
+	//genz:http GET /users/{id}
+	Load(ctx context.Context, id int64) (*User, error)
+	//genz:http DELETE /users/{id}
+	Delete(ctx context.Context, id int64) error
+}
+
+type MemoryStore struct {
+	users map[int64]*User
+}
+
+func (s *MemoryStore) Save(ctx context.Context, user *User) error { return nil }
+func (s *MemoryStore) Load(ctx context.Context, id int64) (*User, error) { return s.users[id], nil }
+func (s *MemoryStore) Delete(ctx context.Context, id int64) error { return nil }
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+`
+
+// TestDeterministic renders every built-in template several times and checks that the outputs are byte-identical.
+func TestDeterministic(t *testing.T) {
+	typeNames := map[string]string{"enum": "Color", "stringer": "Color", "mock": "Store", "http": "Store", "csv": "Address",
+		"sealed": "Store", "flags": "Color"}
+	for _, name := range Names() {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			typeName, ok := typeNames[name]
+			if !ok {
+				typeName = "User"
+			}
+			render := func() string { return renderRaw(t, name, deterministicCode, typeName) }
+			if ParsesFunctions(name) {
+				render = func() string {
+					return renderWithOptions(t, name, deterministicCode, "", parser.Options{Functions: true}, nil)
+				}
+			}
+			if ParsesImplementations(name) {
+				render = func() string {
+					return renderWithOptions(t, name, deterministicCode, typeName, parser.Options{Implementations: true}, nil)
+				}
+			}
+			if name == "mapper" {
+				render = func() string {
+					src, err := renderMapper(t, deterministicCode, typeName, "", "User", nil)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					return src
+				}
+			}
+			expected := render()
+			for i := 0; i < 5; i++ {
+				if diff := cmp.Diff(expected, render()); diff != "" {
+					t.Fatalf("non deterministic output (-first +regenerated):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+// render renders the given built-in template for the given type of the given Go code,
+// as genz builtin does, and fails if the output is not valid Go code.
+func render(t *testing.T, name, goCode, typeName string) string {
+	t.Helper()
+
+	return renderWithVars(t, name, goCode, typeName, nil)
+}
+
+// renderWithVars renders the given built-in template as render does, with the given template variables.
+func renderWithVars(t *testing.T, name, goCode, typeName string, vars map[string]string) string {
+	t.Helper()
+
+	raw := renderRawWithVars(t, name, goCode, typeName, vars)
+	src, err := format.Source([]byte(raw))
+	if err != nil {
+		t.Fatalf("invalid Go code generated: %v\n%s", err, raw)
+	}
+	return string(src)
+}
+
+// assertCompiles fails if the given generated code, its imports fixed as genz does, does not compile along with the
+// given Go code.
+func assertCompiles(t *testing.T, goCode, src string) {
+	t.Helper()
+
+	assertCompilesWith(t, goCode, src, nil)
+}
+
+// assertCompilesWith fails as assertCompiles does, the generated code importing the given stub packages by import path,
+// e.g. in place of the packages generated by protoc and of their dependencies.
+func assertCompilesWith(t *testing.T, goCode, src string, stubs map[string]string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	input, output := filepath.Join(dir, "main.go"), filepath.Join(dir, "main.gen.go")
+	fixed, err := generator.FixImports(output, []byte(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	goMod := "module example.com/app\n\ngo 1.20\n"
+	for path, code := range stubs {
+		// Each stub is a module of its own, replaced by its directory.
+		stubDir := filepath.Join(dir, "stubs", filepath.FromSlash(path))
+		if err := os.MkdirAll(stubDir, 0755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(stubDir, "go.mod"), []byte("module "+path+"\n\ngo 1.20\n"), 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(stubDir, "stub.go"), []byte(code), 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		goMod += "\nrequire " + path + " v0.0.0\n\nreplace " + path + " => ./stubs/" + path + "\n"
+	}
+	files := map[string][]byte{filepath.Join(dir, "go.mod"): []byte(goMod), input: []byte(goCode), output: fixed}
+	for name, content := range files {
+		if err := os.WriteFile(name, content, 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax, Dir: dir}, ".")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, pkgErr := range pkgs[0].Errors {
+		t.Errorf("generated code does not compile: %v\n%s", pkgErr, fixed)
+	}
+}
+
+// renderRaw renders the given built-in template for the given type of the given Go code, as genz builtin does.
+func renderRaw(t *testing.T, name, goCode, typeName string) string {
+	t.Helper()
+
+	return renderRawWithVars(t, name, goCode, typeName, nil)
+}
+
+// renderRawWithVars renders the given built-in template as renderRaw does, with the given template variables.
+func renderRawWithVars(t *testing.T, name, goCode, typeName string, vars map[string]string) string {
+	t.Helper()
+
+	template, err := Template(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return generate(t, template, goCode, typeName, vars)
+}
+
+// renderWithOptions renders the given built-in template for the given type of the given Go code, or for its package
+// when the type is empty, parsed with the given options and given the template variables, as genz builtin does for the
+// templates parsing the functions or the implementations, and fails if the output is not valid Go code.
+func renderWithOptions(t *testing.T, name, goCode, typeName string, options parser.Options, vars map[string]string) string {
+	t.Helper()
+
+	template, err := Template(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pkg := testutils.CreatePkgWithCode(t, goCode)
+	parse := parser.WithOptions(options)
+	buf, err := generator.Generate(pkg, string(template), typeName, func(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+		parsedElement, err := parse(pkg, typeName)
+		parsedElement.Vars = vars
+		return parsedElement, err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		t.Fatalf("invalid Go code generated: %v\n%s", err, buf.String())
+	}
+	return string(src)
+}
+
+// renderMapper renders the mapper built-in template for the given type of the given Go code, converted to the given
+// type of the package of the given path, or of the same package when empty. It returns the raw output and the error.
+func renderMapper(t *testing.T, goCode, typeName, toPath, toName string, vars map[string]string) (string, error) {
+	t.Helper()
+
+	template, err := Template("mapper")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return generateElement(t, template, goCode, typeName, func(pkg *packages.Package, parsedElement *models.ParsedElement) error {
+		toPkg := pkg
+		if toPath != "" {
+			pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax}, toPath)
+			if err != nil {
+				return err
+			}
+			toPkg = pkgs[0]
+		}
+		to, err := parser.ParseTarget(toPkg, pkg, toName, parser.Options{})
+		parsedElement.Target = &to
+		parsedElement.Vars = vars
+		return err
+	})
+}
+
+// renderTest renders the test template of the given built-in template for the given type of the given Go code,
+// as genz builtin -with-tests does, and fails if the output is not valid Go code.
+func renderTest(t *testing.T, name, goCode, typeName string) string {
+	t.Helper()
+
+	template, err := TemplateTests(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw := generate(t, template, goCode, typeName, nil)
+	src, err := format.Source([]byte(raw))
+	if err != nil {
+		t.Fatalf("invalid Go code generated: %v\n%s", err, raw)
+	}
+	return string(src)
+}
+
+// generate renders the given template for the given type of the given Go code, with the given template variables.
+func generate(t *testing.T, template []byte, goCode, typeName string, vars map[string]string) string {
+	t.Helper()
+
+	buf, err := generateElement(t, template, goCode, typeName, func(pkg *packages.Package, parsedElement *models.ParsedElement) error {
+		parsedElement.Vars = vars
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf
+}
+
+// generateElement renders the given template for the given type of the given Go code, once completed by the given
+// function, e.g. with its target type, and returns the rendering error.
+func generateElement(
+	t *testing.T,
+	template []byte,
+	goCode, typeName string,
+	complete func(pkg *packages.Package, parsedElement *models.ParsedElement) error,
+) (string, error) {
+	t.Helper()
+
+	pkg := testutils.CreatePkgWithCode(t, goCode)
+	parse := parser.WithOptions(parser.Options{Recursive: true})
+	buf, err := generator.Generate(pkg, string(template), typeName, func(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+		parsedElement, err := parse(pkg, typeName)
+		if err != nil {
+			return parsedElement, err
+		}
+		return parsedElement, complete(pkg, &parsedElement)
+	})
+	return buf.String(), err
+}
+
+func assertContains(t *testing.T, src string, expected ...string) {
+	t.Helper()
+	for _, e := range expected {
+		if !strings.Contains(src, e) {
+			t.Errorf("expected %q in generated code:\n%s", e, src)
+		}
+	}
+}
+
+func TestGetters(t *testing.T) {
+	src := render(t, "getters", `
+	package main
+
+	import "time"
+
+	type Car struct {
+		// model of the car
+		model   string
+		created time.Time
+		Public  int
+	}
+	`, "Car")
+	assertContains(t, src,
+		"func (c *Car) GetModel() string {",
+		"// model of the car",
+		"func (c *Car) SetModel(value string) {",
+		"func (c *Car) GetCreated() time.Time {",
+		"return *new(time.Time)",
+	)
+	if strings.Contains(src, "GetPublic") {
+		t.Errorf("unexpected getter for an exported attribute:\n%s", src)
+	}
+}
+
+func TestGettersGeneric(t *testing.T) {
+	src := render(t, "getters", `
+	package main
+
+	type Box[K comparable, V any] struct {
+		key K
+	}
+	`, "Box")
+	assertContains(t, src, "func (b *Box[K, V]) GetKey() K {")
+}
+
+func TestBuilder(t *testing.T) {
+	src := render(t, "builder", `
+	package main
+
+	import "time"
+
+	type Config struct {
+		Name    string `+"`genz:\"required\"`"+`
+		timeout time.Duration
+	}
+	`, "Config")
+	assertContains(t, src,
+		"func NewConfigBuilder() *ConfigBuilder {",
+		"func (b *ConfigBuilder) WithName(value string) *ConfigBuilder {",
+		`b.set["Name"] = true`,
+		"func (b *ConfigBuilder) WithTimeout(value time.Duration) *ConfigBuilder {",
+		"func (b *ConfigBuilder) Build() (Config, error) {",
+		`for _, name := range []string{"Name"} {`,
+	)
+}
+
+func TestBuilderGeneric(t *testing.T) {
+	src := render(t, "builder", `
+	package main
+
+	type Box[K comparable, V any] struct {
+		key K
+	}
+	`, "Box")
+	assertContains(t, src,
+		"func NewBoxBuilder[K comparable, V any]() *BoxBuilder[K, V] {",
+		"func (b *BoxBuilder[K, V]) Build() (Box[K, V], error) {\n\treturn b.value, nil\n}",
+	)
+}
+
+func TestOptions(t *testing.T) {
+	src := render(t, "options", `
+	package main
+
+	import (
+		"log"
+		"time"
+	)
+
+	type Server struct {
+		addr    string        `+"`default:\":8080\"`"+`
+		timeout time.Duration `+"`default:\"1m30s\"`"+`
+		logger  *log.Logger   `+"`default:\"log.Default()\"`"+`
+		Debug   bool
+	}
+	`, "Server")
+	assertContains(t, src,
+		"type ServerOption func(*Server)",
+		"func NewServer(options ...ServerOption) *Server {",
+		"\t\taddr:    \":8080\",\n\t\ttimeout: 90 * time.Second,\n\t\tlogger:  log.Default(),\n\t}",
+		"func WithTimeout(value time.Duration) ServerOption {",
+		"\t\tv.timeout = value",
+		"func WithDebug(value bool) ServerOption {",
+	)
+}
+
+func TestOptionsGeneric(t *testing.T) {
+	src := render(t, "options", `
+	package main
+
+	type Box[K comparable, V any] struct {
+		key K
+	}
+	`, "Box")
+	assertContains(t, src,
+		"type BoxOption[K comparable, V any] func(*Box[K, V])",
+		"func NewBox[K comparable, V any](options ...BoxOption[K, V]) *Box[K, V] {",
+		"func WithKey[K comparable, V any](value K) BoxOption[K, V] {",
+	)
+}
+
+func TestConfig(t *testing.T) {
+	src := render(t, "config", `
+	package main
+
+	import "time"
+
+	type Level string
+
+	type Config struct {
+		// Port is the listening port.
+		Port    int           `+"`env:\"PORT\" flag:\"port\" default:\"8080\"`"+`
+		Timeout time.Duration `+"`flag:\"timeout\" default:\"30s\"`"+`
+		Level   Level         `+"`env:\"LEVEL\"`"+`
+		Debug   bool          `+"`flag:\"debug\"`"+`
+		Hosts   []string      `+"`env:\"HOSTS\"`"+`
+		Name    string
+	}
+	`, "Config")
+	assertContains(t, src,
+		"type ConfigSources map[string]string",
+		"func LoadConfig(flags *flag.FlagSet, args []string) (Config, ConfigSources, error) {",
+		"\t\t\"Port\": func(text string) error {\n\t\t\tparsed, err := strconv.ParseInt(text, 0, 0)\n",
+		"\t\t\tvalue.Port = int(parsed)",
+		"\t\t\tvalue.Level = Level(text)",
+		"\t\t\tvalue.Hosts = strings.Split(text, \",\")",
+		"\tif err := set(\"Timeout\", \"default\", \"30s\"); err != nil {",
+		"\tif text, ok := os.LookupEnv(\"PORT\"); ok {\n\t\tif err := set(\"Port\", \"env\", text); err != nil {",
+		"\tflags.Var(configFlag{name: \"Port\", set: set, isBool: false}, \"port\", \"Port is the listening port. (default 8080) (env PORT)\")",
+		"\tflags.Var(configFlag{name: \"Debug\", set: set, isBool: true}, \"debug\", \"\")",
+		"func (f configFlag) IsBoolFlag() bool { return f.isBool }",
+	)
+	if strings.Contains(src, "value.Name") {
+		t.Errorf("unexpected untagged attribute in:\n%s", src)
+	}
+}
+
+func TestConfigPointer(t *testing.T) {
+	goCode := `
+	package main
+
+	type Level string
+
+	type Config struct {
+		Ratio *float64 ` + "`flag:\"ratio\"`" + `
+		Level *Level   ` + "`env:\"LEVEL\" default:\"info\"`" + `
+		Debug *bool    ` + "`flag:\"debug\"`" + `
+	}
+	`
+	src := render(t, "config", goCode, "Config")
+	assertContains(t, src,
+		"\t\t\tparsed, err := strconv.ParseFloat(text, 64)\n\t\t\tif err != nil {\n\t\t\t\treturn err\n\t\t\t}\n\t\t\tvalue.Ratio = &parsed",
+		"\t\t\tconverted := Level(text)\n\t\t\tvalue.Level = &converted",
+		"\tflags.Var(configFlag{name: \"Debug\", set: set, isBool: true}, \"debug\", \"\")",
+	)
+	assertCompiles(t, goCode, src)
+}
+
+func TestConfigWithoutTags(t *testing.T) {
+	goCode := `
+	package main
+
+	type Config struct {
+		Name string
+		Port int ` + "`json:\"port\" default:\"\"`" + `
+	}
+	`
+	src := render(t, "config", goCode, "Config")
+	if strings.Contains(src, "set :=") || strings.Contains(src, "parsers :=") {
+		t.Errorf("unexpected unused parsers in:\n%s", src)
+	}
+	assertCompiles(t, goCode, src)
+}
+
+func TestConfigUnsupportedType(t *testing.T) {
+	pkg := testutils.CreatePkgWithCode(t, `
+	package main
+
+	type Config struct {
+		Ports map[string]int `+"`env:\"PORTS\"`"+`
+	}
+	`)
+	template, err := Template("config")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = generator.Generate(pkg, string(template), "Config", parser.WithOptions(parser.Options{}))
+	if err == nil || !strings.Contains(err.Error(), "unsupported type map[string]int of the attribute Ports") {
+		t.Errorf("expected an unsupported type error, got %v", err)
+	}
+}
+
+const mapperCode = `
+package main
+
+type Level int32
+
+type User struct {
+	ID     string
+	Mail   string
+	Level  Level
+	Name   string
+	Admin  bool ` + "`json:\"admin\"`" + `
+	Secret string
+}
+
+//genz:map Name=fullName,Secret=-
+type UserDTO struct {
+	Id      string
+	Email   string ` + "`map:\"Mail\"`" + `
+	Level   int32
+	IsAdmin bool ` + "`json:\"admin\"`" + `
+	First   string
+	Last    string
+}
+
+func fullName(u UserDTO) string { return u.First + " " + u.Last }
+
+type Account struct {
+	ID    int
+	Owner string
+}
+`
+
+func TestMapper(t *testing.T) {
+	src, err := renderMapper(t, mapperCode, "UserDTO", "", "User", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, src,
+		"// UserDTOToUser converts a UserDTO into a User.\nfunc UserDTOToUser(from UserDTO) User {\n\treturn User{\n",
+		"\t\tID: from.Id,\n\t\tMail: from.Email,\n\t\tLevel: Level(from.Level),\n\t\tName: fullName(from),\n\t\tAdmin: from.IsAdmin,\n\t}",
+	)
+}
+
+func TestMapperUnmapped(t *testing.T) {
+	_, err := renderMapper(t, mapperCode, "UserDTO", "", "Account", nil)
+	if err == nil || !strings.Contains(err.Error(), "cannot map UserDTO to Account: "+
+		"ID: UserDTO.Id of type string cannot be assigned to int, Owner: no attribute of UserDTO matches it") {
+		t.Errorf("expected an unmapped attributes error, got %v", err)
+	}
+
+	src, err := renderMapper(t, mapperCode, "UserDTO", "", "Account", map[string]string{"unmapped": "todo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, src,
+		"\t\t// TODO: map ID: UserDTO.Id of type string cannot be assigned to int.\n",
+		"\t\t// TODO: map Owner: no attribute of UserDTO matches it.\n",
+	)
+}
+
+func TestMapperOtherPackage(t *testing.T) {
+	src, err := renderMapper(t, `
+	package main
+
+	type Link struct {
+		Scheme string
+		Host   string
+		Path   string
+	}
+	`, "Link", "net/url", "URL", map[string]string{"unmapped": "todo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, src,
+		"func LinkToUrlURL(from Link) url.URL {\n\treturn url.URL{\n\t\tScheme: from.Scheme,\n",
+		"\t\t// TODO: map User: no attribute of Link matches it.\n",
+	)
+}
+
+func TestMock(t *testing.T) {
+	src := render(t, "mock", `
+	package main
+
+	import "context"
+
+	type Repository interface {
+		Get(ctx context.Context, id string) (int, error)
+		Delete(string) error
+		Reset()
+		Log(format string, args ...any)
+	}
+	`, "Repository")
+	assertContains(t, src,
+		"type RepositoryMock struct {",
+		"GetFunc    func(context.Context, string) (int, error)",
+		"func (m *RepositoryMock) Get(ctx context.Context, id string) (int, error) {",
+		`m.record("Get", ctx, id)`,
+		"return m.GetFunc(ctx, id)",
+		"func (m *RepositoryMock) Delete(p0 string) error {",
+		"func (m *RepositoryMock) Reset() {",
+		"\tm.ResetFunc()\n",
+		"LogFunc    func(string, ...any)",
+		"func (m *RepositoryMock) Log(format string, args ...any) {",
+		"m.LogFunc(format, args...)",
+	)
+}
+
+const interfaceCode = `
+package main
+
+import "context"
+
+type UserService struct{}
+
+// GetUser returns the user.
+//
+// It fails if the user does not exist.
+//genz:api
+func (s *UserService) GetUser(ctx context.Context, id string) (string, error) { return "", nil }
+
+//genz:api
+func (s UserService) ListUsers(ids ...string) []string { return nil }
+
+func (s *UserService) Reset() {}
+
+func (s *UserService) cache() {}
+`
+
+func TestInterface(t *testing.T) {
+	src := render(t, "interface", interfaceCode, "UserService")
+	assertContains(t, src,
+		"type UserServiceInterface interface {",
+		"\t// GetUser returns the user.\n\t//\n\t// It fails if the user does not exist.\n\tGetUser(ctx context.Context, id string) (string, error)\n",
+		"\tListUsers(ids ...string) []string\n",
+		"\tReset()\n",
+		"var _ UserServiceInterface = (*UserService)(nil)",
+	)
+	if strings.Contains(src, "cache") || strings.Contains(src, "genz:api") {
+		t.Errorf("unexpected unexported method or directive in generated code:\n%s", src)
+	}
+}
+
+func TestInterfaceFiltered(t *testing.T) {
+	byTag := renderWithVars(t, "interface", interfaceCode, "UserService", map[string]string{"interface": "API", "tag": "api"})
+	assertContains(t, byTag, "type API interface {", "GetUser(", "ListUsers(", "var _ API = (*UserService)(nil)")
+	if strings.Contains(byTag, "Reset()") {
+		t.Errorf("unexpected method without the directive:\n%s", byTag)
+	}
+
+	byPrefix := renderWithVars(t, "interface", interfaceCode, "UserService", map[string]string{"prefix": "List"})
+	assertContains(t, byPrefix, "ListUsers(")
+	if strings.Contains(byPrefix, "GetUser(") || strings.Contains(byPrefix, "Reset()") {
+		t.Errorf("unexpected method without the prefix:\n%s", byPrefix)
+	}
+}
+
+func TestClone(t *testing.T) {
+	src := render(t, "clone", `
+	package main
+
+	import "time"
+
+	type Engine struct {
+		parts []string
+	}
+
+	type Car struct {
+		created time.Time
+		engine  Engine
+		spare   *Engine
+		wheels  []int
+		options map[string]*Engine
+		next    *Car
+		grid    [][]int
+	}
+	`, "Car")
+	assertContains(t, src,
+		"func (x Car) Clone() Car {",
+		"c.engine = x.engine.Clone()",
+		"v0 := x.spare.Clone()",
+		"copy(c.wheels, x.wheels)",
+		"c.options = make(map[string]*Engine, len(x.options))",
+		"v0 := x.next.Clone()",
+		"c.grid[i0] = make([]int, len(x.grid[i0]))",
+	)
+	if strings.Contains(src, "x.created.Clone()") {
+		t.Errorf("unexpected Clone call on a type of another package:\n%s", src)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	src := render(t, "equal", `
+	package main
+
+	import "time"
+
+	type Engine struct {
+		power float64 `+"`genz:\"tolerance=0.01\"`"+`
+	}
+
+	type Car struct {
+		created time.Time
+		engine  *Engine
+		wheels  []int
+		options map[string]Engine
+		name    string
+		onStart func()
+	}
+	`, "Car")
+	assertContains(t, src,
+		"func (a Car) Equal(b Car) bool {",
+		"if !a.created.Equal(b.created) {",
+		"if a.engine != nil && !a.engine.Equal(*b.engine) {",
+		"if a.wheels[i0] != b.wheels[i0] {",
+		"if !v0.Equal(w0) {",
+		"if a.name != b.name {",
+	)
+	if strings.Contains(src, "onStart") {
+		t.Errorf("unexpected comparison of a function attribute:\n%s", src)
+	}
+
+	src = render(t, "equal", `
+	package main
+
+	type Engine struct {
+		power float64 `+"`genz:\"tolerance=0.01\"`"+`
+	}
+	`, "Engine")
+	assertContains(t, src, "if math.Abs(float64(a.power-b.power)) > 0.01 {")
+}
+
+func TestEqualGeneric(t *testing.T) {
+	goCode := `
+	package main
+
+	type Box[K comparable, V any] struct {
+		key    K
+		value  V
+		values []V
+		last   *V
+		count  int
+	}
+	`
+	src := render(t, "equal", goCode, "Box")
+	assertContains(t, src,
+		"func (a Box[K, V]) Equal(b Box[K, V]) bool {",
+		"if a.key != b.key {",
+		"if !reflect.DeepEqual(a.value, b.value) {",
+		"if !reflect.DeepEqual(a.values[i0], b.values[i0]) {",
+		"if a.last != nil && !reflect.DeepEqual(*a.last, *b.last) {",
+		"if a.count != b.count {",
+	)
+	assertCompiles(t, goCode, src)
+}
+
+func TestStringer(t *testing.T) {
+	src := render(t, "stringer", `
+	package main
+
+	type Color uint8
+
+	const (
+		Red Color = iota
+		DarkBlue // genz:"dark-blue"
+		Crimson = Red
+	)
+	`, "Color")
+	assertContains(t, src,
+		"func (i Color) String() string {",
+		"case Red:\n\t\treturn \"Red\"",
+		"case DarkBlue:\n\t\treturn \"dark-blue\"",
+		`return "Color(" + strconv.FormatUint(uint64(i), 10) + ")"`,
+	)
+	if strings.Contains(src, "case Crimson") {
+		t.Errorf("unexpected case for a duplicated value:\n%s", src)
+	}
+}
+
+func TestEnum(t *testing.T) {
+	src := render(t, "enum", `
+	package main
+
+	type Color int
+
+	const (
+		Red Color = iota
+		DarkBlue // genz:"dark-blue"
+	)
+	`, "Color")
+	assertContains(t, src,
+		"func (i Color) String() string {",
+		"func ColorFromString(s string) (Color, error) {",
+		"case \"dark-blue\":\n\t\treturn DarkBlue, nil",
+		"func (i Color) MarshalText() ([]byte, error) {",
+		"func (i *Color) UnmarshalText(text []byte) error {",
+	)
+}
+
+func TestFlags(t *testing.T) {
+	src := render(t, "flags", `
+	package main
+
+	type Permission uint8
+
+	const (
+		None Permission = 0
+		Read Permission = 1 << (iota - 1)
+		Write
+		Exec // genz:"execute"
+		All  = Read | Write | Exec // genz:"-"
+	)
+	`, "Permission")
+	assertContains(t, src,
+		"var PermissionFlags = []Permission{Read, Write, Exec}",
+		"func (p Permission) Has(flags Permission) bool {",
+		"func (p Permission) Set(flags Permission) Permission {",
+		"func (p Permission) Clear(flags Permission) Permission {",
+		"if p == 0 {\n\t\treturn \"None\"\n\t}",
+		"if p&Exec != 0 {\n\t\tnames = append(names, \"execute\")",
+		"func ParsePermission(s string) (Permission, error) {",
+		"case \"execute\":\n\t\treturn Exec, nil",
+		"func (p Permission) MarshalJSON() ([]byte, error) {",
+		"func (p *Permission) UnmarshalJSON(data []byte) error {",
+	)
+	if strings.Contains(src, "All") {
+		t.Errorf("expected the mask marked with genz:\"-\" to be left aside, got:\n%s", src)
+	}
+}
+
+func TestFlagsInvalid(t *testing.T) {
+	testCases := map[string]struct {
+		goCode   string
+		expected string
+	}{
+		"not a power of two": {
+			goCode:   "type Permission int\n\nconst (\n\tRead Permission = 1\n\tWrite Permission = 2\n\tReadWrite Permission = 3\n)\n",
+			expected: "the value 3 of the flag ReadWrite is not a power of two",
+		},
+		"negative": {
+			goCode:   "type Permission int\n\nconst Unknown Permission = -1\n",
+			expected: "the value -1 of the flag Unknown is not a power of two",
+		},
+		"not an integer": {
+			goCode:   "type Permission string\n\nconst Read Permission = \"read\"\n",
+			expected: "Permission is not an integer type",
+		},
+		"no flag": {
+			goCode:   "type Permission int\n\nconst None Permission = 0\n",
+			expected: "no flag constant of Permission",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			template, err := Template("flags")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			pkg := testutils.CreatePkgWithCode(t, "package main\n\n"+tc.goCode)
+			_, err = generator.Generate(pkg, string(template), "Permission", parser.WithOptions(parser.Options{}))
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected an error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestSlice(t *testing.T) {
+	goCode := `
+	package main
+
+	import "time"
+
+	type User struct {
+		ID        int64
+		City      string
+		Score     float64
+		Tags      []string
+		CreatedAt time.Time
+	}
+	`
+	src := renderWithVars(t, "slice", goCode, "User", map[string]string{"map_to": "string"})
+	assertContains(t, src,
+		"type UserSlice []User",
+		"func (s UserSlice) Filter(keep func(User) bool) UserSlice {",
+		"func (s UserSlice) Map(f func(User) User) UserSlice {",
+		"func (s UserSlice) MapToString(f func(User) string) []string {",
+		"func (s UserSlice) SortBy(less func(a, b User) bool) UserSlice {",
+		"return s.SortBy(func(a, b User) bool { return a.City < b.City })",
+		"return s.SortBy(func(a, b User) bool { return a.CreatedAt.Before(b.CreatedAt) })",
+		"func (s UserSlice) GroupByCity() map[string]UserSlice {",
+		"func (s UserSlice) IndexByID() map[int64]User {",
+	)
+	for _, unexpected := range []string{"SortByTags", "GroupByScore", "IndexByCreatedAt"} {
+		if strings.Contains(src, unexpected) {
+			t.Errorf("unexpected %s, got:\n%s", unexpected, src)
+		}
+	}
+
+	src = renderWithVars(t, "slice", goCode, "User", map[string]string{"helpers": "index", "by": "City"})
+	assertContains(t, src, "func (s UserSlice) IndexByCity() map[string]User {")
+	for _, unexpected := range []string{"Filter", "SortBy", "IndexByID"} {
+		if strings.Contains(src, unexpected) {
+			t.Errorf("unexpected %s, got:\n%s", unexpected, src)
+		}
+	}
+
+	src = render(t, "slice", `
+	package main
+
+	type Pair[K comparable, V any] struct {
+		Key   K
+		Value V
+	}
+	`, "Pair")
+	assertContains(t, src,
+		"type PairSlice[K comparable, V any] []Pair[K, V]",
+		"func (s PairSlice[K, V]) Filter(keep func(Pair[K, V]) bool) PairSlice[K, V] {",
+	)
+}
+
+func TestImmutable(t *testing.T) {
+	src := render(t, "immutable", `
+	package main
+
+	type Account struct {
+		// Owner is the owner of the account.
+		Owner  string
+		Tags   []string
+		Limits map[string]int
+		Parent *Account
+		secret string
+	}
+	`, "Account")
+	assertContains(t, src,
+		"type AccountView struct {\n\tvalue Account\n}",
+		"func (a Account) Freeze() AccountView {\n\treturn AccountView{value: a}\n}",
+		"//\n// Owner is the owner of the account.\nfunc (v AccountView) Owner() string {\n\treturn v.value.Owner\n}",
+		"return append([]string{}, v.value.Tags...)",
+		"copied := make(map[string]int, len(v.value.Limits))",
+		"copied := *v.value.Parent\n\treturn &copied",
+	)
+	if strings.Contains(src, "Secret") {
+		t.Errorf("expected the unexported attributes not to be exposed, got:\n%s", src)
+	}
+}
+
+func TestMockGeneric(t *testing.T) {
+	src := render(t, "mock", `
+	package main
+
+	type Store[K comparable, V any] interface {
+		Get(key K) (V, bool)
+	}
+	`, "Store")
+	assertContains(t, src,
+		"type StoreMock[K comparable, V any] struct {",
+		"GetFunc func(K) (V, bool)",
+		"func (m *StoreMock[K, V]) Get(key K) (V, bool) {",
+	)
+}
+
+func TestTypeScript(t *testing.T) {
+	src := renderRaw(t, "typescript", `package main
+
+import "time"
+
+// User is a user.
+type User struct {
+	Base
+	ID        int64             `+"`json:\"id,string\"`"+`
+	Name      string            `+"`json:\"full-name\"`"+`
+	Email     *string           `+"`json:\"email,omitempty\"`"+`
+	Admin     bool
+	Tags      []string          `+"`json:\"tags\"`"+`
+	Avatar    []byte            `+"`json:\"avatar\"`"+`
+	Labels    map[string]int    `+"`json:\"labels\"`"+`
+	Addresses []*Address        `+"`json:\"addresses\"`"+`
+	Friends   []User            `+"`json:\"friends\"`"+`
+	CreatedAt time.Time         `+"`json:\"created_at\"`"+`
+	Password  string            `+"`json:\"-\"`"+`
+	Extra     any               `+"`json:\"extra\"`"+`
+	internal  string
+}
+
+type Base struct {
+	Version int `+"`json:\"version\"`"+`
+}
+
+type Address struct {
+	// Street of the address.
+	Street string `+"`json:\"street\"`"+`
+}
+`, "User")
+	assertContains(t, src,
+		"// Code generated by genz builtin typescript. DO NOT EDIT.",
+		"/**\n * User is a user.\n */\nexport interface User extends Base {\n",
+		"  id: string;\n",
+		"  \"full-name\": string;\n",
+		"  email?: string | null;\n",
+		"  Admin: boolean;\n",
+		"  tags: string[];\n",
+		"  avatar: string;\n",
+		"  labels: Record<string, number>;\n",
+		"  addresses: Array<Address | null>;\n",
+		"  friends: User[];\n",
+		"  created_at: string;\n",
+		"  extra: unknown;\n",
+		"export interface Base {\n  version: number;\n}",
+		"export interface Address {\n  /**\n   * Street of the address.\n   */\n  street: string;\n}",
+	)
+	for _, unexpected := range []string{"Password", "internal", "Base:"} {
+		if strings.Contains(src, unexpected) {
+			t.Errorf("unexpected %q in generated code:\n%s", unexpected, src)
+		}
+	}
+}
+
+func TestTypeScriptGeneric(t *testing.T) {
+	src := renderRaw(t, "typescript", `package main
+
+type Page[T any] struct {
+	Items []T `+"`json:\"items\"`"+`
+	Total int `+"`json:\"total\"`"+`
+}
+`, "Page")
+	assertContains(t, src, "export interface Page<T> {\n  items: T[];\n  total: number;\n}")
+}
+
+func TestExtension(t *testing.T) {
+	if extension := Extension("getters"); extension != ".go" {
+		t.Errorf("expected .go, got %s", extension)
+	}
+	if extension := Extension("typescript"); extension != ".ts" {
+		t.Errorf("expected .ts, got %s", extension)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	src := renderRaw(t, "jsonschema", `package main
+
+import "time"
+
+// User is a user.
+type User struct {
+	Base
+	// Name of the user.
+	Name      string            `+"`json:\"name\" validate:\"required,min=1,max=64\"`"+`
+	Email     *string           `+"`json:\"email,omitempty\" validate:\"email\"`"+`
+	Age       int               `+"`json:\"age\" validate:\"gte=0,lt=150\"`"+`
+	Role      string            `+"`json:\"role\" validate:\"oneof=admin user\"`"+`
+	Tags      []string          `+"`json:\"tags\" validate:\"max=10\"`"+`
+	Labels    map[string]string `+"`json:\"labels\"`"+`
+	Address   *Address          `+"`json:\"address\"`"+`
+	Friends   []User            `+"`json:\"friends\"`"+`
+	CreatedAt time.Time         `+"`json:\"created_at\"`"+`
+	Height    Meters            `+"`json:\"height\" validate:\"gt=0\"`"+`
+	Password  string            `+"`json:\"-\"`"+`
+	internal  string
+}
+
+type Meters float64
+
+type Base struct {
+	ID int64 `+"`json:\"id,string\"`"+`
+}
+
+type Address struct {
+	Street string `+"`json:\"street\"`"+`
+}
+`, "User")
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(src), &schema); err != nil {
+		t.Fatalf("invalid JSON generated: %v\n%s", err, src)
+	}
+	expected := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$comment":    "Code generated by genz builtin jsonschema. DO NOT EDIT.",
+		"title":       "User",
+		"description": "User is a user.",
+		"type":        "object",
+		"properties": map[string]any{
+			"id":         map[string]any{"type": "string"},
+			"name":       map[string]any{"type": "string", "minLength": 1.0, "maxLength": 64.0, "description": "Name of the user."},
+			"email":      map[string]any{"type": []any{"string", "null"}, "format": "email"},
+			"age":        map[string]any{"type": "integer", "minimum": 0.0, "exclusiveMaximum": 150.0},
+			"role":       map[string]any{"type": "string", "enum": []any{"admin", "user"}},
+			"tags":       map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "maxItems": 10.0},
+			"labels":     map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			"address":    map[string]any{"$ref": "#/$defs/Address"},
+			"friends":    map[string]any{"type": "array", "items": map[string]any{"$ref": "#"}},
+			"created_at": map[string]any{"type": "string", "format": "date-time"},
+			"height":     map[string]any{"type": "number", "exclusiveMinimum": 0.0},
+		},
+		"required": []any{"name"},
+		"$defs": map[string]any{
+			"Address": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"street": map[string]any{"type": "string"}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("unexpected schema: %s", cmp.Diff(expected, schema))
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	src := renderRaw(t, "openapi", `package main
+
+// Order is an order.
+type Order struct {
+	ID      int64     `+"`json:\"id\" validate:\"required,gt=0\"`"+`
+	Buyer   *User     `+"`json:\"buyer\"`"+`
+	Items   []Item    `+"`json:\"items\" validate:\"min=1\"`"+`
+	Related []*Order  `+"`json:\"related,omitempty\"`"+`
+}
+
+type User struct {
+	Name    string  `+"`json:\"name\"`"+`
+	Address Address `+"`json:\"address\"`"+`
+}
+
+type Item struct {
+	Address Address `+"`json:\"address\"`"+`
+	Price   float64 `+"`json:\"price\"`"+`
+}
+
+type Address struct {
+	Street string `+"`json:\"street\"`"+`
+}
+`, "Order")
+	if !strings.HasPrefix(src, "# Code generated by genz builtin openapi. DO NOT EDIT.\n") {
+		t.Errorf("expected the generated code header:\n%s", src)
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		t.Fatalf("invalid YAML generated: %v\n%s", err, src)
+	}
+	object := func(properties map[string]any) map[string]any {
+		return map[string]any{"type": "object", "properties": properties}
+	}
+	ref := func(name string) map[string]any {
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	order := object(map[string]any{
+		"id":      map[string]any{"type": "integer", "exclusiveMinimum": 0},
+		"buyer":   ref("User"),
+		"items":   map[string]any{"type": "array", "items": ref("Item"), "minItems": 1},
+		"related": map[string]any{"type": "array", "items": ref("Order")},
+	})
+	order["description"] = "Order is an order."
+	order["required"] = []any{"id"}
+	expected := map[string]any{
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"Order":   order,
+				"User":    object(map[string]any{"name": map[string]any{"type": "string"}, "address": ref("Address")}),
+				"Item":    object(map[string]any{"address": ref("Address"), "price": map[string]any{"type": "number"}}),
+				"Address": object(map[string]any{"street": map[string]any{"type": "string"}}),
+			},
+		},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("unexpected document: %s", cmp.Diff(expected, doc))
+	}
+}
+
+func TestSQL(t *testing.T) {
+	goCode := `package main
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Model struct {
+	ID        int64     ` + "`db:\"id,primary\"`" + `
+	CreatedAt time.Time ` + "`db:\"created_at\"`" + `
+}
+
+type User struct {
+	Model
+	Email    string            ` + "`db:\"email,unique\"`" + `
+	Name     *string           ` + "`db:\"name\"`" + `
+	Nickname sql.NullString    ` + "`db:\"nickname\"`" + `
+	Admin    bool
+	Avatar   []byte            ` + "`db:\"avatar\"`" + `
+	Settings map[string]string ` + "`db:\"settings\"`" + `
+	Country  string            ` + "`db:\"country,type=CHAR(2)\"`" + `
+	Password string            ` + "`db:\"-\"`" + `
+	internal string
+}
+`
+	testCases := map[string]struct {
+		dialect  string
+		expected string
+	}{
+		"postgres": {
+			dialect: "postgres",
+			expected: `CREATE TABLE IF NOT EXISTS "users" (
+  "id" BIGINT NOT NULL,
+  "created_at" TIMESTAMPTZ NOT NULL,
+  "email" TEXT NOT NULL UNIQUE,
+  "name" TEXT,
+  "nickname" TEXT,
+  "admin" BOOLEAN NOT NULL,
+  "avatar" BYTEA NOT NULL,
+  "settings" JSONB NOT NULL,
+  "country" CHAR(2) NOT NULL,
+  PRIMARY KEY ("id")
+);
+`,
+		},
+		"mysql": {
+			dialect: "mysql",
+			expected: "CREATE TABLE IF NOT EXISTS `users` (\n" +
+				"  `id` BIGINT NOT NULL,\n" +
+				"  `created_at` DATETIME NOT NULL,\n" +
+				"  `email` VARCHAR(255) NOT NULL UNIQUE,\n" +
+				"  `name` VARCHAR(255),\n" +
+				"  `nickname` VARCHAR(255),\n" +
+				"  `admin` BOOLEAN NOT NULL,\n" +
+				"  `avatar` BLOB NOT NULL,\n" +
+				"  `settings` JSON NOT NULL,\n" +
+				"  `country` CHAR(2) NOT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				");\n",
+		},
+		"sqlite": {
+			dialect: "sqlite",
+			expected: `CREATE TABLE IF NOT EXISTS "users" (
+  "id" INTEGER NOT NULL,
+  "created_at" DATETIME NOT NULL,
+  "email" TEXT NOT NULL UNIQUE,
+  "name" TEXT,
+  "nickname" TEXT,
+  "admin" BOOLEAN NOT NULL,
+  "avatar" BLOB NOT NULL,
+  "settings" TEXT NOT NULL,
+  "country" CHAR(2) NOT NULL,
+  PRIMARY KEY ("id")
+);
+`,
+		},
+		"default dialect": {
+			expected: `CREATE TABLE IF NOT EXISTS "users" (
+  "id" BIGINT NOT NULL,`,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			src := renderRawWithVars(t, "sql", goCode, "User", map[string]string{"dialect": tc.dialect})
+			assertContains(t, src, "-- Code generated by genz builtin sql. DO NOT EDIT.\n\n", tc.expected)
+		})
+	}
+}
+
+func TestSQLTableDirective(t *testing.T) {
+	src := renderRaw(t, "sql", `package main
+
+//genz:table people
+type Person struct {
+	Name string `+"`db:\"name\"`"+`
+}
+`, "Person")
+	assertContains(t, src, `CREATE TABLE IF NOT EXISTS "people" (`)
+}
+
+func TestScan(t *testing.T) {
+	src := render(t, "scan", `
+	package main
+
+	import "time"
+
+	type Model struct {
+		ID        int64     `+"`db:\"id\"`"+`
+		CreatedAt time.Time `+"`db:\"created_at\"`"+`
+	}
+
+	type User struct {
+		Model
+		Email    string `+"`db:\"email\"`"+`
+		FullName string
+		Password string `+"`db:\"-\"`"+`
+		internal string
+	}
+	`, "User")
+	assertContains(t, src,
+		"// Code generated by genz builtin scan. DO NOT EDIT.",
+		"const UserColumns = \"id, created_at, email, full_name\"",
+		"\tUserColumnModelID        = \"id\"\n",
+		"\tUserColumnFullName       = \"full_name\"\n",
+		"func ScanUser(row interface{ Scan(dest ...any) error }) (User, error) {\n\tvar u User\n\terr := row.Scan(\n"+
+			"\t\t&u.Model.ID,\n\t\t&u.Model.CreatedAt,\n\t\t&u.Email,\n\t\t&u.FullName,\n\t)\n\treturn u, err\n}",
+		"func ScanUsers(rows *sql.Rows) ([]User, error) {",
+		"\t\tu, err := ScanUser(rows)\n",
+		"\treturn us, rows.Err()\n",
+	)
+}
+
+func TestScanGeneric(t *testing.T) {
+	src := render(t, "scan", `
+	package main
+
+	type Row[T any] struct {
+		Value T `+"`db:\"value\"`"+`
+	}
+	`, "Row")
+	assertContains(t, src,
+		"func ScanRow[T any](row interface{ Scan(dest ...any) error }) (Row[T], error) {\n\tvar v Row[T]\n",
+		"func ScanRows[T any](rows *sql.Rows) ([]Row[T], error) {",
+		"\t\tv, err := ScanRow[T](rows)\n",
+	)
+}
+
+func TestProto(t *testing.T) {
+	src := renderRaw(t, "proto", `package main
+
+import "time"
+
+// User is a user.
+type User struct {
+	// ID of the user.
+	ID        int64            `+"`protobuf:\"1\"`"+`
+	Email     *string          `+"`protobuf:\"2\"`"+`
+	Tags      []string
+	Labels    map[string]int32 `+"`protobuf:\"4\"`"+`
+	Address   *Address         `+"`protobuf:\"5\"`"+`
+	Friends   []User           `+"`protobuf:\"6\"`"+`
+	CreatedAt time.Time        `+"`protobuf:\"7\"`"+`
+	Avatar    []byte           `+"`protobuf:\"bytes,8,opt,name=avatar\"`"+`
+	Password  string           `+"`protobuf:\"-\"`"+`
+	internal  string
+}
+
+type Address struct {
+	Street string `+"`protobuf:\"1\"`"+`
+}
+`, "User")
+	expected := `// Code generated by genz builtin proto. DO NOT EDIT.
+
+syntax = "proto3";
+
+package main;
+
+import "google/protobuf/timestamp.proto";
+
+// User is a user.
+message User {
+  // ID of the user.
+  int64 id = 1;
+  optional string email = 2;
+  repeated string tags = 8038;
+  map<string, int32> labels = 4;
+  Address address = 5;
+  repeated User friends = 6;
+  google.protobuf.Timestamp created_at = 7;
+  bytes avatar = 8;
+}
+
+message Address {
+  string street = 1;
+}
+`
+	if src != expected {
+		t.Errorf("unexpected proto file: %s", cmp.Diff(expected, src))
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	src := renderRaw(t, "graphql", `package main
+
+import "time"
+
+// User is a user.
+type User struct {
+	ID        string
+	// Email of the user.
+	Email     *string
+	Age       int               `+"`json:\"years\"`"+`
+	Tags      []string
+	Address   *Address
+	CreatedAt time.Time
+	Meta      map[string]string
+	Password  string `+"`json:\"-\"`"+`
+	internal  string
+}
+
+type Address struct {
+	Street string
+}
+`, "User")
+	expected := `# Code generated by genz builtin graphql. DO NOT EDIT.
+
+scalar Map
+
+scalar Time
+
+"""
+User is a user.
+"""
+type User {
+  id: ID!
+  """
+  Email of the user.
+  """
+  email: String
+  years: Int!
+  tags: [String!]
+  address: Address
+  createdAt: Time!
+  meta: Map
+}
+
+type Address {
+  street: String!
+}
+`
+	if src != expected {
+		t.Errorf("unexpected schema: %s", cmp.Diff(expected, src))
+	}
+}
+
+func TestGraphQLInterface(t *testing.T) {
+	src := renderRaw(t, "graphql", `package main
+
+import "context"
+
+type User struct {
+	ID string
+}
+
+type Users interface {
+	Get(ctx context.Context, id string) (*User, error)
+	List() ([]User, error)
+	Delete(ctx context.Context, id string) error
+}
+`, "Users")
+	assertContains(t, src, "interface Users {\n  get(id: String!): User\n  list: [User!]\n  delete(id: String!): Boolean\n}\n")
+}
+
+func TestGRPC(t *testing.T) {
+	raw := renderRaw(t, "grpc", `package main
+
+import (
+	"context"
+	"time"
+)
+
+type Level int32
+
+// Users stores the users.
+type Users interface {
+	// Get returns a user.
+	Get(ctx context.Context, userID int) (name string, level Level, err error)
+	Tag(ctx context.Context, id string, tags ...string) error
+	Expire(after time.Duration) time.Time
+	internal()
+}
+`, "Users")
+	buf, files, err := generator.SplitFiles(*bytes.NewBufferString(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "users.proto" {
+		t.Fatalf("expected the users.proto file, got %v", files)
+	}
+	expected := `// Code generated by genz builtin grpc. DO NOT EDIT.
+
+syntax = "proto3";
+
+package main;
+
+import "google/protobuf/duration.proto";
+import "google/protobuf/timestamp.proto";
+
+option go_package = "command-line-arguments;main";
+
+// Users stores the users.
+service Users {
+  // Get returns a user.
+  rpc Get(GetRequest) returns (GetResponse);
+  rpc Tag(TagRequest) returns (TagResponse);
+  rpc Expire(ExpireRequest) returns (ExpireResponse);
+}
+
+message GetRequest {
+  int64 user_id = 1;
+}
+
+message GetResponse {
+  string name = 1;
+  int32 level = 2;
+}
+
+message TagRequest {
+  string id = 1;
+  repeated string tags = 2;
+}
+
+message TagResponse {
+}
+
+message ExpireRequest {
+  google.protobuf.Duration after = 1;
+}
+
+message ExpireResponse {
+  google.protobuf.Timestamp result = 1;
+}
+`
+	if proto := files[0].Content.String(); proto != expected {
+		t.Errorf("unexpected proto file: %s", cmp.Diff(expected, proto))
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		t.Fatalf("invalid Go code generated: %v\n%s", err, buf.String())
+	}
+	assertContains(t, string(src),
+		"type UsersGRPCServer struct {\n\tUnimplementedUsersServer\n",
+		"var _ UsersServer = (*UsersGRPCServer)(nil)",
+		"func (s *UsersGRPCServer) Get(ctx context.Context, request *GetRequest) (*GetResponse, error) {\n"+
+			"\tr0, r1, err := s.Service.Get(ctx, int(request.GetUserId()))\n"+
+			"\tif err != nil {\n\t\treturn nil, s.status(err)\n\t}\n"+
+			"\treturn &GetResponse{\n\t\tName:  r0,\n\t\tLevel: int32(r1),\n\t}, nil\n}",
+		"err := s.Service.Tag(ctx, request.GetId(), request.GetTags()...)",
+		"return &TagResponse{}, nil",
+		"r0 := s.Service.Expire(request.GetAfter().AsDuration())",
+		"Result: timestamppb.New(r0),",
+		"case errors.Is(err, fs.ErrNotExist):\n\t\t\tcode = codes.NotFound",
+	)
+	if strings.Contains(string(src), "internal") {
+		t.Errorf("unexpected unexported method in generated code:\n%s", src)
+	}
+}
+
+// grpcStubs are the stubs of the gRPC packages imported by the output of the grpc built-in template.
+var grpcStubs = map[string]string{
+	"google.golang.org/grpc/codes": `package codes
+
+type Code uint32
+
+const (
+	OK Code = iota
+	Canceled
+	Unknown
+	InvalidArgument
+	DeadlineExceeded
+	NotFound
+	AlreadyExists
+	PermissionDenied
+)
+`,
+	"google.golang.org/grpc/status": `package status
+
+import "google.golang.org/grpc/codes"
+
+type Status struct{}
+
+func FromError(err error) (*Status, bool) { return nil, false }
+
+func Error(c codes.Code, msg string) error { return nil }
+`,
+}
+
+func TestGRPCStructs(t *testing.T) {
+	goCode := `package main
+
+import "context"
+
+type Address struct {
+	City string
+}
+
+// User is a user.
+type User struct {
+	ID      int64
+	Name    string
+	Home    *Address
+	Friends []Address
+	secret  string
+}
+
+type Users interface {
+	Get(ctx context.Context, id string) (*User, error)
+	Save(ctx context.Context, user User, others ...User) error
+	List(ctx context.Context) ([]*User, error)
+}
+`
+	raw := renderRawWithVars(t, "grpc", goCode, "Users", map[string]string{"pb_package": "example.com/userspb"})
+	buf, files, err := generator.SplitFiles(*bytes.NewBufferString(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected the users.proto file, got %v", files)
+	}
+	assertContains(t, files[0].Content.String(),
+		"message GetResponse {\n  User result = 1;\n}",
+		"message SaveRequest {\n  User user = 1;\n  repeated User others = 2;\n}",
+		"message ListResponse {\n  repeated User result = 1;\n}",
+		"message Address {\n  string city = 575;\n}",
+		"// User is a user.\nmessage User {\n  int64 id = 4515;\n  string name = 13998;\n  Address home = 15716;\n  repeated Address friends = 17768;\n}",
+	)
+	assertContains(t, buf.String(),
+		"Result: userPointerToProto(r0),",
+		"s.Service.Save(ctx, userFromProto(request.GetUser()), userSliceFromProto(request.GetOthers())...)",
+		"Result: userPointerSliceToProto(r0),",
+		"Home: addressPointerToProto(v.Home),",
+		"Friends: addressSliceFromProto(m.GetFriends()),",
+	)
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("unexpected unexported attribute in generated code:\n%s", buf.String())
+	}
+
+	stubs := map[string]string{"example.com/userspb": `package userspb
+
+import "context"
+
+type Address struct{ City string }
+
+func (m *Address) GetCity() string {
+	if m == nil {
+		return ""
+	}
+	return m.City
+}
+
+type User struct {
+	Id      int64
+	Name    string
+	Home    *Address
+	Friends []*Address
+}
+
+func (m *User) GetId() int64 {
+	if m == nil {
+		return 0
+	}
+	return m.Id
+}
+
+func (m *User) GetName() string {
+	if m == nil {
+		return ""
+	}
+	return m.Name
+}
+
+func (m *User) GetHome() *Address {
+	if m == nil {
+		return nil
+	}
+	return m.Home
+}
+
+func (m *User) GetFriends() []*Address {
+	if m == nil {
+		return nil
+	}
+	return m.Friends
+}
+
+type GetRequest struct{ Id string }
+
+func (m *GetRequest) GetId() string { return m.Id }
+
+type GetResponse struct{ Result *User }
+
+type SaveRequest struct {
+	User   *User
+	Others []*User
+}
+
+func (m *SaveRequest) GetUser() *User { return m.User }
+
+func (m *SaveRequest) GetOthers() []*User { return m.Others }
+
+type SaveResponse struct{}
+
+type ListRequest struct{}
+
+type ListResponse struct{ Result []*User }
+
+type UsersServer interface {
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	Save(context.Context, *SaveRequest) (*SaveResponse, error)
+	List(context.Context, *ListRequest) (*ListResponse, error)
+}
+
+type UnimplementedUsersServer struct{}
+`}
+	for path, code := range grpcStubs {
+		stubs[path] = code
+	}
+	assertCompilesWith(t, goCode, buf.String(), stubs)
+}
+
+func TestGRPCStructsInTheirPackage(t *testing.T) {
+	pkg := testutils.CreatePkgWithCode(t, `
+	package main
+
+	type User struct{}
+
+	type Users interface {
+		Save(user *User) error
+	}
+	`)
+	template, err := Template("grpc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = generator.Generate(pkg, string(template), "Users", parser.WithOptions(parser.Options{Recursive: true}))
+	if err == nil || !strings.Contains(err.Error(), "the messages of the structs User would conflict with them in their package") {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestGRPCGeneratedPackage(t *testing.T) {
+	raw := renderRawWithVars(t, "grpc", `package main
+
+type Users interface {
+	Delete(id string) error
+}
+`, "Users", map[string]string{"pb_package": "example.com/app/gen/userspb", "proto_package": "app.users"})
+	assertContains(t, raw,
+		"package app.users;",
+		`option go_package = "example.com/app/gen/userspb";`,
+		`pb "example.com/app/gen/userspb"`,
+		"\tpb.UnimplementedUsersServer\n",
+		"var _ pb.UsersServer = (*UsersGRPCServer)(nil)",
+		"Delete(ctx context.Context, request *pb.DeleteRequest) (*pb.DeleteResponse, error)",
+	)
+}
+
+func TestGRPCUnsupportedType(t *testing.T) {
+	pkg := testutils.CreatePkgWithCode(t, `
+	package main
+
+	type User struct{}
+
+	type Users interface {
+		Save(user *User) error
+	}
+	`)
+	template, err := Template("grpc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = generator.Generate(pkg, string(template), "Users", parser.WithOptions(parser.Options{}))
+	if err == nil || !strings.Contains(err.Error(), "unsupported type *main.User of param user of Users.Save") {
+		t.Errorf("expected an unsupported type error, got %v", err)
+	}
+}
+
+const httpCode = `package main
+
+import (
+	"context"
+	"time"
+)
+
+type Level int32
+
+type User struct {
+	Name string
+}
+
+type Users interface {
+	//genz:http GET /users/{id}
+	Get(ctx context.Context, id int64) (*User, error)
+	//genz:http GET /users
+	List(ctx context.Context, level Level, since time.Duration, tags ...string) (users []User, total int, err error)
+	//genz:http POST /users/{id} http.StatusCreated
+	Save(ctx context.Context, id int64, user User) error
+	Ping()
+}
+`
+
+func TestHTTP(t *testing.T) {
+	src := render(t, "http", httpCode, "Users")
+	assertContains(t, src,
+		"func (h *UsersHTTPHandler) Register(mux *http.ServeMux) {\n"+
+			"\tmux.HandleFunc(\"GET /users/{id}\", h.Get)\n"+
+			"\tmux.HandleFunc(\"GET /users\", h.List)\n"+
+			"\tmux.HandleFunc(\"POST /users/{id}\", h.Save)\n}",
+		"func (h *UsersHTTPHandler) Get(w http.ResponseWriter, r *http.Request) {\n"+
+			"\tvar id int64\n"+
+			"\tif value := r.PathValue(\"id\"); value != \"\" {\n"+
+			"\t\tparsed, err := strconv.ParseInt(value, 10, 64)\n"+
+			"\t\tif err != nil {\n"+
+			"\t\t\th.writeError(w, http.StatusBadRequest, fmt.Errorf(\"invalid id: %w\", err))\n"+
+			"\t\t\treturn\n\t\t}\n"+
+			"\t\tid = parsed\n\t}\n"+
+			"\tr0, err := h.Service.Get(r.Context(), id)\n"+
+			"\tif err != nil {\n\t\th.writeError(w, 0, err)\n\t\treturn\n\t}\n"+
+			"\th.writeJSON(w, http.StatusOK, r0)\n}",
+		"if value := r.URL.Query().Get(\"level\"); value != \"\" {\n\t\tparsed, err := strconv.ParseInt(value, 10, 32)",
+		"level = Level(parsed)",
+		"parsed, err := time.ParseDuration(value)",
+		"since = parsed",
+		"tags := r.URL.Query()[\"tags\"]",
+		"r0, r1, err := h.Service.List(r.Context(), level, since, tags...)",
+		"h.writeJSON(w, http.StatusOK, struct {\n\t\tUsers []User `json:\"users\"`\n\t\tTotal int    `json:\"total\"`\n\t}{r0, r1})",
+		"\tvar user User\n\tif err := json.NewDecoder(r.Body).Decode(&user); err != nil {",
+		"err := h.Service.Save(r.Context(), id, user)",
+		"w.WriteHeader(http.StatusCreated)",
+		"case errors.Is(err, fs.ErrNotExist):\n\t\t\tstatus = http.StatusNotFound",
+	)
+	if strings.Contains(src, "Ping") {
+		t.Errorf("unexpected route of a method without //genz:http:\n%s", src)
+	}
+}
+
+func TestHTTPRouters(t *testing.T) {
+	chi := renderWithVars(t, "http", httpCode, "Users", map[string]string{"router": "chi"})
+	assertContains(t, chi,
+		`router.MethodFunc("GET", "/users/{id}", h.Get)`,
+		`if value := chi.URLParam(r, "id"); value != "" {`,
+	)
+	echo := renderWithVars(t, "http", httpCode, "Users", map[string]string{"router": "echo"})
+	assertContains(t, echo,
+		`e.Add("GET", "/users/:id", h.Get)`,
+		"func (h *UsersHTTPHandler) Get(c echo.Context) error {\n\tw, r := c.Response(), c.Request()",
+		`if value := c.Param("id"); value != "" {`,
+		"\t\th.writeError(w, 0, err)\n\t\treturn nil\n",
+	)
+}
+
+func TestHTTPInvalidRoutes(t *testing.T) {
+	testCases := map[string]struct {
+		code     string
+		expected string
+	}{
+		"no route": {
+			code:     "Get(id string) error",
+			expected: "no method of Users is marked with a //genz:http directive",
+		},
+		"invalid directive": {
+			code:     "//genz:http /users\n\tList() error",
+			expected: `invalid //genz:http directive "/users" of Users.List`,
+		},
+		"unmatched path parameter": {
+			code:     "//genz:http GET /users/{id}\n\tGet(name string) error",
+			expected: "path parameter {id} of Users.Get matches no param",
+		},
+		"several body params": {
+			code:     "//genz:http POST /users\n\tSave(a, b []int) error",
+			expected: "params a and b of Users.Save cannot both be read from the JSON body",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			template, err := Template("http")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			pkg := testutils.CreatePkgWithCode(t, "package main\n\ntype Users interface {\n\t"+tc.code+"\n}\n")
+			_, err = generator.Generate(pkg, string(template), "Users", parser.WithOptions(parser.Options{}))
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected an error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	src := render(t, "cache", `package main
+
+//genz:cache prefix=user ttl=90s key=OrgID,ID
+type User struct {
+	OrgID string
+	ID    int64
+	Type  string
+	Name  string
+}
+`, "User")
+	assertContains(t, src,
+		`const UserCachePrefix = "user"`,
+		"const UserCacheTTL = 90 * time.Second",
+		"func UserCacheKey(orgID string, id int64) string {\n\treturn fmt.Sprintf(\"%s:%v:%v\", UserCachePrefix, orgID, id)\n}",
+		"func (u User) CacheKey() string {\n\treturn UserCacheKey(u.OrgID, u.ID)\n}",
+		"func MarshalUserCache(value User) ([]byte, error) {\n\treturn json.Marshal(value)\n}",
+		"if err := json.Unmarshal(data, &value); err != nil {",
+	)
+	msgpack := render(t, "cache", "package main\n\n//genz:cache key=Type codec=msgpack\ntype UserRole struct {\n\tType string\n}\n", "UserRole")
+	assertContains(t, msgpack,
+		`import "github.com/vmihailenco/msgpack/v5"`,
+		`const UserRoleCachePrefix = "user_role"`,
+		"func UserRoleCacheKey(typeKey string) string {",
+		"return msgpack.Marshal(value)",
+	)
+	if strings.Contains(msgpack, "UserRoleCacheTTL") {
+		t.Errorf("unexpected TTL without ttl option:\n%s", msgpack)
+	}
+}
+
+func TestCacheInvalidDirective(t *testing.T) {
+	testCases := map[string]struct {
+		directive string
+		expected  string
+	}{
+		"no directive": {
+			expected: "missing //genz:cache directive of User",
+		},
+		"no key": {
+			directive: "//genz:cache ttl=5m",
+			expected:  "missing key of the //genz:cache directive of User",
+		},
+		"unknown option": {
+			directive: "//genz:cache key=ID size=10",
+			expected:  `invalid option "size=10" of the //genz:cache directive of User`,
+		},
+		"unknown key attribute": {
+			directive: "//genz:cache key=Email",
+			expected:  "unknown key attribute Email of User",
+		},
+		"unsupported key type": {
+			directive: "//genz:cache key=Tags",
+			expected:  "unsupported type []string of the key attribute Tags of User",
+		},
+		"invalid ttl": {
+			directive: "//genz:cache key=ID ttl=1h30m",
+			expected:  `invalid ttl "1h30m" of User`,
+		},
+		"unknown codec": {
+			directive: "//genz:cache key=ID codec=gob",
+			expected:  `unknown codec "gob" of User`,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			template, err := Template("cache")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			pkg := testutils.CreatePkgWithCode(t, "package main\n\n"+tc.directive+"\ntype User struct {\n\tID int64\n\tTags []string\n}\n")
+			_, err = generator.Generate(pkg, string(template), "User", parser.WithOptions(parser.Options{}))
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected an error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	raw := renderRaw(t, "diff", `package main
+
+import "time"
+
+type Customer struct {
+	Base
+	Name     string            `+"`json:\"name\"`"+`
+	Address  Address           `+"`json:\"address\"`"+`
+	Previous *Address          `+"`json:\"previous\"`"+`
+	Tags     []string          `+"`json:\"tags\"`"+`
+	Since    time.Time         `+"`json:\"since\"`"+`
+	Secret   string            `+"`json:\"-\"`"+`
+	OnChange func()
+	Next     *Customer         `+"`json:\"next\"`"+`
+}
+
+type Base struct {
+	Version int
+}
+
+type Address struct {
+	City string `+"`json:\"city\"`"+`
+}
+`, "Customer")
+	buf, files, err := generator.SplitFiles(*bytes.NewBufferString(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "field_change.gen.go" {
+		t.Fatalf("expected the field_change.gen.go file, got %v", files)
+	}
+	assertContains(t, files[0].Content.String(), "type FieldChange struct {\n\t// Path is")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		t.Fatalf("invalid Go code generated: %v\n%s", err, buf.String())
+	}
+	assertContains(t, string(src),
+		"func DiffCustomer(a, b Customer) []FieldChange {\n\treturn appendCustomerChanges(nil, \"\", a, b)\n}",
+		"changes = appendBaseChanges(changes, path, a.Base, b.Base)",
+		"if a.Name != b.Name {\n\t\tchanges = append(changes, FieldChange{Path: path + \"name\", Old: a.Name, New: b.Name})",
+		"changes = appendAddressChanges(changes, path+\"address.\", a.Address, b.Address)",
+		"case a.Previous == nil || b.Previous == nil:\n\t\tchanges = append(changes, FieldChange{Path: path + \"previous\", Old: a.Previous, New: b.Previous})\n"+
+			"\tdefault:\n\t\tchanges = appendAddressChanges(changes, path+\"previous.\", *a.Previous, *b.Previous)",
+		"if !reflect.DeepEqual(a.Tags, b.Tags) {",
+		"if !a.Since.Equal(b.Since) {",
+		"changes = appendCustomerChanges(changes, path+\"next.\", *a.Next, *b.Next)",
+	)
+	for _, unexpected := range []string{"Secret", "OnChange"} {
+		if strings.Contains(string(src), unexpected) {
+			t.Errorf("unexpected %s, got:\n%s", unexpected, src)
+		}
+	}
+}
+
+func TestFieldMask(t *testing.T) {
+	src := render(t, "fieldmask", `
+	package main
+
+	type User struct {
+		Base
+		Name     string   `+"`json:\"name\"`"+`
+		Address  Address  `+"`json:\"address\"`"+`
+		Previous *Address `+"`json:\"previous\"`"+`
+		Secret   string   `+"`json:\"-\"`"+`
+		internal string
+	}
+
+	type Base struct {
+		Version int `+"`json:\"version\"`"+`
+	}
+
+	type Address struct {
+		City string `+"`json:\"city\"`"+`
+	}
+	`, "User")
+	assertContains(t, src,
+		"func ApplyUserFieldMask(dst *User, src User, paths []string) error {",
+		"if path == \"*\" {\n\t\t\t*dst = src\n\t\t\tcontinue\n\t\t}",
+		"return fmt.Errorf(\"invalid field mask path %q: %w\", path, err)",
+		"name, rest, nested := strings.Cut(path, \".\")",
+		"case \"version\":\n\t\tif nested {\n\t\t\treturn fmt.Errorf(\"version has no field %q\", rest)\n\t\t}\n\t\tdst.Base.Version = src.Base.Version",
+		"case \"name\":",
+		"case \"address\":\n\t\tif !nested {\n\t\t\tdst.Address = src.Address\n\t\t\treturn nil\n\t\t}\n\t\treturn applyAddressFieldMaskPath(&dst.Address, src.Address, rest)",
+		"if dst.Previous == nil {\n\t\t\tdst.Previous = new(Address)\n\t\t}",
+		"return applyAddressFieldMaskPath(dst.Previous, value, rest)",
+		"return fmt.Errorf(\"unknown field %q of User\", name)",
+	)
+	for _, unexpected := range []string{"Secret", "internal"} {
+		if strings.Contains(src, unexpected) {
+			t.Errorf("unexpected %s, got:\n%s", unexpected, src)
+		}
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	raw := renderRaw(t, "deepcopy", `package main
+
+import "time"
+
+// CronJob is a job run on a schedule.
+//genz:crd group=batch.example.com scope=Cluster shortNames=cj
+type CronJob struct {
+	Spec   CronJobSpec    `+"`json:\"spec\"`"+`
+	Status *CronJobStatus `+"`json:\"status,omitempty\"`"+`
+}
+
+type CronJobSpec struct {
+	// Schedule is the cron schedule.
+	Schedule   string              `+"`json:\"schedule\" validate:\"min=1\"`"+`
+	Suspend    *bool               `+"`json:\"suspend,omitempty\"`"+`
+	Retries    int32               `+"`json:\"retries,omitempty\" validate:\"gte=0,lt=10\"`"+`
+	Env        map[string][]string `+"`json:\"env,omitempty\"`"+`
+	Containers []Container         `+"`json:\"containers\"`"+`
+}
+
+type Container struct {
+	Image string `+"`json:\"image\"`"+`
+}
+
+type CronJobStatus struct {
+	LastScheduleTime time.Time             `+"`json:\"lastScheduleTime\"`"+`
+	Active           map[string]*Container `+"`json:\"active,omitempty\"`"+`
+}
+`, "CronJob")
+	buf, files, err := generator.SplitFiles(*bytes.NewBufferString(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "batch.example.com_cronjobs.yaml" {
+		t.Fatalf("expected the batch.example.com_cronjobs.yaml file, got %v", files)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		t.Fatalf("invalid Go code generated: %v\n%s", err, buf.String())
+	}
+	assertContains(t, string(src),
+		"func (in *CronJob) DeepCopyInto(out *CronJob) {\n\t*out = *in\n\tin.Spec.DeepCopyInto(&out.Spec)\n"+
+			"\tif in.Status != nil {\n\t\tval0 := new(CronJobStatus)\n\t\tin.Status.DeepCopyInto(val0)\n\t\tout.Status = val0\n\t}\n}",
+		"func (in *CronJob) DeepCopy() *CronJob {",
+	)
+	if strings.Contains(string(src), "DeepCopyObject") {
+		t.Errorf("unexpected DeepCopyObject of a type without metav1.TypeMeta:\n%s", src)
+	}
+	expected := `# Code generated by genz builtin deepcopy. DO NOT EDIT.
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: cronjobs.batch.example.com
+spec:
+  group: batch.example.com
+  names:
+    kind: CronJob
+    listKind: CronJobList
+    plural: cronjobs
+    shortNames:
+      - cj
+    singular: cronjob
+  scope: Cluster
+  versions:
+    - name: v1
+      schema:
+        openAPIV3Schema:
+          description: CronJob is a job run on a schedule.
+          properties:
+            spec:
+              properties:
+                containers:
+                  items:
+                    properties:
+                      image:
+                        type: string
+                    required:
+                      - image
+                    type: object
+                  type: array
+                env:
+                  additionalProperties:
+                    items:
+                      type: string
+                    type: array
+                  type: object
+                retries:
+                  exclusiveMaximum: true
+                  format: int32
+                  maximum: 10
+                  minimum: 0
+                  type: integer
+                schedule:
+                  description: Schedule is the cron schedule.
+                  minLength: 1
+                  type: string
+                suspend:
+                  type: boolean
+              required:
+                - schedule
+                - containers
+              type: object
+            status:
+              properties:
+                active:
+                  additionalProperties:
+                    properties:
+                      image:
+                        type: string
+                    required:
+                      - image
+                    type: object
+                  type: object
+                lastScheduleTime:
+                  format: date-time
+                  type: string
+              required:
+                - lastScheduleTime
+              type: object
+          required:
+            - spec
+          type: object
+      served: true
+      storage: true
+      subresources:
+        status: {}
+`
+	if diff := cmp.Diff(expected, files[0].Content.String()); diff != "" {
+		t.Errorf("unexpected CRD manifest (-expected +got):\n%s", diff)
+	}
+
+	spec := render(t, "deepcopy", `package main
+
+type CronJobSpec struct {
+	Suspend *bool
+	Env     map[string][]string
+	Jobs    [2]*Job
+}
+
+type Job struct{}
+`, "CronJobSpec")
+	assertContains(t, spec,
+		"\tif in.Suspend != nil {\n\t\tval0 := new(bool)\n\t\t*val0 = *in.Suspend\n\t\tout.Suspend = val0\n\t}\n",
+		"\t\tfor key0, val0 := range in.Env {\n\t\t\tcopied0 := val0\n\t\t\tif val0 != nil {\n"+
+			"\t\t\t\tcopied0 = make([]string, len(val0))\n\t\t\t\tcopy(copied0, val0)\n\t\t\t}\n\t\t\tout.Env[key0] = copied0\n",
+		"\tfor i0 := range in.Jobs {\n\t\tif in.Jobs[i0] != nil {\n\t\t\tval1 := new(Job)\n\t\t\tin.Jobs[i0].DeepCopyInto(val1)\n",
+	)
+}
+
+const providersCode = `package main
+
+type Store interface {
+	Get(id string) (string, error)
+}
+
+type Clock interface {
+	Now() int64
+}
+
+type Config struct{}
+
+type SQLStore struct{}
+
+func (s *SQLStore) Get(id string) (string, error) { return id, nil }
+func (s *SQLStore) Now() int64                    { return 0 }
+
+type MemoryClock struct{}
+
+func (c MemoryClock) Now() int64 { return 0 }
+
+type Service struct{}
+
+func NewConfig() Config                         { return Config{} }
+func NewSQLStore(cfg Config) (*SQLStore, error) { return &SQLStore{}, nil }
+func NewMemoryClock() MemoryClock               { return MemoryClock{} }
+func NewService(store Store) *Service           { return &Service{} }
+func NewPair() (int, int)                       { return 0, 0 }
+func NewBox[T any](value T) T                   { return value }
+func newHidden() int                            { return 0 }
+func Open() (*SQLStore, error)                  { return nil, nil }
+`
+
+func TestProviders(t *testing.T) {
+	wire := renderWithOptions(t, "providers", providersCode, "", parser.Options{Functions: true}, nil)
+	assertContains(t, wire, `import "github.com/google/wire"`, `var ProviderSet = wire.NewSet(
+	NewConfig,
+	NewSQLStore,
+	NewMemoryClock,
+	NewService,
+	wire.Bind(new(Store), new(*SQLStore)),
+)`)
+	fx := renderWithOptions(t, "providers", providersCode, "", parser.Options{Functions: true}, map[string]string{"injector": "fx"})
+	assertContains(t, fx, `import "go.uber.org/fx"`, `var Module = fx.Module("main",
+	fx.Provide(
+		NewConfig,
+		fx.Annotate(NewSQLStore, fx.As(fx.Self()), fx.As(new(Store))),
+		NewMemoryClock,
+		NewService,
+	),
+)`)
+}
+
+func TestSealed(t *testing.T) {
+	src := renderWithOptions(t, "sealed", `package main
+
+type Shape interface {
+	area() float64
+}
+
+type Circle struct{ R float64 }
+
+func (c Circle) area() float64 { return 3 * c.R * c.R }
+
+type Square struct{ S float64 }
+
+func (s *Square) area() float64 { return s.S * s.S }
+`, "Shape", parser.Options{Implementations: true}, nil)
+	assertContains(t, src,
+		"\t_ Shape = Circle{}\n\t_ Shape = (*Square)(nil)\n",
+		"func SwitchShape(v Shape, onCircle func(Circle), onSquare func(*Square)) error {",
+		"\tcase *Square:\n\t\tif onSquare != nil {\n\t\t\tonSquare(v)\n\t\t}\n",
+		"type ShapeVisitor interface {\n\tVisitCircle(Circle) error\n\tVisitSquare(*Square) error\n}",
+		"func VisitShape(v Shape, visitor ShapeVisitor) error {",
+		"\tcase Circle:\n\t\treturn visitor.VisitCircle(v)\n",
+	)
+
+	template, err := Template("sealed")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pkg := testutils.CreatePkgWithCode(t, "package main\n\ntype Shape interface {\n\tarea() float64\n}\n")
+	_, err = generator.Generate(pkg, string(template), "Shape", parser.WithOptions(parser.Options{Implementations: true}))
+	if err == nil || !strings.Contains(err.Error(), "no struct of package main implements Shape") {
+		t.Errorf("expected an error for an interface without implementation, got %v", err)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	src := render(t, "csv", `package main
+
+import "time"
+
+type Level int32
+
+type Base struct {
+	ID int64 `+"`csv:\"id\"`"+`
+}
+
+type User struct {
+	Base
+	Name      string
+	Level     Level
+	Score     *float64
+	Active    bool          `+"`csv:\"is_active\"`"+`
+	CreatedAt time.Time     `+"`csv:\"created_at\"`"+`
+	BirthDate time.Time     `+"`csv:\"birth_date,format=DateOnly\"`"+`
+	Timeout   time.Duration `+"`csv:\"timeout\"`"+`
+	Password  string        `+"`csv:\"-\"`"+`
+	internal  string
+}
+`, "User")
+	assertContains(t, src,
+		`const UserCSVHeader = "id,name,level,score,is_active,created_at,birth_date,timeout"`,
+		`var UserCSVColumns = []string{"id", "name", "level", "score", "is_active", "created_at", "birth_date", "timeout"}`,
+		"\trow[0] = strconv.FormatInt(u.Base.ID, 10)\n",
+		"\trow[2] = strconv.FormatInt(int64(u.Level), 10)\n",
+		"\tif u.Score != nil {\n\t\trow[3] = strconv.FormatFloat(*u.Score, 'g', -1, 64)\n\t}\n",
+		"\trow[5] = u.CreatedAt.Format(time.RFC3339)\n",
+		"\trow[6] = u.BirthDate.Format(time.DateOnly)\n",
+		"\trow[7] = u.Timeout.String()\n",
+		"\tif field := row[2]; field != \"\" {\n"+
+			"\t\tvalue, err := strconv.ParseInt(field, 10, 32)\n"+
+			"\t\tif err != nil {\n\t\t\treturn fmt.Errorf(\"invalid level column: %w\", err)\n\t\t}\n"+
+			"\t\tu.Level = Level(value)\n\t}",
+		"\tif field := row[1]; field != \"\" {\n\t\tu.Name = field\n\t}",
+		"u.Score = &parsed",
+		"parsed, err := time.Parse(time.DateOnly, field)",
+		"parsed, err := time.ParseDuration(field)",
+		"func WriteUsersCSV(w io.Writer, values []User) error {",
+		"writer.Comma = ','",
+		"func ReadUsersCSV(r io.Reader) ([]User, error) {",
+	)
+	if strings.Contains(src, "Password") || strings.Contains(src, "internal") {
+		t.Errorf("unexpected ignored attribute in generated code:\n%s", src)
+	}
+	tab := renderWithVars(t, "csv", "package main\n\ntype User struct {\n\tName string\n\tAge int\n}\n", "User",
+		map[string]string{"delimiter": `\t`, "time_format": "02/01/2006"})
+	assertContains(t, tab, `const UserCSVHeader = "name\tage"`, `reader.Comma = '\t'`)
+}
+
+func TestCloneTests(t *testing.T) {
+	src := renderTest(t, "clone", `
+	package main
+
+	type Engine struct{}
+
+	type Car struct {
+		name   string
+		engine *Engine
+		wheels []int
+		speed  *float64
+	}
+	`, "Car")
+	assertContains(t, src,
+		"func cloneSampleCar() Car {\n\tvar x Car\n\tx.name = \"a\"\n\tx.engine = &Engine{}\n\tx.wheels = []int{int(1)}\n"+
+			"\tx.speed = func() *float64 { v := float64(1); return &v }()\n\treturn x\n}",
+		"func TestCarClone(t *testing.T) {",
+		"func TestCarCloneIsDeep(t *testing.T) {",
+		"\tc.wheels[0] = *new(int)\n\t*c.speed = *new(float64)\n",
+	)
+	if strings.Contains(src, "*c.engine") {
+		t.Errorf("unexpected modification of an empty struct:\n%s", src)
+	}
+}
+
+func TestEqualTests(t *testing.T) {
+	src := renderTest(t, "equal", `
+	package main
+
+	type Engine struct{}
+
+	type Car struct {
+		name   string
+		engine Engine
+		weight float64 `+"`genz:\"tolerance=0.1\"`"+`
+	}
+	`, "Car")
+	assertContains(t, src,
+		"func TestCarEqual(t *testing.T) {",
+		"\tbname := equalSampleCar()\n\tbname.name = *new(string)\n\tif a.Equal(bname) || bname.Equal(a) {\n",
+	)
+	for _, unexpected := range []string{"bengine", "bweight"} {
+		if strings.Contains(src, unexpected) {
+			t.Errorf("unexpected %s in generated code:\n%s", unexpected, src)
+		}
+	}
+}
+
+func TestCloneTestsGeneric(t *testing.T) {
+	src := renderTest(t, "clone", `
+	package main
+
+	type Box[T any] struct {
+		items []T
+	}
+	`, "Box")
+	if strings.Contains(src, "func ") {
+		t.Errorf("unexpected tests of a generic type:\n%s", src)
+	}
+}
+
+func TestFixture(t *testing.T) {
+	src := render(t, "fixture", `
+	package main
+
+	import "time"
+
+	type Level string
+
+	type Address struct {
+		City string
+	}
+
+	type User struct {
+		ID        int64
+		Name      string
+		Contact   string            `+"`validate:\"required,email\"`"+`
+		Website   *string           `+"`fake:\"url\"`"+`
+		Company   string            `+"`fake:\"Acme\"`"+`
+		Age       int               `+"`fake:\"42\"`"+`
+		Secret    string            `+"`fake:\"-\"`"+`
+		Level     Level
+		Score     float64
+		Active    bool
+		Tags      []string
+		Labels    map[string]string
+		CreatedAt time.Time
+		Address   Address
+		err       error
+	}
+	`, "User")
+	assertContains(t, src,
+		"func NewUserFixture(overrides ...func(*User)) User {",
+		"\t\tID:        1,\n",
+		"\t\tName:      \"Jane Doe\",\n",
+		"\t\tContact:   \"jane.doe@example.com\",\n",
+		"\t\tWebsite:   func() *string { var v string = \"https://example.com\"; return &v }(),\n",
+		"\t\tCompany:   \"Acme\",\n",
+		"\t\tAge:       42,\n",
+		"\t\tLevel:     \"level\",\n",
+		"\t\tScore:     1.5,\n",
+		"\t\tActive:    true,\n",
+		"\t\tTags:      []string{\"tags\"},\n",
+		"\t\tLabels:    map[string]string{\"key\": \"labels\"},\n",
+		"\t\tCreatedAt: time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC),\n",
+		"\tfor _, override := range overrides {\n\t\toverride(&value)\n\t}",
+	)
+	for _, unexpected := range []string{"Secret:", "Address:", "err:"} {
+		if strings.Contains(src, unexpected) {
+			t.Errorf("unexpected %s in:\n%s", unexpected, src)
+		}
+	}
+}
+
+func TestFixtureGeneric(t *testing.T) {
+	src := render(t, "fixture", `
+	package main
+
+	type Box[K comparable, V any] struct {
+		key   K
+		count int
+	}
+	`, "Box")
+	assertContains(t, src,
+		"func NewBoxFixture[K comparable, V any](overrides ...func(*Box[K, V])) Box[K, V] {",
+		"\tvalue := Box[K, V]{\n\t\tcount: 1,\n\t}",
+	)
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Entry is the state of a target when it was last generated.
+type Entry struct {
+	// Key is the hash of what the generation depends on besides the source files, e.g. the version of genz and the
+	// templates of the target. The options of the target identify the entry itself, see Cache.Load.
+	Key string `json:"key"`
+	// Inputs are the hashes of the package directories the generation read, by directory. See HashDir.
+	Inputs map[string]string `json:"inputs"`
+	// Outputs are the hashes of the written files, by absolute file name.
+	Outputs map[string]string `json:"outputs"`
+}
+
+// Cache stores one Entry per target in a directory, e.g. ".genz-cache".
+type Cache struct {
+	dir string
+}
+
+// New returns the cache stored in the given directory. The directory is created by the first Store.
+func New(dir string) Cache {
+	return Cache{dir: dir}
+}
+
+// Load returns the entry of the given target, identified by its options, and false if there is none or if it cannot be read.
+func (c Cache) Load(target string) (Entry, bool) {
+	content, err := os.ReadFile(c.path(target))
+	if err != nil {
+		return Entry{}, false
+	}
+	var entry Entry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Store saves the entry of the given target, replacing the previous one.
+func (c Cache) Store(target string, entry Entry) error {
+	content, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", c.dir, err)
+	}
+	if err := os.WriteFile(c.path(target), content, 0644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+func (c Cache) path(target string) string {
+	return filepath.Join(c.dir, Hash([]byte(target))+".json")
+}
+
+// Fresh returns true if the entry has the given key, none of its inputs changed,
+// and its outputs are still on disk as they were written.
+// The outputs are excluded from the hashes of the input directories, as HashDir does.
+func (e Entry) Fresh(key string) bool {
+	if e.Key != key || len(e.Outputs) == 0 {
+		return false
+	}
+	for name, hash := range e.Outputs {
+		if current, err := HashFile(name); err != nil || current != hash {
+			return false
+		}
+	}
+	for dir, hash := range e.Inputs {
+		if current, err := HashDir(dir, e.Outputs); err != nil || current != hash {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash returns the hex encoded SHA-256 of the given parts.
+// The parts are length-prefixed, so that ("ab", "c") and ("a", "bc") have different hashes.
+func Hash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HashFile returns the hash of the content of the given file.
+func HashFile(name string) (string, error) {
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return Hash(content), nil
+}
+
+// HashDir returns the hash of the names and the contents of the .go files of the given directory,
+// test files and the excluded files (e.g. the outputs of the target) aside.
+// Adding, removing or editing a file of the package changes the hash.
+func HashDir(dir string, excluded map[string]string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if _, isExcluded := excluded[path]; isExcluded {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts [][]byte
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, []byte(name), content)
+	}
+	return Hash(parts...), nil
+}
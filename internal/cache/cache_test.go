@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashIsLengthPrefixed(t *testing.T) {
+	if Hash([]byte("ab"), []byte("c")) == Hash([]byte("a"), []byte("bc")) {
+		t.Fatal("expected different hashes")
+	}
+	if Hash([]byte("a")) != Hash([]byte("a")) {
+		t.Fatal("expected equal hashes")
+	}
+}
+
+func TestLoadStore(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), ".genz-cache"))
+	if _, found := c.Load("target"); found {
+		t.Fatal("expected no entry")
+	}
+	entry := Entry{Key: "key", Inputs: map[string]string{"dir": "1"}, Outputs: map[string]string{"out.go": "2"}}
+	if err := c.Store("target", entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, found := c.Load("target")
+	if !found {
+		t.Fatal("expected an entry")
+	}
+	if loaded.Key != "key" || loaded.Inputs["dir"] != "1" || loaded.Outputs["out.go"] != "2" {
+		t.Fatalf("unexpected entry %+v", loaded)
+	}
+	if _, found := c.Load("other target"); found {
+		t.Fatal("expected no entry for another target")
+	}
+}
+
+func TestFresh(t *testing.T) {
+	testCases := map[string]struct {
+		key    string
+		change func(t *testing.T, dir string)
+		fresh  bool
+	}{
+		"unchanged": {
+			key:    "key",
+			change: func(t *testing.T, dir string) {},
+			fresh:  true,
+		},
+		"template changed": {
+			key:    "other key",
+			change: func(t *testing.T, dir string) {},
+		},
+		"source edited": {
+			key: "key",
+			change: func(t *testing.T, dir string) {
+				write(t, filepath.Join(dir, "a.go"), "package a\n\ntype A struct{ B int }\n")
+			},
+		},
+		"source added": {
+			key: "key",
+			change: func(t *testing.T, dir string) {
+				write(t, filepath.Join(dir, "b.go"), "package a\n")
+			},
+		},
+		"test file added": {
+			key: "key",
+			change: func(t *testing.T, dir string) {
+				write(t, filepath.Join(dir, "a_test.go"), "package a\n")
+			},
+			fresh: true,
+		},
+		"output edited": {
+			key: "key",
+			change: func(t *testing.T, dir string) {
+				write(t, filepath.Join(dir, "a.gen.go"), "package a\n\n// edited\n")
+			},
+		},
+		"output removed": {
+			key: "key",
+			change: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "a.gen.go")); err != nil {
+					t.Fatalf("failed while removing file: %v", err)
+				}
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			write(t, filepath.Join(dir, "a.go"), "package a\n\ntype A struct{}\n")
+			write(t, filepath.Join(dir, "a.gen.go"), "package a\n")
+
+			entry := Entry{Key: "key", Inputs: map[string]string{}, Outputs: map[string]string{}}
+			output, err := HashFile(filepath.Join(dir, "a.gen.go"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			entry.Outputs[filepath.Join(dir, "a.gen.go")] = output
+			input, err := HashDir(dir, entry.Outputs)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			entry.Inputs[dir] = input
+
+			tc.change(t, dir)
+			if fresh := entry.Fresh(tc.key); fresh != tc.fresh {
+				t.Fatalf("expected fresh %t, got %t", tc.fresh, fresh)
+			}
+		})
+	}
+}
+
+func write(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatalf("failed while writing file: %v", err)
+	}
+}
@@ -0,0 +1,405 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/leorolland/genz/internal/builtin"
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/remote"
+	"github.com/leorolland/genz/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileName is the name of the configuration file, looked up from the current directory to the module root.
+	FileName = "genz.yaml"
+	// Stdio is the input reading the Go source of a single file from the standard input, and the output writing the
+	// generated code to the standard output. e.g. "genz -type Foo -template x.tmpl -"
+	Stdio = "-"
+
+	// WriteOverwrite is the default write strategy: the outputs are written as generated.
+	WriteOverwrite = "overwrite"
+	// WriteSkipIfExists writes the outputs which do not exist yet only, e.g. files scaffolded once then edited by hand.
+	WriteSkipIfExists = "skip-if-exists"
+	// WriteRegion only rewrites the regions of the existing outputs between the "genz:begin" and "genz:end" markers,
+	// keeping the hand edits elsewhere, see generator.MergeRegions. The outputs which do not exist yet are written whole.
+	WriteRegion = "region"
+)
+
+// envRegex matches the references to the environment variables of the configuration file, e.g. "${HOME}" or
+// "${TABLE:-users}", and the escaped dollar signs "$$".
+var envRegex = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+type (
+	// OutputData is given to the Output of a target written as a template, e.g. "{{ .Type | snakecase }}_gen.go".
+	OutputData struct {
+		// Type is the name of the type rendered into the output, the first one when the types are combined,
+		// or empty when the template is rendered once for the package.
+		Type string
+	}
+
+	// Config is the content of a genz.yaml file.
+	Config struct {
+		// Targets is the list of generations to run.
+		Targets []Target `yaml:"targets"`
+	}
+
+	// Target is a single generation: a type of an input package rendered with a template into an output file.
+	Target struct {
+		// Type is the name of the type to parse. Shorthand for a single element Types.
+		Type string `yaml:"type"`
+		// Types is the list of the names of the types to parse. The package is loaded once for all of them.
+		// A name containing a wildcard (e.g. "*DTO") selects all the matching types of the package.
+		// Two names, one of them at least qualified with its package as To (e.g. ["./v1.User", "./v2.User"]), are
+		// compared: they are parsed into .Left and .Right, and the template is rendered once.
+		Types []string `yaml:"types"`
+		// TypeRegex is a regular expression selecting types of the package, in addition to Types.
+		TypeRegex string `yaml:"type-regex"`
+		// Template is the go-template local file, remote URL, file of a Go module version (e.g.
+		// "github.com/org/templates/builder.tmpl@v1.2.0") or built-in template (e.g. "builtin:getters").
+		Template string `yaml:"template"`
+		// TemplateDir is a directory of templates: all its *.tmpl files, subdirectories included, are parsed together
+		// so that they can share their {{ define }} blocks. Template is then the entrypoint, relative to TemplateDir,
+		// "main.tmpl" by default. It can be a directory of a Go module version, e.g. "github.com/org/templates/api@v1.2.0".
+		TemplateDir string `yaml:"template-dir"`
+		// Output is the output file name, or Stdio. Only .go outputs are gofmt-ed and have their imports fixed.
+		// Default: <input directory>/<type>.gen.go, or Stdio when the input is Stdio.
+		// It can only be set for several types when Combine is true, unless it is a template rendered for each type
+		// with OutputData, e.g. "models/{{ .Type | snakecase }}_gen.go".
+		Output string `yaml:"output"`
+		// Suffix ends the default names of the outputs, before their extension, e.g. "_gen" for <type>_gen.go, or
+		// "_gen_linux" for the outputs to only be built on Linux. Default: ".gen", for <type>.gen.go.
+		Suffix string `yaml:"suffix"`
+		// Build is the //go:build constraint expression added to the Go outputs, e.g. "linux && amd64". It is combined
+		// with the constraint written by the template, if any.
+		Build string `yaml:"build"`
+		// Combine renders all the types into a single output file, named after the first type by default.
+		Combine bool `yaml:"combine"`
+		// Inputs is either one package directory, a list of files of a single package, a package pattern
+		// (e.g. "./...") generating each matching package into its own directory, or Stdio. Default: "."
+		Inputs []string `yaml:"inputs"`
+		// Tags is the list of build tags to apply when loading the package.
+		Tags []string `yaml:"tags"`
+		// Tests includes the _test.go files of the packages, so that test-only types can be generation sources.
+		// The default names of the Go outputs then end with _test.go, as they may use these types.
+		Tests bool `yaml:"tests"`
+		// BuildFlags are given to the build system when loading the packages, e.g. ["-mod=vendor"].
+		BuildFlags []string `yaml:"build-flags"`
+		// AllowErrors generates from the packages having compile errors, e.g. referencing the code not generated yet.
+		AllowErrors bool `yaml:"allow-errors"`
+		// FlattenEmbedded replaces embedded structs by their promoted attributes.
+		FlattenEmbedded bool `yaml:"flatten-embedded"`
+		// Recursive parses the struct types of the attributes declared in the same module.
+		Recursive bool `yaml:"recursive"`
+		// IncludeTags keeps only the attributes having one of these tags: a tag key, e.g. "json", or a tag key and the
+		// name of the tag, e.g. `db:"id"`.
+		IncludeTags []string `yaml:"include-tags"`
+		// ExcludeTags removes the attributes having one of these tags, e.g. `genz:"-"` or `json:"-"`. See IncludeTags.
+		ExcludeTags []string `yaml:"exclude-tags"`
+		// ExcludeUnexported removes the unexported attributes.
+		ExcludeUnexported bool `yaml:"exclude-unexported"`
+		// Functions parses the top-level functions of the package, available as .Functions in the template.
+		// With Functions, the types are optional: the template is then rendered once for the package.
+		Functions bool `yaml:"functions"`
+		// Values parses the exported constants and variables of the package, available as .Package in the template.
+		// With Values, as with Functions, the types are optional.
+		Values bool `yaml:"values"`
+		// Positions fills the source positions of the elements, attributes and methods: .File, .Line and .Column.
+		Positions bool `yaml:"positions"`
+		// Implementations lists the structs of the package implementing an interface, available as .Implementations in
+		// the template.
+		Implementations bool `yaml:"implementations"`
+		// WithTests renders the test template of Template, e.g. "validator.tmpl_test" for "validator.tmpl",
+		// into the companion _test.go file of each output, e.g. "human.gen_test.go" for "human.gen.go".
+		WithTests bool `yaml:"with-tests"`
+		// To is the type the types are converted to, available as .Target in the template, e.g. for the mapper built-in
+		// generator. It is qualified with the path or the directory of its package when declared in another package,
+		// e.g. "./domain.User" or "github.com/foo/bar/domain.User".
+		To string `yaml:"to"`
+		// Interfaces are interfaces parsed along with the types, available as .Interfaces in the template, e.g. to
+		// generate a facade combining them. They are qualified as To when declared in another package, e.g.
+		// ["Reader", "io.Closer", "./store.Repository"]. With Interfaces, the types are optional: the template is then
+		// rendered once for the package.
+		Interfaces []string `yaml:"interfaces"`
+		// Funcs are the files of custom template functions: templates whose {{ define "name" }} blocks are functions,
+		// or Go plugins (.so) exporting a Funcs map, see generator.LoadFuncs. e.g. ["templates/funcs.tmpl"]
+		Funcs []string `yaml:"funcs"`
+		// Plugin is the external generator run instead of a template, see the plugin package: the genz-plugin-<name>
+		// executable of the PATH, e.g. "foo" for genz-plugin-foo, or the path of an executable, e.g. "./bin/foo".
+		Plugin string `yaml:"plugin"`
+		// Vars are the variables given to the template as .Vars, e.g. a table name, an endpoint prefix or a feature toggle:
+		// {{ .Vars.table }}, or the dialect of the sql built-in generator. Set with vars: {table: users} or -var table=users,
+		// which overrides the vars of genz.yaml.
+		Vars map[string]string `yaml:"vars"`
+		// Post are the command lines run after the generation, one after the other, e.g. "go vet ./..." or
+		// "gofmt -w $GENZ_FILES" (the written files): see the hooks package. They are not run when no file was written.
+		Post []string `yaml:"post"`
+		// Dir is the directory of the configuration file declaring the target, which its Post commands are run from.
+		// Default: the current directory.
+		Dir string `yaml:"-"`
+		// DryRun does not write the outputs, and fails if some of them are out of date. Set from the command line.
+		DryRun bool `yaml:"-"`
+		// Diff prints the unified diff of the outputs which are out of date, which are still written without DryRun.
+		// Set from the command line.
+		Diff bool `yaml:"-"`
+		// Cache is the directory of the incremental generation cache, e.g. ".genz-cache": the target is skipped when
+		// neither its package, nor the module packages it imports, nor its templates changed. Set from the command line.
+		Cache string `yaml:"-"`
+		// Raw writes the rendered template as is, without gofmt nor imports fix, e.g. for non-Go outputs.
+		Raw bool `yaml:"raw"`
+		// Header is the template of the comment prepended to the generated Go files which are not marked as generated
+		// yet, given the .Version of genz and its .Command line. Default: generator.DefaultHeader, "none" to disable it.
+		Header string `yaml:"header"`
+		// Protect refuses to overwrite the existing outputs which are not marked as generated, by a
+		// "Code generated ... DO NOT EDIT." comment, e.g. a hand-written file named as an output by mistake.
+		Protect bool `yaml:"protect"`
+		// Write is the strategy of writing the outputs: WriteOverwrite (default), WriteSkipIfExists or WriteRegion.
+		Write string `yaml:"write"`
+		// NoImportsFix keeps the imports of the generated file as rendered.
+		// By default, the missing imports are added and the unused ones removed, like goimports.
+		NoImportsFix bool `yaml:"no-imports-fix"`
+	}
+)
+
+// String returns the types selected by the target, e.g. "Human,Car,/.*Event$/".
+func (t Target) String() string {
+	selectors := append([]string{}, t.Types...)
+	if t.TypeRegex != "" {
+		selectors = append(selectors, "/"+t.TypeRegex+"/")
+	}
+	if len(selectors) == 0 && t.Functions {
+		return "functions"
+	}
+	if len(selectors) == 0 && t.Values {
+		return "values"
+	}
+	if len(selectors) == 0 && len(t.Interfaces) > 0 {
+		return "interfaces"
+	}
+	return strings.Join(selectors, ",")
+}
+
+// Compares returns true if the types of the target are compared, one of them at least being qualified with its
+// package, e.g. ["User", "./v2.User"]. See Types.
+func (t Target) Compares() bool {
+	for _, name := range t.Types {
+		if strings.Contains(name, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCompared returns an error if the target compares types, but not exactly two of them.
+func (t Target) CheckCompared() error {
+	if t.Compares() && (len(t.Types) != 2 || t.TypeRegex != "") {
+		return fmt.Errorf("comparing types requires exactly two types and no type regex, e.g. ./v1.User and ./v2.User")
+	}
+	return nil
+}
+
+// ParsesPackage returns true if the target parses the functions, the values or some interfaces of the package,
+// in which case its types are optional.
+func (t Target) ParsesPackage() bool {
+	return t.Functions || t.Values || len(t.Interfaces) > 0
+}
+
+// Find returns the path of the closest genz.yaml file, looking from dir up to the module root (the directory holding go.mod).
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		candidate := filepath.Join(dir, FileName)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", fmt.Errorf("no %s found", FileName)
+}
+
+// Load reads and validates the configuration file at the given path.
+// Relative paths of the targets are resolved from the directory of the configuration file.
+func Load(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file %s: %w", path, err)
+	}
+	var config Config
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+	}
+	if err := interpolate(&root); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+	}
+	if err := root.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range config.Targets {
+		target := &config.Targets[i]
+		if target.Type != "" {
+			target.Types = append([]string{target.Type}, target.Types...)
+			target.Type = ""
+		}
+		if len(target.Types) == 0 && target.TypeRegex == "" && !target.ParsesPackage() {
+			return nil, fmt.Errorf("target %d of %s: missing 'type'", i, path)
+		}
+		if target.Template == "" && target.TemplateDir == "" && target.Plugin == "" {
+			return nil, fmt.Errorf("target %d of %s: missing 'template'", i, path)
+		}
+		if target.Plugin != "" && (target.Template != "" || target.TemplateDir != "") {
+			return nil, fmt.Errorf("target %d of %s: 'plugin' cannot be set with 'template'", i, path)
+		}
+		if target.Plugin != "" && len(target.Funcs) > 0 {
+			return nil, fmt.Errorf("target %d of %s: 'funcs' cannot be set with 'plugin'", i, path)
+		}
+		for j := range target.Types {
+			target.Types[j] = resolveQualified(dir, target.Types[j])
+		}
+		if err := target.CheckCompared(); err != nil {
+			return nil, fmt.Errorf("target %d of %s: %w", i, path, err)
+		}
+		if err := CheckWrite(target.Write); err != nil {
+			return nil, fmt.Errorf("target %d of %s: %w", i, path, err)
+		}
+		if len(target.Inputs) == 0 {
+			target.Inputs = []string{"."}
+		}
+		target.Dir = dir
+		for j := range target.Funcs {
+			target.Funcs[j] = resolve(dir, target.Funcs[j])
+		}
+		for j := range target.Inputs {
+			target.Inputs[j] = resolve(dir, target.Inputs[j])
+		}
+		if target.TemplateDir != "" {
+			if !remote.IsModule(target.TemplateDir) {
+				target.TemplateDir = resolve(dir, target.TemplateDir)
+			}
+		} else if _, isBuiltin := builtin.FromLocation(target.Template); target.Template != "" && !isBuiltin && !utils.IsRemote(target.Template) && !remote.IsModule(target.Template) {
+			target.Template = resolve(dir, target.Template)
+		}
+		if strings.ContainsAny(target.Plugin, `/\`) {
+			target.Plugin = resolve(dir, target.Plugin)
+		}
+		if target.Output != "" {
+			target.Output = resolve(dir, target.Output)
+		}
+		target.To = resolveQualified(dir, target.To)
+		for j := range target.Interfaces {
+			target.Interfaces[j] = resolveQualified(dir, target.Interfaces[j])
+		}
+	}
+	return &config, nil
+}
+
+// interpolate replaces the references to the environment variables in the values of the given YAML node, see
+// expandEnv. The post commands are left to the shell, e.g. "gofmt -w $GENZ_FILES".
+func interpolate(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		value, err := expandEnv(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		node.Value = value
+		return nil
+	}
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			continue // A key.
+		}
+		if node.Kind == yaml.MappingNode && node.Content[i-1].Value == "post" {
+			continue
+		}
+		if err := interpolate(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandEnv replaces the references to the environment variables in the given value: "${NAME}" by the value of the
+// variable, which must be set, "${NAME:-default}" by its value, or by the default if it is unset or empty, and "$$" by
+// "$".
+func expandEnv(value string) (string, error) {
+	var err error
+	expanded := envRegex.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+		groups := envRegex.FindStringSubmatch(match)
+		env, found := os.LookupEnv(groups[1])
+		if groups[2] != "" && env == "" {
+			return groups[3]
+		}
+		if !found && err == nil {
+			err = fmt.Errorf("environment variable %s is not set, e.g. ${%s:-default} to give a default value", groups[1], groups[1])
+		}
+		return env
+	})
+	return expanded, err
+}
+
+// HasOutputTemplate returns true if the Output of the target is a template, rendered for each type by RenderOutput.
+func (t Target) HasOutputTemplate() bool {
+	return strings.Contains(t.Output, "{{")
+}
+
+// RenderOutput returns the Output of the target rendered for the given type, see OutputData.
+func (t Target) RenderOutput(typeName string) (string, error) {
+	tmpl, err := generator.Parse(t.Output)
+	if err != nil {
+		return "", fmt.Errorf("invalid output %s: %w", t.Output, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, OutputData{Type: typeName}); err != nil {
+		return "", fmt.Errorf("invalid output %s: %w", t.Output, err)
+	}
+	if strings.TrimSpace(buf.String()) == "" {
+		return "", fmt.Errorf("output %s is empty for the type %q", t.Output, typeName)
+	}
+	return buf.String(), nil
+}
+
+// CheckWrite returns an error if the given write strategy is unknown. The empty strategy is WriteOverwrite.
+func CheckWrite(strategy string) error {
+	switch strategy {
+	case "", WriteOverwrite, WriteSkipIfExists, WriteRegion:
+		return nil
+	default:
+		return fmt.Errorf("unknown write strategy %q, expected %s, %s or %s", strategy, WriteOverwrite, WriteSkipIfExists, WriteRegion)
+	}
+}
+
+// resolveQualified returns the given type name, with the directory qualifying it relative to dir, e.g. "./domain.User".
+func resolveQualified(dir, typeName string) string {
+	if i := strings.LastIndex(typeName, "."); i > 0 && strings.HasPrefix(typeName, ".") {
+		return resolve(dir, typeName[:i]) + typeName[i:]
+	}
+	return typeName
+}
+
+// resolve returns the given path relative to dir, unless it is absolute.
+func resolve(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
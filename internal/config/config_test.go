@@ -0,0 +1,251 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed while creating directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed while writing file: %v", err)
+	}
+}
+
+func TestLoadSuccess(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	writeFile(t, path, `
+targets:
+  - type: Human
+    template: templates/validator.tmpl
+    output: models/human.gen.go
+    inputs: [models]
+    tags: [integration]
+    build-flags: [-mod=vendor]
+    recursive: true
+    post: [go vet ./models]
+    vars: {table: people, audit: "true"}
+  - type: Car
+    types: [Truck]
+    type-regex: .*Bike$
+    template: https://example.com/getters.tmpl
+    output: vehicles.gen.go
+    combine: true
+    suffix: _gen_linux
+    build: linux && amd64
+    write: region
+  - functions: true
+    template: builtin:getters
+  - type: Order
+    template-dir: ./templates/orders
+    template: entry.tmpl
+    funcs: [templates/funcs.tmpl, /usr/lib/genz/funcs.so]
+  - types: [./v1.User, User]
+    template: shim.tmpl
+  - interfaces: [Getter, io.Closer, ./store.Putter]
+    template: facade.tmpl
+  - type: Order
+    plugin: ./bin/foo
+  - type: Order
+    plugin: foo
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &Config{
+		Targets: []Target{
+			{
+				Types:      []string{"Human"},
+				Template:   filepath.Join(dir, "templates/validator.tmpl"),
+				Output:     filepath.Join(dir, "models/human.gen.go"),
+				Inputs:     []string{filepath.Join(dir, "models")},
+				Dir:        dir,
+				Tags:       []string{"integration"},
+				BuildFlags: []string{"-mod=vendor"},
+				Recursive:  true,
+				Post:       []string{"go vet ./models"},
+				Vars:       map[string]string{"table": "people", "audit": "true"},
+			},
+			{
+				Types:     []string{"Car", "Truck"},
+				TypeRegex: ".*Bike$",
+				Template:  "https://example.com/getters.tmpl",
+				Output:    filepath.Join(dir, "vehicles.gen.go"),
+				Combine:   true,
+				Suffix:    "_gen_linux",
+				Build:     "linux && amd64",
+				Write:     WriteRegion,
+				Inputs:    []string{dir},
+				Dir:       dir,
+			},
+			{
+				Template:  "builtin:getters",
+				Functions: true,
+				Inputs:    []string{dir},
+				Dir:       dir,
+			},
+			{
+				Types:       []string{"Order"},
+				Template:    "entry.tmpl",
+				TemplateDir: filepath.Join(dir, "templates/orders"),
+				Funcs:       []string{filepath.Join(dir, "templates/funcs.tmpl"), "/usr/lib/genz/funcs.so"},
+				Inputs:      []string{dir},
+				Dir:         dir,
+			},
+			{
+				Types:    []string{filepath.Join(dir, "v1") + ".User", "User"},
+				Template: filepath.Join(dir, "shim.tmpl"),
+				Inputs:   []string{dir},
+				Dir:      dir,
+			},
+			{
+				Interfaces: []string{"Getter", "io.Closer", filepath.Join(dir, "store") + ".Putter"},
+				Template:   filepath.Join(dir, "facade.tmpl"),
+				Inputs:     []string{dir},
+				Dir:        dir,
+			},
+			{
+				Types:  []string{"Order"},
+				Plugin: filepath.Join(dir, "bin/foo"),
+				Inputs: []string{dir},
+				Dir:    dir,
+			},
+			{
+				Types:  []string{"Order"},
+				Plugin: "foo",
+				Inputs: []string{dir},
+				Dir:    dir,
+			},
+		},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Fatalf("config doesn't match expected:\n%s", cmp.Diff(cfg, expected))
+	}
+}
+
+func TestLoadError(t *testing.T) {
+	testCases := map[string]string{
+		"invalid yaml":         "targets: [",
+		"missing type":         "targets:\n  - template: foo.tmpl\n",
+		"missing template":     "targets:\n  - type: Foo\n",
+		"plugin and template":  "targets:\n  - type: Foo\n    plugin: foo\n    template: foo.tmpl\n",
+		"plugin and funcs":     "targets:\n  - type: Foo\n    plugin: foo\n    funcs: [funcs.tmpl]\n",
+		"three compared types": "targets:\n  - types: [./v1.User, ./v2.User, User]\n    template: foo.tmpl\n",
+		"unknown write":        "targets:\n  - type: Foo\n    template: foo.tmpl\n    write: append\n",
+	}
+	for name, content := range testCases {
+		content := content
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			writeFile(t, path, content)
+			if _, err := Load(path); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), FileName)); err == nil {
+		t.Fatal("expected error for a missing file, got nil")
+	}
+}
+
+func TestLoadInterpolation(t *testing.T) {
+	t.Setenv("GENZ_TEST_DIALECT", "mysql")
+	t.Setenv("GENZ_TEST_EMPTY", "")
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	writeFile(t, path, `
+targets:
+  - type: User
+    template: builtin:sql
+    output: "sql/{{ .Type | snakecase }}_${GENZ_TEST_DIALECT}.sql"
+    type-regex: "^Price$$"
+    vars:
+      dialect: ${GENZ_TEST_DIALECT}
+      table: ${GENZ_TEST_EMPTY:-users}
+      schema: ${GENZ_TEST_UNSET:-public}
+    post: ["echo $GENZ_FILES ${GENZ_FILES}"]
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	target := cfg.Targets[0]
+	if expected := filepath.Join(dir, "sql/{{ .Type | snakecase }}_mysql.sql"); target.Output != expected {
+		t.Errorf("expected the output %s, got %s", expected, target.Output)
+	}
+	if target.TypeRegex != "^Price$" {
+		t.Errorf("expected the escaped dollar sign, got %s", target.TypeRegex)
+	}
+	expectedVars := map[string]string{"dialect": "mysql", "table": "users", "schema": "public"}
+	if !reflect.DeepEqual(target.Vars, expectedVars) {
+		t.Errorf("vars don't match expected:\n%s", cmp.Diff(target.Vars, expectedVars))
+	}
+	if target.Post[0] != "echo $GENZ_FILES ${GENZ_FILES}" {
+		t.Errorf("expected the post command as written, got %s", target.Post[0])
+	}
+
+	writeFile(t, path, "targets:\n  - type: User\n    template: ${GENZ_TEST_UNSET}.tmpl\n")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "line 3: environment variable GENZ_TEST_UNSET is not set") {
+		t.Errorf("expected an unset variable error, got %v", err)
+	}
+}
+
+func TestRenderOutput(t *testing.T) {
+	target := Target{Output: "models/{{ .Type | snakecase }}_gen.go"}
+	if !target.HasOutputTemplate() {
+		t.Fatal("expected an output template")
+	}
+	output, err := target.RenderOutput("UserAccount")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output != "models/user_account_gen.go" {
+		t.Errorf("expected models/user_account_gen.go, got %s", output)
+	}
+
+	if _, err := (Target{Output: "{{ .Unknown }}.go"}).RenderOutput("User"); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+	if _, err := (Target{Output: "{{ .Type }}"}).RenderOutput(""); err == nil {
+		t.Error("expected an error for an empty output")
+	}
+	if (Target{Output: "user.gen.go"}).HasOutputTemplate() {
+		t.Error("expected a plain output")
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module foo\n")
+	writeFile(t, filepath.Join(root, FileName), "targets: []\n")
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("failed while creating directory: %v", err)
+	}
+
+	path, err := Find(nested)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != filepath.Join(root, FileName) {
+		t.Fatalf("expected %s, got %s", filepath.Join(root, FileName), path)
+	}
+
+	moduleWithoutConfig := t.TempDir()
+	writeFile(t, filepath.Join(moduleWithoutConfig, "go.mod"), "module bar\n")
+	if _, err := Find(moduleWithoutConfig); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,151 @@
+// Package daemon serves the generation requests of genz client on a unix socket, one JSON request and one JSON
+// response per connection, so that genz daemon keeps the type-checked packages in memory between them.
+// e.g. {"dir":"/src/shop","args":["-type","User","-template","getters.tmpl"]} => {"stdout":"","stderr":"genz: wrote ...","exitCode":0}
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/leorolland/genz/internal/logging"
+)
+
+// SocketEnv is the environment variable overriding the default socket, see DefaultSocket.
+const SocketEnv = "GENZ_SOCKET"
+
+// Request is a generation request: the arguments of genz generate, run from the working directory of the client.
+type Request struct {
+	// Dir is the working directory of the client, from which the arguments are resolved. e.g. "/home/me/shop"
+	Dir string `json:"dir"`
+	// Args are the flags and the inputs of genz generate. e.g. ["-type", "User", "-template", "getters.tmpl"]
+	Args []string `json:"args"`
+}
+
+// Response is the result of a generation request, as the client prints it.
+type Response struct {
+	// Stdout is what the generation printed on the standard output, e.g. the diffs of -diff.
+	Stdout string `json:"stdout"`
+	// Stderr is what the generation printed on the standard error: its logs and its reported error, if any.
+	Stderr string `json:"stderr"`
+	// ExitCode is the exit code of the generation, see failure.ExitCode.
+	ExitCode int `json:"exitCode"`
+}
+
+// Handler runs a generation request.
+type Handler func(request Request) Response
+
+// DefaultSocket returns the socket of the daemon: $GENZ_SOCKET if set, or genz.sock in the runtime directory of the
+// user, $XDG_RUNTIME_DIR, or else in a directory of the user in the temporary directory, e.g. /tmp/genz-1000/genz.sock.
+func DefaultSocket() string {
+	if socket := os.Getenv(SocketEnv); socket != "" {
+		return socket
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "genz.sock")
+	}
+	return filepath.Join(os.TempDir(), "genz-"+strconv.Itoa(os.Getuid()), "genz.sock")
+}
+
+// Listen listens on the given unix socket, which only the user can connect to: the requests run the generations, and
+// their post commands, as the user. The directory of the socket is created if needed, only accessible to the user, and
+// must not be shared with the other users, see checkPrivate. A socket left by a daemon which did not stop cleanly is
+// replaced, but a socket on which a daemon is listening is an error.
+func Listen(socket string) (net.Listener, error) {
+	dir := filepath.Dir(socket)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPrivate(dir, info); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(socket); err == nil {
+		if conn, err := net.DialTimeout("unix", socket, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("a daemon is already listening on %s", socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return nil, err
+		}
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// Serve handles the requests of the connections accepted by the listener until the context is done, then closes the
+// listener. The requests are handled one at a time, since a generation changes the working directory of the process.
+func Serve(ctx context.Context, listener net.Listener, handle Handler) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	defer wg.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			var request Request
+			if err := json.NewDecoder(conn).Decode(&request); err != nil {
+				logging.Warn("invalid request", "error", err)
+				return
+			}
+			mu.Lock()
+			response := handle(request)
+			mu.Unlock()
+			if err := json.NewEncoder(conn).Encode(response); err != nil {
+				logging.Warn("failed to send the response", "error", err)
+			}
+		}()
+	}
+}
+
+// Call sends the request to the daemon listening on the given socket, and returns its response.
+func Call(socket string, request Request) (Response, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return Response{}, fmt.Errorf("no daemon listening on %s, see genz daemon: %w", socket, err)
+	}
+	defer conn.Close()
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return Response{}, fmt.Errorf("failed to send the request: %w", err)
+	}
+	var response Response
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Response{}, fmt.Errorf("the daemon closed the connection without response")
+		}
+		return Response{}, fmt.Errorf("failed to read the response: %w", err)
+	}
+	return response, nil
+}
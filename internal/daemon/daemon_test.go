@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// socketPath returns a socket path short enough for the unix sockets, unlike the directories of t.TempDir.
+func socketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "genz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "d.sock")
+}
+
+func TestServe(t *testing.T) {
+	socket := socketPath(t)
+	listener, err := Listen(socket)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Serve(ctx, listener, func(request Request) Response {
+			return Response{
+				Stdout:   request.Dir,
+				Stderr:   strings.Join(request.Args, " "),
+				ExitCode: len(request.Args),
+			}
+		})
+	}()
+
+	response, err := Call(socket, Request{Dir: "/src/shop", Args: []string{"-type", "User"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Response{Stdout: "/src/shop", Stderr: "-type User", ExitCode: 2}
+	if !reflect.DeepEqual(response, expected) {
+		t.Errorf("expected %+v, got %+v", expected, response)
+	}
+
+	if _, err := Listen(socket); err == nil || !strings.Contains(err.Error(), "already listening") {
+		t.Errorf("expected an error for a socket in use, got %v", err)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := Call(socket, Request{}); err == nil {
+		t.Errorf("expected an error once stopped")
+	}
+}
+
+func TestListenStaleSocket(t *testing.T) {
+	socket := socketPath(t)
+	if err := os.WriteFile(socket, nil, 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	listener, err := Listen(socket)
+	if err != nil {
+		t.Fatalf("expected the stale socket replaced, got %v", err)
+	}
+	listener.Close()
+}
+
+func TestListenSocketMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix permissions")
+	}
+	socket := filepath.Join(filepath.Dir(socketPath(t)), "private", "d.sock")
+	listener, err := Listen(socket)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the socket only accessible to the user, got %v", info.Mode().Perm())
+	}
+	info, err = os.Stat(filepath.Dir(socket))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		t.Errorf("expected the created directory only accessible to the user, got %v", info.Mode().Perm())
+	}
+}
+
+func TestListenSharedDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix permissions")
+	}
+	socket := socketPath(t)
+	if err := os.Chmod(filepath.Dir(socket), 0o777); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := Listen(socket); err == nil || !strings.Contains(err.Error(), "writable by the other users") {
+		t.Errorf("expected an error for a shared directory, got %v", err)
+	}
+}
+
+func TestDefaultSocket(t *testing.T) {
+	t.Setenv(SocketEnv, "")
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if socket := DefaultSocket(); socket != filepath.Join("/run/user/1000", "genz.sock") {
+		t.Errorf("expected the socket in the runtime directory, got %s", socket)
+	}
+	t.Setenv(SocketEnv, "/home/me/genz.sock")
+	if socket := DefaultSocket(); socket != "/home/me/genz.sock" {
+		t.Errorf("expected the socket of $%s, got %s", SocketEnv, socket)
+	}
+}
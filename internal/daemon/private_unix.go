@@ -0,0 +1,22 @@
+//go:build unix
+
+package daemon
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// checkPrivate returns an error if the given directory of a socket is not owned by the user, or is writable by the
+// other users, who could then replace the socket.
+func checkPrivate(dir string, info fs.FileInfo) error {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("the directory %s of the socket is not owned by the user %d, select another socket", dir, os.Getuid())
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf("the directory %s of the socket is writable by the other users, select another socket", dir)
+	}
+	return nil
+}
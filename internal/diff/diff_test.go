@@ -0,0 +1,57 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnified(t *testing.T) {
+	testCases := map[string]struct {
+		oldText, newText string
+		expected         string
+	}{
+		"equal": {
+			oldText: "a\nb\n",
+			newText: "a\nb\n",
+		},
+		"changed line": {
+			oldText:  "a\nb\nc\n",
+			newText:  "a\nB\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		"new file": {
+			newText:  "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		"deleted file": {
+			oldText:  "a\n",
+			expected: "--- old\n+++ new\n@@ -1,1 +0,0 @@\n-a\n",
+		},
+		"missing newline": {
+			oldText:  "a\nb",
+			newText:  "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		"distant changes": {
+			oldText:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			newText:  "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n",
+		},
+		"close changes": {
+			oldText:  "1\n2\n3\n4\n5\n6\n7\n",
+			newText:  "1\n2\nthree\n4\n5\nsix\n7\n",
+			expected: "--- old\n+++ new\n@@ -1,7 +1,7 @@\n 1\n 2\n-3\n+three\n 4\n 5\n-6\n+six\n 7\n",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got := Unified("old", "new", []byte(tc.oldText), []byte(tc.newText))
+			if got != tc.expected {
+				t.Errorf("unexpected diff: %s", cmp.Diff(tc.expected, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package directives
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Prefix starts the comments declaring a genz command, e.g. "//genz:generate builtin getters -type Car".
+// As with //go:generate, the comment must start the line, without space after the slashes.
+const Prefix = "//genz:generate "
+
+// Directive is a //genz:generate comment: the arguments of a genz command to run from the directory of its file.
+type Directive struct {
+	// File is the path of the file declaring the directive.
+	File string
+	// Line is the line of the directive in its file, starting at 1.
+	Line int
+	// Package is the name of the package of the file.
+	Package string
+	// Args are the arguments of the genz command, e.g. ["builtin", "getters", "-type", "Car"].
+	// The $GOFILE, $GOLINE and $GOPACKAGE variables, and the environment variables, are expanded.
+	Args []string
+}
+
+// String returns the location of the directive followed by its command, e.g. "car.go:3: genz builtin getters -type Car".
+func (d Directive) String() string {
+	return fmt.Sprintf("%s:%d: genz %s", d.File, d.Line, strings.Join(d.Args, " "))
+}
+
+// Env returns the variables set in the environment of the command of the directive, as go generate does.
+// e.g. ["GOFILE=car.go", "GOLINE=3", "GOPACKAGE=cars"]
+func (d Directive) Env() []string {
+	return []string{
+		"GOFILE=" + filepath.Base(d.File),
+		"GOLINE=" + strconv.Itoa(d.Line),
+		"GOPACKAGE=" + d.Package,
+	}
+}
+
+// Scan returns the directives of the given file of the given package, in line order.
+func Scan(file, pkg string) ([]Directive, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var directives []Directive
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if !strings.HasPrefix(text, Prefix) {
+			continue
+		}
+		directive := Directive{File: file, Line: line, Package: pkg}
+		args, err := splitArgs(strings.TrimPrefix(text, Prefix), directive)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, line, err)
+		}
+		directive.Args = args
+		directives = append(directives, directive)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return directives, nil
+}
+
+// splitArgs splits the given command line into its space-separated arguments, a double-quoted argument being unquoted
+// as a Go string, and expands the variables of the directive and of the environment in each argument.
+func splitArgs(line string, directive Directive) ([]string, error) {
+	variables := map[string]string{}
+	for _, variable := range directive.Env() {
+		name, value, _ := strings.Cut(variable, "=")
+		variables[name] = value
+	}
+	expand := func(arg string) string {
+		return os.Expand(arg, func(name string) string {
+			if value, ok := variables[name]; ok {
+				return value
+			}
+			return os.Getenv(name)
+		})
+	}
+
+	var args []string
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		if line[0] != '"' {
+			end := strings.IndexAny(line, " \t")
+			if end < 0 {
+				end = len(line)
+			}
+			args = append(args, expand(line[:end]))
+			line = line[end:]
+			continue
+		}
+		quoted, err := strconv.QuotedPrefix(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted argument in %s", line)
+		}
+		arg, _ := strconv.Unquote(quoted)
+		args = append(args, expand(arg))
+		line = line[len(quoted):]
+	}
+	return args, nil
+}
+
+// Run runs the given directives with the given function, at most parallel of them at once, and returns the errors of
+// the failed ones joined, each prefixed with its directive. All the directives are run, even after a failure.
+func Run(directives []Directive, parallel int, run func(Directive) error) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+	errs := make([]error, len(directives))
+	semaphore := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, directive := range directives {
+		i, directive := i, directive
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			if err := run(directive); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", directive, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...) // in the order of the directives, whatever the order they failed in
+}
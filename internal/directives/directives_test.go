@@ -0,0 +1,120 @@
+package directives
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScan(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "car.go")
+	content := `package cars
+
+//genz:generate builtin getters -type Car
+type Car struct{}
+
+// genz:generate is not a directive with a space after the slashes
+	//genz:generate nor indented
+//genz:generate -type "Car Engine" -template $GOPACKAGE.tmpl $GOFILE:$GOLINE
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	directives, err := Scan(file, "cars")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Directive{
+		{File: file, Line: 3, Package: "cars", Args: []string{"builtin", "getters", "-type", "Car"}},
+		{File: file, Line: 8, Package: "cars", Args: []string{"-type", "Car Engine", "-template", "cars.tmpl", "car.go:8"}},
+	}
+	if !reflect.DeepEqual(directives, expected) {
+		t.Errorf("directives don't match expected:\n%s", cmp.Diff(expected, directives))
+	}
+}
+
+func Test_splitArgs(t *testing.T) {
+	t.Setenv("GENZ_TEST_DIR", "out")
+	testCases := map[string]struct {
+		line     string
+		expected []string
+		wantErr  bool
+	}{
+		"spaces": {
+			line:     "  builtin \t getters  -type Car ",
+			expected: []string{"builtin", "getters", "-type", "Car"},
+		},
+		"quoted argument": {
+			line:     `-type-regex ".*Event$" -output "a b\tc.go"`,
+			expected: []string{"-type-regex", ".*Event$", "-output", "a b\tc.go"},
+		},
+		"variables": {
+			line:     "-output $GENZ_TEST_DIR/${GOFILE}.gen.go",
+			expected: []string{"-output", "out/car.go.gen.go"},
+		},
+		"unterminated quote": {
+			line:    `-type "Car`,
+			wantErr: true,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			args, err := splitArgs(tc.line, Directive{File: "cars/car.go", Line: 3, Package: "cars"})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("splitArgs() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.expected, args); !tc.wantErr && diff != "" {
+				t.Errorf("args don't match expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	var directives []Directive
+	for line := 1; line <= 10; line++ {
+		directives = append(directives, Directive{File: "car.go", Line: line, Args: []string{"-type", "Car"}})
+	}
+
+	var running, maxRunning, runs int32
+	err := Run(directives, 3, func(directive Directive) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			highest := atomic.LoadInt32(&maxRunning)
+			if current <= highest || atomic.CompareAndSwapInt32(&maxRunning, highest, current) {
+				break
+			}
+		}
+		atomic.AddInt32(&runs, 1)
+		if directive.Line%5 == 0 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	if runs != 10 {
+		t.Errorf("expected every directive to run, got %d runs", runs)
+	}
+	if maxRunning > 3 {
+		t.Errorf("expected at most 3 directives run at once, got %d", maxRunning)
+	}
+	expected := "car.go:5: genz -type Car: failed\ncar.go:10: genz -type Car: failed"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected the errors of the failed directives in order, got %v", err)
+	}
+	if err := Run(directives[:2], 0, func(Directive) error { return nil }); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(directives[0].String(), "car.go:1: genz -type Car") {
+		t.Errorf("unexpected directive string %s", directives[0])
+	}
+}
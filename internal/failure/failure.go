@@ -0,0 +1,143 @@
+// Package failure classifies the errors of genz, to exit with a distinct code for each kind of failure, and to report
+// them as a list of located errors, e.g. in JSON for the build systems and the editors.
+package failure
+
+import (
+	"errors"
+)
+
+// Kind is the kind of a failure. Its value is the exit code of genz.
+type Kind int
+
+const (
+	// KindOther is an unclassified failure.
+	KindOther Kind = 1
+	// KindUsage is an invalid command line or configuration.
+	KindUsage Kind = 2
+	// KindParse is a failure to load or to parse the Go packages, e.g. a compile error or a type not found.
+	KindParse Kind = 3
+	// KindTemplate is a failure to read, parse or execute a template, or of a plugin, including invalid generated code.
+	KindTemplate Kind = 4
+	// KindWrite is a failure to write an output, e.g. a protected hand-written file.
+	KindWrite Kind = 5
+	// KindOutOfDate is a verification difference: an output is out of date with -dry-run.
+	KindOutOfDate Kind = 6
+)
+
+var kindNames = map[Kind]string{
+	KindOther:     "other",
+	KindUsage:     "usage",
+	KindParse:     "parse",
+	KindTemplate:  "template",
+	KindWrite:     "write",
+	KindOutOfDate: "out-of-date",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Error is an error of a given kind, optionally located in a file.
+type Error struct {
+	Kind Kind
+	// File is the file of the error, if known.
+	File string
+	// Line and Column are the 1-based position of the error in File, or 0 if unknown.
+	Line, Column int
+	Err          error
+}
+
+// New returns the given error classified as the given kind, or nil if the error is nil.
+func New(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Location returns the position of the error, see Locator.
+func (e *Error) Location() (string, int, int) {
+	return e.File, e.Line, e.Column
+}
+
+// Locator is implemented by the errors knowing their position, e.g. the errors of a template.
+// The line and the column are 1-based, or 0 if unknown.
+type Locator interface {
+	Location() (file string, line, column int)
+}
+
+// Entry is a reported error.
+type Entry struct {
+	// Kind is the name of the kind of the error, e.g. "template".
+	Kind string `json:"kind"`
+	// Message is the message of the error.
+	Message string `json:"message"`
+	// File, Line and Column locate the error, if known.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// Entries returns the errors joined in the given error, e.g. one per failing target, with their kind and position.
+func Entries(err error) []Entry {
+	var entries []Entry
+	for _, leaf := range leaves(err, KindOther) {
+		entry := Entry{Kind: leaf.kind.String(), Message: leaf.err.Error()}
+		for e := leaf.err; e != nil; e = errors.Unwrap(e) {
+			if locator, ok := e.(Locator); ok {
+				if file, line, column := locator.Location(); file != "" {
+					entry.File, entry.Line, entry.Column = file, line, column
+					break
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// ExitCode returns the exit code of genz for the given error: 0 without error, or the kind of its first error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return int(leaves(err, KindOther)[0].kind)
+}
+
+// leaf is an error which joins no other errors, with its kind.
+type leaf struct {
+	err  error
+	kind Kind
+}
+
+// leaves returns the errors joined in the given error, e.g. by errors.Join, or the error itself.
+// An error not classified itself has the kind of the error joining it, starting with the given kind.
+func leaves(err error, kind Kind) []leaf {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if classified, ok := e.(*Error); ok && kind == KindOther {
+			kind = classified.Kind
+		}
+		if joined, ok := e.(interface{ Unwrap() []error }); ok {
+			var result []leaf
+			for _, child := range joined.Unwrap() {
+				result = append(result, leaves(child, kind)...)
+			}
+			if len(result) != 0 {
+				return result
+			}
+		}
+	}
+	var classified *Error
+	if errors.As(err, &classified) {
+		kind = classified.Kind
+	}
+	return []leaf{{err: err, kind: kind}}
+}
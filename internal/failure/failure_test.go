@@ -0,0 +1,88 @@
+package failure
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// locatedError is an error knowing its position, as a template error.
+type locatedError struct{}
+
+func (locatedError) Error() string {
+	return "can't evaluate field Foo"
+}
+
+func (locatedError) Location() (string, int, int) {
+	return "getters.tmpl", 4, 3
+}
+
+func TestEntries(t *testing.T) {
+	testCases := map[string]struct {
+		err              error
+		expectedEntries  []Entry
+		expectedExitCode int
+	}{
+		"unclassified": {
+			err:              errors.New("boom"),
+			expectedEntries:  []Entry{{Kind: "other", Message: "boom"}},
+			expectedExitCode: 1,
+		},
+		"classified and wrapped": {
+			err:              fmt.Errorf("Car: %w", New(KindParse, errors.New("Car not found"))),
+			expectedEntries:  []Entry{{Kind: "parse", Message: "Car: Car not found"}},
+			expectedExitCode: 3,
+		},
+		"located": {
+			err: fmt.Errorf("Car: %w", New(KindTemplate, locatedError{})),
+			expectedEntries: []Entry{
+				{Kind: "template", Message: "Car: can't evaluate field Foo", File: "getters.tmpl", Line: 4, Column: 3},
+			},
+			expectedExitCode: 4,
+		},
+		"joined, the first error deciding the exit code": {
+			err: errors.Join(
+				&Error{Kind: KindOutOfDate, File: "car.gen.go", Err: errors.New("car.gen.go is out of date")},
+				New(KindWrite, errors.New("permission denied")),
+			),
+			expectedEntries: []Entry{
+				{Kind: "out-of-date", Message: "car.gen.go is out of date", File: "car.gen.go"},
+				{Kind: "write", Message: "permission denied"},
+			},
+			expectedExitCode: 6,
+		},
+		"joined in a classified error": {
+			err: New(KindParse, errors.Join(
+				&Error{Kind: KindParse, File: "a.go", Line: 3, Column: 18, Err: errors.New("undefined: CarView")},
+				errors.New("undefined: Truck"),
+			)),
+			expectedEntries: []Entry{
+				{Kind: "parse", Message: "undefined: CarView", File: "a.go", Line: 3, Column: 18},
+				{Kind: "parse", Message: "undefined: Truck"},
+			},
+			expectedExitCode: 3,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			entries := Entries(tc.err)
+			if !reflect.DeepEqual(entries, tc.expectedEntries) {
+				t.Errorf("entries don't match expected:\n%s", cmp.Diff(entries, tc.expectedEntries))
+			}
+			if exitCode := ExitCode(tc.err); exitCode != tc.expectedExitCode {
+				t.Errorf("expected exit code %d, got %d", tc.expectedExitCode, exitCode)
+			}
+		})
+	}
+}
+
+func TestExitCodeWithoutError(t *testing.T) {
+	if exitCode := ExitCode(nil); exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+}
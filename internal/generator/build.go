@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/build/constraint"
+	"strings"
+)
+
+// AddBuildConstraint adds the given //go:build constraint expression, e.g. "linux && amd64", to the given Go source,
+// before its package clause and the documentation of its package. A constraint already declared by the source is
+// combined with it, e.g. "//go:build integration && (linux && amd64)".
+func AddBuildConstraint(buf bytes.Buffer, expression string) (bytes.Buffer, error) {
+	if expression == "" {
+		return buf, nil
+	}
+	expr, err := constraint.Parse("//go:build " + expression)
+	if err != nil {
+		return buf, fmt.Errorf("invalid build constraint %q: %w", expression, err)
+	}
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+	packageLine := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if constraint.IsGoBuild(trimmed) {
+			existing, err := constraint.Parse(trimmed)
+			if err != nil {
+				return buf, fmt.Errorf("invalid build constraint %q: %w", trimmed, err)
+			}
+			lines[i] = "//go:build " + (&constraint.AndExpr{X: existing, Y: expr}).String() + "\n"
+			return *bytes.NewBufferString(strings.Join(lines, "")), nil
+		}
+		if strings.HasPrefix(trimmed, "package ") {
+			packageLine = i
+			break
+		}
+	}
+	if packageLine < 0 {
+		return buf, fmt.Errorf("no package clause to add the build constraint %q before", expression)
+	}
+	// A comment right above the package clause documents the package: the constraint goes before it.
+	insert := packageLine
+	for insert > 0 && strings.HasPrefix(strings.TrimSpace(lines[insert-1]), "//") {
+		insert--
+	}
+	var result bytes.Buffer
+	result.WriteString(strings.Join(lines[:insert], ""))
+	result.WriteString("//go:build " + expr.String() + "\n\n")
+	result.WriteString(strings.Join(lines[insert:], ""))
+	return result, nil
+}
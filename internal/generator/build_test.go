@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAddBuildConstraint(t *testing.T) {
+	testCases := map[string]struct {
+		src        string
+		expression string
+		expected   string
+	}{
+		"package clause": {
+			src:        "package cars\n",
+			expression: "linux && amd64",
+			expected:   "//go:build linux && amd64\n\npackage cars\n",
+		},
+		"after the header, before the package documentation": {
+			src:        "// Code generated by genz. DO NOT EDIT.\n\n// Package cars is generated.\npackage cars\n",
+			expression: "linux",
+			expected:   "// Code generated by genz. DO NOT EDIT.\n\n//go:build linux\n\n// Package cars is generated.\npackage cars\n",
+		},
+		"combined with the constraint of the template": {
+			src:        "//go:build integration\n\npackage cars\n",
+			expression: "linux || darwin",
+			expected:   "//go:build integration && (linux || darwin)\n\npackage cars\n",
+		},
+		"no constraint": {
+			src:      "package cars\n",
+			expected: "package cars\n",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			actual, err := AddBuildConstraint(*bytes.NewBufferString(tc.src), tc.expression)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, actual.String())
+			}
+		})
+	}
+	errorCases := map[string]struct{ src, expression string }{
+		"invalid expression": {src: "package cars\n", expression: "linux &&"},
+		"no package clause":  {src: "func A() {}\n", expression: "linux"},
+	}
+	for name, tc := range errorCases {
+		if _, err := AddBuildConstraint(*bytes.NewBufferString(tc.src), tc.expression); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
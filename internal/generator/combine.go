@@ -0,0 +1,374 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Combine merges several generated Go files of the same package into a single one.
+// The package clause is kept once and the imports are merged into a single import block.
+// The identical bodies are kept once, so that the declarations shared by several types (e.g. a type emitted by each of
+// them into the same additional file) are written once.
+// If one of the buffers is not a valid Go file, the buffers are simply concatenated.
+func Combine(bufs []bytes.Buffer) bytes.Buffer {
+	var packageName string
+	imports := map[string]string{} // import spec -> import spec, to deduplicate
+	var bodies []string
+	seen := map[string]bool{}
+	for _, buf := range bufs {
+		src := buf.Bytes()
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			return concat(bufs)
+		}
+		packageName = file.Name.Name
+		for _, spec := range file.Imports {
+			imports[importSpec(spec)] = importSpec(spec)
+		}
+
+		// The body starts after the last import declaration, or after the package clause.
+		bodyStart := fset.Position(file.Name.End()).Offset
+		for _, decl := range file.Decls {
+			if genDecl, isGenDecl := decl.(*ast.GenDecl); isGenDecl && genDecl.Tok == token.IMPORT {
+				bodyStart = fset.Position(genDecl.End()).Offset
+			}
+		}
+		body := strings.TrimSpace(string(src[bodyStart:]))
+		if !seen[body] {
+			seen[body] = true
+			bodies = append(bodies, body)
+		}
+	}
+
+	specs := make([]string, 0, len(imports))
+	for spec := range imports {
+		specs = append(specs, spec)
+	}
+	sort.Strings(specs)
+
+	combined := bytes.Buffer{}
+	combined.WriteString("package " + packageName + "\n\n")
+	if len(specs) > 0 {
+		combined.WriteString("import (\n")
+		for _, spec := range specs {
+			combined.WriteString("\t" + spec + "\n")
+		}
+		combined.WriteString(")\n\n")
+	}
+	combined.WriteString(strings.Join(bodies, "\n\n"))
+	combined.WriteString("\n")
+	return combined
+}
+
+// importSpec returns the source representation of an import, e.g. `f "fmt"`.
+func importSpec(spec *ast.ImportSpec) string {
+	path, _ := strconv.Unquote(spec.Path.Value)
+	if spec.Name != nil {
+		return spec.Name.Name + " " + strconv.Quote(path)
+	}
+	return strconv.Quote(path)
+}
+
+func concat(bufs []bytes.Buffer) bytes.Buffer {
+	combined := bytes.Buffer{}
+	for _, buf := range bufs {
+		combined.Write(buf.Bytes())
+	}
+	return combined
+}
+
+// CombineYAML merges several generated YAML documents into a single one.
+// Mappings are merged recursively, and the first value wins for the other keys found in several documents,
+// so that the schemas shared by several types are written once.
+func CombineYAML(bufs []bytes.Buffer) (bytes.Buffer, error) {
+	var combined *yaml.Node
+	for _, buf := range bufs {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			return bytes.Buffer{}, fmt.Errorf("invalid YAML generated: %w", err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		if combined == nil {
+			combined = &doc
+			continue
+		}
+		mergeYAML(combined.Content[0], doc.Content[0])
+	}
+	if combined == nil {
+		return concat(bufs), nil
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(combined); err != nil {
+		return bytes.Buffer{}, err
+	}
+	return out, encoder.Close()
+}
+
+// mergeYAML adds to the dst mapping the keys of the src mapping it does not have,
+// and merges the values of their common keys.
+func mergeYAML(dst, src *yaml.Node) {
+	if dst.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		found := false
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value == key.Value {
+				mergeYAML(dst.Content[j+1], value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst.Content = append(dst.Content, key, value)
+		}
+	}
+}
+
+// CombineJSON merges several generated JSON documents into a single one. Objects are merged recursively, and the first
+// value wins for the other keys found in several documents. The JSON Schemas of several types, with a $schema and a
+// title, are merged into a single document defining each of them in its $defs, under its title, along with the
+// schemas they reference.
+func CombineJSON(bufs []bytes.Buffer) (bytes.Buffer, error) {
+	combined := map[string]any{}
+	for _, buf := range bufs {
+		decoder := json.NewDecoder(&buf)
+		decoder.UseNumber()
+		var doc map[string]any
+		if err := decoder.Decode(&doc); err != nil {
+			return bytes.Buffer{}, fmt.Errorf("invalid JSON generated, expected an object: %w", err)
+		}
+		if title, isString := doc["title"].(string); isString && doc["$schema"] != nil {
+			doc = schemaDefs(doc, title)
+		}
+		mergeJSON(combined, doc)
+	}
+
+	out, err := json.MarshalIndent(combined, "", "  ")
+	if err != nil {
+		return bytes.Buffer{}, err
+	}
+	return *bytes.NewBuffer(append(out, '\n')), nil
+}
+
+// schemaDefs returns the given JSON Schema of a type as a document defining it in its $defs under the given name.
+// The references to the schema itself, "#", are replaced by references to its definition.
+func schemaDefs(schema map[string]any, name string) map[string]any {
+	replaceRootRef(schema, "#/$defs/"+name)
+	doc := map[string]any{}
+	defs, _ := schema["$defs"].(map[string]any)
+	if defs == nil {
+		defs = map[string]any{}
+	}
+	def := map[string]any{}
+	for key, value := range schema {
+		switch key {
+		case "$schema", "$comment":
+			doc[key] = value
+		case "$defs":
+		default:
+			def[key] = value
+		}
+	}
+	defs[name] = def
+	doc["$defs"] = defs
+	return doc
+}
+
+// replaceRootRef replaces the references to the root of the document, "#", by the given reference, recursively.
+func replaceRootRef(value any, ref string) {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if key == "$ref" && child == "#" {
+				value[key] = ref
+				continue
+			}
+			replaceRootRef(child, ref)
+		}
+	case []any:
+		for _, child := range value {
+			replaceRootRef(child, ref)
+		}
+	}
+}
+
+// mergeJSON adds to the dst object the keys of the src object it does not have, and merges the values of their common
+// keys.
+func mergeJSON(dst, src map[string]any) {
+	for key, value := range src {
+		existing, found := dst[key]
+		if !found {
+			dst[key] = value
+			continue
+		}
+		dstObject, isDstObject := existing.(map[string]any)
+		srcObject, isSrcObject := value.(map[string]any)
+		if isDstObject && isSrcObject {
+			mergeJSON(dstObject, srcObject)
+		}
+	}
+}
+
+// CombineProto merges several generated proto3 files of the same package into a single one. The header, the syntax
+// and the package statements are kept once, the imports and the options are merged, and the messages, enums and
+// services declared by several files (e.g. a message referenced by several types) are kept once, the first one winning.
+func CombineProto(bufs []bytes.Buffer) (bytes.Buffer, error) {
+	return combineBlocks(bufs, blockSyntax{comment: "//", unique: []string{"syntax", "package"}})
+}
+
+// CombineGraphQL merges several generated GraphQL schemas into a single one. The header is kept once, the scalars are
+// merged, and the types declared by several schemas (e.g. a type referenced by several types) are kept once, the first
+// one winning.
+func CombineGraphQL(bufs []bytes.Buffer) (bytes.Buffer, error) {
+	return combineBlocks(bufs, blockSyntax{comment: "#", description: `"""`})
+}
+
+// blockSyntax describes a text format made of top-level statements and of definitions between braces, e.g. proto3.
+type blockSyntax struct {
+	// comment is the prefix of the comment lines, e.g. "//".
+	comment string
+	// description is the delimiter of the multi-line descriptions preceding the definitions, if any, e.g. `"""`.
+	description string
+	// unique are the keywords of the statements which must be the same in all the files, e.g. "package".
+	unique []string
+}
+
+// combineBlocks merges several generated files of the given syntax into a single one. The leading comments of the first
+// file are its header. The single-line statements are merged, sorted and grouped by keyword in the order they are
+// found, and the definitions are kept once per kind and name, e.g. "message Address", in the order they are found.
+func combineBlocks(bufs []bytes.Buffer, syntax blockSyntax) (bytes.Buffer, error) {
+	var (
+		header      string
+		keywords    []string
+		statements  = map[string][]string{} // By keyword.
+		seen        = map[string]bool{}
+		definitions []string
+	)
+	for i, buf := range bufs {
+		for j, block := range splitBlocks(buf.String(), syntax) {
+			declaration := declarationLine(block, syntax)
+			switch {
+			case declaration == "":
+				if i == 0 && j == 0 {
+					header = block
+				}
+			case !strings.Contains(block, "{"):
+				for _, line := range strings.Split(block, "\n") {
+					line = strings.TrimSpace(line)
+					if line == "" || strings.HasPrefix(line, syntax.comment) || seen[line] {
+						continue
+					}
+					seen[line] = true
+					keyword := strings.Fields(line)[0]
+					if existing := statements[keyword]; len(existing) > 0 && contains(syntax.unique, keyword) {
+						return bytes.Buffer{}, fmt.Errorf("cannot combine the files with the statements %s and %s", existing[0], line)
+					}
+					if _, found := statements[keyword]; !found {
+						keywords = append(keywords, keyword)
+					}
+					statements[keyword] = append(statements[keyword], line)
+				}
+			default:
+				key := strings.TrimSpace(strings.TrimSuffix(declaration, "{"))
+				if strings.HasPrefix(key, "extend ") {
+					key = block // The extensions of a type are merged by the schema.
+				}
+				if !seen[key] {
+					seen[key] = true
+					definitions = append(definitions, block)
+				}
+			}
+		}
+	}
+
+	var sections []string
+	if header != "" {
+		sections = append(sections, header)
+	}
+	for _, keyword := range keywords {
+		sort.Strings(statements[keyword])
+		sections = append(sections, strings.Join(statements[keyword], "\n"))
+	}
+	sections = append(sections, definitions...)
+	return *bytes.NewBufferString(strings.Join(sections, "\n\n") + "\n"), nil
+}
+
+// splitBlocks returns the blocks of the given source: its top-level statements and definitions, with their comments,
+// separated by blank lines outside of the definitions.
+func splitBlocks(src string, syntax blockSyntax) []string {
+	var (
+		blocks        []string
+		lines         []string
+		depth         int
+		inDescription bool
+	)
+	flush := func() {
+		if len(lines) > 0 {
+			blocks = append(blocks, strings.Join(lines, "\n"))
+			lines = nil
+		}
+	}
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" && depth == 0 && !inDescription {
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+		if syntax.description != "" && strings.Count(line, syntax.description)%2 == 1 {
+			inDescription = !inDescription
+			continue
+		}
+		if !inDescription && !strings.HasPrefix(strings.TrimSpace(line), syntax.comment) {
+			depth += strings.Count(line, "{") - strings.Count(line, "}")
+		}
+	}
+	flush()
+	return blocks
+}
+
+// declarationLine returns the first line of the given block which is neither a comment nor a description, or "" if it
+// has none.
+func declarationLine(block string, syntax blockSyntax) string {
+	inDescription := false
+	for _, line := range strings.Split(block, "\n") {
+		line = strings.TrimSpace(line)
+		if syntax.description != "" && strings.Contains(line, syntax.description) {
+			if strings.Count(line, syntax.description)%2 == 1 {
+				inDescription = !inDescription
+			}
+			continue
+		}
+		if !inDescription && !strings.HasPrefix(line, syntax.comment) {
+			return line
+		}
+	}
+	return ""
+}
+
+// contains returns true if the given values contain the given value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/leorolland/genz/internal/failure"
+)
+
+// MainTemplate is the name of the main template, as opposed to the names of its partials.
+const MainTemplate = "template"
+
+// TemplateError is an error of the parsing or of the execution of a template, located in the template.
+type TemplateError struct {
+	// Op is the failing operation: "parse" or "execute".
+	Op string
+	// Name of the failing template: MainTemplate, or the name of a partial. The caller can replace it by the name of
+	// the template file.
+	Name string
+	// Line is the 1-based line of the error in the failing template.
+	Line int
+	// Column is the 0-based byte column of the failing action in its line, or -1 if unknown (e.g. syntax errors).
+	Column int
+	// Action is the failing action of an execution error, e.g. "<.Foo>". Empty for syntax errors.
+	Action string
+	// Excerpt is the numbered lines of the failing template around the error.
+	Excerpt string
+	// Err is the error of text/template.
+	Err error
+	// Message is the cause of the error, without its location. e.g. "can't evaluate field Foo in type models.Type"
+	Message string
+}
+
+// templateErrorPattern matches a parse or an execution error of text/template,
+// e.g. `template: partials/header.tmpl:12:5: executing "partials/header.tmpl" at <.Foo>: can't evaluate field Foo`
+// or "template: template:3: unexpected EOF".
+var templateErrorPattern = regexp.MustCompile(`(?s)^template: (\S+?):(\d+):(?:(\d+):)? (?:executing "[^"]*" at (<.*?>): )?(.*)$`)
+
+// newTemplateError returns the *TemplateError of the given text/template error, or a plain error if it has no location.
+// Both are classified as failure.KindTemplate.
+func newTemplateError(op string, err error, templateContent string, partials []Partial) error {
+	match := templateErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return failure.New(failure.KindTemplate, fmt.Errorf("failed to %s template: %v", op, err))
+	}
+	templateErr := &TemplateError{Op: op, Name: match[1], Column: -1, Action: match[4], Err: err, Message: match[5]}
+	templateErr.Line, _ = strconv.Atoi(match[2])
+	if match[3] != "" {
+		templateErr.Column, _ = strconv.Atoi(match[3])
+	}
+
+	content, found := templateContent, templateErr.Name == MainTemplate
+	for _, partial := range partials {
+		if partial.Name == templateErr.Name {
+			content, found = partial.Content, true
+		}
+	}
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if templateErr.Line > len(lines) {
+		lines = strings.Split(content, "\n") // e.g. an unexpected EOF, reported on the line after the last newline.
+	}
+	if found && templateErr.Line >= 1 && templateErr.Line <= len(lines) {
+		templateErr.Excerpt = numberedLines(lines, templateErr.Line, templateErr.Column)
+	}
+	return failure.New(failure.KindTemplate, templateErr)
+}
+
+// Error returns the location, the failing action and the cause of the error, followed by the excerpt of the template.
+// e.g. "failed to execute template validator.tmpl:4:3 at <.Foo>: can't evaluate field Foo in type models.Type"
+func (e *TemplateError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to %s template %s:%d", e.Op, e.Name, e.Line)
+	if e.Column >= 0 {
+		fmt.Fprintf(&b, ":%d", e.Column)
+	}
+	if e.Action != "" {
+		fmt.Fprintf(&b, " at %s", e.Action)
+	}
+	fmt.Fprintf(&b, ": %s", e.Message)
+	if e.Excerpt != "" {
+		b.WriteString("\n")
+		b.WriteString(strings.TrimRight(e.Excerpt, "\n"))
+	}
+	return b.String()
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// Location returns the template, the line and the 1-based column of the error, see failure.Locator.
+func (e *TemplateError) Location() (string, int, int) {
+	return e.Name, e.Line, e.Column + 1
+}
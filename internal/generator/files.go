@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// The markers written by the file and endfile template functions, delimiting the output of an additional file.
+const (
+	fileMarker    = "\x00genz:file:"
+	endFileMarker = "\x00genz:endfile\x00"
+)
+
+// File is an additional output file emitted by a template with {{ file "name" }} ... {{ endfile }}.
+type File struct {
+	// Name of the file, relative to the directory of the main output unless absolute. e.g. "user_mock.gen.go"
+	Name string
+	// Content rendered between the file and endfile actions.
+	Content bytes.Buffer
+}
+
+// file starts the additional output file with the given name. e.g. {{ file "user_test.go" }} ... {{ endfile }}
+func file(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, "\x00\n") {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	return fileMarker + name + "\x00", nil
+}
+
+// endfile ends the additional output file started by file.
+func endfile() string {
+	return endFileMarker
+}
+
+// SplitFiles extracts the additional files emitted by the template from the generated buffer.
+// It returns the rest of the buffer, written to the main output, and the files in their rendering order.
+func SplitFiles(buf bytes.Buffer) (bytes.Buffer, []File, error) {
+	// The buffer is copied only if it has files: a large output is not duplicated in memory.
+	if !bytes.Contains(buf.Bytes(), []byte(fileMarker)) && !bytes.Contains(buf.Bytes(), []byte(endFileMarker)) {
+		return buf, nil, nil
+	}
+	src := buf.String()
+	var main bytes.Buffer
+	var files []File
+	for {
+		start := strings.Index(src, fileMarker)
+		if end := strings.Index(src, endFileMarker); end >= 0 && (start < 0 || end < start) {
+			return bytes.Buffer{}, nil, fmt.Errorf("{{ endfile }} without {{ file }}")
+		}
+		if start < 0 {
+			main.WriteString(src)
+			return main, files, nil
+		}
+		main.WriteString(src[:start])
+		src = src[start+len(fileMarker):]
+		nameEnd := strings.Index(src, "\x00")
+		name := src[:nameEnd]
+		src = src[nameEnd+1:]
+
+		end := strings.Index(src, endFileMarker)
+		if end < 0 {
+			return bytes.Buffer{}, nil, fmt.Errorf("{{ file %q }} without {{ endfile }}", name)
+		}
+		content := src[:end]
+		if strings.Contains(content, fileMarker) {
+			return bytes.Buffer{}, nil, fmt.Errorf("{{ file }} nested in {{ file %q }}", name)
+		}
+		files = append(files, File{Name: name, Content: *bytes.NewBufferString(strings.TrimPrefix(content, "\n"))})
+		src = strings.TrimPrefix(src[end+len(endFileMarker):], "\n")
+	}
+}
+
+// UsesFiles returns true if the given template, or one of its partials, calls the file or endfile functions: its output
+// has to be rendered in memory to be split, see SplitFiles.
+func UsesFiles(tmpl *template.Template) bool {
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && usesFiles(t.Tree.Root) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesFiles returns true if the given node, or one of its children, calls the file or endfile functions.
+func usesFiles(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.IdentifierNode:
+		return n.Ident == "file" || n.Ident == "endfile"
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if usesFiles(child) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return usesFiles(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if usesFiles(cmd) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if usesFiles(arg) {
+				return true
+			}
+		}
+	case *parse.IfNode:
+		return usesFiles(&n.BranchNode)
+	case *parse.RangeNode:
+		return usesFiles(&n.BranchNode)
+	case *parse.WithNode:
+		return usesFiles(&n.BranchNode)
+	case *parse.BranchNode:
+		return usesFiles(n.Pipe) || usesFiles(n.List) || usesFiles(n.ElseList)
+	case *parse.ChainNode:
+		return usesFiles(n.Node)
+	case *parse.TemplateNode:
+		return usesFiles(n.Pipe)
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package generator_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestSplitFiles(t *testing.T) {
+	testCases := map[string]struct {
+		template     string
+		expectedMain string
+		expected     map[string]string
+		expectedErr  bool
+	}{
+		"no file": {
+			template:     "package main\n",
+			expectedMain: "package main\n",
+		},
+		"main and files": {
+			template: "package main\n{{ file \"a_test.go\" }}\npackage a\n{{ endfile }}\n// end\n" +
+				"{{ file (printf \"%s.sql\" (lower .Type.Name)) }}\nCREATE TABLE t;\n{{ endfile }}",
+			expectedMain: "package main\n// end\n",
+			expected:     map[string]string{"a_test.go": "package a\n", "user.sql": "CREATE TABLE t;\n"},
+		},
+		"only files": {
+			template: "{{ file \"a.go\" }}a{{ endfile }}",
+			expected: map[string]string{"a.go": "a"},
+		},
+		"missing endfile": {
+			template:    "{{ file \"a.go\" }}a",
+			expectedErr: true,
+		},
+		"missing file": {
+			template:    "a{{ endfile }}",
+			expectedErr: true,
+		},
+		"nested files": {
+			template:    "{{ file \"a.go\" }}{{ file \"b.go\" }}{{ endfile }}{{ endfile }}",
+			expectedErr: true,
+		},
+	}
+	parseFunc := func(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+		return models.ParsedElement{Element: models.Element{Type: models.Type{Name: "User"}}}, nil
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			buf, err := generator.Generate(nil, tc.template, "User", parseFunc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			main, files, err := generator.SplitFiles(buf)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if main.String() != tc.expectedMain {
+				t.Errorf("expected main %q, got %q", tc.expectedMain, main.String())
+			}
+			got := map[string]string{}
+			for _, file := range files {
+				got[file.Name] = file.Content.String()
+			}
+			if tc.expected == nil {
+				tc.expected = map[string]string{}
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("unexpected files: %s", cmp.Diff(tc.expected, got))
+			}
+		})
+	}
+}
+
+func TestFileErrorInvalidName(t *testing.T) {
+	parseFunc := func(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+		return models.ParsedElement{}, nil
+	}
+	if _, err := generator.Generate(nil, `{{ file "" }}{{ endfile }}`, "User", parseFunc); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestUsesFiles(t *testing.T) {
+	testCases := map[string]struct {
+		template string
+		partials []generator.Partial
+		expected bool
+	}{
+		"no file":      {template: "package {{ .Type.Name }}\n{{ range .Attributes }}{{ .Name }}{{ end }}"},
+		"file":         {template: "{{ file \"a.sql\" }}CREATE TABLE t;{{ endfile }}", expected: true},
+		"nested":       {template: "{{ range .Attributes }}{{ if .Tags }}{{ file (printf \"%s.sql\" .Name) }}{{ endfile }}{{ end }}{{ end }}", expected: true},
+		"else":         {template: "{{ with .Target }}{{ else }}{{ endfile }}{{ end }}", expected: true},
+		"partial":      {template: "{{ template \"files\" . }}", partials: []generator.Partial{{Name: "files", Content: "{{ file \"a\" }}{{ endfile }}"}}, expected: true},
+		"field":        {template: "{{ .Type.file }}"},
+		"text":         {template: "file endfile"},
+		"pipe command": {template: "{{ \"a\" | file }}{{ endfile }}", expected: true},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := generator.Parse(tc.template, tc.partials...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := generator.UsesFiles(tmpl); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
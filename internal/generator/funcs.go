@@ -0,0 +1,312 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/leorolland/genz/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// genzFuncs are the helpers added by genz to the sprig functions.
+var genzFuncs = template.FuncMap{
+	"camelCase":  camelCase,
+	"pascalCase": pascalCase,
+	"snakeCase":  snakeCase,
+	"kebabCase":  kebabCase,
+	"pluralize":  pluralize,
+	"hasTag":     hasTag,
+	"tagValue":   tagValue,
+	"zeroValue":  zeroValue,
+	"literal":    literal,
+	"fakeValue":  fakeValue,
+	"isExported": token.IsExported,
+	"toYaml":     toYaml,
+	"file":       file,
+	"endfile":    endfile,
+
+	"methodUnion":        methodUnion,
+	"methodIntersection": methodIntersection,
+	"methodDifference":   methodDifference,
+}
+
+// FuncMap returns the functions available in every template:
+// the sprig functions (https://masterminds.github.io/sprig/) plus the genz helpers.
+func FuncMap() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	for name, f := range genzFuncs {
+		funcs[name] = f
+	}
+	return funcs
+}
+
+// splitWords splits an identifier into words, on underscores, hyphens, spaces and case changes.
+// Acronyms are kept as a single word. e.g. "HTTPServer_name" => ["HTTP", "Server", "name"]
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' || r == '-' || unicode.IsSpace(r) {
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+		previousIsLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+		nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if previousIsLower || (unicode.IsUpper(runes[i-1]) && nextIsLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// capitalize returns the word with an upper case first letter. Acronyms are kept in upper case.
+func capitalize(word string) string {
+	if strings.ToUpper(word) == word {
+		return word
+	}
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// camelCase returns the identifier in lower camel case. e.g. "user_id" => "userId", "UserID" => "userID"
+func camelCase(s string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word)
+			continue
+		}
+		words[i] = capitalize(word)
+	}
+	return strings.Join(words, "")
+}
+
+// pascalCase returns the identifier in upper camel case. e.g. "user_id" => "UserId", "userID" => "UserID"
+func pascalCase(s string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+	return strings.Join(words, "")
+}
+
+// snakeCase returns the identifier in snake case. e.g. "UserID" => "user_id"
+func snakeCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "_"))
+}
+
+// kebabCase returns the identifier in kebab case. e.g. "UserID" => "user-id"
+func kebabCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "-"))
+}
+
+var (
+	pluralEsSuffix  = regexp.MustCompile(`(s|x|z|ch|sh)$`)
+	pluralIesSuffix = regexp.MustCompile(`[^aeiou]y$`)
+)
+
+// pluralize returns the plural of an english noun, using the regular rules. e.g. "user" => "users", "category" => "categories"
+func pluralize(s string) string {
+	switch {
+	case s == "":
+		return s
+	case pluralIesSuffix.MatchString(s):
+		return s[:len(s)-1] + "ies"
+	case pluralEsSuffix.MatchString(s):
+		return s + "es"
+	default:
+		return s + "s"
+	}
+}
+
+// hasTag returns true if the attribute has the given tag key. e.g. {{ if hasTag . "json" }}
+func hasTag(attribute models.Attribute, key string) bool {
+	_, ok := attribute.Tags[key]
+	return ok
+}
+
+// tagValue returns the value of the given tag key of the attribute, or an empty string. e.g. {{ tagValue . "json" }}
+func tagValue(attribute models.Attribute, key string) string {
+	return attribute.Tags[key]
+}
+
+// zeroValue returns the Go literal of the zero value of the given type name. e.g. {{ zeroValue .Type.InternalName }}
+// Types which cannot be inferred from their name (e.g. named structs) use the "*new(T)" expression.
+func zeroValue(typeName string) string {
+	switch {
+	case typeName == "string":
+		return `""`
+	case typeName == "bool":
+		return "false"
+	case typeName == "error" || typeName == "any" || strings.HasPrefix(typeName, "interface{"):
+		return "nil"
+	case strings.HasPrefix(typeName, "*"), strings.HasPrefix(typeName, "[]"), strings.HasPrefix(typeName, "map["),
+		strings.HasPrefix(typeName, "chan "), strings.HasPrefix(typeName, "<-chan "), strings.HasPrefix(typeName, "func("):
+		return "nil"
+	case isNumeric(typeName):
+		return "0"
+	case strings.HasPrefix(typeName, "["), strings.HasPrefix(typeName, "struct{"):
+		return typeName + "{}"
+	default:
+		return "*new(" + typeName + ")"
+	}
+}
+
+// literal returns the Go expression of the given value, written as text (e.g. in a `default:"5s"` tag), for the given
+// type. e.g. {{ literal .Type (tagValue . "default") }}
+// Strings are quoted, booleans and numbers are checked, and durations are written with their largest unit,
+// e.g. "90s" => "90 * time.Second". The value of another type is a Go expression, returned as is.
+func literal(t models.Type, value string) (string, error) {
+	kind := t.BasicKind
+	if kind == "" {
+		kind = t.Name
+	}
+	var err error
+	switch {
+	case t.Name == "time.Duration":
+		var d time.Duration
+		if d, err = time.ParseDuration(value); err == nil {
+			return durationLiteral(d), nil
+		}
+	case kind == "string":
+		return strconv.Quote(value), nil
+	case kind == "bool":
+		var b bool
+		if b, err = strconv.ParseBool(value); err == nil {
+			return strconv.FormatBool(b), nil
+		}
+	case strings.HasPrefix(kind, "int"):
+		_, err = strconv.ParseInt(value, 0, 64)
+	case strings.HasPrefix(kind, "uint") || kind == "byte":
+		_, err = strconv.ParseUint(value, 0, 64)
+	case strings.HasPrefix(kind, "float"):
+		_, err = strconv.ParseFloat(value, 64)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid %s value %q: %w", t.Name, value, err)
+	}
+	return value, nil
+}
+
+// fakeStrings are the fake strings of the formats of fakeValue.
+var fakeStrings = map[string]string{
+	"email": "jane.doe@example.com",
+	"name":  "Jane Doe",
+	"phone": "+1 555 0100",
+	"uri":   "https://example.com",
+	"url":   "https://example.com",
+	"uuid":  "123e4567-e89b-12d3-a456-426614174000",
+}
+
+// fakeValue returns the Go expression of a fake value of the given type, e.g. for a test fixture, or nothing if the type
+// has no fake value, e.g. an interface or a named struct. A string is the fake string of the given format, e.g. "email",
+// or the format itself, and a boolean or a number is the format if it is a valid literal, see literal.
+// Pointers, slices and maps hold a fake value. e.g. {{ fakeValue .Type "email" }} => "jane.doe@example.com"
+func fakeValue(t models.Type, format string) string {
+	kind := t.BasicKind
+	if kind == "" {
+		kind = t.Name
+	}
+	switch {
+	case t.Name == "time.Time":
+		return "time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC)"
+	case t.Name == "time.Duration":
+		if value, err := literal(t, format); err == nil && format != "" {
+			return value
+		}
+		return "time.Second"
+	case kind == "string":
+		if value, found := fakeStrings[format]; found {
+			return strconv.Quote(value)
+		}
+		return strconv.Quote(format)
+	case kind == "bool", isNumeric(kind):
+		if value, err := literal(t, format); err == nil && format != "" {
+			return value
+		}
+		if kind == "bool" {
+			return "true"
+		}
+		if strings.HasPrefix(kind, "float") || strings.HasPrefix(kind, "complex") {
+			return "1.5"
+		}
+		return "1"
+	case t.IsPointer && t.Elem != nil:
+		if elem := fakeValue(*t.Elem, format); elem != "" {
+			return fmt.Sprintf("func() %s { var v %s = %s; return &v }()", t.LocalName, t.Elem.LocalName, elem)
+		}
+	case t.IsSlice && t.Elem != nil:
+		if elem := fakeValue(*t.Elem, format); elem != "" {
+			return fmt.Sprintf("%s{%s}", t.LocalName, elem)
+		}
+	case t.IsMap && t.Key != nil && t.Elem != nil:
+		key, elem := fakeValue(*t.Key, "key"), fakeValue(*t.Elem, format)
+		if key != "" && elem != "" {
+			return fmt.Sprintf("%s{%s: %s}", t.LocalName, key, elem)
+		}
+	}
+	return ""
+}
+
+// durationLiteral returns the Go expression of the given duration with its largest unit, e.g. "90 * time.Second".
+func durationLiteral(d time.Duration) string {
+	units := []struct {
+		duration time.Duration
+		name     string
+	}{
+		{time.Hour, "time.Hour"}, {time.Minute, "time.Minute"}, {time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"}, {time.Microsecond, "time.Microsecond"},
+	}
+	for _, unit := range units {
+		if d != 0 && d%unit.duration == 0 {
+			return fmt.Sprintf("%d * %s", d/unit.duration, unit.name)
+		}
+	}
+	return strconv.FormatInt(int64(d), 10)
+}
+
+func isNumeric(typeName string) bool {
+	switch typeName {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64", "complex64", "complex128", "byte", "rune":
+		return true
+	}
+	return false
+}
+
+// toYaml returns the YAML document of the given value, indented with 2 spaces. Map keys are sorted.
+// e.g. {{ toYaml (dict "components" $components) }}
+func toYaml(value any) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,203 @@
+package generator
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/leorolland/genz/pkg/models"
+)
+
+func TestCaseFuncs(t *testing.T) {
+	testCases := map[string]struct {
+		camel, pascal, snake, kebab string
+	}{
+		"user_id":         {camel: "userId", pascal: "UserId", snake: "user_id", kebab: "user-id"},
+		"UserID":          {camel: "userID", pascal: "UserID", snake: "user_id", kebab: "user-id"},
+		"HTTPServer":      {camel: "httpServer", pascal: "HTTPServer", snake: "http_server", kebab: "http-server"},
+		"first-name":      {camel: "firstName", pascal: "FirstName", snake: "first_name", kebab: "first-name"},
+		"createdAt":       {camel: "createdAt", pascal: "CreatedAt", snake: "created_at", kebab: "created-at"},
+		"address line 2":  {camel: "addressLine2", pascal: "AddressLine2", snake: "address_line_2", kebab: "address-line-2"},
+		"":                {},
+		"X":               {camel: "x", pascal: "X", snake: "x", kebab: "x"},
+		"utf8Decoder_v2":  {camel: "utf8DecoderV2", pascal: "Utf8DecoderV2", snake: "utf8_decoder_v2", kebab: "utf8-decoder-v2"},
+		"already_snake_2": {camel: "alreadySnake2", pascal: "AlreadySnake2", snake: "already_snake_2", kebab: "already-snake-2"},
+	}
+	for input, tc := range testCases {
+		if got := camelCase(input); got != tc.camel {
+			t.Errorf("camelCase(%q) = %q, want %q", input, got, tc.camel)
+		}
+		if got := pascalCase(input); got != tc.pascal {
+			t.Errorf("pascalCase(%q) = %q, want %q", input, got, tc.pascal)
+		}
+		if got := snakeCase(input); got != tc.snake {
+			t.Errorf("snakeCase(%q) = %q, want %q", input, got, tc.snake)
+		}
+		if got := kebabCase(input); got != tc.kebab {
+			t.Errorf("kebabCase(%q) = %q, want %q", input, got, tc.kebab)
+		}
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	testCases := map[string]string{
+		"user":     "users",
+		"category": "categories",
+		"day":      "days",
+		"box":      "boxes",
+		"address":  "addresses",
+		"match":    "matches",
+		"":         "",
+	}
+	for input, expected := range testCases {
+		if got := pluralize(input); got != expected {
+			t.Errorf("pluralize(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestTagFuncs(t *testing.T) {
+	attribute := models.Attribute{Tags: map[string]string{"json": "name,omitempty"}}
+	if !hasTag(attribute, "json") {
+		t.Errorf("expected json tag")
+	}
+	if hasTag(attribute, "xml") {
+		t.Errorf("unexpected xml tag")
+	}
+	if got := tagValue(attribute, "json"); got != "name,omitempty" {
+		t.Errorf("tagValue() = %q, want %q", got, "name,omitempty")
+	}
+	if got := tagValue(models.Attribute{}, "json"); got != "" {
+		t.Errorf("tagValue() = %q, want empty string", got)
+	}
+}
+
+func TestTagTemplate(t *testing.T) {
+	attribute := models.Attribute{
+		Tags:       map[string]string{"json": "name,omitempty"},
+		ParsedTags: map[string]models.Tag{"json": {Value: "name,omitempty", Name: "name", Options: []string{"omitempty"}}},
+	}
+	tmpl := template.Must(template.New("").Funcs(FuncMap()).Parse(
+		`{{ index .Tags "json" }} {{ (index .ParsedTags "json").Name }} {{ (index .ParsedTags "json").HasOption "omitempty" }} {{ (index .ParsedTags "xml").HasOption "attr" }}`,
+	))
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, attribute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "name,omitempty name true false"; buf.String() != expected {
+		t.Errorf("got %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	testCases := map[string]string{
+		"string":         `""`,
+		"bool":           "false",
+		"int64":          "0",
+		"float32":        "0",
+		"error":          "nil",
+		"*User":          "nil",
+		"[]string":       "nil",
+		"map[string]int": "nil",
+		"chan int":       "nil",
+		"func() error":   "nil",
+		"interface{}":    "nil",
+		"[2]int":         "[2]int{}",
+		"struct{a int}":  "struct{a int}{}",
+		"time.Time":      "*new(time.Time)",
+	}
+	for input, expected := range testCases {
+		if got := zeroValue(input); got != expected {
+			t.Errorf("zeroValue(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	duration := models.Type{Name: "time.Duration", BasicKind: "int64"}
+	testCases := map[string]struct {
+		t        models.Type
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		"string":            {t: models.Type{Name: "string"}, value: `a "b"`, expected: `"a \"b\""`},
+		"named string":      {t: models.Type{Name: "main.Level", BasicKind: "string"}, value: "info", expected: `"info"`},
+		"bool":              {t: models.Type{Name: "bool"}, value: "1", expected: "true"},
+		"int":               {t: models.Type{Name: "int"}, value: "8080", expected: "8080"},
+		"float":             {t: models.Type{Name: "float64"}, value: "0.5", expected: "0.5"},
+		"duration":          {t: duration, value: "1m30s", expected: "90 * time.Second"},
+		"duration in hours": {t: duration, value: "2h", expected: "2 * time.Hour"},
+		"zero duration":     {t: duration, value: "0s", expected: "0"},
+		"expression":        {t: models.Type{Name: "*log.Logger"}, value: "log.Default()", expected: "log.Default()"},
+		"invalid int":       {t: models.Type{Name: "int"}, value: "ten", wantErr: true},
+		"invalid duration":  {t: duration, value: "5", wantErr: true},
+	}
+	for name, tc := range testCases {
+		got, err := literal(tc.t, tc.value)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: literal() error = %v, wantErr %v", name, err, tc.wantErr)
+		}
+		if got != tc.expected {
+			t.Errorf("%s: literal() = %q, want %q", name, got, tc.expected)
+		}
+	}
+}
+
+func TestFakeValue(t *testing.T) {
+	str := models.Type{Name: "string", InternalName: "string", LocalName: "string"}
+	integer := models.Type{Name: "int", InternalName: "int", LocalName: "int"}
+	testCases := map[string]struct {
+		t        models.Type
+		format   string
+		expected string
+	}{
+		"string of a format":     {t: str, format: "email", expected: `"jane.doe@example.com"`},
+		"string":                 {t: str, format: "first_name", expected: `"first_name"`},
+		"named string":           {t: models.Type{Name: "main.Level", BasicKind: "string"}, format: "level", expected: `"level"`},
+		"bool":                   {t: models.Type{Name: "bool"}, format: "active", expected: "true"},
+		"int":                    {t: integer, format: "age", expected: "1"},
+		"int of the format":      {t: integer, format: "42", expected: "42"},
+		"float":                  {t: models.Type{Name: "float64"}, format: "price", expected: "1.5"},
+		"time":                   {t: models.Type{Name: "time.Time"}, format: "created_at", expected: "time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC)"},
+		"duration":               {t: models.Type{Name: "time.Duration", BasicKind: "int64"}, format: "timeout", expected: "time.Second"},
+		"duration of the format": {t: models.Type{Name: "time.Duration", BasicKind: "int64"}, format: "1m", expected: "1 * time.Minute"},
+		"pointer": {
+			t:        models.Type{Name: "*string", LocalName: "*string", IsPointer: true, Elem: &str},
+			format:   "url",
+			expected: `func() *string { var v string = "https://example.com"; return &v }()`,
+		},
+		"slice": {
+			t:        models.Type{Name: "[]string", LocalName: "[]string", IsSlice: true, Elem: &str},
+			format:   "tags",
+			expected: `[]string{"tags"}`,
+		},
+		"map": {
+			t:        models.Type{Name: "map[string]int", LocalName: "map[string]int", IsMap: true, Key: &str, Elem: &integer},
+			format:   "counts",
+			expected: `map[string]int{"key": 1}`,
+		},
+		"named struct": {t: models.Type{Name: "main.Address", LocalName: "Address"}, format: "address", expected: ""},
+		"slice of interfaces": {
+			t:        models.Type{Name: "[]error", LocalName: "[]error", IsSlice: true, Elem: &models.Type{Name: "error"}},
+			format:   "errors",
+			expected: "",
+		},
+	}
+	for name, tc := range testCases {
+		if got := fakeValue(tc.t, tc.format); got != tc.expected {
+			t.Errorf("%s: fakeValue() = %q, want %q", name, got, tc.expected)
+		}
+	}
+}
+
+func TestToYaml(t *testing.T) {
+	got, err := toYaml(map[string]any{"b": []int{1, 2}, "a": map[string]string{"$ref": "#/x"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "a:\n  $ref: '#/x'\nb:\n  - 1\n  - 2\n"
+	if got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
@@ -3,54 +3,204 @@ package generator
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"go/format"
-	"html/template"
-	"log"
+	"go/scanner"
+	"io"
+	"strings"
+	"text/template"
 
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/logging"
 	"github.com/leorolland/genz/pkg/models"
 
-	"github.com/Masterminds/sprig/v3"
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/imports"
 )
 
 type parseFunc func(pkg *packages.Package, typeName string) (models.ParsedElement, error)
 
+// Partial is a template file parsed along the main template, e.g. from a template directory.
+// Its {{ define }} blocks can be used by the main template, and the partial itself with {{ template "<name>" . }}.
+type Partial struct {
+	// Name of the partial, e.g. its path relative to the template directory: "partials/header.tmpl"
+	Name string
+	// Content of the partial.
+	Content string
+}
+
 func Generate(
 	pkg *packages.Package,
 	templateContent string,
 	typeName string,
 	parse parseFunc,
+	partials ...Partial,
 ) (bytes.Buffer, error) {
-	log.Printf("generating template for type %s", typeName)
+	return GenerateWithFuncs(pkg, templateContent, typeName, parse, nil, partials...)
+}
+
+// GenerateWithFuncs renders the template as Generate does, with the given custom functions in addition to the ones of
+// FuncMap, see LoadFuncs.
+func GenerateWithFuncs(
+	pkg *packages.Package,
+	templateContent string,
+	typeName string,
+	parse parseFunc,
+	funcs template.FuncMap,
+	partials ...Partial,
+) (bytes.Buffer, error) {
+	buf := bytes.Buffer{}
+	if err := GenerateTo(&buf, pkg, templateContent, typeName, parse, funcs, partials...); err != nil {
+		return bytes.Buffer{}, err
+	}
+	logging.Debug("rendered the template", "bytes", buf.Len())
+	return buf, nil
+}
+
+// GenerateTo renders the template as GenerateWithFuncs does, into the given writer, e.g. the buffered writer of an
+// output file too large to be held in memory. The writer may have been partially written on error.
+func GenerateTo(
+	w io.Writer,
+	pkg *packages.Package,
+	templateContent string,
+	typeName string,
+	parse parseFunc,
+	funcs template.FuncMap,
+	partials ...Partial,
+) error {
+	if typeName == "" {
+		logging.Debug("rendering the template", "package", pkg.Name)
+	} else {
+		logging.Debug("rendering the template", "type", typeName)
+	}
 
 	parsedElement, err := parse(pkg, typeName)
 	if err != nil {
-		return bytes.Buffer{}, fmt.Errorf("failed to inspect package: %v", err)
+		return failure.New(failure.KindParse, fmt.Errorf("failed to inspect package: %w", err))
 	}
 
-	tmpl, err := template.New("template").Funcs(sprig.FuncMap()).Parse(templateContent)
+	tmpl, err := ParseWithFuncs(templateContent, funcs, partials...)
 	if err != nil {
-		return bytes.Buffer{}, fmt.Errorf("failed to parse template: %v", err)
+		return err
 	}
-	buf := bytes.Buffer{}
-	err = tmpl.Execute(&buf, parsedElement)
+	if err := tmpl.Execute(w, parsedElement); err != nil {
+		return newTemplateError("execute", err, templateContent, partials)
+	}
+	return nil
+}
+
+// Parse parses the given template, named "template", along with its partials.
+// A syntax error is returned as a *TemplateError.
+func Parse(templateContent string, partials ...Partial) (*template.Template, error) {
+	return ParseWithFuncs(templateContent, nil, partials...)
+}
+
+// ParseWithFuncs parses the template as Parse does, with the given custom functions in addition to the ones of FuncMap.
+func ParseWithFuncs(templateContent string, funcs template.FuncMap, partials ...Partial) (*template.Template, error) {
+	tmpl, err := template.New(MainTemplate).Funcs(FuncMap()).Funcs(funcs).Parse(templateContent)
 	if err != nil {
-		return bytes.Buffer{}, fmt.Errorf("failed to execute template: %v", err)
+		return nil, newTemplateError("parse", err, templateContent, partials)
+	}
+	for _, partial := range partials {
+		if _, err := tmpl.New(partial.Name).Parse(partial.Content); err != nil {
+			return nil, newTemplateError("parse", err, templateContent, partials)
+		}
 	}
+	return tmpl, nil
+}
 
-	log.Printf("generated buffer (%d bytes)", buf.Len())
-	return buf, nil
+// Evaluate executes the given expression with the given data, e.g. ".Type.Name" or "{{ range .Attributes }}...{{ end }}".
+// An expression without action delimiters is evaluated as a single action. It can use the {{ define }} blocks of the
+// given template, which is left unchanged, or nil.
+func Evaluate(tmpl *template.Template, expression string, data any) (string, error) {
+	if !strings.Contains(expression, "{{") {
+		expression = "{{ " + expression + " }}"
+	}
+	var expressionTmpl *template.Template
+	if tmpl == nil {
+		expressionTmpl = template.New("expression").Funcs(FuncMap())
+	} else {
+		clone, err := tmpl.Clone()
+		if err != nil {
+			return "", err
+		}
+		expressionTmpl = clone.New("expression")
+	}
+	if _, err := expressionTmpl.Parse(expression); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := expressionTmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
 }
 
-func Format(buf bytes.Buffer) []byte {
-	log.Print("gofmt-ing buffer")
+// Format gofmts the given generated Go source.
+// It returns an error showing the invalid lines if the source is not valid Go code.
+func Format(buf bytes.Buffer) ([]byte, error) {
+	logging.Debug("formatting")
 
 	src, err := format.Source(buf.Bytes())
 	if err != nil {
-		log.Printf("warning: internal error: invalid Go generated: %s", err)
-		log.Printf("warning: compile the package to analyze the error")
-		return buf.Bytes()
+		err = fmt.Errorf("invalid Go code generated (use -raw to write it as is): %v\n%s", err, excerpt(buf.Bytes(), err))
+		return nil, failure.New(failure.KindTemplate, err)
+	}
+	return src, nil
+}
+
+// excerpt returns the numbered lines of src around the position of the first error of the given format error,
+// or nothing if the error has no position.
+func excerpt(src []byte, err error) string {
+	var errs scanner.ErrorList
+	if !errors.As(err, &errs) || len(errs) == 0 {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(string(src), "\n"), "\n")
+	return numberedLines(lines, errs[0].Pos.Line, -1)
+}
+
+// numberedLines returns the lines around the given 1-based line, the line being marked with ">",
+// and followed by a caret under the given 0-based byte column, if not negative.
+func numberedLines(lines []string, line, column int) string {
+	const context = 2
+	first, last := line-context, line+context
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	var b strings.Builder
+	for i := first; i <= last; i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %4d | %s\n", marker, i, lines[i-1])
+		if i == line && column >= 0 && column <= len(lines[i-1]) {
+			// Tabs are kept, so that the caret is aligned whatever the tab width.
+			indent := strings.Map(func(r rune) rune {
+				if r == '\t' {
+					return r
+				}
+				return ' '
+			}, lines[i-1][:column])
+			fmt.Fprintf(&b, "         %s^\n", indent)
+		}
+	}
+	return b.String()
+}
+
+// FixImports adds the missing imports and removes the unused ones of the given Go source.
+// The filename is used to resolve the imports from the other files of its directory first.
+func FixImports(filename string, src []byte) ([]byte, error) {
+	logging.Debug("fixing the imports")
+
+	fixed, err := imports.Process(filename, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fix imports: %v", err)
 	}
-	return src
+	return fixed, nil
 }
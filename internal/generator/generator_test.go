@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/leorolland/genz/pkg/models"
 
 	"github.com/leorolland/genz/internal/generator"
@@ -70,16 +71,325 @@ func TestGenerateSuccess(t *testing.T) {
 	}
 }
 
-func TestFormatSuccessInvalidGoCode(t *testing.T) {
-	src := generator.Format(*bytes.NewBufferString("package[main\n\n\n"))
-	if string(src) != "package[main\n\n\n" {
-		t.Fatalf("expected formatted code, got: %q", src)
+func TestGenerateTo(t *testing.T) {
+	parseFunc := func(pkg *packages.Package, structName string) (models.ParsedElement, error) {
+		return models.ParsedElement{Element: models.Element{Type: models.Type{Name: "TypeName"}}}, nil
+	}
+	var buf bytes.Buffer
+	if err := generator.GenerateTo(&buf, nil, "{{ .Type.Name }} {{ template \"p\" }}", "TypeName", parseFunc, nil, generator.Partial{Name: "p", Content: "ok"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "TypeName ok" {
+		t.Fatalf("expected TypeName ok, got %s", buf.String())
+	}
+	if err := generator.GenerateTo(&buf, nil, "{{ .Type.Nope }}", "TypeName", parseFunc, nil); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestGenerateSuccessWithPartials(t *testing.T) {
+	parseFunc := func(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+		return models.ParsedElement{Element: models.Element{Type: models.Type{Name: "TypeName"}}}, nil
+	}
+	buf, err := generator.Generate(nil, `{{ template "greet" .Type.Name }} {{ template "partials/end.tmpl" }}`, "TypeName", parseFunc,
+		generator.Partial{Name: "partials/greet.tmpl", Content: `{{ define "greet" }}hello {{ . }}{{ end }}`},
+		generator.Partial{Name: "partials/end.tmpl", Content: `!`},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "hello TypeName !" {
+		t.Fatalf("expected hello TypeName !, got %s", buf.String())
+	}
+}
+
+func TestGenerateErrorInvalidPartial(t *testing.T) {
+	parseFunc := func(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+		return models.ParsedElement{}, nil
+	}
+	_, err := generator.Generate(nil, "", "TypeName", parseFunc, generator.Partial{Name: "broken.tmpl", Content: "{{ end }}"})
+	if err == nil || !strings.Contains(err.Error(), "broken.tmpl") {
+		t.Fatalf("expected an error naming the partial, got %v", err)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tmpl, err := generator.Parse(`{{ define "upper" }}{{ upper . }}{{ end }}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testCases := map[string]struct {
+		expression string
+		expected   string
+		wantErr    bool
+	}{
+		"single action":        {expression: ".PackageName", expected: "main"},
+		"template text":        {expression: "package {{ .PackageName }}", expected: "package main"},
+		"define of template":   {expression: `template "upper" .PackageName`, expected: "MAIN"},
+		"unknown field":        {expression: ".Unknown", wantErr: true},
+		"invalid expression":   {expression: "{{ end }}", wantErr: true},
+		"genz and sprig funcs": {expression: `snakeCase "UserID" | upper`, expected: "USER_ID"},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := generator.Evaluate(tmpl, tc.expression, models.ParsedElement{PackageName: "main"})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %t, got %v", tc.wantErr, err)
+			}
+			if result != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+	if tmpl.Lookup("expression") != nil {
+		t.Fatal("expected the template to be left unchanged")
+	}
+}
+
+func TestGenerateErrorTemplateLocation(t *testing.T) {
+	parseFunc := func(pkg *packages.Package, structName string) (models.ParsedElement, error) {
+		return models.ParsedElement{Element: models.Element{Attributes: []models.Attribute{{Name: "B"}}}}, nil
+	}
+	testCases := map[string]struct {
+		template string
+		partials []generator.Partial
+		expected generator.TemplateError
+	}{
+		"execution error in a partial": {
+			template: `{{ range .Attributes }}{{ template "partials/field.tmpl" . }}{{ end }}`,
+			partials: []generator.Partial{{Name: "partials/field.tmpl", Content: "// field\n\t{{ .Nope }}\n"}},
+			expected: generator.TemplateError{
+				Op:      "execute",
+				Name:    "partials/field.tmpl",
+				Line:    2,
+				Column:  4,
+				Action:  "<.Nope>",
+				Excerpt: "     1 | // field\n>    2 | \t{{ .Nope }}\n         \t   ^\n",
+				Message: "can't evaluate field Nope in type models.Attribute",
+			},
+		},
+		"syntax error in the main template": {
+			template: "package main\n\n{{ if .Type }}\n",
+			expected: generator.TemplateError{
+				Op:      "parse",
+				Name:    generator.MainTemplate,
+				Line:    4,
+				Column:  -1,
+				Excerpt: "     2 | \n     3 | {{ if .Type }}\n>    4 | \n",
+				Message: "unexpected EOF",
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := generator.Generate(nil, tc.template, "A", parseFunc, tc.partials...)
+			var templateErr *generator.TemplateError
+			if !errors.As(err, &templateErr) {
+				t.Fatalf("expected a template error, got %v", err)
+			}
+			templateErr.Err = nil
+			if diff := cmp.Diff(tc.expected, *templateErr); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTemplateErrorMessage(t *testing.T) {
+	err := &generator.TemplateError{
+		Op:      "execute",
+		Name:    "validator.tmpl",
+		Line:    4,
+		Column:  3,
+		Action:  "<.Foo>",
+		Excerpt: ">    4 | {{ .Foo }}\n           ^\n",
+		Message: "can't evaluate field Foo in type models.Type",
+	}
+	expected := "failed to execute template validator.tmpl:4:3 at <.Foo>: can't evaluate field Foo in type models.Type\n" +
+		">    4 | {{ .Foo }}\n           ^"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestFormatErrorInvalidGoCode(t *testing.T) {
+	_, err := generator.Format(*bytes.NewBufferString("package main\n\nfunc main() {\n\treturn [\n}\n"))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), ">    5 | }") {
+		t.Fatalf("expected the invalid line in error: %v", err)
 	}
 }
 
 func TestFormatSuccessValidGoCode(t *testing.T) {
-	src := generator.Format(*bytes.NewBufferString(" package main\n\n\n"))
+	src, err := generator.Format(*bytes.NewBufferString(" package main\n\n\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if string(src) != "package main\n" {
 		t.Fatalf("expected formatted code, got: %q", src)
 	}
 }
+
+func TestCombineSuccessGoCode(t *testing.T) {
+	combined := generator.Combine([]bytes.Buffer{
+		*bytes.NewBufferString("package main\n\nimport \"fmt\"\n\nfunc (a A) String() string { return fmt.Sprint(a.name) }\n"),
+		*bytes.NewBufferString("package main\n\nimport (\n\t\"fmt\"\n\t\"strings\"\n)\n\nfunc (b B) String() string { return fmt.Sprint(strings.ToLower(b.name)) }\n"),
+	})
+	expected := "package main\n\nimport (\n\t\"fmt\"\n\t\"strings\"\n)\n\n" +
+		"func (a A) String() string { return fmt.Sprint(a.name) }\n\n" +
+		"func (b B) String() string { return fmt.Sprint(strings.ToLower(b.name)) }\n"
+	if combined.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, combined.String())
+	}
+}
+
+func TestCombineIdenticalBodies(t *testing.T) {
+	shared := "package main\n\n// Change is a change.\ntype Change struct{ Path string }\n"
+	combined := generator.Combine([]bytes.Buffer{*bytes.NewBufferString(shared), *bytes.NewBufferString(shared)})
+	expected := "package main\n\n// Change is a change.\ntype Change struct{ Path string }\n"
+	if combined.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, combined.String())
+	}
+}
+
+func TestCombineYAML(t *testing.T) {
+	combined, err := generator.CombineYAML([]bytes.Buffer{
+		*bytes.NewBufferString("# generated\ncomponents:\n  schemas:\n    User:\n      type: object\n    Address:\n      type: object\n"),
+		*bytes.NewBufferString("# generated\ncomponents:\n  schemas:\n    Order:\n      type: object\n    Address:\n      type: string\n"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "# generated\ncomponents:\n  schemas:\n    User:\n      type: object\n    Address:\n      type: object\n" +
+		"    Order:\n      type: object\n"
+	if combined.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, combined.String())
+	}
+}
+
+func TestCombineYAMLErrorInvalidYAML(t *testing.T) {
+	_, err := generator.CombineYAML([]bytes.Buffer{*bytes.NewBufferString("a: [")})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestCombineJSON(t *testing.T) {
+	combined, err := generator.CombineJSON([]bytes.Buffer{
+		*bytes.NewBufferString(`{"$schema": "s", "title": "User", "type": "object", "properties": {"friends": {"items": {"$ref": "#"}}, "address": {"$ref": "#/$defs/Address"}}, "$defs": {"Address": {"type": "object"}}}`),
+		*bytes.NewBufferString(`{"$schema": "s", "title": "Order", "type": "object", "properties": {"total": {"type": "number", "maximum": 1.5}}, "$defs": {"Address": {"type": "string"}}}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{
+  "$defs": {
+    "Address": {
+      "type": "object"
+    },
+    "Order": {
+      "properties": {
+        "total": {
+          "maximum": 1.5,
+          "type": "number"
+        }
+      },
+      "title": "Order",
+      "type": "object"
+    },
+    "User": {
+      "properties": {
+        "address": {
+          "$ref": "#/$defs/Address"
+        },
+        "friends": {
+          "items": {
+            "$ref": "#/$defs/User"
+          }
+        }
+      },
+      "title": "User",
+      "type": "object"
+    }
+  },
+  "$schema": "s"
+}
+`
+	if combined.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, combined.String())
+	}
+}
+
+func TestCombineJSONErrorNotAnObject(t *testing.T) {
+	_, err := generator.CombineJSON([]bytes.Buffer{*bytes.NewBufferString("[1]")})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestCombineProto(t *testing.T) {
+	combined, err := generator.CombineProto([]bytes.Buffer{
+		*bytes.NewBufferString("// generated\n\nsyntax = \"proto3\";\n\npackage main;\n\nimport \"google/protobuf/timestamp.proto\";\n\n" +
+			"// User is a user.\nmessage User {\n  Address address = 1;\n  google.protobuf.Timestamp created = 2;\n}\n\nmessage Address {\n  string street = 1;\n}\n"),
+		*bytes.NewBufferString("// generated\n\nsyntax = \"proto3\";\n\npackage main;\n\nimport \"google/protobuf/any.proto\";\n" +
+			"import \"google/protobuf/timestamp.proto\";\n\nmessage Order {\n  Address address = 1;\n  google.protobuf.Any meta = 2;\n}\n\n" +
+			"message Address {\n  string street = 1;\n}\n"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "// generated\n\nsyntax = \"proto3\";\n\npackage main;\n\n" +
+		"import \"google/protobuf/any.proto\";\nimport \"google/protobuf/timestamp.proto\";\n\n" +
+		"// User is a user.\nmessage User {\n  Address address = 1;\n  google.protobuf.Timestamp created = 2;\n}\n\n" +
+		"message Address {\n  string street = 1;\n}\n\nmessage Order {\n  Address address = 1;\n  google.protobuf.Any meta = 2;\n}\n"
+	if combined.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, combined.String())
+	}
+}
+
+func TestCombineProtoErrorPackages(t *testing.T) {
+	_, err := generator.CombineProto([]bytes.Buffer{
+		*bytes.NewBufferString("syntax = \"proto3\";\n\npackage a;\n"),
+		*bytes.NewBufferString("syntax = \"proto3\";\n\npackage b;\n"),
+	})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestCombineGraphQL(t *testing.T) {
+	combined, err := generator.CombineGraphQL([]bytes.Buffer{
+		*bytes.NewBufferString("# generated\n\nscalar Time\n\n\"\"\"\nUser is a user {with braces}.\n\"\"\"\ntype User {\n  address: Address\n  created: Time!\n}\n\n" +
+			"type Address {\n  street: String!\n}\n"),
+		*bytes.NewBufferString("# generated\n\nscalar Map\n\nscalar Time\n\ntype Order {\n  \"\"\"\n  Address of the order.\n\n  \"\"\"\n  address: Address\n  meta: Map\n}\n\n" +
+			"type Address {\n  street: String!\n}\n"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "# generated\n\nscalar Map\nscalar Time\n\n" +
+		"\"\"\"\nUser is a user {with braces}.\n\"\"\"\ntype User {\n  address: Address\n  created: Time!\n}\n\n" +
+		"type Address {\n  street: String!\n}\n\n" +
+		"type Order {\n  \"\"\"\n  Address of the order.\n\n  \"\"\"\n  address: Address\n  meta: Map\n}\n"
+	if combined.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, combined.String())
+	}
+}
+
+func TestCombineSuccessInvalidGoCode(t *testing.T) {
+	combined := generator.Combine([]bytes.Buffer{
+		*bytes.NewBufferString("CREATE TABLE a;\n"),
+		*bytes.NewBufferString("CREATE TABLE b;\n"),
+	})
+	if combined.String() != "CREATE TABLE a;\nCREATE TABLE b;\n" {
+		t.Fatalf("expected concatenated buffers, got %q", combined.String())
+	}
+}
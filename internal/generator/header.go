@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// DefaultHeader is the header of the generated Go files, see AddHeader.
+const DefaultHeader = "Code generated by genz {{ .Version }}. DO NOT EDIT.\n{{ .Command }}"
+
+var (
+	// goMarker is the comment marking a generated Go file, see https://go.dev/s/generatedcode.
+	goMarker = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+	// marker is the text marking a generated file, in a comment of any syntax, e.g. "-- Code generated ... DO NOT EDIT."
+	marker = regexp.MustCompile(`Code generated .* DO NOT EDIT\.`)
+)
+
+// HeaderData is the data of the header template, see AddHeader.
+type HeaderData struct {
+	// Version is the version of genz, e.g. "0.0.1".
+	Version string
+	// Command is the genz command line, e.g. "genz -type Car -template getters.tmpl".
+	Command string
+}
+
+// AddHeader prepends the given header template, rendered with the given data, as line comments to the given Go source,
+// unless the source is already marked as generated, e.g. by the header of a built-in template, or the header is empty.
+func AddHeader(buf bytes.Buffer, header string, data HeaderData) (bytes.Buffer, error) {
+	if header == "" || goMarker.Match(buf.Bytes()) {
+		return buf, nil
+	}
+	tmpl, err := template.New("header").Parse(header)
+	if err != nil {
+		return buf, fmt.Errorf("invalid header: %w", err)
+	}
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return buf, fmt.Errorf("invalid header: %w", err)
+	}
+	var result bytes.Buffer
+	for _, line := range strings.Split(strings.TrimSpace(rendered.String()), "\n") {
+		result.WriteString(strings.TrimSpace("// " + line))
+		result.WriteString("\n")
+	}
+	result.WriteString("\n") // Not the documentation of the package.
+	result.Write(buf.Bytes())
+	return result, nil
+}
+
+// IsGenerated returns true if the given content is marked as generated, by a "Code generated ... DO NOT EDIT." comment.
+func IsGenerated(content []byte) bool {
+	return marker.Match(content)
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAddHeader(t *testing.T) {
+	data := HeaderData{Version: "1.2.3", Command: "genz -type Car -template getters.tmpl"}
+	testCases := map[string]struct {
+		src      string
+		header   string
+		expected string
+	}{
+		"default header": {
+			src:      "package cars\n",
+			header:   DefaultHeader,
+			expected: "// Code generated by genz 1.2.3. DO NOT EDIT.\n// genz -type Car -template getters.tmpl\n\npackage cars\n",
+		},
+		"custom header": {
+			src:      "//go:build linux\n\npackage cars\n",
+			header:   "Copyright ACME.\n\nCode generated by {{ .Command }}. DO NOT EDIT.",
+			expected: "// Copyright ACME.\n//\n// Code generated by genz -type Car -template getters.tmpl. DO NOT EDIT.\n\n//go:build linux\n\npackage cars\n",
+		},
+		"already generated": {
+			src:      "// Code generated by genz builtin getters. DO NOT EDIT.\n\npackage cars\n",
+			header:   DefaultHeader,
+			expected: "// Code generated by genz builtin getters. DO NOT EDIT.\n\npackage cars\n",
+		},
+		"no header": {
+			src:      "package cars\n",
+			expected: "package cars\n",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			actual, err := AddHeader(*bytes.NewBufferString(tc.src), tc.header, data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, actual.String())
+			}
+		})
+	}
+	if _, err := AddHeader(*bytes.NewBufferString("package cars\n"), "{{ .Nope }}", data); err == nil {
+		t.Error("expected an error for an invalid header")
+	}
+}
+
+func TestIsGenerated(t *testing.T) {
+	testCases := map[string]bool{
+		"// Code generated by genz. DO NOT EDIT.\n\npackage cars": true,
+		"-- Code generated by genz builtin sql. DO NOT EDIT.":     true,
+		"package cars\n\n// Code generated by hand.":              false,
+		"package cars": false,
+	}
+	for content, expected := range testCases {
+		if actual := IsGenerated([]byte(content)); actual != expected {
+			t.Errorf("IsGenerated(%q) = %t, expected %t", content, actual, expected)
+		}
+	}
+}
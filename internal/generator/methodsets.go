@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/leorolland/genz/pkg/models"
+)
+
+// methodSets returns the method sets of the given template values: a list of methods, an element (e.g. an interface of
+// .Interfaces) or a parsed element, or a list of elements, each of them being a set, e.g. methodUnion .Interfaces.
+func methodSets(values []any) ([][]models.Method, error) {
+	var sets [][]models.Method
+	for _, value := range values {
+		switch v := value.(type) {
+		case []models.Method:
+			sets = append(sets, v)
+		case models.Element:
+			sets = append(sets, v.Methods)
+		case *models.Element:
+			if v != nil {
+				sets = append(sets, v.Methods)
+			}
+		case models.ParsedElement:
+			sets = append(sets, v.Methods)
+		case []models.Element:
+			for _, element := range v {
+				sets = append(sets, element.Methods)
+			}
+		default:
+			return nil, fmt.Errorf("expected methods or elements, got %T", value)
+		}
+	}
+	return sets, nil
+}
+
+// sameSignature returns true if the given methods have the same params and returns, whatever their names.
+func sameSignature(a, b models.Method) bool {
+	return sameTypes(a.Params, b.Params) && sameTypes(a.Returns, b.Returns)
+}
+
+// sameTypes returns true if the given lists have the same types, variadic ones included.
+func sameTypes(a, b []models.Type) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].IsVariadic != b[i].IsVariadic {
+			return false
+		}
+	}
+	return true
+}
+
+// methodUnion returns the methods of all the given sets, once each, in their order of appearance, e.g. the methods of a
+// facade of several interfaces: {{ range methodUnion .Interfaces }}...{{ end }}
+// It fails if a method is declared with different signatures.
+func methodUnion(values ...any) ([]models.Method, error) {
+	sets, err := methodSets(values)
+	if err != nil {
+		return nil, fmt.Errorf("methodUnion: %w", err)
+	}
+	union := []models.Method{}
+	indexes := map[string]int{}
+	for _, set := range sets {
+		for _, method := range set {
+			if i, found := indexes[method.Name]; found {
+				if !sameSignature(union[i], method) {
+					return nil, fmt.Errorf("methodUnion: method %s declared with different signatures", method.Name)
+				}
+				continue
+			}
+			indexes[method.Name] = len(union)
+			union = append(union, method)
+		}
+	}
+	return union, nil
+}
+
+// methodIntersection returns the methods of the first set which all the other sets have, e.g. the methods shared by the
+// interfaces: {{ range methodIntersection .Interfaces }}...{{ end }}
+// It fails if a method is declared with different signatures.
+func methodIntersection(values ...any) ([]models.Method, error) {
+	sets, err := methodSets(values)
+	if err != nil {
+		return nil, fmt.Errorf("methodIntersection: %w", err)
+	}
+	intersection := []models.Method{}
+	if len(sets) == 0 {
+		return intersection, nil
+	}
+	for _, method := range sets[0] {
+		shared := true
+		for _, set := range sets[1:] {
+			other, found := findMethod(set, method.Name)
+			if found && !sameSignature(method, other) {
+				return nil, fmt.Errorf("methodIntersection: method %s declared with different signatures", method.Name)
+			}
+			shared = shared && found
+		}
+		if shared {
+			intersection = append(intersection, method)
+		}
+	}
+	return intersection, nil
+}
+
+// methodDifference returns the methods of the first set which none of the other sets has, by name, e.g. the methods a
+// struct lacks to implement an interface: {{ range methodDifference (index .Interfaces 0) . }}...{{ end }}
+func methodDifference(values ...any) ([]models.Method, error) {
+	sets, err := methodSets(values)
+	if err != nil {
+		return nil, fmt.Errorf("methodDifference: %w", err)
+	}
+	difference := []models.Method{}
+	if len(sets) == 0 {
+		return difference, nil
+	}
+	for _, method := range sets[0] {
+		excluded := false
+		for _, set := range sets[1:] {
+			if _, found := findMethod(set, method.Name); found {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			difference = append(difference, method)
+		}
+	}
+	return difference, nil
+}
+
+// findMethod returns the method of the given name of the set, if any.
+func findMethod(set []models.Method, name string) (models.Method, bool) {
+	for _, method := range set {
+		if method.Name == name {
+			return method, true
+		}
+	}
+	return models.Method{}, false
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leorolland/genz/pkg/models"
+)
+
+func method(name string, params ...string) models.Method {
+	m := models.Method{Name: name}
+	for _, param := range params {
+		m.Params = append(m.Params, models.Type{Name: param})
+	}
+	return m
+}
+
+func methodNames(methods []models.Method) []string {
+	names := []string{}
+	for _, m := range methods {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+func TestMethodSets(t *testing.T) {
+	reader := models.Element{Methods: []models.Method{method("Read", "[]byte"), method("Close")}}
+	writer := models.Element{Methods: []models.Method{method("Write", "[]byte"), method("Close")}}
+	closer := &models.Element{Methods: []models.Method{method("Close")}}
+	parsed := models.ParsedElement{Element: models.Element{Methods: []models.Method{method("Read", "[]byte")}}}
+
+	testCases := map[string]struct {
+		f        func(...any) ([]models.Method, error)
+		values   []any
+		expected []string
+	}{
+		"union":                      {methodUnion, []any{reader, writer}, []string{"Read", "Close", "Write"}},
+		"union of a list":            {methodUnion, []any{[]models.Element{writer, reader}}, []string{"Write", "Close", "Read"}},
+		"union of nothing":           {methodUnion, nil, []string{}},
+		"intersection":               {methodIntersection, []any{reader, writer}, []string{"Close"}},
+		"intersection with pointer":  {methodIntersection, []any{[]models.Element{reader, writer}, closer}, []string{"Close"}},
+		"intersection with methods":  {methodIntersection, []any{reader.Methods, parsed}, []string{"Read"}},
+		"difference":                 {methodDifference, []any{reader, writer}, []string{"Read"}},
+		"difference of several sets": {methodDifference, []any{reader, closer, parsed}, []string{}},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			methods, err := tc.f(tc.values...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if names := methodNames(methods); !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestMethodSetsError(t *testing.T) {
+	reader := models.Element{Methods: []models.Method{method("Read", "[]byte")}}
+	otherReader := models.Element{Methods: []models.Method{method("Read", "string")}}
+
+	if _, err := methodUnion(reader, otherReader); err == nil || !strings.Contains(err.Error(), "method Read declared with different signatures") {
+		t.Errorf("expected a conflicting signatures error, got %v", err)
+	}
+	if _, err := methodIntersection(reader, otherReader); err == nil || !strings.Contains(err.Error(), "method Read declared with different signatures") {
+		t.Errorf("expected a conflicting signatures error, got %v", err)
+	}
+	if _, err := methodDifference(reader, "Read"); err == nil || !strings.Contains(err.Error(), "expected methods or elements, got string") {
+		t.Errorf("expected an invalid value error, got %v", err)
+	}
+}
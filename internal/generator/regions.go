@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	// RegionBegin starts a generated region of a file written with the region strategy, e.g. "// genz:begin methods".
+	// The name following it is optional: the unnamed regions are matched by their order.
+	RegionBegin = "genz:begin"
+	// RegionEnd ends a generated region, e.g. "// genz:end".
+	RegionEnd = "genz:end"
+)
+
+// region is a generated region of a file: its lines, between the lines of its markers.
+type region struct {
+	key   string
+	begin int // index of the line of the begin marker
+	end   int // index of the line of the end marker
+}
+
+// MergeRegions returns the current content of a file with its generated regions replaced by the ones of the same name
+// of the generated content, the lines between the "genz:begin" and "genz:end" markers, in any comment syntax (e.g.
+// "// genz:begin methods" or "-- genz:begin"). The rest of the current content, e.g. edited by hand, is kept as is.
+// The generated regions the current content does not have are appended to it.
+func MergeRegions(current, generated []byte) ([]byte, error) {
+	currentLines := strings.SplitAfter(string(current), "\n")
+	currentRegions, err := regions(currentLines)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regions of the current file: %w", err)
+	}
+	generatedLines := strings.SplitAfter(string(generated), "\n")
+	generatedRegions, err := regions(generatedLines)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regions of the generated file: %w", err)
+	}
+	byKey := map[string]region{}
+	for _, r := range generatedRegions {
+		byKey[r.key] = r
+	}
+
+	var merged bytes.Buffer
+	next := 0
+	for _, r := range currentRegions {
+		generatedRegion, found := byKey[r.key]
+		if !found {
+			continue
+		}
+		delete(byKey, r.key)
+		merged.WriteString(strings.Join(currentLines[next:r.begin+1], ""))
+		merged.WriteString(strings.Join(generatedLines[generatedRegion.begin+1:generatedRegion.end], ""))
+		next = r.end
+	}
+	merged.WriteString(strings.Join(currentLines[next:], ""))
+	for _, r := range generatedRegions {
+		if _, appended := byKey[r.key]; !appended {
+			continue
+		}
+		if merged.Len() > 0 && !bytes.HasSuffix(merged.Bytes(), []byte("\n")) {
+			merged.WriteString("\n")
+		}
+		merged.WriteString("\n")
+		lines := generatedLines[r.begin : r.end+1]
+		merged.WriteString(strings.Join(lines, ""))
+		if !strings.HasSuffix(lines[len(lines)-1], "\n") {
+			merged.WriteString("\n")
+		}
+	}
+	return merged.Bytes(), nil
+}
+
+// regions returns the regions of the given lines, keyed by their name, or by their order among the unnamed ones.
+func regions(lines []string) ([]region, error) {
+	var found []region
+	seen := map[string]bool{}
+	unnamed := 0
+	begin := -1
+	key := ""
+	for i, line := range lines {
+		if j := strings.Index(line, RegionBegin); j >= 0 {
+			if begin >= 0 {
+				return nil, fmt.Errorf("line %d: region started at line %d is not ended", i+1, begin+1)
+			}
+			begin = i
+			if fields := strings.Fields(line[j+len(RegionBegin):]); len(fields) > 0 && fields[0] != "*/" && fields[0] != "-->" {
+				key = fields[0]
+			} else {
+				key = fmt.Sprintf("#%d", unnamed)
+				unnamed++
+			}
+			if seen[key] {
+				return nil, fmt.Errorf("line %d: region %s declared twice", i+1, key)
+			}
+			seen[key] = true
+			continue
+		}
+		if strings.Contains(line, RegionEnd) {
+			if begin < 0 {
+				return nil, fmt.Errorf("line %d: region ended without being started", i+1)
+			}
+			found = append(found, region{key: key, begin: begin, end: i})
+			begin = -1
+		}
+	}
+	if begin >= 0 {
+		return nil, fmt.Errorf("line %d: region is not ended", begin+1)
+	}
+	return found, nil
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeRegions(t *testing.T) {
+	testCases := map[string]struct {
+		current   string
+		generated string
+		expected  string
+	}{
+		"named regions": {
+			current:   "package main\n\n// Hand-written.\nfunc a() {}\n\n// genz:begin methods\nfunc old() {}\n// genz:end\n\nfunc b() {}\n",
+			generated: "package main\n\n// genz:begin methods\nfunc new1() {}\nfunc new2() {}\n// genz:end\n",
+			expected:  "package main\n\n// Hand-written.\nfunc a() {}\n\n// genz:begin methods\nfunc new1() {}\nfunc new2() {}\n// genz:end\n\nfunc b() {}\n",
+		},
+		"unnamed regions by order": {
+			current:   "-- genz:begin\nold 1\n-- genz:end\nmine\n-- genz:begin\nold 2\n-- genz:end\n",
+			generated: "-- genz:begin\nnew 1\n-- genz:end\n-- genz:begin\nnew 2\n-- genz:end\n",
+			expected:  "-- genz:begin\nnew 1\n-- genz:end\nmine\n-- genz:begin\nnew 2\n-- genz:end\n",
+		},
+		"new region appended": {
+			current:   "package main\n\n// genz:begin a\nfunc a() {}\n// genz:end\n\nfunc mine() {}",
+			generated: "package main\n\n// genz:begin a\nfunc a() {}\n// genz:end\n\n// genz:begin b\nfunc b() {}\n// genz:end",
+			expected:  "package main\n\n// genz:begin a\nfunc a() {}\n// genz:end\n\nfunc mine() {}\n\n// genz:begin b\nfunc b() {}\n// genz:end\n",
+		},
+		"region not generated anymore kept": {
+			current:   "/* genz:begin a */\nold\n/* genz:end */\n",
+			generated: "/* genz:begin b */\nnew\n/* genz:end */\n",
+			expected:  "/* genz:begin a */\nold\n/* genz:end */\n\n/* genz:begin b */\nnew\n/* genz:end */\n",
+		},
+		"no region": {
+			current:   "mine\n",
+			generated: "ignored\n",
+			expected:  "mine\n",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			merged, err := MergeRegions([]byte(tc.current), []byte(tc.generated))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(merged) != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, merged)
+			}
+		})
+	}
+}
+
+func TestMergeRegionsError(t *testing.T) {
+	testCases := map[string]struct {
+		current   string
+		generated string
+		expected  string
+	}{
+		"not ended": {
+			current:  "// genz:begin\n",
+			expected: "invalid regions of the current file: line 1: region is not ended",
+		},
+		"nested": {
+			generated: "// genz:begin a\n// genz:begin b\n// genz:end\n",
+			expected:  "invalid regions of the generated file: line 2: region started at line 1 is not ended",
+		},
+		"not started": {
+			current:  "// genz:end\n",
+			expected: "line 1: region ended without being started",
+		},
+		"declared twice": {
+			current:  "// genz:begin a\n// genz:end\n// genz:begin a\n// genz:end\n",
+			expected: "line 3: region a declared twice",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := MergeRegions([]byte(tc.current), []byte(tc.generated))
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected an error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
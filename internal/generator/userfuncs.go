@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"reflect"
+	"regexp"
+	"text/template"
+)
+
+// defineRegex matches the {{ define "name" }} actions of a template of functions, capturing the name.
+var defineRegex = regexp.MustCompile(`{{-?\s*define\s+"([^"]*)"\s*-?}}`)
+
+// identifierRegex matches the valid names of template functions.
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LoadFuncs returns the custom template functions declared in the given files, in addition to the ones of FuncMap:
+//   - a Go plugin (.so, built with go build -buildmode=plugin) exports them in a Funcs variable of type
+//     map[string]any (or template.FuncMap), e.g. var Funcs = map[string]any{"tableName": TableName}
+//   - any other file is a template whose {{ define "name" }} blocks are functions returning their rendering, given
+//     their single argument as data, or the list of their arguments if they have several, e.g.
+//     {{ define "tableName" }}{{ snakeCase (pluralize .) }}{{ end }} called as {{ tableName .Type.Name }}
+//
+// A function cannot replace a function of FuncMap nor one declared by a previous file.
+func LoadFuncs(paths []string) (template.FuncMap, error) {
+	funcs := template.FuncMap{}
+	for _, path := range paths {
+		var (
+			fileFuncs template.FuncMap
+			err       error
+		)
+		if filepath.Ext(path) == ".so" {
+			fileFuncs, err = pluginFuncs(path)
+		} else {
+			fileFuncs, err = templateFuncs(path, funcs)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the functions of %s: %w", path, err)
+		}
+		builtins := FuncMap()
+		for name, f := range fileFuncs {
+			if !identifierRegex.MatchString(name) {
+				return nil, fmt.Errorf("failed to load the functions of %s: invalid function name %q", path, name)
+			}
+			if _, exists := builtins[name]; exists {
+				return nil, fmt.Errorf("function %s of %s is already a template function", name, path)
+			}
+			if _, exists := funcs[name]; exists {
+				return nil, fmt.Errorf("function %s of %s is already declared by another file", name, path)
+			}
+			if err := checkFunc(f); err != nil {
+				return nil, fmt.Errorf("invalid function %s of %s: %w", name, path, err)
+			}
+			funcs[name] = f
+		}
+	}
+	return funcs, nil
+}
+
+// pluginFuncs returns the functions of the Funcs variable exported by the Go plugin of the given path.
+func pluginFuncs(path string) (template.FuncMap, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := p.Lookup("Funcs")
+	if err != nil {
+		return nil, err
+	}
+	switch funcs := symbol.(type) {
+	case *map[string]any:
+		return *funcs, nil
+	case *template.FuncMap:
+		return *funcs, nil
+	default:
+		return nil, fmt.Errorf("unexpected type %T of Funcs, expected map[string]any", symbol)
+	}
+}
+
+// templateFuncs returns the functions of the {{ define }} blocks of the template of the given path. The blocks can call
+// each other and the given functions, declared by the previous files.
+func templateFuncs(path string, previous template.FuncMap) (template.FuncMap, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// The functions are declared before parsing the template, so that the blocks can call each other.
+	funcs := template.FuncMap{}
+	for _, match := range defineRegex.FindAllStringSubmatch(string(content), -1) {
+		if !identifierRegex.MatchString(match[1]) {
+			return nil, fmt.Errorf("invalid function name %q", match[1])
+		}
+		funcs[match[1]] = func(...any) (string, error) { return "", nil }
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(FuncMap()).Funcs(previous).Funcs(funcs).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	for name := range funcs {
+		name := name
+		funcs[name] = func(args ...any) (string, error) {
+			var data any
+			switch len(args) {
+			case 0:
+			case 1:
+				data = args[0]
+			default:
+				data = args
+			}
+			var buf bytes.Buffer
+			if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		}
+	}
+	tmpl.Funcs(funcs)
+	return funcs, nil
+}
+
+// checkFunc returns an error if f cannot be a template function: a function returning a value, and optionally an
+// error.
+func checkFunc(f any) error {
+	t := reflect.TypeOf(f)
+	if t == nil || t.Kind() != reflect.Func {
+		return fmt.Errorf("%T is not a function", f)
+	}
+	switch {
+	case t.NumOut() == 1:
+	case t.NumOut() == 2 && t.Out(1) == reflect.TypeOf((*error)(nil)).Elem():
+	default:
+		return fmt.Errorf("%s must return a value, and optionally an error", t)
+	}
+	return nil
+}
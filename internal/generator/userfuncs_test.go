@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFuncsTemplate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "funcs.tmpl")
+	content := `{{- define "tableName" }}{{ snakeCase (pluralize .) }}{{ end }}
+{{- define "column" }}{{ index . 0 }}.{{ snakeCase (index . 1) }}{{ end }}
+{{- define "quotedTable" }}"{{ tableName . }}"{{ end }}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	funcs, err := LoadFuncs([]string{path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tmpl, err := ParseWithFuncs(`{{ tableName "UserAccount" }} {{ column "u" "CreatedAt" }} {{ quotedTable "Order" }}`, funcs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `user_accounts u.created_at "orders"`
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestLoadFuncsError(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return path
+	}
+	testCases := map[string]struct {
+		paths    []string
+		expected string
+	}{
+		"missing file": {
+			paths:    []string{filepath.Join(dir, "missing.tmpl")},
+			expected: "failed to load the functions of",
+		},
+		"invalid template": {
+			paths:    []string{write("invalid.tmpl", `{{ define "f" }}{{ unknown }}{{ end }}`)},
+			expected: `function "unknown" not defined`,
+		},
+		"invalid name": {
+			paths:    []string{write("name.tmpl", `{{ define "table-name" }}{{ end }}`)},
+			expected: `invalid function name "table-name"`,
+		},
+		"existing function": {
+			paths:    []string{write("existing.tmpl", `{{ define "snakeCase" }}{{ end }}`)},
+			expected: "function snakeCase of",
+		},
+		"declared twice": {
+			paths:    []string{write("a.tmpl", `{{ define "f" }}{{ end }}`), write("b.tmpl", `{{ define "f" }}{{ end }}`)},
+			expected: "is already declared by another file",
+		},
+		"invalid plugin": {
+			paths:    []string{write("funcs.so", "not a plugin")},
+			expected: "failed to load the functions of",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadFuncs(tc.paths)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected an error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestCheckFunc(t *testing.T) {
+	valid := []any{strings.ToUpper, func(string) (int, error) { return 0, nil }}
+	for _, f := range valid {
+		if err := checkFunc(f); err != nil {
+			t.Errorf("unexpected error for %T: %v", f, err)
+		}
+	}
+	invalid := []any{"f", func() {}, func() (int, int) { return 0, 0 }}
+	for _, f := range invalid {
+		if err := checkFunc(f); err == nil {
+			t.Errorf("expected an error for %T", f)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// FilesVariable is the environment variable listing the files written by the generation, separated by spaces,
+// e.g. "gofmt -w $GENZ_FILES".
+const FilesVariable = "GENZ_FILES"
+
+// Run runs the given command line with the shell of the system (sh, or cmd on Windows) from the given directory,
+// the current one when empty, with the given files in FilesVariable. It returns the standard output and the standard
+// error of the command, interleaved.
+func Run(command, dir string, files []string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var output bytes.Buffer
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), FilesVariable+"="+strings.Join(files, " "))
+	cmd.Stdout, cmd.Stderr = &output, &output
+	if err := cmd.Run(); err != nil {
+		return output.Bytes(), fmt.Errorf("%s: %w", command, err)
+	}
+	return output.Bytes(), nil
+}
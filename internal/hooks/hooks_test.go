@@ -0,0 +1,29 @@
+package hooks
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands are written for sh")
+	}
+	dir := t.TempDir()
+	output, err := Run("pwd; echo $GENZ_FILES; echo warning >&2", dir, []string{"a.gen.go", "b.gen.go"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actualDir, _ := filepath.EvalSymlinks(strings.Split(string(output), "\n")[0])
+	expectedDir, _ := filepath.EvalSymlinks(dir)
+	if actualDir != expectedDir || !strings.Contains(string(output), "a.gen.go b.gen.go\n") || !strings.Contains(string(output), "warning") {
+		t.Errorf("unexpected output from %s: %s", dir, output)
+	}
+
+	output, err = Run("echo failed; exit 3", dir, nil)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") || string(output) != "failed\n" {
+		t.Errorf("expected the failure of the command, got %v: %s", err, output)
+	}
+}
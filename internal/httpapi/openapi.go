@@ -0,0 +1,167 @@
+package httpapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is the part of an OpenAPI 3 document describing its operations, in YAML or JSON.
+type Document struct {
+	OpenAPI    string              `yaml:"openapi"`
+	Paths      map[string]PathItem `yaml:"paths"`
+	Components struct {
+		Parameters map[string]Parameter `yaml:"parameters"`
+	} `yaml:"components"`
+}
+
+// PathItem is the description of the operations of a path.
+type PathItem struct {
+	// Parameters are the parameters shared by the operations of the path.
+	Parameters []Parameter `yaml:"parameters"`
+	Get        *Operation  `yaml:"get"`
+	Put        *Operation  `yaml:"put"`
+	Post       *Operation  `yaml:"post"`
+	Delete     *Operation  `yaml:"delete"`
+	Options    *Operation  `yaml:"options"`
+	Head       *Operation  `yaml:"head"`
+	Patch      *Operation  `yaml:"patch"`
+}
+
+// Operation is the description of an operation.
+type Operation struct {
+	OperationID string       `yaml:"operationId"`
+	Parameters  []Parameter  `yaml:"parameters"`
+	RequestBody *RequestBody `yaml:"requestBody"`
+}
+
+// RequestBody is the description of the body of the requests of an operation.
+type RequestBody struct {
+	Required bool `yaml:"required"`
+}
+
+// Parameter is the description of a parameter of an operation, or a reference to one of the components.
+type Parameter struct {
+	Ref    string `yaml:"$ref"`
+	Name   string `yaml:"name"`
+	In     string `yaml:"in"`
+	Schema Schema `yaml:"schema"`
+}
+
+// Schema is the part of a JSON schema describing the type of a parameter.
+type Schema struct {
+	Ref    string  `yaml:"$ref"`
+	Type   Types   `yaml:"type"`
+	Format string  `yaml:"format"`
+	Items  *Schema `yaml:"items"`
+}
+
+// Types are the types of a schema, written as a single type or, since OpenAPI 3.1, as a list. e.g. ["integer", "null"]
+type Types []string
+
+// UnmarshalYAML reads a single type or a list of types.
+func (t *Types) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = Types{node.Value}
+		return nil
+	}
+	var types []string
+	if err := node.Decode(&types); err != nil {
+		return err
+	}
+	*t = types
+	return nil
+}
+
+// ReadDocument reads the given OpenAPI document, in YAML or JSON.
+func ReadDocument(content []byte) (Document, error) {
+	var document Document
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return Document{}, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		return Document{}, fmt.Errorf("not an OpenAPI 3 document: openapi is %q", document.OpenAPI)
+	}
+	return document, nil
+}
+
+// operation is an operation of a document with its method and its path.
+type operation struct {
+	method, path string
+	*Operation
+	// params are the parameters of the path and of the operation, with their references resolved.
+	params []Parameter
+}
+
+// operations returns the operations of the document, sorted by path and method.
+func (d Document) operations() ([]operation, error) {
+	paths := make([]string, 0, len(d.Paths))
+	for path := range d.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var result []operation
+	for _, path := range paths {
+		item := d.Paths[path]
+		for _, method := range methods {
+			op := item.operation(method)
+			if op == nil {
+				continue
+			}
+			params, err := d.resolve(append(append([]Parameter(nil), item.Parameters...), op.Parameters...))
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			result = append(result, operation{method: method, path: path, Operation: op, params: params})
+		}
+	}
+	return result, nil
+}
+
+func (p PathItem) operation(method string) *Operation {
+	switch method {
+	case "GET":
+		return p.Get
+	case "HEAD":
+		return p.Head
+	case "POST":
+		return p.Post
+	case "PUT":
+		return p.Put
+	case "PATCH":
+		return p.Patch
+	case "DELETE":
+		return p.Delete
+	case "OPTIONS":
+		return p.Options
+	}
+	return nil
+}
+
+// resolve returns the given parameters with their references to the components resolved. A parameter overrides the
+// previous one of the same name and location, e.g. a parameter of the path redefined by the operation.
+func (d Document) resolve(params []Parameter) ([]Parameter, error) {
+	var resolved []Parameter
+	for _, param := range params {
+		if param.Ref != "" {
+			name := strings.TrimPrefix(param.Ref, "#/components/parameters/")
+			component, ok := d.Components.Parameters[name]
+			if !ok || name == param.Ref {
+				return nil, fmt.Errorf("unresolved parameter reference %s", param.Ref)
+			}
+			param = component
+		}
+		replaced := false
+		for i := range resolved {
+			if resolved[i].Name == param.Name && resolved[i].In == param.In {
+				resolved[i], replaced = param, true
+			}
+		}
+		if !replaced {
+			resolved = append(resolved, param)
+		}
+	}
+	return resolved, nil
+}
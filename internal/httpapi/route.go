@@ -0,0 +1,132 @@
+// Package httpapi reads the HTTP routes of the methods of an interface marked with //genz:http, bound to the path, the
+// query and the body as the http built-in generator does, and verifies them against an OpenAPI document.
+package httpapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/leorolland/genz/pkg/models"
+)
+
+// Route is the HTTP route of a method of an interface, declared with a //genz:http directive.
+// e.g. "//genz:http GET /users/{id}" for "Get(ctx context.Context, id int64) (*User, error)"
+type Route struct {
+	// Method is the HTTP method. e.g. "GET"
+	Method string
+	// Path is the path pattern, with a {placeholder} per path parameter. e.g. "/users/{id}"
+	Path string
+	// Operation is the method of the interface serving the route. e.g. "Users.Get"
+	Operation string
+	// Params are the params of the method read from the request, in their order. The context param is left aside.
+	Params []Param
+	// Position of the method, if parsed with the positions option.
+	Position models.Position
+}
+
+// Param is a param of a method read from a request.
+type Param struct {
+	// Name is the name of the parameter in the path or the query, or the name of the param for the body.
+	Name string
+	// In is where the param is read from: "path", "query" or "body".
+	In string
+	// Type is the Go type of the param.
+	Type models.Type
+}
+
+// String returns the route as written in its directive. e.g. "GET /users/{id}"
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+var (
+	methods      = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	placeholders = regexp.MustCompile(`\{([^}]*)\}`)
+	scalars      = map[string]bool{
+		"string": true, "bool": true, "int": true, "int8": true, "int16": true, "int32": true, "int64": true, "rune": true,
+		"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "byte": true, "float32": true,
+		"float64": true, "time.Duration": true, "time.Time": true,
+	}
+)
+
+// Routes returns the routes of the methods of the given interface marked with //genz:http, in their order.
+// The params named after a {placeholder} of the path are read from the path, the other basic params, time.Duration,
+// time.Time and []string from the query, and the remaining one from the JSON body.
+func Routes(element models.ParsedElement) ([]Route, error) {
+	var routes []Route
+	for _, method := range element.Methods {
+		directive, ok := method.Directives["http"]
+		if !ok {
+			continue
+		}
+		operation := element.Type.InternalName + "." + method.Name
+		fields := strings.Fields(directive)
+		if len(fields) < 2 || len(fields) > 3 || !contains(methods, fields[0]) {
+			return nil, fmt.Errorf("invalid //genz:http directive %q of %s, expected //genz:http <METHOD> <path> [status]", directive, operation)
+		}
+		route := Route{Method: fields[0], Path: fields[1], Operation: operation, Position: method.Position}
+		names := pathParams(route.Path)
+		matched := map[string]bool{}
+		body := ""
+		for i, paramType := range method.Params {
+			if i == 0 && method.FirstParamIsContext {
+				continue
+			}
+			name := ""
+			if i < len(method.ParamNames) && method.ParamNames[i] != "_" {
+				name = method.ParamNames[i]
+			}
+			param := Param{Name: name, In: "query", Type: paramType}
+			for _, placeholder := range names {
+				if name != "" && strings.EqualFold(placeholder, name) {
+					param.Name, param.In = placeholder, "path"
+					matched[placeholder] = true
+				}
+			}
+			if param.In == "query" && !scalars[kind(paramType)] && paramType.Name != "[]string" {
+				if body != "" {
+					return nil, fmt.Errorf("params %s and %s of %s cannot both be read from the JSON body", body, name, operation)
+				}
+				param.In, body = "body", name
+			}
+			if param.In == "query" && name == "" {
+				return nil, fmt.Errorf("param %d of %s must be named, to be read from the query", i, operation)
+			}
+			route.Params = append(route.Params, param)
+		}
+		for _, placeholder := range names {
+			if !matched[placeholder] {
+				return nil, fmt.Errorf("path parameter {%s} of %s matches no param", placeholder, operation)
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// pathParams returns the names of the {placeholders} of the given path, in their order. e.g. ["id"] for "/users/{id}"
+func pathParams(path string) []string {
+	var names []string
+	for _, match := range placeholders.FindAllStringSubmatch(path, -1) {
+		names = append(names, strings.TrimSuffix(match[1], "..."))
+	}
+	return names
+}
+
+// kind returns the name of the basic type underlying the given type, or its name. e.g. "int32" for "type Level int32"
+func kind(t models.Type) string {
+	if t.BasicKind == "" || t.Name == "time.Duration" {
+		return t.Name
+	}
+	return t.BasicKind
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
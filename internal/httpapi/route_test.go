@@ -0,0 +1,98 @@
+package httpapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leorolland/genz/internal/parser"
+	"github.com/leorolland/genz/internal/testutils"
+)
+
+const usersCode = `package main
+
+import (
+	"context"
+	"time"
+)
+
+type Level int32
+
+type User struct {
+	Name string
+}
+
+type Users interface {
+	//genz:http GET /users/{id}
+	Get(ctx context.Context, ID int64) (*User, error)
+	//genz:http GET /users
+	List(ctx context.Context, level Level, since time.Duration, tags ...string) ([]User, error)
+	//genz:http PUT /users/{id} http.StatusCreated
+	Save(ctx context.Context, id int64, user User) error
+	Ping()
+}
+`
+
+// routes returns the routes of the Users interface declared by the given Go code.
+func routes(t *testing.T, goCode string) ([]Route, error) {
+	t.Helper()
+
+	pkg := testutils.CreatePkgWithCode(t, goCode)
+	element, err := parser.WithOptions(parser.Options{})(pkg, "Users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return Routes(element)
+}
+
+func TestRoutes(t *testing.T) {
+	actual, err := routes(t, usersCode)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var lines []string
+	for _, route := range actual {
+		line := route.String() + " " + route.Operation
+		for _, param := range route.Params {
+			line += " " + param.In + ":" + param.Name + ":" + param.Type.Name
+		}
+		lines = append(lines, line)
+	}
+	expected := []string{
+		"GET /users/{id} Users.Get path:id:int64",
+		"GET /users Users.List query:level:main.Level query:since:time.Duration query:tags:[]string",
+		"PUT /users/{id} Users.Save path:id:int64 body:user:main.User",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected routes:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+func TestRoutesInvalid(t *testing.T) {
+	testCases := map[string]struct {
+		method   string
+		expected string
+	}{
+		"invalid directive": {
+			method:   "//genz:http FETCH /users\n\tList() error",
+			expected: `invalid //genz:http directive "FETCH /users" of Users.List`,
+		},
+		"unmatched path parameter": {
+			method:   "//genz:http GET /users/{id}\n\tGet(name string) error",
+			expected: "path parameter {id} of Users.Get matches no param",
+		},
+		"several body params": {
+			method:   "//genz:http POST /users\n\tSave(a, b []int) error",
+			expected: "params a and b of Users.Save cannot both be read from the JSON body",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := routes(t, "package main\n\ntype Users interface {\n\t"+tc.method+"\n}\n")
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected an error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leorolland/genz/pkg/models"
+)
+
+// Drift is a difference between the routes and an OpenAPI document.
+type Drift struct {
+	// Route is the route of the drift, or nil for an operation of the document served by no route.
+	Route *Route
+	// Message describes the drift. e.g. "GET /users/{id} (Users.Get) is missing from the OpenAPI document"
+	Message string
+}
+
+// Verify returns the drifts between the given routes and the operations of the given document: the routes missing from
+// the document, the operations served by no route, and the parameters and the request bodies of an operation which
+// are not the ones read by its route, or of another type. The path parameters are matched by position, whatever their
+// names, and the query ones by name.
+func Verify(routes []Route, document Document) ([]Drift, error) {
+	operations, err := document.operations()
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]operation, len(operations))
+	for _, op := range operations {
+		byKey[key(op.method, op.path)] = op
+	}
+	served := map[string]bool{}
+	var drifts []Drift
+	for i := range routes {
+		route := &routes[i]
+		k := key(route.Method, route.Path)
+		op, ok := byKey[k]
+		if !ok {
+			drifts = append(drifts, Drift{Route: route, Message: fmt.Sprintf("%s (%s) is missing from the OpenAPI document", route, route.Operation)})
+			continue
+		}
+		served[k] = true
+		drifts = append(drifts, compare(route, op)...)
+	}
+	for _, op := range operations {
+		if !served[key(op.method, op.path)] {
+			drifts = append(drifts, Drift{Message: fmt.Sprintf("%s %s of the OpenAPI document is served by no route", op.method, op.path)})
+		}
+	}
+	return drifts, nil
+}
+
+// key returns the given method and path with unnamed placeholders, matching a route and an operation whatever the
+// names of their path parameters. e.g. "GET /users/{}"
+func key(method, path string) string {
+	return method + " " + placeholders.ReplaceAllString(path, "{}")
+}
+
+// compare returns the drifts between the params of the given route and the parameters of the given operation.
+func compare(route *Route, op operation) []Drift {
+	var drifts []Drift
+	report := func(format string, args ...any) {
+		drifts = append(drifts, Drift{Route: route, Message: fmt.Sprintf("%s (%s): %s", route, route.Operation, fmt.Sprintf(format, args...))})
+	}
+	routePath, documentedPath := pathParams(route.Path), pathParams(op.path)
+	read := map[string]bool{}
+	readsBody := false
+	for _, param := range route.Params {
+		if param.In == "body" {
+			readsBody = true
+			if op.RequestBody == nil {
+				report("the param %s is read from the request body, which is not documented", param.Name)
+			}
+			continue
+		}
+		name := param.Name
+		if param.In == "path" {
+			for i, placeholder := range routePath {
+				if placeholder == param.Name {
+					name = documentedPath[i]
+				}
+			}
+		}
+		read[param.In+" "+name] = true
+		documented, ok := find(op.params, param.In, name)
+		if !ok {
+			report("the %s parameter %s is not documented", param.In, name)
+			continue
+		}
+		if expected := schemaOf(param.Type); !matches(expected, documented.Schema) {
+			report("the %s parameter %s is %s in Go and %s in the OpenAPI document", param.In, name, describe(expected), describe(documented.Schema))
+		}
+	}
+	for _, documented := range op.params {
+		if (documented.In == "path" || documented.In == "query") && !read[documented.In+" "+documented.Name] {
+			report("the %s parameter %s is documented, but not read", documented.In, documented.Name)
+		}
+	}
+	if op.RequestBody != nil && !readsBody {
+		report("the request body is documented, but not read")
+	}
+	return drifts
+}
+
+func find(params []Parameter, in, name string) (Parameter, bool) {
+	for _, param := range params {
+		if param.In == in && param.Name == name {
+			return param, true
+		}
+	}
+	return Parameter{}, false
+}
+
+// schemaOf returns the schema of the given type of a path or query param.
+func schemaOf(t models.Type) Schema {
+	if t.Name == "[]string" {
+		return Schema{Type: Types{"array"}, Items: &Schema{Type: Types{"string"}}}
+	}
+	switch kind(t) {
+	case "string":
+		return Schema{Type: Types{"string"}}
+	case "bool":
+		return Schema{Type: Types{"boolean"}}
+	case "int", "int64", "uint", "uint64":
+		return Schema{Type: Types{"integer"}, Format: "int64"}
+	case "int8", "int16", "int32", "rune", "uint8", "uint16", "uint32", "byte":
+		return Schema{Type: Types{"integer"}, Format: "int32"}
+	case "float32":
+		return Schema{Type: Types{"number"}, Format: "float"}
+	case "float64":
+		return Schema{Type: Types{"number"}, Format: "double"}
+	case "time.Time":
+		return Schema{Type: Types{"string"}, Format: "date-time"}
+	}
+	return Schema{Type: Types{"string"}} // time.Duration
+}
+
+// matches returns true if the given documented schema accepts the values of the given expected one. The references
+// to the components, the schemas without type and the formats left unspecified match any type.
+func matches(expected, documented Schema) bool {
+	if documented.Ref != "" || len(documented.Type) == 0 {
+		return true
+	}
+	if !contains(documented.Type, expected.Type[0]) {
+		return false
+	}
+	if expected.Format != "" && documented.Format != "" && expected.Format != documented.Format {
+		return false
+	}
+	if expected.Items != nil && documented.Items != nil {
+		return matches(*expected.Items, *documented.Items)
+	}
+	return true
+}
+
+// describe returns the type of the given schema, e.g. "integer (int64)" or "array of string".
+func describe(schema Schema) string {
+	description := strings.Join(schema.Type, " or ")
+	if schema.Format != "" {
+		description += " (" + schema.Format + ")"
+	}
+	if schema.Items != nil {
+		description += " of " + describe(*schema.Items)
+	}
+	return description
+}
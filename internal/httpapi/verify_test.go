@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestVerify(t *testing.T) {
+	testCases := map[string]struct {
+		document string
+		expected []string
+	}{
+		"consistent": {
+			document: `
+openapi: 3.1.0
+paths:
+  /users/{userId}:
+    parameters:
+      - $ref: '#/components/parameters/UserID'
+    get:
+      operationId: getUser
+    put:
+      requestBody:
+        content:
+          application/json: {}
+  /users:
+    get:
+      parameters:
+        - {name: level, in: query, schema: {type: integer, format: int32}}
+        - {name: since, in: query, schema: {type: string}}
+        - {name: tags, in: query, schema: {type: array, items: {type: string}}}
+        - {name: X-Request-ID, in: header, schema: {type: string}}
+components:
+  parameters:
+    UserID: {name: userId, in: path, required: true, schema: {type: [integer, "null"], format: int64}}
+`,
+		},
+		"drifts": {
+			document: `{
+  "openapi": "3.0.3",
+  "paths": {
+    "/users/{id}": {
+      "get": {"parameters": [{"name": "id", "in": "path", "schema": {"type": "string"}}]},
+      "put": {"parameters": [{"name": "id", "in": "path", "schema": {"type": "integer"}}]},
+      "delete": {}
+    },
+    "/users": {
+      "get": {
+        "parameters": [
+          {"name": "level", "in": "query", "schema": {"type": "integer", "format": "int64"}},
+          {"name": "tags", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}},
+          {"name": "page", "in": "query", "schema": {"type": "integer"}}
+        ],
+        "requestBody": {"content": {"application/json": {}}}
+      }
+    }
+  }
+}`,
+			expected: []string{
+				"GET /users/{id} (Users.Get): the path parameter id is integer (int64) in Go and string in the OpenAPI document",
+				"GET /users (Users.List): the query parameter level is integer (int32) in Go and integer (int64) in the OpenAPI document",
+				"GET /users (Users.List): the query parameter since is not documented",
+				"GET /users (Users.List): the query parameter page is documented, but not read",
+				"GET /users (Users.List): the request body is documented, but not read",
+				"PUT /users/{id} (Users.Save): the param user is read from the request body, which is not documented",
+				"DELETE /users/{id} of the OpenAPI document is served by no route",
+			},
+		},
+		"missing routes": {
+			document: "openapi: 3.0.0\npaths: {}\n",
+			expected: []string{
+				"GET /users/{id} (Users.Get) is missing from the OpenAPI document",
+				"GET /users (Users.List) is missing from the OpenAPI document",
+				"PUT /users/{id} (Users.Save) is missing from the OpenAPI document",
+			},
+		},
+	}
+	routes, err := routes(t, usersCode)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			document, err := ReadDocument([]byte(tc.document))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			drifts, err := Verify(routes, document)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var actual []string
+			for _, drift := range drifts {
+				actual = append(actual, drift.Message)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("unexpected drifts: %s", cmp.Diff(tc.expected, actual))
+			}
+		})
+	}
+}
+
+func TestReadDocumentInvalid(t *testing.T) {
+	for _, content := range []string{"swagger: '2.0'", "openapi: [", "{}"} {
+		if _, err := ReadDocument([]byte(content)); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
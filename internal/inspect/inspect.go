@@ -0,0 +1,67 @@
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/leorolland/genz/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats are the formats the parsed elements can be encoded to.
+var Formats = []string{"json", "yaml"}
+
+// Encode writes the given parsed elements in the given format, with the field names of the models,
+// as used in the templates. e.g. {"PackageName": "main", "Type": {"Name": "User", ...}, "Attributes": [...]}
+// A single element is written as a JSON object or a YAML document, several elements as a JSON array
+// or a YAML document each.
+func Encode(w io.Writer, elements []models.ParsedElement, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if len(elements) == 1 {
+			return encoder.Encode(elements[0])
+		}
+		return encoder.Encode(elements)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		for _, element := range elements {
+			node, err := toYamlNode(element)
+			if err != nil {
+				return err
+			}
+			if err := encoder.Encode(node); err != nil {
+				return err
+			}
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unknown format %s, expected one of %v", format, Formats)
+	}
+}
+
+// toYamlNode returns the YAML node of the given element, with the same keys, in the same order, as its JSON encoding.
+// yaml.v3 would otherwise lower case the field names.
+func toYamlNode(element models.ParsedElement) (*yaml.Node, error) {
+	content, err := json.Marshal(element)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+	return &node, nil
+}
+
+// blockStyle resets the flow style of the decoded JSON, so that the node is written as a usual YAML document.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,76 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+
+	"github.com/leorolland/genz/pkg/models"
+)
+
+var user = models.ParsedElement{
+	PackageName: "main",
+	Element: models.Element{
+		Type: models.Type{Name: "main.User", InternalName: "User", LocalName: "User"},
+		Attributes: []models.Attribute{
+			{
+				Name: "Admin",
+				Type: models.Type{Name: "bool", InternalName: "bool", LocalName: "bool"},
+				Tags: map[string]string{"json": "true"},
+			},
+		},
+	},
+}
+
+func TestEncodeJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, []models.ParsedElement{user}, "json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded models.ParsedElement
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(decoded, user) {
+		t.Fatalf("unexpected decoded element:\n%s", cmp.Diff(user, decoded))
+	}
+
+	buf.Reset()
+	if err := Encode(&buf, []models.ParsedElement{user, user}, "json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "[") {
+		t.Fatalf("expected a JSON array, got:\n%s", buf.String())
+	}
+}
+
+func TestEncodeYAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, []models.ParsedElement{user}, "yaml"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{"PackageName: main\n", "  Name: main.User\n", "      json: \"true\"\n"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, buf.String())
+		}
+	}
+	var decoded map[string]any
+	if err := yaml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, buf.String())
+	}
+	tag := decoded["Attributes"].([]any)[0].(map[string]any)["Tags"].(map[string]any)["json"]
+	if tag != "true" {
+		t.Fatalf("expected the string \"true\", got %#v", tag)
+	}
+}
+
+func TestEncodeErrorUnknownFormat(t *testing.T) {
+	if err := Encode(&bytes.Buffer{}, []models.ParsedElement{user}, "xml"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,165 @@
+// Package jsonrpc serves JSON-RPC 2.0 requests framed as the messages of the Language Server Protocol: each message
+// is a JSON object preceded by a Content-Length header, so that editor plugins can reuse their LSP client to talk to
+// genz lsp. e.g.
+//
+//	Content-Length: 62\r\n\r\n{"jsonrpc":"2.0","id":1,"method":"genz/listTargets","params":{}}
+package jsonrpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// Version is the version of JSON-RPC of the messages.
+const Version = "2.0"
+
+// The error codes of JSON-RPC 2.0.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// ErrExit is returned by a Handler to stop serving, e.g. on the "exit" notification of LSP.
+var ErrExit = errors.New("exit")
+
+// Request is a request, or a notification when it has no ID.
+type Request struct {
+	JSONRPC string `json:"jsonrpc"`
+	// ID identifies the request in its response: a number or a string. Nil for a notification, which has no response.
+	ID     *json.RawMessage `json:"id,omitempty"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params,omitempty"`
+}
+
+// Response is the response to a request: its result, or its error.
+type Response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *Error           `json:"error,omitempty"`
+}
+
+// Error is the error of a request. A Handler returns it to choose its code, e.g. CodeInvalidParams.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	// Data details the error, e.g. the errors of genz with their kind and position.
+	Data any `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Handler returns the result of the given method, encoded as JSON, or its error.
+// The result of a notification is ignored.
+type Handler func(method string, params json.RawMessage) (any, error)
+
+// Read reads the content of the next message.
+func Read(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	value := header.Get("Content-Length")
+	if value == "" {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header %q", value)
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, fmt.Errorf("truncated message: %w", err)
+	}
+	return content, nil
+}
+
+// Write writes the given value as a message.
+func Write(w io.Writer, v any) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// Serve handles the requests read from r, one at a time, and writes their responses to w, until r ends or the handler
+// returns ErrExit. An invalid message is answered with an error, without stopping.
+func Serve(r io.Reader, w io.Writer, handle Handler) error {
+	reader := bufio.NewReader(r)
+	for {
+		content, err := Read(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var request Request
+		if err := json.Unmarshal(content, &request); err != nil {
+			if err := Write(w, Response{JSONRPC: Version, Error: &Error{Code: CodeParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if request.JSONRPC != Version || request.Method == "" {
+			if request.ID == nil {
+				continue
+			}
+			response := Response{JSONRPC: Version, ID: request.ID, Error: &Error{Code: CodeInvalidRequest, Message: "invalid request"}}
+			if err := Write(w, response); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, err := handle(request.Method, request.Params)
+		if errors.Is(err, ErrExit) {
+			return nil
+		}
+		if request.ID == nil {
+			continue
+		}
+		if err := Write(w, respond(request, result, err)); err != nil {
+			return err
+		}
+	}
+}
+
+// respond returns the response to the request from the result or the error of its handler.
+func respond(request Request, result any, err error) Response {
+	response := Response{JSONRPC: Version, ID: request.ID}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		response.Error = rpcErr
+		return response
+	}
+	content, err := json.Marshal(result)
+	if err != nil {
+		response.Error = &Error{Code: CodeInternalError, Message: fmt.Sprintf("failed to encode the result: %v", err)}
+		return response
+	}
+	response.Result = content
+	return response
+}
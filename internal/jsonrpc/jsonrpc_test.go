@@ -0,0 +1,124 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// messages frames the given JSON contents as messages.
+func messages(contents ...string) string {
+	var framed strings.Builder
+	for _, content := range contents {
+		fmt.Fprintf(&framed, "Content-Length: %d\r\n\r\n%s", len(content), content)
+	}
+	return framed.String()
+}
+
+// responses reads the responses written by Serve.
+func responses(t *testing.T, output string) []string {
+	t.Helper()
+	reader := bufio.NewReader(strings.NewReader(output))
+	var result []string
+	for {
+		content, err := Read(reader)
+		if errors.Is(err, io.EOF) {
+			return result
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result = append(result, string(content))
+	}
+}
+
+func TestServe(t *testing.T) {
+	input := messages(
+		`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"hello"}}`,
+		`{"jsonrpc":"2.0","method":"echo","params":{"text":"notified"}}`,
+		`{"jsonrpc":"2.0","id":"b","method":"missing"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"fail"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"nothing"}`,
+		`{"jsonrpc":"2.0","id":5`,
+		`{"id":6,"method":"echo"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+		`{"jsonrpc":"2.0","id":7,"method":"echo","params":{"text":"after exit"}}`,
+	)
+	var notified []string
+	var output bytes.Buffer
+	err := Serve(strings.NewReader(input), &output, func(method string, params json.RawMessage) (any, error) {
+		switch method {
+		case "echo":
+			var p struct{ Text string }
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+			}
+			notified = append(notified, p.Text)
+			return p, nil
+		case "fail":
+			return nil, fmt.Errorf("failed")
+		case "nothing":
+			return nil, nil
+		case "exit":
+			return nil, ErrExit
+		}
+		return nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + method}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"Text":"hello"}}`,
+		`{"jsonrpc":"2.0","id":"b","error":{"code":-32601,"message":"method not found: missing"}}`,
+		`{"jsonrpc":"2.0","id":3,"error":{"code":-32603,"message":"failed"}}`,
+		`{"jsonrpc":"2.0","id":4,"result":null}`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"unexpected end of JSON input"}}`,
+		`{"jsonrpc":"2.0","id":6,"error":{"code":-32600,"message":"invalid request"}}`,
+	}
+	got := responses(t, output.String())
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected responses:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	if strings.Join(notified, ",") != "hello,notified" {
+		t.Errorf("expected the request and the notification to be handled, got %v", notified)
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      string
+	}{
+		{name: "message", input: "Content-Length: 2\r\n\r\n{}", expected: "{}"},
+		{name: "other headers", input: "Content-Type: application/vscode-jsonrpc; charset=utf-8\r\nContent-Length: 2\r\n\r\n{}", expected: "{}"},
+		{name: "end of input", input: "", err: "EOF"},
+		{name: "missing length", input: "Content-Type: json\r\n\r\n{}", err: "missing Content-Length"},
+		{name: "invalid length", input: "Content-Length: two\r\n\r\n{}", err: "invalid Content-Length"},
+		{name: "truncated", input: "Content-Length: 10\r\n\r\n{}", err: "truncated message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := Read(bufio.NewReader(strings.NewReader(tt.input)))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, content)
+			}
+		})
+	}
+}
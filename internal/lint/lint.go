@@ -0,0 +1,711 @@
+// Package lint checks the templates against the models given to them, without rendering them: the fields and the
+// functions they use, the templates they invoke, and the nil pointers and the slice elements they may fail to
+// evaluate. e.g. {{ .Type.Nmae }} => getters.tmpl:3:5: error: unknown field Nmae of models.Type, did you mean Name? (unknown-field)
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/pkg/models"
+)
+
+// Severity is the severity of a diagnostic: an error fails the rendering, a warning may fail it for some types.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// The rules checked by Lint.
+const (
+	// RuleSyntax reports the syntax errors, e.g. an unclosed action or an undefined variable.
+	RuleSyntax = "syntax"
+	// RuleUnknownFunction reports the functions which are neither built in nor genz, sprig or custom functions.
+	RuleUnknownFunction = "unknown-function"
+	// RuleUnknownField reports the fields and the methods the models do not have.
+	RuleUnknownField = "unknown-field"
+	// RuleUnknownTemplate reports the templates invoked without being defined.
+	RuleUnknownTemplate = "unknown-template"
+	// RuleNilPointer reports the fields read through a pointer of the models which may be nil, e.g. .Target.Name,
+	// outside of an {{ if }} or a {{ with }} checking it.
+	RuleNilPointer = "nil-pointer"
+	// RuleUncheckedIndex reports the elements of a slice read by index, e.g. index .Methods 0, outside of an {{ if }}
+	// or a {{ with }} checking the slice.
+	RuleUncheckedIndex = "unchecked-index"
+)
+
+// Diagnostic is a problem found in a template.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	// File is the name of the template: generator.MainTemplate, or the name of a partial.
+	File string `json:"file"`
+	// Line and Column locate the problem, 1-based. The column is 0 if unknown, e.g. for a syntax error.
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	location := fmt.Sprintf("%s:%d", d.File, d.Line)
+	if d.Column > 0 {
+		location += fmt.Sprintf(":%d", d.Column)
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", location, d.Severity, d.Message, d.Rule)
+}
+
+// Location returns the position of the diagnostic, see failure.Locator.
+func (d Diagnostic) Location() (string, int, int) {
+	return d.File, d.Line, d.Column
+}
+
+// HasErrors returns true if one of the diagnostics is an error.
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// builtins are the result types of the functions of text/template, nil when it depends on their arguments.
+var builtins = map[string]reflect.Type{
+	"and":      nil,
+	"or":       nil,
+	"not":      reflect.TypeOf(false),
+	"eq":       reflect.TypeOf(false),
+	"ne":       reflect.TypeOf(false),
+	"lt":       reflect.TypeOf(false),
+	"le":       reflect.TypeOf(false),
+	"gt":       reflect.TypeOf(false),
+	"ge":       reflect.TypeOf(false),
+	"len":      reflect.TypeOf(0),
+	"index":    nil,
+	"slice":    nil,
+	"call":     nil,
+	"print":    reflect.TypeOf(""),
+	"printf":   reflect.TypeOf(""),
+	"println":  reflect.TypeOf(""),
+	"html":     reflect.TypeOf(""),
+	"js":       reflect.TypeOf(""),
+	"urlquery": reflect.TypeOf(""),
+}
+
+// kindGuards are the fields of models.Type set for some kinds of types only, with the fields checking these kinds:
+// {{ if .Type.IsPointer }}{{ .Type.Elem.Name }}{{ end }} reads a non-nil Elem.
+var kindGuards = map[string][]string{
+	"Elem":    {"IsPointer", "IsSlice", "IsArray", "IsMap", "IsChan", "IsVariadic"},
+	"Key":     {"IsMap"},
+	"Aliased": {"IsAlias"},
+}
+
+// syntaxErrorPattern matches a syntax error of text/template/parse, e.g. `template: getters.tmpl:3: unexpected "}" in operand`.
+var syntaxErrorPattern = regexp.MustCompile(`(?s)^template: (.+?):(\d+): (.*)$`)
+
+// Lint checks the main template, named generator.MainTemplate, and its partials, with the functions of
+// generator.FuncMap and the given custom ones. The main template is checked with a models.ParsedElement, and the
+// {{ define }} blocks and the partials with the values they are invoked with, or only for their functions and the
+// templates they invoke if they are not. The diagnostics are sorted by file and position.
+func Lint(content string, partials []generator.Partial, funcs template.FuncMap) []Diagnostic {
+	c := &checker{
+		trees:   map[string]*parse.Tree{},
+		funcs:   generator.FuncMap(),
+		checked: map[string]bool{},
+		seen:    map[Diagnostic]bool{},
+	}
+	for name, f := range funcs {
+		c.funcs[name] = f
+	}
+	files := append([]generator.Partial{{Name: generator.MainTemplate, Content: content}}, partials...)
+	for _, file := range files {
+		// Each file is parsed on its own, so that its {{ define }} blocks replace the previous ones, as in text/template.
+		trees := map[string]*parse.Tree{}
+		tree := parse.New(file.Name)
+		tree.Mode = parse.SkipFuncCheck
+		if _, err := tree.Parse(file.Content, "", "", trees); err != nil {
+			c.syntaxError(file.Name, err)
+			continue
+		}
+		for name, tree := range trees {
+			if existing := c.trees[name]; existing == nil || !parse.IsEmptyTree(tree.Root) {
+				c.trees[name] = tree
+			}
+		}
+	}
+
+	if c.trees[generator.MainTemplate] != nil {
+		c.checkTree(generator.MainTemplate, reflect.TypeOf(models.ParsedElement{}))
+	}
+	names := make([]string, 0, len(c.trees))
+	for name := range c.trees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !c.invoked[name] {
+			c.checkTree(name, nil)
+		}
+	}
+
+	sort.SliceStable(c.diagnostics, func(i, j int) bool {
+		a, b := c.diagnostics[i], c.diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return c.diagnostics
+}
+
+// checker checks the trees of a template.
+type checker struct {
+	trees map[string]*parse.Tree
+	funcs template.FuncMap
+	// checked are the trees already checked, by name and type of dot, and invoked the names of the trees checked with
+	// a known type.
+	checked map[string]bool
+	invoked map[string]bool
+	// seen are the diagnostics already reported, e.g. by a {{ define }} block invoked twice with the same type.
+	seen        map[Diagnostic]bool
+	diagnostics []Diagnostic
+}
+
+// scope is the context of the nodes of a tree: the type of dot and of the variables, nil when unknown, and the
+// chains checked by the enclosing {{ if }} and {{ with }}, e.g. ".Target" or "$t.IsPointer".
+type scope struct {
+	dot    reflect.Type
+	vars   map[string]*variable
+	guards map[string]bool
+}
+
+// variable is a variable of a template, shared by the blocks assigning it.
+type variable struct {
+	t reflect.Type
+}
+
+// child returns the scope of a block: its variables are dropped at its end. A block changing dot drops the guards
+// relative to dot.
+func (s scope) child(dot reflect.Type, changesDot bool) scope {
+	child := scope{dot: dot, vars: map[string]*variable{}, guards: map[string]bool{}}
+	for name, v := range s.vars {
+		child.vars[name] = v
+	}
+	for chain := range s.guards {
+		if !changesDot || strings.HasPrefix(chain, "$") {
+			child.guards[chain] = true
+		}
+	}
+	return child
+}
+
+// checkTree checks the tree of the given name, with the given type of dot, once.
+func (c *checker) checkTree(name string, dot reflect.Type) {
+	key := name + "\x00" + fmt.Sprint(dot)
+	if c.checked[key] {
+		return
+	}
+	c.checked[key] = true
+	if dot != nil {
+		if c.invoked == nil {
+			c.invoked = map[string]bool{}
+		}
+		c.invoked[name] = true
+	}
+	tree := c.trees[name]
+	s := scope{dot: dot, vars: map[string]*variable{"$": {t: dot}}, guards: map[string]bool{}}
+	c.walk(tree, tree.Root, s)
+}
+
+// walk checks the given node and its children.
+func (c *checker) walk(tree *parse.Tree, node parse.Node, s scope) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			c.walk(tree, child, s)
+			if ifNode, isIf := child.(*parse.IfNode); isIf && fails(ifNode.List) {
+				// {{ if not .Target }}{{ fail "..." }}{{ end }} checks .Target for the rest of the block.
+				addGuards(s.guards, ifNode.Pipe)
+			}
+		}
+	case *parse.ActionNode:
+		c.pipe(tree, n.Pipe, s, true)
+	case *parse.IfNode:
+		inner := s.child(s.dot, false)
+		c.pipe(tree, n.Pipe, inner, true)
+		body := inner.child(s.dot, false)
+		addGuards(body.guards, n.Pipe)
+		c.walk(tree, n.List, body)
+		c.walk(tree, n.ElseList, inner)
+	case *parse.WithNode:
+		inner := s.child(s.dot, false)
+		t := c.pipe(tree, n.Pipe, inner, true)
+		body := inner.child(t, true)
+		addGuards(body.guards, n.Pipe)
+		c.walk(tree, n.List, body)
+		c.walk(tree, n.ElseList, inner)
+	case *parse.RangeNode:
+		inner := s.child(s.dot, false)
+		key, elem := iterate(c.pipe(tree, n.Pipe, inner, false))
+		body := inner.child(elem, true)
+		switch len(n.Pipe.Decl) {
+		case 1:
+			body.vars[n.Pipe.Decl[0].Ident[0]] = &variable{t: elem}
+		case 2:
+			body.vars[n.Pipe.Decl[0].Ident[0]] = &variable{t: key}
+			body.vars[n.Pipe.Decl[1].Ident[0]] = &variable{t: elem}
+		}
+		c.walk(tree, n.List, body)
+		c.walk(tree, n.ElseList, inner)
+	case *parse.TemplateNode:
+		t := c.pipe(tree, n.Pipe, s, true)
+		if c.trees[n.Name] == nil {
+			c.report(tree, n, SeverityError, RuleUnknownTemplate, fmt.Sprintf("template %q is not defined%s", n.Name, suggest(n.Name, c.templateNames())))
+			return
+		}
+		c.checkTree(n.Name, t)
+	}
+}
+
+// pipe checks the pipeline and returns the type of its result, declaring its variables unless they are the ones of a
+// {{ range }}, see iterate.
+func (c *checker) pipe(tree *parse.Tree, pipe *parse.PipeNode, s scope, declare bool) reflect.Type {
+	if pipe == nil {
+		return nil
+	}
+	var t reflect.Type
+	for _, cmd := range pipe.Cmds {
+		t = c.command(tree, cmd, s)
+	}
+	if !declare {
+		return t
+	}
+	for _, node := range pipe.Decl {
+		name := node.Ident[0]
+		if v := s.vars[name]; pipe.IsAssign && v != nil {
+			// A variable assigned values of different types, e.g. {{ $match := false }} then a models.Attribute, is
+			// not checked.
+			if v.t != t {
+				v.t = nil
+			}
+			continue
+		}
+		s.vars[name] = &variable{t: t}
+	}
+	return t
+}
+
+// command checks the command and returns the type of its result.
+func (c *checker) command(tree *parse.Tree, cmd *parse.CommandNode, s scope) reflect.Type {
+	if identifier, isCall := cmd.Args[0].(*parse.IdentifierNode); isCall {
+		return c.call(tree, identifier, cmd.Args[1:], s)
+	}
+	for _, arg := range cmd.Args[1:] {
+		c.operand(tree, arg, s)
+	}
+	return c.operand(tree, cmd.Args[0], s)
+}
+
+// operand checks the operand and returns its type.
+func (c *checker) operand(tree *parse.Tree, node parse.Node, s scope) reflect.Type {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return s.dot
+	case *parse.FieldNode:
+		return c.fields(tree, n, s.dot, ".", n.Ident, s)
+	case *parse.VariableNode:
+		var t reflect.Type
+		if v := s.vars[n.Ident[0]]; v != nil {
+			t = v.t
+		}
+		return c.fields(tree, n, t, n.Ident[0], n.Ident[1:], s)
+	case *parse.ChainNode:
+		return c.fields(tree, n, c.operand(tree, n.Node, s), "", n.Field, s)
+	case *parse.PipeNode:
+		return c.pipe(tree, n, s, true)
+	case *parse.IdentifierNode:
+		return c.call(tree, n, nil, s)
+	case *parse.StringNode:
+		return reflect.TypeOf("")
+	case *parse.BoolNode:
+		return reflect.TypeOf(false)
+	case *parse.NumberNode:
+		if n.IsInt {
+			return reflect.TypeOf(0)
+		}
+		return reflect.TypeOf(0.0)
+	}
+	return nil
+}
+
+// call checks the call of the function and its arguments, and returns the type of its result.
+func (c *checker) call(tree *parse.Tree, identifier *parse.IdentifierNode, args []parse.Node, s scope) reflect.Type {
+	if identifier.Ident == "and" {
+		// The arguments are evaluated until one is false: each one is checked by the previous ones.
+		s = s.child(s.dot, false)
+		for _, arg := range args {
+			c.operand(tree, arg, s)
+			addGuards(s.guards, arg)
+		}
+		return nil
+	}
+	types := make([]reflect.Type, len(args))
+	for i, arg := range args {
+		types[i] = c.operand(tree, arg, s)
+	}
+	if result, isBuiltin := builtins[identifier.Ident]; isBuiltin {
+		switch identifier.Ident {
+		case "index":
+			return c.index(tree, identifier, args, types, s)
+		case "slice":
+			if len(types) > 0 {
+				return types[0]
+			}
+		}
+		return result
+	}
+	f, defined := c.funcs[identifier.Ident]
+	if !defined {
+		names := make([]string, 0, len(c.funcs)+len(builtins))
+		for name := range c.funcs {
+			names = append(names, name)
+		}
+		for name := range builtins {
+			names = append(names, name)
+		}
+		c.report(tree, identifier, SeverityError, RuleUnknownFunction, fmt.Sprintf("function %q is not defined%s", identifier.Ident, suggest(identifier.Ident, names)))
+		return nil
+	}
+	t := reflect.TypeOf(f)
+	if t == nil || t.Kind() != reflect.Func || t.NumOut() == 0 || t.Out(0).Kind() == reflect.Interface {
+		return nil
+	}
+	return t.Out(0)
+}
+
+// index checks the index builtin, e.g. index .Methods 0, and returns the type of its result.
+func (c *checker) index(tree *parse.Tree, identifier *parse.IdentifierNode, args []parse.Node, types []reflect.Type, s scope) reflect.Type {
+	if len(args) < 2 {
+		return nil
+	}
+	t := indirect(types[0])
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		if _, constant := args[1].(*parse.NumberNode); constant {
+			if chain := chainOf(args[0]); chain != "" && !s.guards[chain] {
+				message := fmt.Sprintf("%s may have no element %s: check it first, e.g. {{ if %s }}", chain, args[1], chain)
+				c.report(tree, identifier, SeverityWarning, RuleUncheckedIndex, message)
+			}
+		}
+	}
+	for range args[1:] {
+		t = indirect(t)
+		if t == nil {
+			return nil
+		}
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+	if t != nil && t.Kind() == reflect.Interface {
+		return nil
+	}
+	return t
+}
+
+// fields checks the fields or the methods read from the given type, e.g. ["Type", "Name"] for .Type.Name, and returns
+// the type of the last one. The chain is the operand before the fields, e.g. "." or "$t", or "" if it is not a field
+// chain.
+func (c *checker) fields(tree *parse.Tree, node parse.Node, t reflect.Type, chain string, names []string, s scope) reflect.Type {
+	for i, name := range names {
+		if t == nil {
+			return nil
+		}
+		if t.Kind() == reflect.Pointer {
+			if i > 0 && chain != "" && !guarded(s.guards, chain) {
+				message := fmt.Sprintf("%s may be nil: check it first, e.g. {{ with %s }}", chain, chain)
+				c.report(tree, node, SeverityWarning, RuleNilPointer, message)
+			}
+			t = t.Elem()
+		}
+		if method, found := methodByName(t, name); found {
+			t = nil
+			if method.Type.NumOut() > 0 && method.Type.Out(0).Kind() != reflect.Interface {
+				t = method.Type.Out(0)
+			}
+		} else {
+			switch t.Kind() {
+			case reflect.Struct:
+				field, found := t.FieldByName(name)
+				if !found || !field.IsExported() {
+					c.report(tree, node, SeverityError, RuleUnknownField, fmt.Sprintf("unknown field %s of %s%s", name, t, suggest(name, memberNames(t))))
+					return nil
+				}
+				t = field.Type
+			case reflect.Map:
+				t = t.Elem()
+			case reflect.Interface:
+				return nil
+			default:
+				c.report(tree, node, SeverityError, RuleUnknownField, fmt.Sprintf("%s has no field %s: it is a %s", chainOr(chain, t), name, t))
+				return nil
+			}
+		}
+		if chain != "" {
+			if chain == "." {
+				chain = "." + name
+			} else {
+				chain += "." + name
+			}
+		}
+		if t != nil && t.Kind() == reflect.Interface {
+			return nil
+		}
+	}
+	return t
+}
+
+// report adds a diagnostic located at the given node, once.
+func (c *checker) report(tree *parse.Tree, node parse.Node, severity Severity, rule, message string) {
+	diagnostic := Diagnostic{Severity: severity, Rule: rule, File: tree.ParseName, Message: message}
+	location, _ := tree.ErrorContext(node)
+	if parts := strings.Split(location, ":"); len(parts) >= 3 {
+		diagnostic.Line, _ = strconv.Atoi(parts[len(parts)-2])
+		column, _ := strconv.Atoi(parts[len(parts)-1])
+		diagnostic.Column = column + 1
+	}
+	if c.seen[diagnostic] {
+		return
+	}
+	c.seen[diagnostic] = true
+	c.diagnostics = append(c.diagnostics, diagnostic)
+}
+
+// syntaxError adds the diagnostic of a syntax error of the given file.
+func (c *checker) syntaxError(file string, err error) {
+	diagnostic := Diagnostic{Severity: SeverityError, Rule: RuleSyntax, File: file, Message: err.Error()}
+	if match := syntaxErrorPattern.FindStringSubmatch(err.Error()); match != nil {
+		diagnostic.Line, _ = strconv.Atoi(match[2])
+		diagnostic.Message = match[3]
+	}
+	c.diagnostics = append(c.diagnostics, diagnostic)
+}
+
+// templateNames returns the names of the defined templates.
+func (c *checker) templateNames() []string {
+	names := make([]string, 0, len(c.trees))
+	for name := range c.trees {
+		names = append(names, name)
+	}
+	return names
+}
+
+// fails returns true if the given block calls the fail function of sprig, e.g. {{ fail "the type is not a struct" }}.
+func fails(list *parse.ListNode) bool {
+	if list == nil {
+		return false
+	}
+	for _, node := range list.Nodes {
+		action, isAction := node.(*parse.ActionNode)
+		if !isAction {
+			continue
+		}
+		for _, cmd := range action.Pipe.Cmds {
+			if identifier, isCall := cmd.Args[0].(*parse.IdentifierNode); isCall && identifier.Ident == "fail" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// iterate returns the types of the key and of the element of a {{ range }} over the given type.
+func iterate(t reflect.Type) (reflect.Type, reflect.Type) {
+	t = indirect(t)
+	if t == nil {
+		return nil, nil
+	}
+	var key, elem reflect.Type
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		key, elem = reflect.TypeOf(0), t.Elem()
+	case reflect.Map:
+		key, elem = t.Key(), t.Elem()
+	case reflect.Chan:
+		elem = t.Elem()
+	case reflect.Int:
+		elem = t
+	}
+	if elem != nil && elem.Kind() == reflect.Interface {
+		elem = nil
+	}
+	return key, elem
+}
+
+// indirect returns the type a pointer points to, or the given type.
+func indirect(t reflect.Type) reflect.Type {
+	if t != nil && t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+// methodByName returns the method of the given type, or of a pointer to it.
+func methodByName(t reflect.Type, name string) (reflect.Method, bool) {
+	if method, found := t.MethodByName(name); found {
+		return method, true
+	}
+	if t.Kind() != reflect.Interface {
+		return reflect.PointerTo(t).MethodByName(name)
+	}
+	return reflect.Method{}, false
+}
+
+// memberNames returns the exported fields, promoted ones included, and the methods of the given struct type.
+func memberNames(t reflect.Type) []string {
+	var names []string
+	for _, field := range reflect.VisibleFields(t) {
+		if field.IsExported() {
+			names = append(names, field.Name)
+		}
+	}
+	pointer := reflect.PointerTo(t)
+	for i := 0; i < pointer.NumMethod(); i++ {
+		names = append(names, pointer.Method(i).Name)
+	}
+	return names
+}
+
+// addGuards adds the field chains read by the given node to the guards, e.g. ".Target" and ".Target.Name" for
+// {{ if .Target.Name }}.
+func addGuards(guards map[string]bool, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			addGuards(guards, cmd)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			addGuards(guards, arg)
+		}
+	case *parse.FieldNode, *parse.VariableNode:
+		chain := chainOf(n)
+		for i := 1; i < len(chain); i++ {
+			if chain[i] == '.' {
+				guards[chain[:i]] = true
+			}
+		}
+		guards[chain] = true
+	}
+}
+
+// guarded returns true if the given chain was checked, or the kind of the type it is read from for the fields set for
+// some kinds only, see kindGuards.
+func guarded(guards map[string]bool, chain string) bool {
+	if guards[chain] {
+		return true
+	}
+	i := strings.LastIndex(chain, ".")
+	if i < 0 {
+		return false
+	}
+	for _, kind := range kindGuards[chain[i+1:]] {
+		if guards[chain[:i]+"."+kind] {
+			return true
+		}
+	}
+	return false
+}
+
+// chainOf returns the field chain of the given node, e.g. ".Type.Methods" or "$t.Elem", or "" if it is not one.
+func chainOf(node parse.Node) string {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		return "." + strings.Join(n.Ident, ".")
+	case *parse.VariableNode:
+		return strings.Join(n.Ident, ".")
+	}
+	return ""
+}
+
+// chainOr returns the chain, or the type if it is not known.
+func chainOr(chain string, t reflect.Type) string {
+	if chain == "" {
+		return "the " + t.String()
+	}
+	return chain
+}
+
+// suggest returns ", did you mean <name>?" for the closest of the names to the given one, or "" if none is close.
+func suggest(name string, names []string) string {
+	best, bestDistance := "", len(name)/3+1
+	sort.Strings(names)
+	for _, candidate := range names {
+		if strings.EqualFold(candidate, name) {
+			return fmt.Sprintf(", did you mean %s?", candidate)
+		}
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(candidate)); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %s?", best)
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions of adjacent bytes
+// turning a into b, e.g. 1 for "Nmae" and "Name".
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,128 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/leorolland/genz/internal/builtin"
+	"github.com/leorolland/genz/internal/generator"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		partials []generator.Partial
+		funcs    template.FuncMap
+		expected []string
+	}{
+		{
+			name:     "valid",
+			template: "package {{ .PackageName }}\n{{ range $i, $a := .Attributes }}{{ $a.Name | snakeCase }} {{ index $a.Tags \"json\" }}{{ end }}{{ .HasMethod \"String\" }}",
+		},
+		{
+			name:     "unknown field",
+			template: "{{ .Type.Nmae }}\n{{ range .Attributes }}{{ .Type.Foo }}{{ end }}",
+			expected: []string{
+				"template:1:9: error: unknown field Nmae of models.Type, did you mean Name? (unknown-field)",
+				"template:2:32: error: unknown field Foo of models.Type (unknown-field)",
+			},
+		},
+		{
+			name:     "field of a string",
+			template: "{{ $name := .Type.Name }}{{ $name.Length }}",
+			expected: []string{"template:1:34: error: $name has no field Length: it is a string (unknown-field)"},
+		},
+		{
+			name:     "maps and interfaces are not checked",
+			template: "{{ .Vars.table }}{{ (index .Attributes 0).ParsedTags.json.Name }}{{ (dict \"a\" 1).a.b }}",
+			expected: []string{"template:1:22: warning: .Attributes may have no element 0: check it first, e.g. {{ if .Attributes }} (unchecked-index)"},
+		},
+		{
+			name:     "unknown function",
+			template: "{{ .Type.Name | pascalcase }}{{ frobnicate }}",
+			expected: []string{
+				`template:1:17: error: function "pascalcase" is not defined, did you mean pascalCase? (unknown-function)`,
+				`template:1:33: error: function "frobnicate" is not defined (unknown-function)`,
+			},
+		},
+		{
+			name:     "custom function",
+			template: "{{ shout .Type.Name }}",
+			funcs:    template.FuncMap{"shout": strings.ToUpper},
+		},
+		{
+			name:     "nil pointer",
+			template: "{{ .Target.Type.Name }}\n{{ with .Target }}{{ .Type.Name }}{{ end }}{{ if .Left }}{{ .Left.Type.Name }}{{ end }}",
+			expected: []string{"template:1:11: warning: .Target may be nil: check it first, e.g. {{ with .Target }} (nil-pointer)"},
+		},
+		{
+			name: "checked pointers",
+			template: "{{ if not .Target }}{{ fail \"no target\" }}{{ end }}{{ .Target.Type.Name }}" +
+				"{{ range .Attributes }}{{ if and .Resolved .Resolved.Attributes }}{{ end }}{{ if .Type.IsPointer }}{{ .Type.Elem.Name }}{{ end }}{{ end }}",
+		},
+		{
+			name:     "nil pointer of a variable",
+			template: "{{ range $a := .Attributes }}{{ $a.Resolved.Type.Name }}{{ end }}",
+			expected: []string{"template:1:35: warning: $a.Resolved may be nil: check it first, e.g. {{ with $a.Resolved }} (nil-pointer)"},
+		},
+		{
+			name:     "reassigned variable",
+			template: "{{ $match := false }}{{ range .Attributes }}{{ $match = . }}{{ end }}{{ if $match }}{{ $match.Name }}{{ end }}",
+		},
+		{
+			name:     "templates",
+			template: "{{ define \"attr\" }}{{ .Nam }}{{ end }}{{ range .Attributes }}{{ template \"attr\" . }}{{ end }}{{ template \"atr\" . }}{{ template \"header.tmpl\" .Type }}",
+			partials: []generator.Partial{{Name: "header.tmpl", Content: "// {{ .Kind }}"}},
+			expected: []string{
+				"header.tmpl:1:7: error: unknown field Kind of models.Type (unknown-field)",
+				"template:1:23: error: unknown field Nam of models.Attribute, did you mean Name? (unknown-field)",
+				`template:1:106: error: template "atr" is not defined, did you mean attr? (unknown-template)`,
+			},
+		},
+		{
+			name:     "block not invoked",
+			template: "{{ define \"unused\" }}{{ .Anything }}{{ missing }}{{ end }}",
+			expected: []string{`template:1:40: error: function "missing" is not defined (unknown-function)`},
+		},
+		{
+			name:     "syntax error",
+			template: "package x\n{{ range .Attributes }}",
+			expected: []string{"template:2: error: unexpected EOF (syntax)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, diagnostic := range Lint(tt.template, tt.partials, tt.funcs) {
+				got = append(got, diagnostic.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestLintBuiltinTemplates(t *testing.T) {
+	for _, name := range builtin.Names() {
+		content, err := builtin.Template(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, diagnostic := range Lint(string(content), nil, nil) {
+			t.Errorf("%s: %s", name, diagnostic)
+		}
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	warning := Diagnostic{Severity: SeverityWarning}
+	if HasErrors([]Diagnostic{warning}) {
+		t.Errorf("expected no error for a warning")
+	}
+	if !HasErrors([]Diagnostic{warning, {Severity: SeverityError}}) {
+		t.Errorf("expected an error")
+	}
+}
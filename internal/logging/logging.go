@@ -0,0 +1,154 @@
+// Package logging writes the leveled and structured logs of genz to the standard error: a message followed by
+// key-value attributes, as text (e.g. "genz: wrote file=car.gen.go bytes=1017") or as one JSON object per line.
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log. The logs below the configured level are discarded.
+type Level int
+
+const (
+	// LevelDebug logs the steps of the generation, e.g. the rendering and the formatting of each output.
+	LevelDebug Level = iota
+	// LevelInfo logs the discovered targets, the written files, the durations and the cache hits. It is the default.
+	LevelInfo
+	// LevelWarn logs the ignored problems, e.g. the compile errors of a package with -allow-errors.
+	LevelWarn
+	// LevelError logs the failures only.
+	LevelError
+)
+
+// Formats are the formats of the logs, see Configure.
+var Formats = []string{"text", "json"}
+
+var levelNames = map[Level]string{LevelDebug: "debug", LevelInfo: "info", LevelWarn: "warn", LevelError: "error"}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+var (
+	mu     sync.Mutex
+	level  = LevelInfo
+	format = "text"
+	now    = time.Now
+)
+
+// output is the writer of the logs, replaced in the tests.
+var output io.Writer = os.Stderr
+
+// Configure sets the minimum level and the format of the logs, "text" or "json".
+func Configure(minLevel Level, logFormat string) error {
+	if logFormat != "text" && logFormat != "json" {
+		return fmt.Errorf("invalid log format %q, expected one of %s", logFormat, strings.Join(Formats, ", "))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	level, format = minLevel, logFormat
+	return nil
+}
+
+// SetOutput sets the writer of the logs, the standard error by default, e.g. to send the logs of a request of genz daemon
+// to its client. It returns the previous writer.
+func SetOutput(w io.Writer) io.Writer {
+	mu.Lock()
+	defer mu.Unlock()
+	previous := output
+	output = w
+	return previous
+}
+
+// Debug logs the given message and key-value attributes at the debug level, e.g. Debug("rendered", "bytes", 42).
+func Debug(msg string, args ...any) {
+	write(LevelDebug, msg, args)
+}
+
+// Info logs the given message and key-value attributes at the info level, see Debug.
+func Info(msg string, args ...any) {
+	write(LevelInfo, msg, args)
+}
+
+// Warn logs the given message and key-value attributes at the warn level, see Debug.
+func Warn(msg string, args ...any) {
+	write(LevelWarn, msg, args)
+}
+
+// Error logs the given message and key-value attributes at the error level, see Debug.
+func Error(msg string, args ...any) {
+	write(LevelError, msg, args)
+}
+
+func write(logLevel Level, msg string, args []any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if logLevel < level {
+		return
+	}
+	if len(args)%2 != 0 {
+		args = append(args, "")
+	}
+	var buf bytes.Buffer
+	if format == "json" {
+		buf.WriteString(`{"time":`)
+		writeJSON(&buf, now().Format(time.RFC3339Nano))
+		buf.WriteString(`,"level":`)
+		writeJSON(&buf, logLevel.String())
+		buf.WriteString(`,"msg":`)
+		writeJSON(&buf, msg)
+		for i := 0; i < len(args); i += 2 {
+			buf.WriteString(",")
+			writeJSON(&buf, fmt.Sprint(args[i]))
+			buf.WriteString(":")
+			writeJSON(&buf, value(args[i+1]))
+		}
+		buf.WriteString("}\n")
+	} else {
+		buf.WriteString("genz: ")
+		if logLevel >= LevelWarn {
+			buf.WriteString(logLevel.String() + ": ")
+		}
+		buf.WriteString(msg)
+		for i := 0; i < len(args); i += 2 {
+			text := fmt.Sprint(value(args[i+1]))
+			if text == "" || strings.ContainsAny(text, " \t\n\"=") {
+				text = fmt.Sprintf("%q", text)
+			}
+			fmt.Fprintf(&buf, " %v=%s", args[i], text)
+		}
+		buf.WriteString("\n")
+	}
+	_, _ = output.Write(buf.Bytes())
+}
+
+// value returns the logged value of an attribute: the durations and the errors are logged as strings.
+func value(v any) any {
+	switch v := v.(type) {
+	case time.Duration:
+		return v.Round(time.Microsecond).String()
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return v
+}
+
+func writeJSON(buf *bytes.Buffer, v any) {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		encoded.Reset()
+		_ = encoder.Encode(fmt.Sprint(v))
+	}
+	buf.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLogs(t *testing.T) {
+	testCases := map[string]struct {
+		level    Level
+		format   string
+		log      func()
+		expected string
+	}{
+		"text with attributes": {
+			level:    LevelInfo,
+			format:   "text",
+			log:      func() { Info("wrote", "file", "car.gen.go", "bytes", 42) },
+			expected: "genz: wrote file=car.gen.go bytes=42\n",
+		},
+		"text quoting the values with spaces": {
+			level:    LevelInfo,
+			format:   "text",
+			log:      func() { Info("watching", "files", "a.go b.go", "empty", "") },
+			expected: "genz: watching files=\"a.go b.go\" empty=\"\"\n",
+		},
+		"text with the level of the problems": {
+			level:    LevelInfo,
+			format:   "text",
+			log:      func() { Warn("ignoring error", "error", errors.New("undefined: CarView")) },
+			expected: "genz: warn: ignoring error error=\"undefined: CarView\"\n",
+		},
+		"json": {
+			level:  LevelInfo,
+			format: "json",
+			log:    func() { Info("generated", "target", "Car", "files", 2, "duration", 1500*time.Microsecond) },
+			expected: `{"time":"2023-01-02T03:04:05Z","level":"info","msg":"generated",` +
+				`"target":"Car","files":2,"duration":"1.5ms"}` + "\n",
+		},
+		"json with a missing value": {
+			level:    LevelInfo,
+			format:   "json",
+			log:      func() { Error("failed", "error") },
+			expected: `{"time":"2023-01-02T03:04:05Z","level":"error","msg":"failed","error":""}` + "\n",
+		},
+		"debug discarded by default": {
+			level:    LevelInfo,
+			format:   "text",
+			log:      func() { Debug("formatting") },
+			expected: "",
+		},
+		"debug when verbose": {
+			level:    LevelDebug,
+			format:   "text",
+			log:      func() { Debug("formatting") },
+			expected: "genz: formatting\n",
+		},
+		"info discarded when quiet": {
+			level:    LevelError,
+			format:   "text",
+			log:      func() { Info("wrote", "file", "car.gen.go") },
+			expected: "",
+		},
+	}
+	// The configuration is global: the test cases are not run in parallel.
+	previousOutput, previousNow := output, now
+	defer func() {
+		output, now = previousOutput, previousNow
+		_ = Configure(LevelInfo, "text")
+	}()
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			output = &buf
+			now = func() time.Time { return time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC) }
+			if err := Configure(tc.level, tc.format); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tc.log()
+			if buf.String() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestConfigureInvalidFormat(t *testing.T) {
+	if err := Configure(LevelInfo, "xml"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	previous := SetOutput(&buf)
+	Info("wrote", "file", "car.gen.go")
+	if restored := SetOutput(previous); restored != &buf {
+		t.Errorf("expected the buffer returned as the previous output")
+	}
+	if buf.String() != "genz: wrote file=car.gen.go\n" {
+		t.Errorf("expected the log in the buffer, got %q", buf.String())
+	}
+}
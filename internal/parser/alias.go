@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
+)
+
+// parseAlias parses the type alias declared by the given type name, e.g. "type Customer = User".
+// The element keeps the names, the comments and the directives of the alias, with the aliased type in Type.Aliased.
+// When the alias stands for a type declared in the package (e.g. a struct), the element has its attributes, methods,
+// type parameters and enum values, parsed with the given options. Otherwise, only its underlying type is parsed.
+func parseAlias(pkg *packages.Package, object *types.TypeName, options Options) (models.Element, error) {
+	aliased := parseType(object.Type(), pkg.Types)
+	alias, err := parseElementType(pkg, object.Name())
+	if err != nil {
+		return models.Element{}, err
+	}
+
+	element := alias
+	if named, isNamed := unalias(object.Type()).(*types.Named); isNamed && named.Obj().Pkg() == pkg.Types && named.TypeArgs().Len() == 0 {
+		target, err := parse(pkg, named.Obj().Name(), options)
+		if err != nil {
+			return models.Element{}, err
+		}
+		element = target.Element
+		element.Type.Name, element.Type.InternalName, element.Type.LocalName = alias.Type.Name, alias.Type.InternalName, alias.Type.LocalName
+		element.Comments, element.Directives = alias.Comments, alias.Directives
+	} else {
+		element.Underlying = parseType(object.Type().Underlying(), pkg.Types)
+		element.Imports = typeImports(object.Type(), pkg.Types)
+	}
+	element.Type.IsAlias = true
+	element.Type.Aliased = &aliased
+	if basic, isBasic := object.Type().Underlying().(*types.Basic); isBasic {
+		element.Type.BasicKind = basic.Name()
+	}
+	if options.Positions {
+		element.Position = position(pkg.Fset, object.Pos())
+	}
+	return element, nil
+}
+
+// typeImports returns the sorted import paths of the packages referenced by the given type, see collectImports.
+// It returns nil if no import is required.
+func typeImports(t types.Type, local *types.Package) []string {
+	imports := map[string]bool{}
+	collectImports(t, local, imports)
+	if len(imports) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// parseTypeExpr returns the models.Type of the given type expression of the source, see parseType.
+// Unlike the type checker, which replaces the aliases by the types they stand for, it keeps the names of the aliases
+// written in the expression, e.g. "[]ID" with "type ID = uuid.UUID", and reports the aliased types. See models.Type.IsAlias.
+func parseTypeExpr(pkg *packages.Package, expr ast.Expr) models.Type {
+	t := pkg.TypesInfo.TypeOf(expr)
+	if t == nil || strings.Contains(types.TypeString(t, nil), "invalid type") {
+		return invalidTypeExpr(expr)
+	}
+	parsed := parseType(t, pkg.Types)
+	applyAliases(pkg, expr, &parsed)
+	applyStructFields(pkg, expr, &parsed)
+	return parsed
+}
+
+// qualifier matches the package qualifiers of a type expression, e.g. "uuid." in "[]uuid.UUID".
+var qualifier = regexp.MustCompile(`\b\w+\.`)
+
+// invalidTypeExpr returns the models.Type of a type expression the type checker failed on, as written in the source,
+// e.g. "[]CarGetter" when CarGetter is not generated yet. See utils.LoadOptions.AllowErrors.
+func invalidTypeExpr(expr ast.Expr) models.Type {
+	name := types.ExprString(expr)
+	return models.Type{
+		Name:         name,
+		InternalName: qualifier.ReplaceAllString(name, ""),
+		LocalName:    name,
+	}
+}
+
+// applyStructFields replaces the fields of the anonymous struct types of the given parsed type, built from the type
+// checker information, by the fields parsed from the given type expression, along with their comments.
+func applyStructFields(pkg *packages.Package, expr ast.Expr, parsed *models.Type) {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		applyStructFields(pkg, expr.X, parsed)
+	case *ast.StructType:
+		if fields, err := structAttributes(pkg, expr); err == nil && parsed.IsStruct {
+			parsed.Fields = fields
+		}
+	case *ast.StarExpr:
+		if parsed.Elem != nil {
+			applyStructFields(pkg, expr.X, parsed.Elem)
+		}
+	case *ast.ArrayType:
+		if parsed.Elem != nil {
+			applyStructFields(pkg, expr.Elt, parsed.Elem)
+		}
+	case *ast.ChanType:
+		if parsed.Elem != nil {
+			applyStructFields(pkg, expr.Value, parsed.Elem)
+		}
+	case *ast.MapType:
+		if parsed.Key != nil && parsed.Elem != nil {
+			applyStructFields(pkg, expr.Key, parsed.Key)
+			applyStructFields(pkg, expr.Value, parsed.Elem)
+		}
+	}
+}
+
+// applyAliases replaces the given parsed type, and its element and key types, by the aliases written in the given
+// type expression. It returns true if an alias was found, the names of the type literals being then rebuilt from the
+// names of their element and key types.
+func applyAliases(pkg *packages.Package, expr ast.Expr, parsed *models.Type) bool {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return applyAliases(pkg, expr.X, parsed)
+	case *ast.Ident:
+		return applyAlias(pkg, pkg.TypesInfo.Uses[expr], parsed)
+	case *ast.SelectorExpr:
+		return applyAlias(pkg, pkg.TypesInfo.Uses[expr.Sel], parsed)
+	case *ast.StarExpr:
+		return applyElemAliases(pkg, expr.X, parsed, "*")
+	case *ast.ArrayType:
+		if array, isArray := pkg.TypesInfo.TypeOf(expr).(*types.Array); isArray {
+			return applyElemAliases(pkg, expr.Elt, parsed, fmt.Sprintf("[%d]", array.Len()))
+		}
+		return applyElemAliases(pkg, expr.Elt, parsed, "[]")
+	case *ast.ChanType:
+		prefix := "chan "
+		switch expr.Dir {
+		case ast.SEND:
+			prefix = "chan<- "
+		case ast.RECV:
+			prefix = "<-chan "
+		}
+		return applyElemAliases(pkg, expr.Value, parsed, prefix)
+	case *ast.MapType:
+		if parsed.Key == nil || parsed.Elem == nil {
+			return false
+		}
+		keyAlias := applyAliases(pkg, expr.Key, parsed.Key)
+		elemAlias := applyAliases(pkg, expr.Value, parsed.Elem)
+		if !keyAlias && !elemAlias {
+			return false
+		}
+		key := *parsed.Key
+		renameLiteral(parsed, "map[", models.Type{
+			Name:         key.Name + "]" + parsed.Elem.Name,
+			InternalName: key.InternalName + "]" + parsed.Elem.InternalName,
+			LocalName:    key.LocalName + "]" + parsed.Elem.LocalName,
+		})
+		return true
+	}
+	return false
+}
+
+// applyElemAliases applies the aliases of the given element type expression of a pointer, a slice, an array or a
+// channel, whose names start with the given prefix, e.g. "[4]" or "chan<- ".
+func applyElemAliases(pkg *packages.Package, expr ast.Expr, parsed *models.Type, prefix string) bool {
+	if parsed.Elem == nil || !applyAliases(pkg, expr, parsed.Elem) {
+		return false
+	}
+	renameLiteral(parsed, prefix, *parsed.Elem)
+	return true
+}
+
+// applyAlias replaces the given parsed type by the alias declared by the given object, if it is one.
+// The aliases of the predeclared types (e.g. byte) are left aside, their object having no package.
+func applyAlias(pkg *packages.Package, object types.Object, parsed *models.Type) bool {
+	typeName, isTypeName := object.(*types.TypeName)
+	if !isTypeName || !typeName.IsAlias() || typeName.Pkg() == nil {
+		return false
+	}
+	aliased := *parsed
+	*parsed = models.Type{
+		Name:         typeName.Pkg().Name() + "." + typeName.Name(),
+		InternalName: typeName.Name(),
+		LocalName:    typeName.Name(),
+		PkgPath:      typeName.Pkg().Path(),
+		IsAlias:      true,
+		Aliased:      &aliased,
+	}
+	if typeName.Pkg() != pkg.Types {
+		parsed.LocalName = parsed.Name
+	}
+	if basic, isBasic := typeName.Type().Underlying().(*types.Basic); isBasic {
+		parsed.BasicKind = basic.Name()
+	}
+	return true
+}
+
+// renameLiteral sets the names of the given type literal to the given prefix followed by the names of the given type.
+func renameLiteral(parsed *models.Type, prefix string, elem models.Type) {
+	parsed.Name = prefix + elem.Name
+	parsed.InternalName = prefix + elem.InternalName
+	parsed.LocalName = prefix + elem.LocalName
+}
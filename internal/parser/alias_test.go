@@ -0,0 +1,235 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leorolland/genz/internal/testutils"
+	"github.com/leorolland/genz/pkg/models"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseAliasAttributesSuccess(t *testing.T) {
+	timeType := models.Type{Name: "time.Time", InternalName: "Time", LocalName: "time.Time", PkgPath: "time"}
+	stampType := models.Type{
+		Name: "main.Stamp", InternalName: "Stamp", LocalName: "Stamp", PkgPath: "command-line-arguments",
+		IsAlias: true, Aliased: &timeType,
+	}
+	testCases := map[string]struct {
+		goCode             string
+		expectedAttributes []models.Attribute
+	}{
+		"alias of a type of another package": {
+			goCode: `
+			package main
+
+			import "time"
+
+			type Stamp = time.Time
+
+			type A struct {
+				Created Stamp
+			}
+			`,
+			expectedAttributes: []models.Attribute{
+				{Name: "Created", Type: stampType, Comments: []string{}},
+			},
+		},
+		"aliases in type literals": {
+			goCode: `
+			package main
+
+			import "time"
+
+			type Stamp = time.Time
+
+			type A struct {
+				History []*Stamp
+				ByName  map[Stamp][2]Stamp
+			}
+			`,
+			expectedAttributes: []models.Attribute{
+				{
+					Name: "History",
+					Type: models.Type{
+						Name: "[]*main.Stamp", InternalName: "[]*Stamp", LocalName: "[]*Stamp", IsSlice: true,
+						Elem: &models.Type{
+							Name: "*main.Stamp", InternalName: "*Stamp", LocalName: "*Stamp", IsPointer: true,
+							Elem: &stampType,
+						},
+					},
+					Comments: []string{},
+				},
+				{
+					Name: "ByName",
+					Type: models.Type{
+						Name: "map[main.Stamp][2]main.Stamp", InternalName: "map[Stamp][2]Stamp", LocalName: "map[Stamp][2]Stamp", IsMap: true,
+						Key: &stampType,
+						Elem: &models.Type{
+							Name: "[2]main.Stamp", InternalName: "[2]Stamp", LocalName: "[2]Stamp", IsArray: true,
+							Elem: &stampType,
+						},
+					},
+					Comments: []string{},
+				},
+			},
+		},
+		"embedded alias": {
+			goCode: `
+			package main
+
+			type B struct{}
+
+			type Base = B
+
+			type A struct {
+				*Base
+			}
+			`,
+			expectedAttributes: []models.Attribute{
+				{
+					Name: "Base",
+					Type: models.Type{
+						Name: "*main.Base", InternalName: "*Base", LocalName: "*Base", IsPointer: true,
+						Elem: &models.Type{
+							Name: "main.Base", InternalName: "Base", LocalName: "Base", PkgPath: "command-line-arguments",
+							IsAlias: true,
+							Aliased: &models.Type{Name: "main.B", InternalName: "B", LocalName: "B", PkgPath: "command-line-arguments"},
+						},
+					},
+					IsEmbedded: true,
+					Comments:   []string{},
+				},
+			},
+		},
+		"predeclared alias": {
+			goCode: `
+			package main
+
+			type A struct {
+				Data []byte
+			}
+			`,
+			expectedAttributes: []models.Attribute{
+				{
+					Name: "Data",
+					Type: models.Type{
+						Name: "[]byte", InternalName: "[]byte", LocalName: "[]byte", IsSlice: true,
+						Elem: &models.Type{Name: "byte", InternalName: "byte", LocalName: "byte"},
+					},
+					Comments: []string{},
+				},
+			},
+		},
+		"types not declared yet, kept as written": {
+			goCode: `
+			package main
+
+			type A struct {
+				Views []*CarView
+				Store db.Store
+			}
+			`,
+			expectedAttributes: []models.Attribute{
+				{
+					Name:     "Views",
+					Type:     models.Type{Name: "[]*CarView", InternalName: "[]*CarView", LocalName: "[]*CarView"},
+					Comments: []string{},
+				},
+				{
+					Name:     "Store",
+					Type:     models.Type{Name: "db.Store", InternalName: "Store", LocalName: "db.Store"},
+					Comments: []string{},
+				},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			pkg := testutils.CreatePkgWithCode(t, tc.goCode)
+
+			parsed, err := Parser(pkg, "A")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(parsed.Attributes, tc.expectedAttributes) {
+				t.Errorf("output attributes don't match expected:\n%s", cmp.Diff(parsed.Attributes, tc.expectedAttributes))
+			}
+		})
+	}
+}
+
+func TestParseAliasElementSuccess(t *testing.T) {
+	testCases := map[string]struct {
+		goCode          string
+		expectedElement models.Element
+	}{
+		"alias of a local struct": {
+			goCode: `
+			package main
+
+			type User struct {
+				Name string
+			}
+
+			// Customer is a user.
+			type Customer = User
+			`,
+			expectedElement: models.Element{
+				Type: models.Type{
+					Name: "main.Customer", InternalName: "Customer", LocalName: "Customer", PkgPath: "command-line-arguments",
+					IsAlias: true,
+					Aliased: &models.Type{Name: "main.User", InternalName: "User", LocalName: "User", PkgPath: "command-line-arguments"},
+				},
+				Comments: []string{" Customer is a user."},
+				Attributes: []models.Attribute{
+					{Name: "Name", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{}},
+				},
+			},
+		},
+		"alias of a type literal": {
+			goCode: `
+			package main
+
+			import "time"
+
+			type Durations = map[string]time.Duration
+			`,
+			expectedElement: models.Element{
+				Type: models.Type{
+					Name: "main.Durations", InternalName: "Durations", LocalName: "Durations", PkgPath: "command-line-arguments",
+					IsAlias: true,
+					Aliased: &models.Type{
+						Name: "map[string]time.Duration", InternalName: "map[string]Duration", LocalName: "map[string]time.Duration", IsMap: true,
+						Key:  &models.Type{Name: "string", InternalName: "string", LocalName: "string"},
+						Elem: &models.Type{Name: "time.Duration", InternalName: "Duration", LocalName: "time.Duration", PkgPath: "time", BasicKind: "int64"},
+					},
+				},
+				Imports: []string{"time"},
+				Underlying: models.Type{
+					Name: "map[string]time.Duration", InternalName: "map[string]Duration", LocalName: "map[string]time.Duration", IsMap: true,
+					Key:  &models.Type{Name: "string", InternalName: "string", LocalName: "string"},
+					Elem: &models.Type{Name: "time.Duration", InternalName: "Duration", LocalName: "time.Duration", PkgPath: "time", BasicKind: "int64"},
+				},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			pkg := testutils.CreatePkgWithCode(t, tc.goCode)
+
+			parsed, err := Parser(pkg, tc.expectedElement.Type.InternalName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(parsed.Element, tc.expectedElement) {
+				t.Errorf("output element doesn't match expected:\n%s", cmp.Diff(parsed.Element, tc.expectedElement))
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// typeChecker is the models.TypeChecker of a named type of a package.
+type typeChecker struct {
+	pkg   *packages.Package
+	named *types.Named
+}
+
+// methodSet returns the method set of the pointer to the type, which includes the methods of the type itself, or the
+// method set of the interface.
+func (c typeChecker) methodSet() *types.MethodSet {
+	if types.IsInterface(c.named) {
+		return types.NewMethodSet(c.named)
+	}
+	return types.NewMethodSet(types.NewPointer(c.named))
+}
+
+func (c typeChecker) HasMethod(name string) bool {
+	return c.methodSet().Lookup(c.pkg.Types, name) != nil
+}
+
+func (c typeChecker) HasField(name string) bool {
+	object, _, _ := types.LookupFieldOrMethod(c.named, true, c.pkg.Types, name)
+	_, isField := object.(*types.Var)
+	return isField
+}
+
+func (c typeChecker) Implements(name string) (bool, error) {
+	iface, err := c.lookupInterface(name)
+	if err != nil {
+		return false, err
+	}
+	if types.IsInterface(c.named) {
+		return implements(c.named, iface), nil
+	}
+	return implements(types.NewPointer(c.named), iface), nil
+}
+
+// lookupInterface returns the interface of the given name: an interface of the package, or qualified with the name or
+// the path of a package imported, directly or not, by the package. The other packages are type-checked from their
+// sources, e.g. "io.Reader" when the package does not import io.
+func (c typeChecker) lookupInterface(name string) (*types.Interface, error) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return interfaceOf(c.pkg.Types, name)
+	}
+	pkgName, typeName := name[:i], name[i+1:]
+	if pkg := importedPackage(c.pkg.Types, pkgName, map[*types.Package]bool{}); pkg != nil {
+		return interfaceOf(pkg, typeName)
+	}
+	pkg, err := importer.ForCompiler(token.NewFileSet(), "source", nil).Import(pkgName)
+	if err != nil {
+		return nil, fmt.Errorf("package %s of the interface %s not found: %w", pkgName, name, err)
+	}
+	return interfaceOf(pkg, typeName)
+}
+
+// importedPackage returns the package of the given name or path among the packages imported by pkg, directly or not.
+func importedPackage(pkg *types.Package, nameOrPath string, seen map[*types.Package]bool) *types.Package {
+	for _, imported := range pkg.Imports() {
+		if seen[imported] {
+			continue
+		}
+		seen[imported] = true
+		if imported.Path() == nameOrPath || imported.Name() == nameOrPath {
+			return imported
+		}
+		if found := importedPackage(imported, nameOrPath, seen); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// interfaceOf returns the interface of the given name declared by the given package.
+func interfaceOf(pkg *types.Package, name string) (*types.Interface, error) {
+	object, isTypeName := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !isTypeName {
+		return nil, fmt.Errorf("%s not found in package %s", name, pkg.Path())
+	}
+	iface, isInterface := object.Type().Underlying().(*types.Interface)
+	if !isInterface {
+		return nil, fmt.Errorf("%s.%s is not an interface", pkg.Path(), name)
+	}
+	return iface, nil
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leorolland/genz/internal/testutils"
+)
+
+func TestTypeChecker(t *testing.T) {
+	pkg := testutils.CreatePkgWithCode(t, `
+	package main
+
+	import "fmt"
+
+	type Base struct {
+		ID int
+	}
+
+	func (b *Base) Key() string { return fmt.Sprint(b.ID) }
+
+	type User struct {
+		Base
+		Name string
+	}
+
+	func (u User) String() string { return u.Name }
+
+	type Keyer interface {
+		Key() string
+	}
+
+	type Stringer interface {
+		fmt.Stringer
+	}
+
+	type Color int
+	`)
+
+	user, err := parse(pkg, "User", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.TypeChecker == nil {
+		t.Fatal("expected a type checker")
+	}
+	for name, expected := range map[string]bool{"String": true, "Key": true, "Name": false, "Missing": false} {
+		if got := user.HasMethod(name); got != expected {
+			t.Errorf("HasMethod(%q) = %v, want %v", name, got, expected)
+		}
+	}
+	for name, expected := range map[string]bool{"Name": true, "ID": true, "Base": true, "String": false, "Missing": false} {
+		if got := user.HasField(name); got != expected {
+			t.Errorf("HasField(%q) = %v, want %v", name, got, expected)
+		}
+	}
+	for iface, expected := range map[string]bool{"fmt.Stringer": true, "Keyer": true, "Stringer": true, "io.Reader": false, "encoding.TextMarshaler": false} {
+		got, err := user.Implements(iface)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", iface, err)
+		}
+		if got != expected {
+			t.Errorf("Implements(%q) = %v, want %v", iface, got, expected)
+		}
+	}
+
+	color, err := parse(pkg, "Color", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if color.HasMethod("String") {
+		t.Error("expected Color not to have a String method")
+	}
+
+	for iface, expected := range map[string]string{
+		"Color":           "Color is not an interface",
+		"Missing":         "Missing not found",
+		"fmt.Missing":     "Missing not found in package fmt",
+		"not/a/package.I": "package not/a/package of the interface not/a/package.I not found",
+	} {
+		if _, err := user.Implements(iface); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected an error containing %q for %s, got %v", expected, iface, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"go/ast"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// directivePrefix is the prefix of the genz directives written in the doc comment of a type. e.g. "//genz:table users"
+const directivePrefix = "//genz:"
+
+// typeDoc returns the doc comment of the given type declared in the given package, or nil if it has none.
+// The doc comment of a type declared alone is attached to its declaration ("type A struct{}"),
+// and the one of a type declared in a group to its spec ("type ( A struct{} )").
+func typeDoc(pkg *packages.Package, typeName string) *ast.CommentGroup {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, isGenDecl := decl.(*ast.GenDecl)
+			if !isGenDecl {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec, isTypeSpec := spec.(*ast.TypeSpec)
+				if !isTypeSpec || typeSpec.Name.Name != typeName {
+					continue
+				}
+				if typeSpec.Doc != nil {
+					return typeSpec.Doc
+				}
+				if len(genDecl.Specs) == 1 {
+					return genDecl.Doc
+				}
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// parseDoc splits the given doc comment into its comments and its genz directives.
+// e.g. "// A user.\n//genz:table users" => [" A user."], {"table": "users"}
+// It returns nil slices and maps if there is no comment or directive.
+func parseDoc(doc *ast.CommentGroup) ([]string, map[string]string) {
+	if doc == nil {
+		return nil, nil
+	}
+	var comments []string
+	var directives map[string]string
+	for _, comment := range doc.List {
+		if !strings.HasPrefix(comment.Text, directivePrefix) {
+			comments = append(comments, comment.Text[2:])
+			continue
+		}
+		if directives == nil {
+			directives = map[string]string{}
+		}
+		name, args, _ := strings.Cut(strings.TrimPrefix(comment.Text, directivePrefix), " ")
+		directives[name] = strings.TrimSpace(args)
+	}
+	return comments, directives
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
+)
+
+// embeddedFieldName returns the implicit name of an embedded field of the given type.
+// e.g. "Bar" for "*foo.Bar" or "Box" for "Box[int]"
+func embeddedFieldName(t types.Type) string {
+	if pointer, isPointer := unalias(t).(*types.Pointer); isPointer {
+		t = pointer.Elem()
+	}
+	switch t := unalias(t).(type) {
+	case *types.Named:
+		return t.Obj().Name()
+	case *types.Basic:
+		return t.Name()
+	default:
+		return types.TypeString(t, func(_ *types.Package) string { return "" })
+	}
+}
+
+// flattenEmbedded replaces the embedded struct attributes by the attributes they promote, recursively.
+// Attributes declared by the parent shadow the promoted attributes with the same name, as in Go.
+// Embedded types that are not structs (e.g. interfaces) are kept as is.
+// The seen set holds the types being flattened, to stop on self-referencing embedded pointers.
+func flattenEmbedded(pkg *packages.Package, attributes []models.Attribute, embeddedTypes []types.Type, seen map[string]bool) ([]models.Attribute, error) {
+	declared := map[string]bool{}
+	for _, attribute := range attributes {
+		if !attribute.IsEmbedded {
+			declared[attribute.Name] = true
+		}
+	}
+
+	flattened := []models.Attribute{}
+	for i, attribute := range attributes {
+		if !attribute.IsEmbedded {
+			flattened = append(flattened, attribute)
+			continue
+		}
+		typeString := types.TypeString(embeddedTypes[i], nil)
+		if seen[typeString] {
+			flattened = append(flattened, attribute)
+			continue
+		}
+		seen[typeString] = true
+		promoted, isStruct, err := embeddedAttributes(pkg, embeddedTypes[i], seen)
+		delete(seen, typeString)
+		if err != nil {
+			return nil, err
+		}
+		if !isStruct {
+			flattened = append(flattened, attribute)
+			continue
+		}
+		for _, p := range promoted {
+			if declared[p.Name] {
+				continue
+			}
+			flattened = append(flattened, p)
+		}
+	}
+	return flattened, nil
+}
+
+// embeddedAttributes returns the flattened attributes of the given embedded type.
+// The attributes are parsed from the source when the struct is declared in the parsed package, so that comments are kept.
+// Otherwise, they are built from the type checker information.
+// The returned boolean is false if the embedded type is not a struct.
+func embeddedAttributes(pkg *packages.Package, t types.Type, seen map[string]bool) ([]models.Attribute, bool, error) {
+	if pointer, isPointer := unalias(t).(*types.Pointer); isPointer {
+		t = pointer.Elem()
+	}
+	t = unalias(t) // An embedded alias promotes the attributes of the type it names.
+	structType, isStruct := t.Underlying().(*types.Struct)
+	if !isStruct {
+		return nil, false, nil
+	}
+
+	if named, isNamed := t.(*types.Named); isNamed && named.Obj().Pkg() == pkg.Types && named.TypeArgs() == nil {
+		expr, err := loadAstExpr(pkg, named.Obj().Name())
+		if err == nil {
+			if astStruct, isAstStruct := expr.(*ast.StructType); isAstStruct {
+				attributes, err := structAttributes(pkg, astStruct)
+				if err != nil {
+					return nil, false, err
+				}
+				flattened, err := flattenEmbedded(pkg, attributes, fieldTypes(pkg.TypesInfo, astStruct), seen)
+				return flattened, true, err
+			}
+		}
+	}
+
+	attributes, embeddedTypes, err := typesStructAttributes(structType, pkg.Types)
+	if err != nil {
+		return nil, false, err
+	}
+	flattened, err := flattenEmbedded(pkg, attributes, embeddedTypes, seen)
+	return flattened, true, err
+}
+
+// fieldTypes returns the type of each field of the given struct, in the same order as structAttributes.
+func fieldTypes(typesInfo *types.Info, structType *ast.StructType) []types.Type {
+	fieldTypes := make([]types.Type, len(structType.Fields.List))
+	for i, field := range structType.Fields.List {
+		fieldTypes[i] = typesInfo.TypeOf(field.Type)
+	}
+	return fieldTypes
+}
@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leorolland/genz/internal/testutils"
+	"github.com/leorolland/genz/pkg/models"
+)
+
+func TestParseFlattenEmbeddedSuccess(t *testing.T) {
+	testCases := map[string]struct {
+		goCode             string
+		structName         string
+		expectedAttributes []models.Attribute
+	}{
+		"no embedded field": {
+			goCode: `
+			package main
+
+			type A struct {
+				foo string
+			}
+			`,
+			structName: "A",
+			expectedAttributes: []models.Attribute{
+				{Name: "foo", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{}},
+			},
+		},
+		"embedded struct of the same package": {
+			goCode: `
+			package main
+
+			type A struct {
+				// foo comment
+				foo string ` + "`json:\"foo\"`" + `
+			}
+			type B struct {
+				A
+				bar string
+			}
+			`,
+			structName: "B",
+			expectedAttributes: []models.Attribute{
+				{
+					Name:       "foo",
+					Type:       models.Type{Name: "string", InternalName: "string", LocalName: "string"},
+					Comments:   []string{" foo comment"},
+					Tags:       map[string]string{"json": "foo"},
+					ParsedTags: map[string]models.Tag{"json": {Value: "foo", Name: "foo"}},
+				},
+				{Name: "bar", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{}},
+			},
+		},
+		"recursively embedded structs with shadowing": {
+			goCode: `
+			package main
+
+			type A struct {
+				foo string
+				bar string
+			}
+			type B struct {
+				*A
+				baz string
+			}
+			type C struct {
+				B
+				bar int
+			}
+			`,
+			structName: "C",
+			expectedAttributes: []models.Attribute{
+				{Name: "foo", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{}},
+				{Name: "baz", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{}},
+				{Name: "bar", Type: models.Type{Name: "int", InternalName: "int", LocalName: "int"}, Comments: []string{}},
+			},
+		},
+		"embedded alias of a struct of the same package": {
+			goCode: `
+			package main
+
+			type A struct {
+				// foo comment
+				foo string
+			}
+			type AliasA = A
+			type B struct {
+				*AliasA
+				bar string
+			}
+			`,
+			structName: "B",
+			expectedAttributes: []models.Attribute{
+				{Name: "foo", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{" foo comment"}},
+				{Name: "bar", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{}},
+			},
+		},
+		"embedded struct of another package": {
+			goCode: `
+			package main
+
+			import "go/token"
+
+			type A struct {
+				token.Position
+			}
+			`,
+			structName: "A",
+			expectedAttributes: []models.Attribute{
+				{Name: "Filename", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{}},
+				{Name: "Offset", Type: models.Type{Name: "int", InternalName: "int", LocalName: "int"}, Comments: []string{}},
+				{Name: "Line", Type: models.Type{Name: "int", InternalName: "int", LocalName: "int"}, Comments: []string{}},
+				{Name: "Column", Type: models.Type{Name: "int", InternalName: "int", LocalName: "int"}, Comments: []string{}},
+			},
+		},
+		"embedded interface is kept": {
+			goCode: `
+			package main
+
+			import "fmt"
+
+			type A struct {
+				fmt.Stringer
+			}
+			`,
+			structName: "A",
+			expectedAttributes: []models.Attribute{
+				{
+					Name:       "Stringer",
+					Type:       models.Type{Name: "fmt.Stringer", InternalName: "Stringer", LocalName: "fmt.Stringer", PkgPath: "fmt"},
+					IsEmbedded: true,
+					Comments:   []string{},
+				},
+			},
+		},
+		"self referencing embedded pointer is kept": {
+			goCode: `
+			package main
+
+			type Node struct {
+				*Node
+				value int
+			}
+			`,
+			structName: "Node",
+			expectedAttributes: []models.Attribute{
+				{
+					Name:       "Node",
+					Type:       models.Type{Name: "*main.Node", InternalName: "*Node", LocalName: "*Node", IsPointer: true, Elem: &models.Type{Name: "main.Node", InternalName: "Node", LocalName: "Node", PkgPath: "command-line-arguments"}},
+					IsEmbedded: true,
+					Comments:   []string{},
+				},
+				{Name: "value", Type: models.Type{Name: "int", InternalName: "int", LocalName: "int"}, Comments: []string{}},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pkg := testutils.CreatePkgWithCode(t, tc.goCode)
+
+			parsedElement, err := WithOptions(Options{FlattenEmbedded: true})(pkg, tc.structName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(parsedElement.Attributes, tc.expectedAttributes) {
+				t.Fatalf("output attributes don't match expected:\n%s", cmp.Diff(parsedElement.Attributes, tc.expectedAttributes))
+			}
+		})
+	}
+}
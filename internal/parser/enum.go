@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
+)
+
+// parseEnumValues returns every constant of the package declared with the given named type, in source order.
+// Constants declared in iota blocks are resolved to their actual value.
+// It returns nil if no constant of this type is declared.
+func parseEnumValues(pkg *packages.Package, namedType *types.Named) []models.EnumValue {
+	var values []models.EnumValue
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, isGenDecl := decl.(*ast.GenDecl)
+			if !isGenDecl || genDecl.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for _, ident := range valueSpec.Names {
+					constant, isConst := pkg.TypesInfo.Defs[ident].(*types.Const)
+					if !isConst || !types.Identical(constant.Type(), namedType) {
+						continue
+					}
+					comments := []string{}
+					if valueSpec.Doc != nil {
+						for _, comment := range valueSpec.Doc.List {
+							comments = append(comments, comment.Text[2:])
+						}
+					}
+					tags := inlineTags(valueSpec.Comment)
+					values = append(values, models.EnumValue{
+						Name:       ident.Name,
+						Value:      constant.Val().ExactString(),
+						IsExported: ident.IsExported(),
+						Comments:   comments,
+						Tags:       tags,
+						ParsedTags: parseTagValues(tags),
+					})
+				}
+			}
+		}
+	}
+	return values
+}
+
+// inlineTags parses the given inline comment as tags (e.g. // json:"red").
+// It returns nil if there is no inline comment or if it is not written with the tags syntax.
+func inlineTags(comment *ast.CommentGroup) map[string]string {
+	if comment == nil {
+		return nil
+	}
+	text := strings.TrimSpace(comment.Text())
+	if !strings.Contains(text, `:"`) {
+		return nil
+	}
+	tags, err := parseTags(text)
+	if err != nil {
+		return nil
+	}
+	return tags
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/leorolland/genz/pkg/models"
+)
+
+// filterAttributes returns the attributes kept by the IncludeTags, ExcludeTags and ExcludeUnexported options.
+func filterAttributes(attributes []models.Attribute, options Options) ([]models.Attribute, error) {
+	if len(options.IncludeTags) == 0 && len(options.ExcludeTags) == 0 && !options.ExcludeUnexported {
+		return attributes, nil
+	}
+	var kept []models.Attribute
+	for _, attribute := range attributes {
+		if options.ExcludeUnexported && !token.IsExported(attribute.Name) {
+			continue
+		}
+		included, err := matchesAnyTag(attribute, options.IncludeTags)
+		if err != nil {
+			return nil, err
+		}
+		excluded, err := matchesAnyTag(attribute, options.ExcludeTags)
+		if err != nil {
+			return nil, err
+		}
+		if (len(options.IncludeTags) == 0 || included) && !excluded {
+			kept = append(kept, attribute)
+		}
+	}
+	return kept, nil
+}
+
+// matchesAnyTag returns true if the attribute has one of the given tags: a tag key, e.g. "json", or a tag key and the
+// name of the tag, with or without quotes, e.g. `genz:"-"` or "db:id".
+func matchesAnyTag(attribute models.Attribute, selectors []string) (bool, error) {
+	for _, selector := range selectors {
+		key, name, hasName := strings.Cut(selector, ":")
+		if key == "" {
+			return false, fmt.Errorf("invalid tag %q, expected key or key:\"name\"", selector)
+		}
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+		if tag, found := attribute.ParsedTags[key]; found && (!hasName || tag.Name == name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leorolland/genz/internal/testutils"
+)
+
+func TestParseFilterAttributes(t *testing.T) {
+	goCode := `
+	package main
+
+	type A struct {
+		ID       int    ` + "`json:\"id\" db:\"id\"`" + `
+		Name     string ` + "`json:\"name\"`" + `
+		Password string ` + "`json:\"-\" genz:\"-\"`" + `
+		internal string
+		B
+	}
+	type B struct {
+		Token  string ` + "`genz:\"-\"`" + `
+		Public bool   ` + "`json:\"public\"`" + `
+	}
+	`
+	testCases := map[string]struct {
+		options       Options
+		expectedNames []string
+	}{
+		"no filter": {
+			expectedNames: []string{"ID", "Name", "Password", "internal", "B"},
+		},
+		"exclude unexported": {
+			options:       Options{ExcludeUnexported: true},
+			expectedNames: []string{"ID", "Name", "Password", "B"},
+		},
+		"include tag key": {
+			options:       Options{IncludeTags: []string{"json"}},
+			expectedNames: []string{"ID", "Name", "Password"},
+		},
+		"include tag name": {
+			options:       Options{IncludeTags: []string{`db:"id"`, "json:name"}},
+			expectedNames: []string{"ID", "Name"},
+		},
+		"exclude tag name": {
+			options:       Options{ExcludeTags: []string{`genz:"-"`}},
+			expectedNames: []string{"ID", "Name", "internal", "B"},
+		},
+		"exclude the promoted attributes": {
+			options:       Options{FlattenEmbedded: true, ExcludeTags: []string{`genz:"-"`}, ExcludeUnexported: true},
+			expectedNames: []string{"ID", "Name", "Public"},
+		},
+		"include and exclude": {
+			options:       Options{IncludeTags: []string{"json"}, ExcludeTags: []string{`json:"-"`}},
+			expectedNames: []string{"ID", "Name"},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pkg := testutils.CreatePkgWithCode(t, goCode)
+
+			parsedElement, err := WithOptions(tc.options)(pkg, "A")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, attribute := range parsedElement.Attributes {
+				names = append(names, attribute.Name)
+			}
+			if !reflect.DeepEqual(names, tc.expectedNames) {
+				t.Errorf("expected attributes %v, got %v", tc.expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestParseFilterAttributesError(t *testing.T) {
+	pkg := testutils.CreatePkgWithCode(t, "package main\n\ntype A struct {\n\tID int\n}\n")
+	if _, err := WithOptions(Options{ExcludeTags: []string{`:"-"`}})(pkg, "A"); err == nil {
+		t.Error("expected an error for a tag without key")
+	}
+}
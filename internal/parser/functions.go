@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
+)
+
+// parseFunctions returns the top-level functions of the package, in source order.
+// Methods and init functions are ignored.
+func parseFunctions(pkg *packages.Package) []models.Function {
+	interfaces := packageInterfaces(pkg.Types)
+	var functions []models.Function
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			funcDecl, isFuncDecl := decl.(*ast.FuncDecl)
+			if !isFuncDecl || funcDecl.Recv != nil || funcDecl.Name.Name == "init" {
+				continue
+			}
+			function, isFunc := pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+			if !isFunc {
+				continue
+			}
+			signature := function.Type().(*types.Signature)
+			params, returns := signatureTypes(signature, pkg.Types)
+
+			comments := []string{}
+			if funcDecl.Doc != nil {
+				for _, comment := range funcDecl.Doc.List {
+					comments = append(comments, comment.Text[2:])
+				}
+			}
+			functions = append(functions, models.Function{
+				Name:        function.Name(),
+				Params:      params,
+				Returns:     returns,
+				ParamNames:  tupleNames(signature.Params()),
+				ReturnNames: tupleNames(signature.Results()),
+				TypeParams:  parseTypeParams(signature.TypeParams(), pkg.Types),
+				IsExported:  function.Exported(),
+				Implements:  implemented(signature, interfaces, pkg.Types),
+				Comments:    comments,
+			})
+		}
+	}
+	return functions
+}
+
+// packageInterfaces returns the exported interfaces of the given package having methods, sorted by name.
+// The generic ones are left aside, since only their instantiations can be implemented.
+func packageInterfaces(pkg *types.Package) []*types.Named {
+	var interfaces []*types.Named
+	for _, name := range pkg.Scope().Names() { // sorted
+		typeName, isTypeName := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !isTypeName || !typeName.Exported() || typeName.IsAlias() {
+			continue
+		}
+		named, isNamed := typeName.Type().(*types.Named)
+		if !isNamed || named.TypeParams().Len() != 0 {
+			continue
+		}
+		if iface, isInterface := named.Underlying().(*types.Interface); isInterface && iface.NumMethods() > 0 {
+			interfaces = append(interfaces, named)
+		}
+	}
+	return interfaces
+}
+
+// implemented returns the given interfaces implemented by the first result of the given signature, unless it is an
+// interface itself.
+func implemented(signature *types.Signature, interfaces []*types.Named, local *types.Package) []models.Type {
+	if signature.Results().Len() == 0 {
+		return nil
+	}
+	result := unalias(signature.Results().At(0).Type())
+	if types.IsInterface(result) {
+		return nil
+	}
+	var found []models.Type
+	for _, named := range interfaces {
+		if implements(result, named.Underlying().(*types.Interface)) {
+			found = append(found, parseType(named, local))
+		}
+	}
+	return found
+}
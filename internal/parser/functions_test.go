@@ -0,0 +1,216 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leorolland/genz/internal/testutils"
+	"github.com/leorolland/genz/pkg/models"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseFunctionsSuccess(t *testing.T) {
+	testCases := map[string]struct {
+		goCode            string
+		typeName          string
+		expectedFunctions []models.Function
+	}{
+		"no function": {
+			goCode: `
+			package main
+			`,
+			expectedFunctions: nil,
+		},
+		"functions without type": {
+			goCode: `
+			package main
+
+			import "time"
+
+			func init() {}
+
+			// Now returns the current time.
+			func Now() time.Time {
+				return time.Now()
+			}
+
+			func add(a, b int) (int, error) {
+				return a + b, nil
+			}
+			`,
+			expectedFunctions: []models.Function{
+				{
+					Name:        "Now",
+					Params:      []models.Type{},
+					Returns:     []models.Type{{Name: "time.Time", InternalName: "Time", LocalName: "time.Time", PkgPath: "time"}},
+					ParamNames:  []string{},
+					ReturnNames: []string{""},
+					IsExported:  true,
+					Comments:    []string{" Now returns the current time."},
+				},
+				{
+					Name: "add",
+					Params: []models.Type{
+						{Name: "int", InternalName: "int", LocalName: "int"},
+						{Name: "int", InternalName: "int", LocalName: "int"},
+					},
+					Returns: []models.Type{
+						{Name: "int", InternalName: "int", LocalName: "int"},
+						{Name: "error", InternalName: "error", LocalName: "error"},
+					},
+					ParamNames:  []string{"a", "b"},
+					ReturnNames: []string{"", ""},
+					IsExported:  false,
+					Comments:    []string{},
+				},
+			},
+		},
+		"function with named returns": {
+			goCode: `
+			package main
+
+			func parse(s string) (n int, err error) {
+				return 0, nil
+			}
+			`,
+			expectedFunctions: []models.Function{
+				{
+					Name:   "parse",
+					Params: []models.Type{{Name: "string", InternalName: "string", LocalName: "string"}},
+					Returns: []models.Type{
+						{Name: "int", InternalName: "int", LocalName: "int"},
+						{Name: "error", InternalName: "error", LocalName: "error"},
+					},
+					ParamNames:  []string{"s"},
+					ReturnNames: []string{"n", "err"},
+					IsExported:  false,
+					Comments:    []string{},
+				},
+			},
+		},
+		"generic function": {
+			goCode: `
+			package main
+
+			func Map[T, U any](values []T, f func(T) U) []U {
+				return nil
+			}
+			`,
+			expectedFunctions: []models.Function{
+				{
+					Name: "Map",
+					Params: []models.Type{
+						{
+							Name: "[]T", InternalName: "[]T", LocalName: "[]T", IsSlice: true,
+							Elem: &models.Type{Name: "T", InternalName: "T", LocalName: "T"},
+						},
+						{Name: "func(T) U", InternalName: "func(T) U", LocalName: "func(T) U", IsFunc: true},
+					},
+					Returns: []models.Type{
+						{
+							Name: "[]U", InternalName: "[]U", LocalName: "[]U", IsSlice: true,
+							Elem: &models.Type{Name: "U", InternalName: "U", LocalName: "U"},
+						},
+					},
+					ParamNames:  []string{"values", "f"},
+					ReturnNames: []string{""},
+					TypeParams: []models.TypeParam{
+						{Name: "T", Constraint: models.Type{Name: "any", InternalName: "any", LocalName: "any"}},
+						{Name: "U", Constraint: models.Type{Name: "any", InternalName: "any", LocalName: "any"}},
+					},
+					IsExported: true,
+					Comments:   []string{},
+				},
+			},
+		},
+		"methods are ignored": {
+			goCode: `
+			package main
+
+			type A struct{}
+
+			func (a A) Method() {}
+
+			func NewA() A {
+				return A{}
+			}
+			`,
+			typeName: "A",
+			expectedFunctions: []models.Function{
+				{
+					Name:        "NewA",
+					Params:      []models.Type{},
+					Returns:     []models.Type{{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"}},
+					ParamNames:  []string{},
+					ReturnNames: []string{""},
+					IsExported:  true,
+					Comments:    []string{},
+				},
+			},
+		},
+		"constructor implementing interfaces": {
+			goCode: `
+			package main
+
+			type Store interface{ Get() string }
+
+			type Closer interface{ Close() error }
+
+			type empty interface{}
+
+			type SQLStore struct{}
+
+			func (s *SQLStore) Get() string { return "" }
+
+			func NewSQLStore() (*SQLStore, error) {
+				return &SQLStore{}, nil
+			}
+
+			func NewStore() Store {
+				return nil
+			}
+			`,
+			expectedFunctions: []models.Function{
+				{
+					Name:   "NewSQLStore",
+					Params: []models.Type{},
+					Returns: []models.Type{
+						{Name: "*main.SQLStore", InternalName: "*SQLStore", LocalName: "*SQLStore", IsPointer: true,
+							Elem: &models.Type{Name: "main.SQLStore", InternalName: "SQLStore", LocalName: "SQLStore", PkgPath: "command-line-arguments"}},
+						{Name: "error", InternalName: "error", LocalName: "error"},
+					},
+					ParamNames:  []string{},
+					ReturnNames: []string{"", ""},
+					IsExported:  true,
+					Implements:  []models.Type{{Name: "main.Store", InternalName: "Store", LocalName: "Store", PkgPath: "command-line-arguments"}},
+					Comments:    []string{},
+				},
+				{
+					Name:        "NewStore",
+					Params:      []models.Type{},
+					Returns:     []models.Type{{Name: "main.Store", InternalName: "Store", LocalName: "Store", PkgPath: "command-line-arguments"}},
+					ParamNames:  []string{},
+					ReturnNames: []string{""},
+					IsExported:  true,
+					Comments:    []string{},
+				},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			pkg := testutils.CreatePkgWithCode(t, tc.goCode)
+
+			parsed, err := parse(pkg, tc.typeName, Options{Functions: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(parsed.Functions, tc.expectedFunctions) {
+				t.Errorf("output functions don't match expected:\n%s", cmp.Diff(parsed.Functions, tc.expectedFunctions))
+			}
+		})
+	}
+}
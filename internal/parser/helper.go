@@ -9,19 +9,30 @@ import (
 	"golang.org/x/tools/go/packages"
 )
 
-// parseElementType initializes a models.Element with the given name and package.
+// parseElementType initializes a models.Element with the given name and package, and its doc comment.
 // It does not parse the element's methods or attributes.
 // It should be called first by every parse* function.
 func parseElementType(pkg *packages.Package, name string) (models.Element, error) {
 	if pkg.Types == nil {
 		return models.Element{}, fmt.Errorf("package %s has no types", pkg.Name)
 	}
-	return models.Element{
+	comments, directives := parseDoc(typeDoc(pkg, name))
+	element := models.Element{
 		Type: models.Type{
 			Name:         fmt.Sprintf("%s.%s", pkg.Name, name),
 			InternalName: name,
+			LocalName:    name,
+			PkgPath:      pkg.Types.Path(),
 		},
-	}, nil
+		Comments:   comments,
+		Directives: directives,
+	}
+	if object := pkg.Types.Scope().Lookup(name); object != nil {
+		if named, err := objectAsNamedType(object); err == nil {
+			element.Imports = elementImports(named, pkg.Types)
+		}
+	}
+	return element, nil
 }
 
 // objectAsNamedType returns the given object as a *types.Named.
@@ -40,8 +51,11 @@ func objectAsNamedType(object types.Object) (*types.Named, error) {
 }
 
 // parseType returns a models.Type from the given types.Type.
-// It returns the type name with the package qualifier and without the package qualifier.
-func parseType(t types.Type) models.Type {
+// It returns the type name with the package qualifier, without the package qualifier,
+// and qualified only when declared outside the local package (i.e. the package of the parsed element).
+func parseType(t types.Type, local *types.Package) models.Type {
+	t = unalias(t)
+
 	// Remove every qualifier before the type name
 	// transforming "github.com/google/uuid.UUID" into "UUID"
 	noPackageQualifier := func(_ *types.Package) string { return "" }
@@ -52,11 +66,76 @@ func parseType(t types.Type) models.Type {
 		return pkg.Name()
 	}
 
-	return models.Type{
+	// Adds the package name qualifier before the type name, unless it is declared in the local package
+	// transforming "github.com/google/uuid.UUID" into "uuid.UUID", and "main.A" into "A"
+	localQualifier := func(pkg *types.Package) string {
+		if pkg == local {
+			return ""
+		}
+		return pkg.Name()
+	}
+
+	parsed := models.Type{
 		Name:         types.TypeString(t, packageNameQualifier), // (e.g. "uuid.UUID")
 		InternalName: types.TypeString(t, noPackageQualifier),   // (e.g. "UUID")
+		LocalName:    types.TypeString(t, localQualifier),       // (e.g. "uuid.UUID")
+	}
+	if named, isNamed := t.(*types.Named); isNamed && named.Obj().Pkg() != nil {
+		parsed.PkgPath = named.Obj().Pkg().Path() // (e.g. "github.com/google/uuid")
+		if basic, isBasic := named.Underlying().(*types.Basic); isBasic {
+			parsed.BasicKind = basic.Name() // (e.g. "float64" for "type Meters float64")
+		}
 	}
 
+	// Describe the type literals, with their element and key types.
+	switch t := t.(type) {
+	case *types.Pointer:
+		parsed.IsPointer = true
+		parsed.Elem = parseSubType(t.Elem(), local)
+	case *types.Slice:
+		parsed.IsSlice = true
+		parsed.Elem = parseSubType(t.Elem(), local)
+	case *types.Array:
+		parsed.IsArray = true
+		parsed.Elem = parseSubType(t.Elem(), local)
+	case *types.Map:
+		parsed.IsMap = true
+		parsed.Key = parseSubType(t.Key(), local)
+		parsed.Elem = parseSubType(t.Elem(), local)
+	case *types.Chan:
+		parsed.IsChan = true
+		parsed.Elem = parseSubType(t.Elem(), local)
+	case *types.Signature:
+		parsed.IsFunc = true
+	case *types.Struct:
+		parsed.IsStruct = true
+		if fields, _, err := typesStructAttributes(t, local); err == nil {
+			parsed.Fields = fields
+		}
+	}
+	return parsed
+}
+
+// parseSubType returns the models.Type of an element or a key type, see parseType.
+func parseSubType(t types.Type, local *types.Package) *models.Type {
+	parsed := parseType(t, local)
+	return &parsed
+}
+
+// unalias returns the type the given alias stands for, following the chains of aliases, or the given type if it is not
+// an alias. Since Go 1.23, the type checker reports the aliases as types of their own (see GODEBUG gotypesalias),
+// whose Rhs method returns the aliased type. The aliases of the predeclared types (i.e. any) are kept.
+func unalias(t types.Type) types.Type {
+	for {
+		alias, isAlias := t.(interface {
+			Obj() *types.TypeName
+			Rhs() types.Type
+		})
+		if !isAlias || alias.Obj().Pkg() == nil {
+			return t
+		}
+		t = alias.Rhs()
+	}
 }
 
 // loadAstExpr returns the ast.Expr of the given typeName in the given package.
@@ -78,3 +157,19 @@ func loadAstExpr(pkg *packages.Package, typeName string) (ast.Expr, error) {
 	}
 	return nil, fmt.Errorf("%s not found in package %s", typeName, pkg.Name)
 }
+
+// parseTypeParams returns the models.TypeParam of the given type parameter list.
+// It returns nil if the list is empty, i.e. if the type is not generic.
+func parseTypeParams(typeParams *types.TypeParamList, local *types.Package) []models.TypeParam {
+	if typeParams == nil || typeParams.Len() == 0 {
+		return nil
+	}
+	params := make([]models.TypeParam, typeParams.Len())
+	for i := 0; i < typeParams.Len(); i++ {
+		params[i] = models.TypeParam{
+			Name:       typeParams.At(i).Obj().Name(),
+			Constraint: parseType(typeParams.At(i).Constraint(), local),
+		}
+	}
+	return params
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
+)
+
+// LookupInterface returns the package declaring the given interface among the given packages.
+// The name can be qualified with the path of its package (e.g. "github.com/foo/bar/store.Repository") to choose
+// among several interfaces of the same name. It returns an error if none or several packages declare it.
+func LookupInterface(pkgs []*packages.Package, name string) (*packages.Package, string, error) {
+	pkgPath, typeName := "", name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		pkgPath, typeName = name[:i], name[i+1:]
+	}
+	var found []*packages.Package
+	for _, pkg := range pkgs {
+		if pkg.Types == nil || (pkgPath != "" && pkg.PkgPath != pkgPath) {
+			continue
+		}
+		if object, isTypeName := pkg.Types.Scope().Lookup(typeName).(*types.TypeName); isTypeName && types.IsInterface(object.Type()) {
+			found = append(found, pkg)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, "", fmt.Errorf("interface %s not found in the loaded packages", name)
+	case 1:
+		return found[0], typeName, nil
+	default:
+		return nil, "", fmt.Errorf("interface %s declared by several packages (%s and %s), qualify it with the package path", name, found[0].PkgPath, found[1].PkgPath)
+	}
+}
+
+// ParseImplementations returns the structs of the given packages implementing the given interface of the local package,
+// parsed with the given options, sorted by package path and name. See models.Implementation.
+// The generic structs are left aside, since only their instantiations can implement an interface.
+func ParseImplementations(pkgs []*packages.Package, local *packages.Package, interfaceName string, options Options) ([]models.Implementation, error) {
+	object, isTypeName := local.Types.Scope().Lookup(interfaceName).(*types.TypeName)
+	if !isTypeName {
+		return nil, fmt.Errorf("%s not found in package %s", interfaceName, local.Name)
+	}
+	iface, isInterface := object.Type().Underlying().(*types.Interface)
+	if !isInterface {
+		return nil, fmt.Errorf("%s is not an interface", interfaceName)
+	}
+
+	sorted := append([]*packages.Package{}, pkgs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PkgPath < sorted[j].PkgPath })
+	var implementations []models.Implementation
+	for _, pkg := range sorted {
+		if pkg.Types == nil {
+			continue
+		}
+		for _, name := range pkg.Types.Scope().Names() { // sorted
+			typeName, isTypeName := pkg.Types.Scope().Lookup(name).(*types.TypeName)
+			if !isTypeName || typeName.IsAlias() {
+				continue
+			}
+			named, isNamed := typeName.Type().(*types.Named)
+			if !isNamed || named.TypeParams().Len() != 0 {
+				continue
+			}
+			if _, isStruct := named.Underlying().(*types.Struct); !isStruct {
+				continue
+			}
+			pointerReceiver := false
+			if !implements(named, iface) {
+				if !implements(types.NewPointer(named), iface) {
+					continue
+				}
+				pointerReceiver = true
+			}
+
+			parsed, err := parse(pkg, name, options)
+			if err != nil {
+				return nil, err
+			}
+			if pkg.Types != local.Types {
+				parsed.Type.LocalName = parsed.Type.Name
+			}
+			implementations = append(implementations, models.Implementation{Element: parsed.Element, PointerReceiver: pointerReceiver})
+		}
+	}
+	return implementations, nil
+}
+
+// implements returns true if the method set of the given type has every method of the given interface.
+// The signatures are also compared by their fully qualified names, since the packages loaded together can be
+// type-checked separately, each one having its own types for the packages it imports.
+func implements(t types.Type, iface *types.Interface) bool {
+	methods := types.NewMethodSet(t)
+	qualifier := func(pkg *types.Package) string { return pkg.Path() }
+	for i := 0; i < iface.NumMethods(); i++ {
+		method := iface.Method(i)
+		selection := methods.Lookup(method.Pkg(), method.Name())
+		if selection == nil {
+			return false
+		}
+		if !types.Identical(selection.Type(), method.Type()) &&
+			types.TypeString(selection.Type(), qualifier) != types.TypeString(method.Type(), qualifier) {
+			return false
+		}
+	}
+	return true
+}
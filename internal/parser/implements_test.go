@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/leorolland/genz/internal/testutils"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestParseImplementationsSuccess(t *testing.T) {
+	testCases := map[string]struct {
+		goCode   string
+		expected []string
+	}{
+		"value and pointer receivers": {
+			goCode: `
+			package main
+
+			type Store interface {
+				Get(id string) (string, error)
+			}
+
+			type Mem struct{}
+
+			func (m *Mem) Get(id string) (string, error) { return "", nil }
+
+			type Static struct{}
+
+			func (Static) Get(id string) (string, error) { return "", nil }
+			`,
+			expected: []string{"*Mem", "Static"},
+		},
+		"promoted methods": {
+			goCode: `
+			package main
+
+			type Store interface {
+				Get(id string) (string, error)
+			}
+
+			type Static struct{}
+
+			func (Static) Get(id string) (string, error) { return "", nil }
+
+			type Cached struct {
+				Static
+			}
+			`,
+			expected: []string{"Cached", "Static"},
+		},
+		"other types left aside": {
+			goCode: `
+			package main
+
+			type Store interface {
+				Get(id string) (string, error)
+			}
+
+			type WrongSignature struct{}
+
+			func (WrongSignature) Get(id int) (string, error) { return "", nil }
+
+			type Generic[T any] struct{}
+
+			func (Generic[T]) Get(id string) (string, error) { return "", nil }
+
+			type Func func()
+
+			func (Func) Get(id string) (string, error) { return "", nil }
+			`,
+			expected: nil,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			pkg := testutils.CreatePkgWithCode(t, tc.goCode)
+
+			implementations, err := ParseImplementations([]*packages.Package{pkg}, pkg, "Store", Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, implementation := range implementations {
+				name := implementation.Type.LocalName
+				if implementation.PointerReceiver {
+					name = "*" + name
+				}
+				names = append(names, name)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("implementations don't match expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLookupInterface(t *testing.T) {
+	pkg := testutils.CreatePkgWithCode(t, `
+	package main
+
+	type Store interface{ Get() }
+
+	type Mem struct{}
+	`)
+	pkgs := []*packages.Package{pkg}
+
+	if found, name, err := LookupInterface(pkgs, "Store"); err != nil || found != pkg || name != "Store" {
+		t.Errorf("LookupInterface(Store) = %v, %s, %v", found, name, err)
+	}
+	if _, name, err := LookupInterface(pkgs, pkg.PkgPath+".Store"); err != nil || name != "Store" {
+		t.Errorf("LookupInterface(qualified Store) = %s, %v", name, err)
+	}
+	for _, name := range []string{"Mem", "Unknown", "example.com/other.Store"} {
+		if _, _, err := LookupInterface(pkgs, name); err == nil {
+			t.Errorf("LookupInterface(%s): expected an error", name)
+		}
+	}
+}
+
+func TestParseWithImplementations(t *testing.T) {
+	pkg := testutils.CreatePkgWithCode(t, `
+	package main
+
+	type Store interface{ Get() }
+
+	type Mem struct{ Size int }
+
+	func (m *Mem) Get() {}
+	`)
+
+	parsed, err := parse(pkg, "Store", Options{Implementations: true, ExcludeUnexported: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parsed.Implementations) != 1 || parsed.Implementations[0].Type.LocalName != "Mem" ||
+		!parsed.Implementations[0].PointerReceiver || len(parsed.Implementations[0].Attributes) != 1 {
+		t.Errorf("unexpected implementations of Store: %+v", parsed.Implementations)
+	}
+	parsed, err = parse(pkg, "Mem", Options{Implementations: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.Implementations != nil {
+		t.Errorf("unexpected implementations of the struct Mem: %+v", parsed.Implementations)
+	}
+}
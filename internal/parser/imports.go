@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"go/types"
+	"sort"
+)
+
+// elementImports returns the sorted import paths of the packages referenced by the given named type:
+// by its underlying type (e.g. the attributes of a struct), its methods and its type parameters.
+// The local package is excluded. It returns nil if no import is required.
+func elementImports(named *types.Named, local *types.Package) []string {
+	imports := map[string]bool{}
+	collectImports(named.Underlying(), local, imports)
+	for i := 0; i < named.NumMethods(); i++ {
+		collectImports(named.Method(i).Type(), local, imports)
+	}
+	for i := 0; i < named.TypeParams().Len(); i++ {
+		collectImports(named.TypeParams().At(i).Constraint(), local, imports)
+	}
+	if len(imports) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// collectImports adds to imports the import paths of the packages referenced by the given type,
+// except the local package. The underlying types of named types are not walked.
+func collectImports(t types.Type, local *types.Package, imports map[string]bool) {
+	switch t := unalias(t).(type) {
+	case *types.Named:
+		if pkg := t.Obj().Pkg(); pkg != nil && pkg != local {
+			imports[pkg.Path()] = true
+		}
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			collectImports(t.TypeArgs().At(i), local, imports)
+		}
+	case *types.Pointer:
+		collectImports(t.Elem(), local, imports)
+	case *types.Slice:
+		collectImports(t.Elem(), local, imports)
+	case *types.Array:
+		collectImports(t.Elem(), local, imports)
+	case *types.Chan:
+		collectImports(t.Elem(), local, imports)
+	case *types.Map:
+		collectImports(t.Key(), local, imports)
+		collectImports(t.Elem(), local, imports)
+	case *types.Signature:
+		collectImports(t.Params(), local, imports)
+		collectImports(t.Results(), local, imports)
+	case *types.Tuple:
+		for i := 0; i < t.Len(); i++ {
+			collectImports(t.At(i).Type(), local, imports)
+		}
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			collectImports(t.Field(i).Type(), local, imports)
+		}
+	case *types.Interface:
+		for i := 0; i < t.NumMethods(); i++ {
+			collectImports(t.Method(i).Type(), local, imports)
+		}
+	case *types.Union:
+		for i := 0; i < t.Len(); i++ {
+			collectImports(t.Term(i).Type(), local, imports)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leorolland/genz/internal/testutils"
+)
+
+func TestElementImports(t *testing.T) {
+	pkg := testutils.CreatePkgWithCode(t, `
+	package main
+
+	import (
+		"bytes"
+		"io"
+		"net/http"
+		"time"
+	)
+
+	type Local struct{}
+
+	type A[T io.Reader] struct {
+		durations []map[string]time.Duration
+		local     *Local
+		handler   func(http.ResponseWriter)
+	}
+
+	func (a A[T]) Buffer() *bytes.Buffer {
+		return nil
+	}
+	`)
+	named, err := objectAsNamedType(pkg.Types.Scope().Lookup("A"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"bytes", "io", "net/http", "time"}
+	if imports := elementImports(named, pkg.Types); !reflect.DeepEqual(imports, expected) {
+		t.Errorf("elementImports() = %v, want %v", imports, expected)
+	}
+}
@@ -9,7 +9,8 @@ import (
 )
 
 // parseInterface parses the given interface and returns a models.Element.
-// It also parses the subInterfaces of the given interface and the methods of the subInterfaces recursively.
+// It also parses the subInterfaces of the given interface and the methods of the subInterfaces recursively,
+// including the interfaces embedded from other packages (e.g. io.Reader).
 func parseInterface(pkg *packages.Package, interfaceName string, interfaceType *ast.InterfaceType) (models.Element, error) {
 
 	parsedInterface, err := parseElementType(pkg, interfaceName)
@@ -17,34 +18,45 @@ func parseInterface(pkg *packages.Package, interfaceName string, interfaceType *
 		return models.Element{}, err
 	}
 
+	if object := pkg.Types.Scope().Lookup(interfaceName); object != nil {
+		namedType, err := objectAsNamedType(object)
+		if err != nil {
+			return models.Element{}, err
+		}
+		parsedInterface.TypeParams = parseTypeParams(namedType.TypeParams(), pkg.Types)
+	}
+
 	var methods []models.Method
+	seen := map[string]bool{}
 
 	for _, method := range interfaceType.Methods.List {
-		switch pkg.TypesInfo.TypeOf(method.Type).(type) {
+		comments, directives := parseDoc(method.Doc)
+
+		switch methodType := pkg.TypesInfo.TypeOf(method.Type).(type) {
 		case *types.Signature:
-			methodModel, err := parseMethod(method.Names[0].Name, pkg.TypesInfo.TypeOf(method.Type).(*types.Signature))
+			methodModel, err := parseMethod(method.Names[0].Name, methodType, pkg.Types)
 			if err != nil {
 				return models.Element{}, err
 			}
-			if method.Doc != nil {
-				for _, comment := range method.Doc.List {
-					methodModel.Comments = append(methodModel.Comments, comment.Text[2:])
-				}
-			}
+			methodModel.Comments = append(methodModel.Comments, comments...)
+			methodModel.Directives = directives
+			seen[methodModel.Name] = true
 			methods = append(methods, methodModel)
-		case *types.Named: // Embedded interface
-			namedType := pkg.TypesInfo.TypeOf(method.Type).(*types.Named)
-			iface := namedType.Origin().Underlying().(*types.Interface).Complete()
+		default: // Embedded interface, declared in this package or another one, through an alias or inline.
+			iface, isInterface := methodType.Underlying().(*types.Interface)
+			if !isInterface { // e.g. a type set of a constraint, "~int | ~string"
+				continue
+			}
 			for i := 0; i < iface.NumMethods(); i++ {
-				methodModel, err := parseMethod(iface.Method(i).Name(), iface.Method(i).Type().(*types.Signature))
+				if seen[iface.Method(i).Name()] {
+					continue
+				}
+				methodModel, err := parseMethod(iface.Method(i).Name(), iface.Method(i).Type().(*types.Signature), pkg.Types)
 				if err != nil {
 					return models.Element{}, err
 				}
-				if method.Doc != nil {
-					for _, comment := range method.Doc.List {
-						methodModel.Comments = append(methodModel.Comments, comment.Text[2:])
-					}
-				}
+				methodModel.Comments = append(methodModel.Comments, comments...)
+				seen[methodModel.Name] = true
 				methods = append(methods, methodModel)
 			}
 		}
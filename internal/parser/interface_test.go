@@ -24,7 +24,7 @@ func TestParseInterfaceSuccess(t *testing.T) {
 			`,
 			interfaceName: "A",
 			expectedInterface: models.Element{
-				Type:    models.Type{Name: "main.A", InternalName: "A"},
+				Type:    models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Methods: nil,
 			},
 		},
@@ -38,12 +38,14 @@ func TestParseInterfaceSuccess(t *testing.T) {
 			`,
 			interfaceName: "A",
 			expectedInterface: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Methods: []models.Method{
 					{
 						Name:              "Foo",
 						Params:            []models.Type{},
 						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
 						IsPointerReceiver: false,
 						IsExported:        true,
 						Comments:          []string{},
@@ -62,12 +64,14 @@ func TestParseInterfaceSuccess(t *testing.T) {
 			`,
 			interfaceName: "A",
 			expectedInterface: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Methods: []models.Method{
 					{
 						Name:              "Foo",
 						Params:            []models.Type{},
 						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
 						IsPointerReceiver: false,
 						IsExported:        true,
 						Comments:          []string{},
@@ -76,6 +80,8 @@ func TestParseInterfaceSuccess(t *testing.T) {
 						Name:              "Bar",
 						Params:            []models.Type{},
 						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
 						IsPointerReceiver: false,
 						IsExported:        true,
 						Comments:          []string{},
@@ -94,12 +100,14 @@ func TestParseInterfaceSuccess(t *testing.T) {
 			`,
 			interfaceName: "A",
 			expectedInterface: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Methods: []models.Method{
 					{
 						Name:              "Foo",
 						Params:            []models.Type{},
 						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
 						IsPointerReceiver: false,
 						IsExported:        true,
 						Comments:          []string{"Foo does something"},
@@ -107,6 +115,34 @@ func TestParseInterfaceSuccess(t *testing.T) {
 				},
 			},
 		},
+		"interface with one method with directives": {
+			goCode: `
+			package main
+
+			type A interface {
+				//Foo does something
+				//genz:http GET /foo
+				Foo()
+			}
+			`,
+			interfaceName: "A",
+			expectedInterface: models.Element{
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+				Methods: []models.Method{
+					{
+						Name:              "Foo",
+						Params:            []models.Type{},
+						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
+						IsPointerReceiver: false,
+						IsExported:        true,
+						Comments:          []string{"Foo does something"},
+						Directives:        map[string]string{"http": "GET /foo"},
+					},
+				},
+			},
+		},
 		"interface with one method with params": {
 			goCode: `
 			package main
@@ -117,12 +153,14 @@ func TestParseInterfaceSuccess(t *testing.T) {
 			`,
 			interfaceName: "A",
 			expectedInterface: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Methods: []models.Method{
 					{
 						Name:              "Foo",
-						Params:            []models.Type{{Name: "int", InternalName: "int"}, {Name: "string", InternalName: "string"}},
+						Params:            []models.Type{{Name: "int", InternalName: "int", LocalName: "int"}, {Name: "string", InternalName: "string", LocalName: "string"}},
 						Returns:           []models.Type{},
+						ParamNames:        []string{"a", "b"},
+						ReturnNames:       []string{},
 						IsPointerReceiver: false,
 						IsExported:        true,
 						Comments:          []string{},
@@ -140,12 +178,14 @@ func TestParseInterfaceSuccess(t *testing.T) {
 			`,
 			interfaceName: "A",
 			expectedInterface: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Methods: []models.Method{
 					{
 						Name:              "Foo",
 						Params:            []models.Type{},
-						Returns:           []models.Type{{Name: "int", InternalName: "int"}, {Name: "string", InternalName: "string"}},
+						Returns:           []models.Type{{Name: "int", InternalName: "int", LocalName: "int"}, {Name: "string", InternalName: "string", LocalName: "string"}},
+						ParamNames:        []string{},
+						ReturnNames:       []string{"", ""},
 						IsPointerReceiver: false,
 						IsExported:        true,
 						Comments:          []string{},
@@ -169,12 +209,14 @@ func TestParseInterfaceSuccess(t *testing.T) {
 			`,
 			interfaceName: "B",
 			expectedInterface: models.Element{
-				Type: models.Type{Name: "main.B", InternalName: "B"},
+				Type: models.Type{Name: "main.B", InternalName: "B", LocalName: "B", PkgPath: "command-line-arguments"},
 				Methods: []models.Method{
 					{
 						Name:              "Foo",
 						Params:            []models.Type{},
-						Returns:           []models.Type{{Name: "int", InternalName: "int"}, {Name: "string", InternalName: "string"}},
+						Returns:           []models.Type{{Name: "int", InternalName: "int", LocalName: "int"}, {Name: "string", InternalName: "string", LocalName: "string"}},
+						ParamNames:        []string{},
+						ReturnNames:       []string{"", ""},
 						IsPointerReceiver: false,
 						IsExported:        true,
 						Comments:          []string{" A is a sub interface"},
@@ -183,6 +225,158 @@ func TestParseInterfaceSuccess(t *testing.T) {
 						Name:              "Bar",
 						Params:            []models.Type{},
 						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
+						IsPointerReceiver: false,
+						IsExported:        true,
+						Comments:          []string{},
+					},
+				},
+			},
+		},
+		"interface with sub interfaces from another package": {
+			goCode: `
+			package main
+
+			import (
+				"fmt"
+				"io"
+			)
+
+			type Stringer = fmt.Stringer
+
+			type A interface {
+				io.Closer
+				Stringer
+				interface{ Bar() }
+			}
+			`,
+			interfaceName: "A",
+			expectedInterface: models.Element{
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+				Methods: []models.Method{
+					{
+						Name:              "Close",
+						Params:            []models.Type{},
+						Returns:           []models.Type{{Name: "error", InternalName: "error", LocalName: "error"}},
+						ReturnsError:      true,
+						ParamNames:        []string{},
+						ReturnNames:       []string{""},
+						IsPointerReceiver: false,
+						IsExported:        true,
+						Comments:          []string{},
+					},
+					{
+						Name:              "String",
+						Params:            []models.Type{},
+						Returns:           []models.Type{{Name: "string", InternalName: "string", LocalName: "string"}},
+						ParamNames:        []string{},
+						ReturnNames:       []string{""},
+						IsPointerReceiver: false,
+						IsExported:        true,
+						Comments:          []string{},
+					},
+					{
+						Name:              "Bar",
+						Params:            []models.Type{},
+						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
+						IsPointerReceiver: false,
+						IsExported:        true,
+						Comments:          []string{},
+					},
+				},
+			},
+		},
+		"interface with a variadic method": {
+			goCode: `
+			package main
+
+			type A interface {
+				Foo(format string, args ...any)
+			}
+			`,
+			interfaceName: "A",
+			expectedInterface: models.Element{
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+				Methods: []models.Method{
+					{
+						Name: "Foo",
+						Params: []models.Type{
+							{Name: "string", InternalName: "string", LocalName: "string"},
+							{
+								Name: "[]any", InternalName: "[]any", LocalName: "[]any", IsSlice: true, IsVariadic: true,
+								Elem: &models.Type{Name: "any", InternalName: "any", LocalName: "any"},
+							},
+						},
+						Returns:           []models.Type{},
+						ParamNames:        []string{"format", "args"},
+						ReturnNames:       []string{},
+						IsPointerReceiver: false,
+						IsExported:        true,
+						Comments:          []string{},
+					},
+				},
+			},
+		},
+		"generic interface": {
+			goCode: `
+			package main
+
+			type Store[K comparable, V any] interface {
+				Get(K) (V, bool)
+			}
+			`,
+			interfaceName: "Store",
+			expectedInterface: models.Element{
+				Type: models.Type{Name: "main.Store", InternalName: "Store", LocalName: "Store", PkgPath: "command-line-arguments"},
+				Methods: []models.Method{
+					{
+						Name:   "Get",
+						Params: []models.Type{{Name: "K", InternalName: "K", LocalName: "K"}},
+						Returns: []models.Type{
+							{Name: "V", InternalName: "V", LocalName: "V"},
+							{Name: "bool", InternalName: "bool", LocalName: "bool"},
+						},
+						ParamNames:        []string{""},
+						ReturnNames:       []string{"", ""},
+						IsPointerReceiver: false,
+						IsExported:        true,
+						Comments:          []string{},
+					},
+				},
+				TypeParams: []models.TypeParam{
+					{Name: "K", Constraint: models.Type{Name: "comparable", InternalName: "comparable", LocalName: "comparable"}},
+					{Name: "V", Constraint: models.Type{Name: "any", InternalName: "any", LocalName: "any"}},
+				},
+			},
+		},
+		"interface embedding an instantiated generic interface": {
+			goCode: `
+			package main
+
+			type Store[K comparable, V any] interface {
+				Get(K) (V, bool)
+			}
+
+			type Users interface {
+				Store[string, int]
+			}
+			`,
+			interfaceName: "Users",
+			expectedInterface: models.Element{
+				Type: models.Type{Name: "main.Users", InternalName: "Users", LocalName: "Users", PkgPath: "command-line-arguments"},
+				Methods: []models.Method{
+					{
+						Name:   "Get",
+						Params: []models.Type{{Name: "string", InternalName: "string", LocalName: "string"}},
+						Returns: []models.Type{
+							{Name: "int", InternalName: "int", LocalName: "int"},
+							{Name: "bool", InternalName: "bool", LocalName: "bool"},
+						},
+						ParamNames:        []string{""},
+						ReturnNames:       []string{"", ""},
 						IsPointerReceiver: false,
 						IsExported:        true,
 						Comments:          []string{},
@@ -199,12 +393,14 @@ func TestParseInterfaceSuccess(t *testing.T) {
 			}`,
 			interfaceName: "A",
 			expectedInterface: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Methods: []models.Method{
 					{
 						Name:              "Foo",
-						Params:            []models.Type{{Name: "int", InternalName: "int"}, {Name: "string", InternalName: "string"}},
+						Params:            []models.Type{{Name: "int", InternalName: "int", LocalName: "int"}, {Name: "string", InternalName: "string", LocalName: "string"}},
 						Returns:           []models.Type{},
+						ParamNames:        []string{"a", "b"},
+						ReturnNames:       []string{},
 						IsPointerReceiver: false,
 						IsExported:        true,
 						Comments:          []string{},
@@ -223,12 +419,15 @@ func TestParseInterfaceSuccess(t *testing.T) {
 			}`,
 			interfaceName: "A",
 			expectedInterface: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type:    models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+				Imports: []string{"github.com/google/uuid"},
 				Methods: []models.Method{
 					{
 						Name:              "Foo",
-						Params:            []models.Type{{Name: "uuid.UUID", InternalName: "UUID"}},
+						Params:            []models.Type{{Name: "uuid.UUID", InternalName: "UUID", LocalName: "uuid.UUID", PkgPath: "github.com/google/uuid"}},
 						Returns:           []models.Type{},
+						ParamNames:        []string{"a"},
+						ReturnNames:       []string{},
 						IsPointerReceiver: false,
 						IsExported:        true,
 						Comments:          []string{},
@@ -247,12 +446,14 @@ func TestParseInterfaceSuccess(t *testing.T) {
 			}`,
 			interfaceName: "A",
 			expectedInterface: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Methods: []models.Method{
 					{
 						Name:              "Foo",
 						Params:            []models.Type{},
 						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
 						IsPointerReceiver: false,
 						IsExported:        true,
 						Comments:          []string{" Foo does something", " Foo does something else"},
@@ -269,12 +470,14 @@ func TestParseInterfaceSuccess(t *testing.T) {
 			}`,
 			interfaceName: "A",
 			expectedInterface: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Methods: []models.Method{
 					{
 						Name:              "foo",
 						Params:            []models.Type{},
 						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
 						IsPointerReceiver: false,
 						IsExported:        false,
 						Comments:          []string{},
@@ -1,48 +1,168 @@
 package parser
 
 import (
+	"fmt"
 	"go/ast"
 	"go/doc"
 	"go/types"
 	"strings"
 
 	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
 )
 
-func parseMethod(name string, signature *types.Signature) (models.Method, error) {
-	return parseMethodWithComments(nil, name, signature)
+// parseNamedTypeMethods returns the methods declared on the named type typeName, with their doc comments.
+// It returns nil if the type has no method.
+func parseNamedTypeMethods(pkg *packages.Package, typeName string) ([]models.Method, error) {
+	object := pkg.Types.Scope().Lookup(typeName)
+	if object == nil {
+		return nil, fmt.Errorf("%s not found in package %s", typeName, pkg.Name)
+	}
+	namedType, err := objectAsNamedType(object)
+	if err != nil {
+		return nil, err
+	}
+	if namedType.NumMethods() == 0 {
+		return nil, nil
+	}
+
+	pkgDoc, err := doc.NewFromFiles(pkg.Fset, pkg.Syntax, "./", doc.AllDecls|doc.PreserveAST)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create doc while parsing %s: %w", typeName, err)
+	}
+
+	var methods []models.Method
+	for i := 0; i < namedType.NumMethods(); i++ {
+		method, err := parseMethodWithComments(
+			getFuncDoc(pkgDoc, typeName, namedType.Method(i).Name()),
+			namedType.Method(i).Name(),
+			namedType.Method(i).Type().(*types.Signature),
+			pkg.Types,
+		)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, method)
+	}
+	return methods, nil
+}
+
+func parseMethod(name string, signature *types.Signature, local *types.Package) (models.Method, error) {
+	return parseMethodWithComments(nil, name, signature, local)
 }
 
-func parseMethodWithComments(doc *doc.Func, name string, signature *types.Signature) (models.Method, error) {
+func parseMethodWithComments(doc *doc.Func, name string, signature *types.Signature, local *types.Package) (models.Method, error) {
 	comments := []string{}
+	var directives map[string]string
 	if doc != nil && doc.Doc != "" {
 		comments = strings.Split(strings.Trim(doc.Doc, "\n"), "\n")
 	}
+	if doc != nil && doc.Decl != nil {
+		_, directives = parseDoc(doc.Decl.Doc)
+	}
+
+	params, returns := signatureTypes(signature, local)
+
+	_, isPointerReceiver := signature.Recv().Type().(*types.Pointer)
+	receiverName := signature.Recv().Name()
+	if _, isInterface := signature.Recv().Type().Underlying().(*types.Interface); isInterface || receiverName == "_" {
+		receiverName = ""
+	}
+
+	return models.Method{
+		Name:                name,
+		IsExported:          ast.IsExported(name),
+		IsPointerReceiver:   isPointerReceiver,
+		ReceiverName:        receiverName,
+		FirstParamIsContext: signature.Params().Len() > 0 && isContext(signature.Params().At(0).Type()),
+		ReturnsError:        signature.Results().Len() > 0 && isError(signature.Results().At(signature.Results().Len()-1).Type()),
+		Panics:              doc != nil && doc.Decl != nil && callsPanic(doc.Decl.Body),
+		Params:              params,
+		Returns:             returns,
+		ParamNames:          tupleNames(signature.Params()),
+		ReturnNames:         tupleNames(signature.Results()),
+		TypeParams:          parseTypeParams(signature.RecvTypeParams(), local),
+		Comments:            comments,
+		Directives:          directives,
+	}, nil
+}
+
+// isContext returns true if the given type is context.Context.
+func isContext(t types.Type) bool {
+	named, isNamed := unalias(t).(*types.Named)
+	return isNamed && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+// isError returns true if the given type is the predeclared error interface.
+func isError(t types.Type) bool {
+	return types.Identical(unalias(t), types.Universe.Lookup("error").Type())
+}
 
-	params := []models.Type{}
-	if signature.Params() != nil {
-		params = make([]models.Type, signature.Params().Len())
-		for j := 0; j < signature.Params().Len(); j++ {
-			params[j] = parseType(signature.Params().At(j).Type())
+// callsPanic returns true if the given function body calls panic, outside of the function literals it declares.
+func callsPanic(body *ast.BlockStmt) bool {
+	if body == nil {
+		return false
+	}
+	found := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if ident, isIdent := node.Fun.(*ast.Ident); isIdent && ident.Name == "panic" {
+				found = true
+			}
 		}
+		return !found
+	})
+	return found
+}
+
+// signatureTypes returns the types of the params and of the returns of the given signature.
+// The last param of a variadic signature is flagged IsVariadic, e.g. "...string" => {Name: "[]string", IsVariadic: true}
+func signatureTypes(signature *types.Signature, local *types.Package) ([]models.Type, []models.Type) {
+	params := tupleTypes(signature.Params(), local)
+	if signature.Variadic() {
+		params[len(params)-1].IsVariadic = true
+	}
+	return params, tupleTypes(signature.Results(), local)
+}
+
+// tupleTypes returns the types of the given params or results.
+func tupleTypes(tuple *types.Tuple, local *types.Package) []models.Type {
+	result := make([]models.Type, tuple.Len())
+	for i := 0; i < tuple.Len(); i++ {
+		result[i] = parseType(tuple.At(i).Type(), local)
+	}
+	return result
+}
+
+// tupleNames returns the names of the given params or results. A name is empty when unnamed.
+func tupleNames(tuple *types.Tuple) []string {
+	names := make([]string, tuple.Len())
+	for i := 0; i < tuple.Len(); i++ {
+		names[i] = tuple.At(i).Name()
 	}
+	return names
+}
 
-	returns := []models.Type{}
-	if signature.Results() != nil {
-		returns = make([]models.Type, signature.Results().Len())
-		for j := 0; j < signature.Results().Len(); j++ {
-			returns[j] = parseType(signature.Results().At(j).Type())
+func getFuncDoc(pkgDoc *doc.Package, structName, funcName string) *doc.Func {
+	var typeDoc *doc.Type
+	for _, t := range pkgDoc.Types {
+		if t.Name == structName {
+			typeDoc = t
 		}
 	}
 
-	_, isPointerReceiver := signature.Recv().Type().(*types.Pointer)
+	if typeDoc == nil {
+		return nil
+	}
 
-	return models.Method{
-		Name:              name,
-		IsExported:        ast.IsExported(name),
-		IsPointerReceiver: isPointerReceiver,
-		Params:            params,
-		Returns:           returns,
-		Comments:          comments,
-	}, nil
+	for _, m := range typeDoc.Methods {
+		if m.Name == funcName {
+			return m
+		}
+	}
+
+	return nil
 }
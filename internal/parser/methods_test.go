@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leorolland/genz/internal/testutils"
+	"github.com/leorolland/genz/pkg/models"
+)
+
+func TestParseMethodsMetadata(t *testing.T) {
+	pkg := testutils.CreatePkgWithCode(t, `
+	package main
+
+	import "context"
+
+	type Ctx = context.Context
+
+	type A struct{}
+
+	func (a *A) Get(ctx context.Context, id string) (string, error) { return id, nil }
+	func (A) Must(ctx Ctx) string {
+		if ctx == nil {
+			panic("nil context")
+		}
+		return ""
+	}
+	func (_ A) Later() func() {
+		return func() { panic("later") }
+	}
+
+	type I interface {
+		Close() error
+	}
+	`)
+	testCases := map[string]struct {
+		typeName string
+		expected map[string]models.Method
+	}{
+		"struct": {
+			typeName: "A",
+			expected: map[string]models.Method{
+				"Get":   {ReceiverName: "a", FirstParamIsContext: true, ReturnsError: true},
+				"Must":  {FirstParamIsContext: true, Panics: true},
+				"Later": {},
+			},
+		},
+		"interface": {
+			typeName: "I",
+			expected: map[string]models.Method{
+				"Close": {ReturnsError: true},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			parsedElement, err := Parser(pkg, tc.typeName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(parsedElement.Methods) != len(tc.expected) {
+				t.Fatalf("expected %d methods, got %d", len(tc.expected), len(parsedElement.Methods))
+			}
+			for _, method := range parsedElement.Methods {
+				expected := tc.expected[method.Name]
+				actual := models.Method{
+					ReceiverName:        method.ReceiverName,
+					FirstParamIsContext: method.FirstParamIsContext,
+					ReturnsError:        method.ReturnsError,
+					Panics:              method.Panics,
+				}
+				if !reflect.DeepEqual(actual, expected) {
+					t.Errorf("%s: expected %+v, got %+v", method.Name, expected, actual)
+				}
+			}
+		})
+	}
+}
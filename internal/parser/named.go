@@ -0,0 +1,30 @@
+package parser
+
+import (
+	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
+)
+
+// parseNamedType parses a named type that is neither a struct nor an interface (e.g. "type UserID string")
+// and returns a models.Element with its underlying type, its methods and the constants declared with this type.
+func parseNamedType(pkg *packages.Package, typeName string) (models.Element, error) {
+	parsedType, err := parseElementType(pkg, typeName)
+	if err != nil {
+		return models.Element{}, err
+	}
+
+	namedType, err := objectAsNamedType(pkg.Types.Scope().Lookup(typeName))
+	if err != nil {
+		return models.Element{}, err
+	}
+	parsedType.Underlying = parseType(namedType.Underlying(), pkg.Types)
+	parsedType.TypeParams = parseTypeParams(namedType.TypeParams(), pkg.Types)
+	parsedType.EnumValues = parseEnumValues(pkg, namedType)
+
+	methods, err := parseNamedTypeMethods(pkg, typeName)
+	if err != nil {
+		return models.Element{}, err
+	}
+	parsedType.Methods = methods
+	return parsedType, nil
+}
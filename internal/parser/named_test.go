@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leorolland/genz/internal/testutils"
+	"github.com/leorolland/genz/pkg/models"
+)
+
+func TestParseNamedTypeSuccess(t *testing.T) {
+	testCases := map[string]struct {
+		goCode       string
+		typeName     string
+		expectedType models.Element
+	}{
+		"basic named type": {
+			goCode: `
+			package main
+
+			type UserID string
+			`,
+			typeName: "UserID",
+			expectedType: models.Element{
+				Type:       models.Type{Name: "main.UserID", InternalName: "UserID", LocalName: "UserID", PkgPath: "command-line-arguments"},
+				Underlying: models.Type{Name: "string", InternalName: "string", LocalName: "string"},
+			},
+		},
+		"named type over an imported type": {
+			goCode: `
+			package main
+
+			import "time"
+
+			type Timeout time.Duration
+			`,
+			typeName: "Timeout",
+			expectedType: models.Element{
+				Type:       models.Type{Name: "main.Timeout", InternalName: "Timeout", LocalName: "Timeout", PkgPath: "command-line-arguments"},
+				Underlying: models.Type{Name: "int64", InternalName: "int64", LocalName: "int64"},
+			},
+		},
+		"named slice type": {
+			goCode: `
+			package main
+
+			type A struct {}
+			type List []A
+			`,
+			typeName: "List",
+			expectedType: models.Element{
+				Type:       models.Type{Name: "main.List", InternalName: "List", LocalName: "List", PkgPath: "command-line-arguments"},
+				Underlying: models.Type{Name: "[]main.A", InternalName: "[]A", LocalName: "[]A", IsSlice: true, Elem: &models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"}},
+			},
+		},
+		"named type with iota constants": {
+			goCode: `
+			package main
+
+			type Color int
+
+			const (
+				// Red is the first color.
+				Red Color = iota
+				Green // genz:"green"
+				blue // the last one
+			)
+
+			const Unrelated = 3
+			`,
+			typeName: "Color",
+			expectedType: models.Element{
+				Type:       models.Type{Name: "main.Color", InternalName: "Color", LocalName: "Color", PkgPath: "command-line-arguments"},
+				Underlying: models.Type{Name: "int", InternalName: "int", LocalName: "int"},
+				EnumValues: []models.EnumValue{
+					{Name: "Red", Value: "0", IsExported: true, Comments: []string{" Red is the first color."}},
+					{Name: "Green", Value: "1", IsExported: true, Comments: []string{}, Tags: map[string]string{"genz": "green"}, ParsedTags: map[string]models.Tag{"genz": {Value: "green", Name: "green"}}},
+					{Name: "blue", Value: "2", IsExported: false, Comments: []string{}},
+				},
+			},
+		},
+		"named type with string constants": {
+			goCode: `
+			package main
+
+			type Status string
+
+			const StatusActive Status = "active"
+
+			const (
+				StatusDisabled Status = "disabled"
+				other = "other"
+			)
+			`,
+			typeName: "Status",
+			expectedType: models.Element{
+				Type:       models.Type{Name: "main.Status", InternalName: "Status", LocalName: "Status", PkgPath: "command-line-arguments"},
+				Underlying: models.Type{Name: "string", InternalName: "string", LocalName: "string"},
+				EnumValues: []models.EnumValue{
+					{Name: "StatusActive", Value: `"active"`, IsExported: true, Comments: []string{}},
+					{Name: "StatusDisabled", Value: `"disabled"`, IsExported: true, Comments: []string{}},
+				},
+			},
+		},
+		"named type with methods": {
+			goCode: `
+			package main
+
+			type Temperature float64
+
+			// Celsius returns the temperature in celsius.
+			func (t Temperature) Celsius() float64 {
+				return float64(t)
+			}
+
+			func (t *Temperature) Set(v float64) {
+				*t = Temperature(v)
+			}
+			`,
+			typeName: "Temperature",
+			expectedType: models.Element{
+				Type:       models.Type{Name: "main.Temperature", InternalName: "Temperature", LocalName: "Temperature", PkgPath: "command-line-arguments"},
+				Underlying: models.Type{Name: "float64", InternalName: "float64", LocalName: "float64"},
+				Methods: []models.Method{
+					{
+						Name:              "Celsius",
+						IsExported:        true,
+						IsPointerReceiver: false,
+						ReceiverName:      "t",
+						Params:            []models.Type{},
+						Returns:           []models.Type{{Name: "float64", InternalName: "float64", LocalName: "float64"}},
+						ParamNames:        []string{},
+						ReturnNames:       []string{""},
+						Comments:          []string{"Celsius returns the temperature in celsius."},
+					},
+					{
+						Name:              "Set",
+						IsExported:        true,
+						IsPointerReceiver: true,
+						ReceiverName:      "t",
+						Params:            []models.Type{{Name: "float64", InternalName: "float64", LocalName: "float64"}},
+						Returns:           []models.Type{},
+						ParamNames:        []string{"v"},
+						ReturnNames:       []string{},
+						Comments:          []string{},
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pkg := testutils.CreatePkgWithCode(t, tc.goCode)
+
+			gotType, err := parseNamedType(pkg, tc.typeName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(gotType, tc.expectedType) {
+				t.Fatalf("output type doesn't match expected:\n%s", cmp.Diff(gotType, tc.expectedType))
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"go/types"
+
+	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
+)
+
+// Options tunes the parsing of an element.
+// The zero value parses the element as it is declared in the source code.
+type Options struct {
+	// FlattenEmbedded replaces the embedded struct attributes by the attributes they promote, recursively.
+	FlattenEmbedded bool
+	// Recursive parses the struct type of every attribute declared in the same module and attaches it to the attribute,
+	// and the ones of the params and the results of the methods of the element to the methods.
+	Recursive bool
+	// Functions parses the top-level functions of the package into models.ParsedElement.Functions.
+	// With this option, the type name can be empty to parse only the package.
+	Functions bool
+	// Values parses the exported constants and variables of the package into models.ParsedElement.Package.
+	// With this option, the type name can be empty to parse only the package.
+	Values bool
+	// Implementations fills models.ParsedElement.Implementations of an interface with the structs of its package
+	// implementing it, parsed with the other options.
+	Implementations bool
+	// Positions fills the source positions of the element, of its attributes and of its methods.
+	// See models.Position for more details.
+	Positions bool
+	// IncludeTags keeps only the attributes having one of these tags: a tag key, e.g. "json", or a tag key and the name
+	// of the tag, e.g. `db:"id"`.
+	IncludeTags []string
+	// ExcludeTags removes the attributes having one of these tags, e.g. `genz:"-"` or `json:"-"`. See IncludeTags.
+	ExcludeTags []string
+	// ExcludeUnexported removes the unexported attributes.
+	ExcludeUnexported bool
+}
+
+// applyStructOptions applies the given options to the parsed struct element.
+// fieldTypes holds the types of the element attributes, in the same order.
+func applyStructOptions(
+	pkg *packages.Package,
+	named *types.Named,
+	element models.Element,
+	fieldTypes []types.Type,
+	options Options,
+	seen map[*types.TypeName]bool,
+) (models.Element, error) {
+	var err error
+	if options.FlattenEmbedded {
+		element.Attributes, err = flattenEmbedded(pkg, element.Attributes, fieldTypes, map[string]bool{})
+		if err != nil {
+			return models.Element{}, err
+		}
+	}
+	element.Attributes, err = filterAttributes(element.Attributes, options)
+	if err != nil {
+		return models.Element{}, err
+	}
+	if options.Recursive {
+		element.Attributes, err = resolveAttributes(pkg, named, element.Attributes, options, seen)
+		if err != nil {
+			return models.Element{}, err
+		}
+	}
+	return element, nil
+}
@@ -1,21 +1,43 @@
 package parser
 
 import (
+	"go/types"
+	"sort"
+
 	"github.com/leorolland/genz/pkg/models"
 	"golang.org/x/tools/go/packages"
 )
 
 // parsePackage returns a models.ParsedElement from the given *packages.Package.
-// It does not parse the package's elements. Only the package's name and imports are parsed.
+// It does not parse the package's elements. Only the package's name, paths, type names and imports are parsed.
+// The imports are sorted, so that the result does not depend on the map iteration order of pkg.Imports.
 func parsePackage(pkg *packages.Package) (models.ParsedElement, error) {
 	parsedPackage := models.ParsedElement{
 		PackageName:    pkg.Name,
 		PackageImports: []string{},
+		Package: models.Package{
+			Name:      pkg.Name,
+			Path:      pkg.PkgPath,
+			TypeNames: []string{},
+		},
+	}
+	if pkg.Module != nil {
+		parsedPackage.Package.ModulePath = pkg.Module.Path
+		parsedPackage.Package.GoVersion = pkg.Module.GoVersion
+	}
+	if pkg.Types != nil {
+		// The names of the scope are sorted.
+		for _, name := range pkg.Types.Scope().Names() {
+			if _, isTypeName := pkg.Types.Scope().Lookup(name).(*types.TypeName); isTypeName {
+				parsedPackage.Package.TypeNames = append(parsedPackage.Package.TypeNames, name)
+			}
+		}
 	}
 
 	for i := range pkg.Imports {
 		parsedPackage.PackageImports = append(parsedPackage.PackageImports, i)
 	}
+	sort.Strings(parsedPackage.PackageImports)
 
 	return parsedPackage, nil
 }
@@ -1,7 +1,7 @@
 package parser
 
 import (
-	"sort"
+	"reflect"
 	"testing"
 
 	"github.com/leorolland/genz/internal/testutils"
@@ -44,7 +44,23 @@ func TestParsePackageSuccess(t *testing.T) {
 			`,
 			expectedPackage: models.ParsedElement{
 				PackageName:    "main",
-				PackageImports: []string{"time", "fmt"},
+				PackageImports: []string{"fmt", "time"},
+			},
+		},
+		"package with unordered imports": {
+			goCode: `
+			package main
+
+			import (
+				"time"
+				"strings"
+				"fmt"
+				"bytes"
+			)
+			`,
+			expectedPackage: models.ParsedElement{
+				PackageName:    "main",
+				PackageImports: []string{"bytes", "fmt", "strings", "time"},
 			},
 		},
 		"package with one import alias": {
@@ -76,8 +92,6 @@ func TestParsePackageSuccess(t *testing.T) {
 			if len(parsedPackage.PackageImports) != len(tc.expectedPackage.PackageImports) {
 				t.Fatalf("expected %d imports, got %d", len(tc.expectedPackage.PackageImports), len(parsedPackage.PackageImports))
 			}
-			sort.Strings(parsedPackage.PackageImports)
-			sort.Strings(tc.expectedPackage.PackageImports)
 			for i := range parsedPackage.PackageImports {
 				if parsedPackage.PackageImports[i] != tc.expectedPackage.PackageImports[i] {
 					t.Fatalf("expected import %s, got %s", tc.expectedPackage.PackageImports[i], parsedPackage.PackageImports[i])
@@ -86,3 +100,31 @@ func TestParsePackageSuccess(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePackageTypeNames(t *testing.T) {
+	pkg := testutils.CreatePkgWithCode(t, `
+	package main
+
+	type User struct{}
+
+	type ID = string
+
+	type Color int
+
+	const Red Color = 0
+
+	func NewUser() User { return User{} }
+	`)
+
+	parsedPackage, err := parsePackage(pkg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsedPackage.Package.Name != "main" || parsedPackage.Package.Path != pkg.PkgPath {
+		t.Errorf("expected the package main of path %s, got %+v", pkg.PkgPath, parsedPackage.Package)
+	}
+	expected := []string{"Color", "ID", "User"}
+	if !reflect.DeepEqual(parsedPackage.Package.TypeNames, expected) {
+		t.Errorf("expected the type names %v, got %v", expected, parsedPackage.Package.TypeNames)
+	}
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"go/ast"
+	"go/types"
 
 	"github.com/leorolland/genz/pkg/models"
 	"golang.org/x/tools/go/packages"
@@ -11,10 +12,44 @@ import (
 // Parser returns a models.ParsedElement from the given *packages.Package and type name.
 // It's the main entry point for the different parsers (struct, interface, etc).
 func Parser(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+	return parse(pkg, typeName, Options{})
+}
+
+// WithOptions returns a Parser applying the given options.
+func WithOptions(options Options) func(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+	return func(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+		return parse(pkg, typeName, options)
+	}
+}
+
+func parse(pkg *packages.Package, typeName string, options Options) (models.ParsedElement, error) {
 	parsedElement, err := parsePackage(pkg)
 	if err != nil {
 		return models.ParsedElement{}, err
 	}
+	if options.Functions {
+		parsedElement.Functions = parseFunctions(pkg)
+	}
+	if options.Values {
+		values := parseValues(pkg)
+		parsedElement.Package.Constants, parsedElement.Package.Variables = values.Constants, values.Variables
+	}
+	if typeName == "" {
+		return parsedElement, nil
+	}
+	if object, isTypeName := pkg.Types.Scope().Lookup(typeName).(*types.TypeName); isTypeName {
+		if named, isNamed := unalias(object.Type()).(*types.Named); isNamed {
+			parsedElement.TypeChecker = typeChecker{pkg: pkg, named: named}
+		}
+	}
+	if object, isTypeName := pkg.Types.Scope().Lookup(typeName).(*types.TypeName); isTypeName && object.IsAlias() {
+		element, err := parseAlias(pkg, object, options)
+		if err != nil {
+			return models.ParsedElement{}, err
+		}
+		parsedElement.Element = element
+		return parsedElement, nil
+	}
 	expr, err := loadAstExpr(pkg, typeName)
 	if err != nil {
 		return models.ParsedElement{}, err
@@ -23,6 +58,39 @@ func Parser(pkg *packages.Package, typeName string) (models.ParsedElement, error
 	if err != nil {
 		return models.ParsedElement{}, err
 	}
+	if structType, isStruct := expr.(*ast.StructType); isStruct {
+		named, err := objectAsNamedType(pkg.Types.Scope().Lookup(typeName))
+		if err != nil {
+			return models.ParsedElement{}, err
+		}
+		seen := map[*types.TypeName]bool{named.Obj(): true}
+		element, err = applyStructOptions(pkg, named, element, fieldTypes(pkg.TypesInfo, structType), options, seen)
+		if err != nil {
+			return models.ParsedElement{}, err
+		}
+	}
+	if options.Recursive && len(element.Methods) != 0 {
+		named, err := objectAsNamedType(pkg.Types.Scope().Lookup(typeName))
+		if err != nil {
+			return models.ParsedElement{}, err
+		}
+		element.Methods, err = resolveMethods(pkg, named, element.Methods, options)
+		if err != nil {
+			return models.ParsedElement{}, err
+		}
+	}
+	if options.Positions {
+		if object, isTypeName := pkg.Types.Scope().Lookup(typeName).(*types.TypeName); isTypeName {
+			applyPositions(pkg.Fset, object, &element)
+		}
+	}
+	if _, isInterface := expr.(*ast.InterfaceType); isInterface && options.Implementations {
+		options.Implementations = false
+		parsedElement.Implementations, err = ParseImplementations([]*packages.Package{pkg}, pkg, typeName, options)
+		if err != nil {
+			return models.ParsedElement{}, err
+		}
+	}
 	parsedElement.Element = element
 	return parsedElement, nil
 }
@@ -35,6 +103,9 @@ func parseElement(pkg *packages.Package, expr ast.Expr, name string) (models.Ele
 		return parseStruct(pkg, name, expr)
 	case *ast.InterfaceType:
 		return parseInterface(pkg, name, expr)
+	case *ast.Ident, *ast.SelectorExpr, *ast.StarExpr, *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType,
+		*ast.IndexExpr, *ast.IndexListExpr:
+		return parseNamedType(pkg, name)
 	default:
 		return models.Element{}, fmt.Errorf("unsupported type %T", expr)
 	}
@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"go/token"
+	"go/types"
+
+	"github.com/leorolland/genz/pkg/models"
+)
+
+// applyPositions fills the positions of the given element, declared by the given type name, of its attributes,
+// of its methods and of the structs resolved by its attributes, recursively.
+// The attributes and the methods are looked up by name, so that the promoted ones (see FlattenEmbedded) are positioned
+// at their declaration in the embedded type.
+func applyPositions(fset *token.FileSet, object *types.TypeName, element *models.Element) {
+	element.Position = position(fset, object.Pos())
+	for i, attribute := range element.Attributes {
+		field, _, _ := types.LookupFieldOrMethod(object.Type(), false, object.Pkg(), attribute.Name)
+		if field == nil {
+			continue
+		}
+		element.Attributes[i].Position = position(fset, field.Pos())
+		if named := namedStruct(field.Type()); named != nil && attribute.Resolved != nil {
+			applyPositions(fset, named.Obj(), attribute.Resolved)
+		}
+	}
+	for i, method := range element.Methods {
+		if function, _, _ := types.LookupFieldOrMethod(object.Type(), true, object.Pkg(), method.Name); function != nil {
+			element.Methods[i].Position = position(fset, function.Pos())
+		}
+	}
+}
+
+// position returns the position of the given token.Pos, or the zero position if it is unknown.
+func position(fset *token.FileSet, pos token.Pos) models.Position {
+	if !pos.IsValid() {
+		return models.Position{}
+	}
+	p := fset.Position(pos)
+	return models.Position{File: p.Filename, Line: p.Line, Column: p.Column}
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/leorolland/genz/internal/testutils"
+)
+
+func TestPositions(t *testing.T) {
+	goCode := `package main
+
+type Base struct {
+	ID int
+}
+
+type User struct {
+	Base
+	Name    string
+	Address *Address
+}
+
+func (u *User) Rename(name string) { u.Name = name }
+
+type Address struct {
+	Street string
+}
+
+type Store interface {
+	Save(u User) error
+}
+`
+	pkg := testutils.CreatePkgWithCode(t, goCode)
+
+	parsed, err := WithOptions(Options{Positions: true, Recursive: true})(pkg, "User")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Base(parsed.File) != "main.go" || parsed.Line != 7 || parsed.Column != 6 {
+		t.Errorf("unexpected position of the struct: %+v", parsed.Position)
+	}
+	expectedLines := map[string]int{"Base": 8, "Name": 9, "Address": 10}
+	for _, attribute := range parsed.Attributes {
+		if attribute.Line != expectedLines[attribute.Name] || attribute.Column != 2 {
+			t.Errorf("unexpected position of %s: %+v", attribute.Name, attribute.Position)
+		}
+	}
+	if street := parsed.Attributes[2].Resolved.Attributes[0]; street.Line != 16 {
+		t.Errorf("unexpected position of the resolved attribute: %+v", street.Position)
+	}
+	if parsed.Attributes[2].Resolved.Line != 15 {
+		t.Errorf("unexpected position of the resolved struct: %+v", parsed.Attributes[2].Resolved.Position)
+	}
+	if rename := parsed.Methods[0]; rename.Line != 13 || rename.Column != 16 {
+		t.Errorf("unexpected position of the method: %+v", rename.Position)
+	}
+
+	flattened, err := WithOptions(Options{Positions: true, FlattenEmbedded: true})(pkg, "User")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id := flattened.Attributes[0]; id.Name != "ID" || id.Line != 4 {
+		t.Errorf("unexpected position of the promoted attribute: %+v", id)
+	}
+
+	store, err := WithOptions(Options{Positions: true})(pkg, "Store")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.Line != 19 || store.Methods[0].Line != 20 {
+		t.Errorf("unexpected positions of the interface: %+v, %+v", store.Position, store.Methods[0].Position)
+	}
+
+	withoutPositions, err := WithOptions(Options{})(pkg, "User")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if withoutPositions.Line != 0 || withoutPositions.Attributes[0].Line != 0 {
+		t.Error("expected no positions without the option")
+	}
+}
@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
+)
+
+// resolveAttributes parses the named struct type of each attribute and attaches it to the attribute, recursively.
+// Pointers, slices, arrays and map values are looked through, so "[]*Foo" resolves "Foo".
+// Only the structs declared in the module of the parsed package are resolved.
+// The seen set holds the structs being resolved, to stop on recursive types (e.g. linked lists).
+func resolveAttributes(pkg *packages.Package, parent *types.Named, attributes []models.Attribute, options Options, seen map[*types.TypeName]bool) ([]models.Attribute, error) {
+	for i, attribute := range attributes {
+		object, _, _ := types.LookupFieldOrMethod(parent, false, parent.Obj().Pkg(), attribute.Name)
+		field, isField := object.(*types.Var)
+		if !isField {
+			continue
+		}
+		named := namedStruct(field.Type())
+		if named == nil || seen[named.Obj()] || !isInModule(pkg, named) {
+			continue
+		}
+
+		seen[named.Obj()] = true
+		resolved, err := parseResolvedStruct(pkg, named, options, seen)
+		delete(seen, named.Obj())
+		if err != nil {
+			return nil, err
+		}
+		attributes[i].Resolved = &resolved
+	}
+	return attributes, nil
+}
+
+// resolveMethods parses the named struct type referenced by each param and each result of the methods of the given
+// named type, and attaches them to the methods, recursively, see resolveAttributes.
+func resolveMethods(pkg *packages.Package, named *types.Named, methods []models.Method, options Options) ([]models.Method, error) {
+	for i, method := range methods {
+		object, _, _ := types.LookupFieldOrMethod(named, true, named.Obj().Pkg(), method.Name)
+		function, isFunc := object.(*types.Func)
+		if !isFunc {
+			continue
+		}
+		signature := function.Type().(*types.Signature)
+		var err error
+		if methods[i].ResolvedParams, err = resolveTuple(pkg, signature.Params(), options); err != nil {
+			return nil, err
+		}
+		if methods[i].ResolvedReturns, err = resolveTuple(pkg, signature.Results(), options); err != nil {
+			return nil, err
+		}
+	}
+	return methods, nil
+}
+
+// resolveTuple parses the named struct type referenced by each variable of the given tuple, e.g. the params of a
+// method. It returns nil if none of them references a struct declared in the module of the parsed package.
+func resolveTuple(pkg *packages.Package, tuple *types.Tuple, options Options) ([]*models.Element, error) {
+	var resolved []*models.Element
+	for i := 0; i < tuple.Len(); i++ {
+		named := namedStruct(tuple.At(i).Type())
+		if named == nil || !isInModule(pkg, named) {
+			continue
+		}
+		element, err := parseResolvedStruct(pkg, named, options, map[*types.TypeName]bool{named.Obj(): true})
+		if err != nil {
+			return nil, err
+		}
+		if resolved == nil {
+			resolved = make([]*models.Element, tuple.Len())
+		}
+		resolved[i] = &element
+	}
+	return resolved, nil
+}
+
+// parseResolvedStruct parses the given named struct.
+// The struct is parsed from the source when it is declared in the parsed package, so that comments are kept.
+// Otherwise, it is built from the type checker information.
+func parseResolvedStruct(pkg *packages.Package, named *types.Named, options Options, seen map[*types.TypeName]bool) (models.Element, error) {
+	if named.Obj().Pkg() == pkg.Types && named.TypeArgs() == nil {
+		expr, err := loadAstExpr(pkg, named.Obj().Name())
+		if err != nil {
+			return models.Element{}, err
+		}
+		if structType, isStruct := expr.(*ast.StructType); isStruct {
+			element, err := parseStruct(pkg, named.Obj().Name(), structType)
+			if err != nil {
+				return models.Element{}, err
+			}
+			return applyStructOptions(pkg, named, element, fieldTypes(pkg.TypesInfo, structType), options, seen)
+		}
+	}
+
+	attributes, fieldTypes, err := typesStructAttributes(named.Underlying().(*types.Struct), pkg.Types)
+	if err != nil {
+		return models.Element{}, err
+	}
+	element := models.Element{
+		Type:       parseType(named, pkg.Types),
+		Attributes: attributes,
+		TypeParams: parseTypeParams(named.TypeParams(), pkg.Types),
+	}
+	for i := 0; i < named.NumMethods(); i++ {
+		method, err := parseMethod(named.Method(i).Name(), named.Method(i).Type().(*types.Signature), pkg.Types)
+		if err != nil {
+			return models.Element{}, err
+		}
+		element.Methods = append(element.Methods, method)
+	}
+	return applyStructOptions(pkg, named, element, fieldTypes, options, seen)
+}
+
+// namedStruct returns the named struct type referenced by the given type, looking through pointers, slices, arrays and map values.
+// It returns nil if the given type does not reference a named struct.
+func namedStruct(t types.Type) *types.Named {
+	for {
+		switch typ := unalias(t).(type) {
+		case *types.Pointer:
+			t = typ.Elem()
+		case *types.Slice:
+			t = typ.Elem()
+		case *types.Array:
+			t = typ.Elem()
+		case *types.Map:
+			t = typ.Elem()
+		case *types.Named:
+			if _, isStruct := typ.Underlying().(*types.Struct); isStruct {
+				return typ
+			}
+			return nil
+		default:
+			return nil
+		}
+	}
+}
+
+// isInModule returns true if the given named type is declared in the parsed package or in another package of its module.
+func isInModule(pkg *packages.Package, named *types.Named) bool {
+	typePkg := named.Obj().Pkg()
+	if typePkg == nil {
+		return false
+	}
+	if typePkg == pkg.Types {
+		return true
+	}
+	if pkg.Module == nil {
+		return false
+	}
+	return typePkg.Path() == pkg.Module.Path || strings.HasPrefix(typePkg.Path(), pkg.Module.Path+"/")
+}
@@ -0,0 +1,196 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leorolland/genz/internal/testutils"
+	"github.com/leorolland/genz/pkg/models"
+)
+
+func TestParseRecursiveSuccess(t *testing.T) {
+	testCases := map[string]struct {
+		goCode             string
+		structName         string
+		expectedAttributes []models.Attribute
+	}{
+		"attribute of basic type is not resolved": {
+			goCode: `
+			package main
+
+			type A struct {
+				foo string
+			}
+			`,
+			structName: "A",
+			expectedAttributes: []models.Attribute{
+				{Name: "foo", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{}},
+			},
+		},
+		"attributes of struct types are resolved": {
+			goCode: `
+			package main
+
+			type A struct {
+				// foo comment
+				foo string
+			}
+			type B struct {
+				a  A
+				as []*A
+			}
+			`,
+			structName: "B",
+			expectedAttributes: []models.Attribute{
+				{
+					Name:     "a",
+					Type:     models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+					Comments: []string{},
+					Resolved: &models.Element{
+						Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+						Attributes: []models.Attribute{
+							{Name: "foo", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{" foo comment"}},
+						},
+					},
+				},
+				{
+					Name:     "as",
+					Type:     models.Type{Name: "[]*main.A", InternalName: "[]*A", LocalName: "[]*A", IsSlice: true, Elem: &models.Type{Name: "*main.A", InternalName: "*A", LocalName: "*A", IsPointer: true, Elem: &models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"}}},
+					Comments: []string{},
+					Resolved: &models.Element{
+						Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+						Attributes: []models.Attribute{
+							{Name: "foo", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{" foo comment"}},
+						},
+					},
+				},
+			},
+		},
+		"nested struct types are resolved recursively": {
+			goCode: `
+			package main
+
+			type A struct {
+				foo string
+			}
+			type B struct {
+				a map[string]A
+			}
+			type C struct {
+				b B
+			}
+			`,
+			structName: "C",
+			expectedAttributes: []models.Attribute{
+				{
+					Name:     "b",
+					Type:     models.Type{Name: "main.B", InternalName: "B", LocalName: "B", PkgPath: "command-line-arguments"},
+					Comments: []string{},
+					Resolved: &models.Element{
+						Type: models.Type{Name: "main.B", InternalName: "B", LocalName: "B", PkgPath: "command-line-arguments"},
+						Attributes: []models.Attribute{
+							{
+								Name:     "a",
+								Type:     models.Type{Name: "map[string]main.A", InternalName: "map[string]A", LocalName: "map[string]A", IsMap: true, Key: &models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Elem: &models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"}},
+								Comments: []string{},
+								Resolved: &models.Element{
+									Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+									Attributes: []models.Attribute{
+										{Name: "foo", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"recursive type is not resolved twice": {
+			goCode: `
+			package main
+
+			type Node struct {
+				next *Node
+			}
+			`,
+			structName: "Node",
+			expectedAttributes: []models.Attribute{
+				{Name: "next", Type: models.Type{Name: "*main.Node", InternalName: "*Node", LocalName: "*Node", IsPointer: true, Elem: &models.Type{Name: "main.Node", InternalName: "Node", LocalName: "Node", PkgPath: "command-line-arguments"}}, Comments: []string{}},
+			},
+		},
+		"struct of another module is not resolved": {
+			goCode: `
+			package main
+
+			import "go/token"
+
+			type A struct {
+				position token.Position
+			}
+			`,
+			structName: "A",
+			expectedAttributes: []models.Attribute{
+				{Name: "position", Type: models.Type{Name: "token.Position", InternalName: "Position", LocalName: "token.Position", PkgPath: "go/token"}, Comments: []string{}},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pkg := testutils.CreatePkgWithCode(t, tc.goCode)
+
+			parsedElement, err := WithOptions(Options{Recursive: true})(pkg, tc.structName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(parsedElement.Attributes, tc.expectedAttributes) {
+				t.Fatalf("output attributes don't match expected:\n%s", cmp.Diff(parsedElement.Attributes, tc.expectedAttributes))
+			}
+		})
+	}
+}
+
+func TestParseRecursiveMethods(t *testing.T) {
+	pkg := testutils.CreatePkgWithCode(t, `
+	package main
+
+	import "context"
+
+	type User struct {
+		Name string
+	}
+
+	type Users interface {
+		Get(ctx context.Context, id string) (*User, error)
+		List() []User
+		Count() int
+	}
+	`)
+
+	parsedElement, err := WithOptions(Options{Recursive: true})(pkg, "Users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	user := &models.Element{
+		Type: models.Type{Name: "main.User", InternalName: "User", LocalName: "User", PkgPath: "command-line-arguments"},
+		Attributes: []models.Attribute{
+			{Name: "Name", Type: models.Type{Name: "string", InternalName: "string", LocalName: "string"}, Comments: []string{}},
+		},
+	}
+	expected := map[string][2][]*models.Element{
+		"Get":   {nil, {user, nil}},
+		"List":  {nil, {user}},
+		"Count": {nil, nil},
+	}
+	for _, method := range parsedElement.Methods {
+		resolved := [2][]*models.Element{method.ResolvedParams, method.ResolvedReturns}
+		if !reflect.DeepEqual(resolved, expected[method.Name]) {
+			t.Errorf("unexpected resolved params and returns of %s:\n%s", method.Name, cmp.Diff(expected[method.Name], resolved))
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"fmt"
+	"go/types"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// SelectTypes returns the names of the types of the package selected by the given names and regular expression.
+// A name containing a wildcard (e.g. "*DTO") is a glob pattern, matched against every type declared in the package.
+// Other names are returned as is. The result keeps the order of the names, patterns being expanded in alphabetical order,
+// and has no duplicates.
+func SelectTypes(pkg *packages.Package, names []string, regex string) ([]string, error) {
+	var declared []string
+	for _, name := range pkg.Types.Scope().Names() { // sorted
+		if _, isTypeName := pkg.Types.Scope().Lookup(name).(*types.TypeName); isTypeName {
+			declared = append(declared, name)
+		}
+	}
+
+	var selected []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			selected = append(selected, name)
+		}
+	}
+
+	for _, name := range names {
+		if !strings.ContainsAny(name, "*?[") {
+			add(name)
+			continue
+		}
+		matched := false
+		for _, typeName := range declared {
+			match, err := path.Match(name, typeName)
+			if err != nil {
+				return nil, fmt.Errorf("invalid type pattern %s: %w", name, err)
+			}
+			if match {
+				matched = true
+				add(typeName)
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no type matching %s in package %s", name, pkg.Name)
+		}
+	}
+
+	if regex != "" {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type regular expression %s: %w", regex, err)
+		}
+		matched := false
+		for _, typeName := range declared {
+			if re.MatchString(typeName) {
+				matched = true
+				add(typeName)
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no type matching %s in package %s", regex, pkg.Name)
+		}
+	}
+	return selected, nil
+}
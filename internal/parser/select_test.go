@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leorolland/genz/internal/testutils"
+)
+
+func TestSelectTypes(t *testing.T) {
+	goCode := `
+	package main
+
+	type UserDTO struct {}
+	type OrderDTO struct {}
+	type User struct {}
+	type OrderCreatedEvent struct {}
+	type UserDeletedEvent struct {}
+
+	var EventCount int
+	`
+	testCases := map[string]struct {
+		names    []string
+		regex    string
+		expected []string
+		wantErr  bool
+	}{
+		"plain names are kept as is": {
+			names:    []string{"User", "Missing"},
+			expected: []string{"User", "Missing"},
+		},
+		"glob pattern": {
+			names:    []string{"*DTO"},
+			expected: []string{"OrderDTO", "UserDTO"},
+		},
+		"glob pattern and plain name without duplicates": {
+			names:    []string{"UserDTO", "User*"},
+			expected: []string{"UserDTO", "User", "UserDeletedEvent"},
+		},
+		"regular expression": {
+			regex:    ".*Event$",
+			expected: []string{"OrderCreatedEvent", "UserDeletedEvent"},
+		},
+		"glob pattern without match": {
+			names:   []string{"*Entity"},
+			wantErr: true,
+		},
+		"regular expression without match": {
+			regex:   "^Entity",
+			wantErr: true,
+		},
+		"invalid regular expression": {
+			regex:   "(",
+			wantErr: true,
+		},
+	}
+
+	pkg := testutils.CreatePkgWithCode(t, goCode)
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := SelectTypes(pkg, tc.names, tc.regex)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("SelectTypes() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("SelectTypes() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
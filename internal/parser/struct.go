@@ -3,7 +3,6 @@ package parser
 import (
 	"fmt"
 	"go/ast"
-	"go/doc"
 	"go/types"
 	"strings"
 
@@ -13,52 +12,35 @@ import (
 )
 
 func parseStruct(pkg *packages.Package, structName string, structType *ast.StructType) (models.Element, error) {
-	parsedStruct := models.Element{
-		Type: models.Type{
-			Name:         fmt.Sprintf("%s.%s", pkg.Name, structName),
-			InternalName: structName,
-		},
+	parsedStruct, err := parseElementType(pkg, structName)
+	if err != nil {
+		return models.Element{}, err
 	}
 
-	attributes, err := structAttributes(pkg.TypesInfo, structType)
+	attributes, err := structAttributes(pkg, structType)
 	if err != nil {
 		return models.Element{}, err
 	}
 
 	parsedStruct.Attributes = attributes
 
-	pkgDoc, err := doc.NewFromFiles(pkg.Fset, pkg.Syntax, "./", doc.AllDecls)
-	if err != nil {
-		return models.Element{}, fmt.Errorf("failed to create doc while parsing struct: %w", err)
+	if object := pkg.Types.Scope().Lookup(structName); object != nil {
+		namedType, err := objectAsNamedType(object)
+		if err != nil {
+			return models.Element{}, err
+		}
+		parsedStruct.TypeParams = parseTypeParams(namedType.TypeParams(), pkg.Types)
 	}
 
-	var methods []models.Method
-
-	for ident, object := range pkg.TypesInfo.Uses {
-		if ident.Name == structName {
-			namedType, err := objectAsNamedType(object)
-			if err != nil {
-				return models.Element{}, err
-			}
-
-			for i := 0; i < namedType.NumMethods(); i++ {
-				method, err := parseMethodWithComments(
-					getFuncDoc(pkgDoc, structName, namedType.Method(i).Name()),
-					namedType.Method(i).Name(),
-					namedType.Method(i).Type().(*types.Signature),
-				)
-				if err != nil {
-					return models.Element{}, err
-				}
-				methods = append(methods, method)
-			}
-		}
+	methods, err := parseNamedTypeMethods(pkg, structName)
+	if err != nil {
+		return models.Element{}, err
 	}
 	parsedStruct.Methods = methods
 	return parsedStruct, nil
 }
 
-func structAttributes(typesInfo *types.Info, structType *ast.StructType) ([]models.Attribute, error) {
+func structAttributes(pkg *packages.Package, structType *ast.StructType) ([]models.Attribute, error) {
 	attributes := make([]models.Attribute, len(structType.Fields.List))
 
 	for i, field := range structType.Fields.List {
@@ -69,22 +51,56 @@ func structAttributes(typesInfo *types.Info, structType *ast.StructType) ([]mode
 			}
 		}
 		attributes[i] = models.Attribute{
-			Name:     field.Names[0].Name,
-			Type:     parseType(typesInfo.TypeOf(field.Type)),
+			Type:     parseTypeExpr(pkg, field.Type),
 			Comments: comments,
 		}
+		if len(field.Names) == 0 { // Embedded field, e.g. "type B struct { A }"
+			attributes[i].Name = embeddedFieldName(pkg.TypesInfo.TypeOf(field.Type))
+			if embedded := attributes[i].Type; embedded.IsAlias || embedded.IsPointer && embedded.Elem.IsAlias {
+				// The field is named after the alias, e.g. "type B struct { *Alias }" => "Alias"
+				attributes[i].Name = strings.TrimPrefix(embedded.InternalName, "*")
+			}
+			attributes[i].IsEmbedded = true
+		} else {
+			attributes[i].Name = field.Names[0].Name
+		}
 		if field.Tag != nil {
 			tags, err := parseTags(field.Tag.Value)
 			if err != nil {
 				return nil, err
 			}
-			attributes[i].Tags = tags
+			attributes[i].Tags, attributes[i].ParsedTags = tags, parseTagValues(tags)
 		}
 	}
 
 	return attributes, nil
 }
 
+// typesStructAttributes returns the attributes of the given struct and their types, built from the type checker information.
+// It is used for structs declared outside the parsed package, whose source code is not loaded. Comments are thus not available.
+func typesStructAttributes(structType *types.Struct, local *types.Package) ([]models.Attribute, []types.Type, error) {
+	attributes := make([]models.Attribute, structType.NumFields())
+	fieldTypes := make([]types.Type, structType.NumFields())
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		attributes[i] = models.Attribute{
+			Name:       field.Name(),
+			Type:       parseType(field.Type(), local),
+			IsEmbedded: field.Embedded(),
+			Comments:   []string{},
+		}
+		if structType.Tag(i) != "" {
+			tags, err := parseTags(structType.Tag(i))
+			if err != nil {
+				return nil, nil, err
+			}
+			attributes[i].Tags, attributes[i].ParsedTags = tags, parseTagValues(tags)
+		}
+		fieldTypes[i] = field.Type()
+	}
+	return attributes, fieldTypes, nil
+}
+
 // parseTags take a string of tags (e.g. `json:"name,omitempty" xml:"name"`)
 // and returns a map of tags (e.g. map[string]string{"json": "name,omitempty", "xml": "name"})
 func parseTags(tags string) (map[string]string, error) {
@@ -94,28 +110,37 @@ func parseTags(tags string) (map[string]string, error) {
 		if tag == "" {
 			continue
 		}
-		splitTag := strings.Split(tag, ":")
-		if len(splitTag) != 2 {
+		key, value, found := strings.Cut(tag, ":") // the value can contain colons, e.g. `default:":8080"`
+		if !found {
 			return nil, fmt.Errorf("invalid tag: %s", tag)
 		}
-		result[splitTag[0]] = strings.ReplaceAll(splitTag[1], "\"", "")
+		result[key] = strings.ReplaceAll(value, "\"", "")
 	}
 	return result, nil
 }
 
-func getFuncDoc(pkgDoc *doc.Package, structName, funcName string) *doc.Func {
-	var typeDoc *doc.Type
-	for _, t := range pkgDoc.Types {
-		if t.Name == structName {
-			typeDoc = t
-		}
+// parseTagValues splits the values of the given tags into their name and their options, see parseTag.
+// It returns nil if there is no tag.
+func parseTagValues(tags map[string]string) map[string]models.Tag {
+	if tags == nil {
+		return nil
 	}
+	result := make(map[string]models.Tag, len(tags))
+	for key, value := range tags {
+		result[key] = parseTag(value)
+	}
+	return result
+}
 
-	for _, m := range typeDoc.Methods {
-		if m.Name == funcName {
-			return m
+// parseTag splits the given tag value into its name and its options.
+// e.g. "name, omitempty" => {Value: "name, omitempty", Name: "name", Options: ["omitempty"]}
+func parseTag(value string) models.Tag {
+	name, options, _ := strings.Cut(value, ",")
+	tag := models.Tag{Value: value, Name: strings.TrimSpace(name)}
+	for _, option := range strings.Split(options, ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			tag.Options = append(tag.Options, option)
 		}
 	}
-
-	return nil
+	return tag
 }
@@ -25,7 +25,42 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type:       models.Type{Name: "main.A", InternalName: "A"},
+				Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+				Attributes: []models.Attribute{},
+			},
+		},
+		"struct with doc and directives": {
+			goCode: `
+			package main
+
+			// A is a table.
+			//genz:table as
+			//genz:readonly
+			type A struct {}
+			`,
+			structName: "A",
+			expectedStruct: models.Element{
+				Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+				Comments:   []string{" A is a table."},
+				Directives: map[string]string{"table": "as", "readonly": ""},
+				Attributes: []models.Attribute{},
+			},
+		},
+		"struct with doc in a type group": {
+			goCode: `
+			package main
+
+			// Types.
+			type (
+				// A is a struct.
+				A struct {}
+				B struct {}
+			)
+			`,
+			structName: "A",
+			expectedStruct: models.Element{
+				Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+				Comments:   []string{" A is a struct."},
 				Attributes: []models.Attribute{},
 			},
 		},
@@ -39,11 +74,11 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{
 					{
 						Name:     "foo",
-						Type:     models.Type{Name: "string", InternalName: "string"},
+						Type:     models.Type{Name: "string", InternalName: "string", LocalName: "string"},
 						Comments: []string{},
 					},
 				},
@@ -60,16 +95,16 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{
 					{
 						Name:     "foo",
-						Type:     models.Type{Name: "string", InternalName: "string"},
+						Type:     models.Type{Name: "string", InternalName: "string", LocalName: "string"},
 						Comments: []string{},
 					},
 					{
 						Name:     "bar",
-						Type:     models.Type{Name: "uint", InternalName: "uint"},
+						Type:     models.Type{Name: "uint", InternalName: "uint", LocalName: "uint"},
 						Comments: []string{},
 					},
 				},
@@ -87,11 +122,11 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{
 					{
 						Name:     "foo",
-						Type:     models.Type{Name: "string", InternalName: "string"},
+						Type:     models.Type{Name: "string", InternalName: "string", LocalName: "string"},
 						Comments: []string{"comment 1", "comment 2"},
 					},
 				},
@@ -107,11 +142,11 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{
 					{
 						Name:     "foo",
-						Type:     models.Type{Name: "string", InternalName: "string"},
+						Type:     models.Type{Name: "string", InternalName: "string", LocalName: "string"},
 						Comments: []string{},
 					},
 				},
@@ -127,11 +162,49 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "B",
 			expectedStruct: models.Element{
-				Type: models.Type{Name: "main.B", InternalName: "B"},
+				Type: models.Type{Name: "main.B", InternalName: "B", LocalName: "B", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{
 					{
 						Name:     "foo",
-						Type:     models.Type{Name: "[]string", InternalName: "[]string"},
+						Type:     models.Type{Name: "[]string", InternalName: "[]string", LocalName: "[]string", IsSlice: true, Elem: &models.Type{Name: "string", InternalName: "string", LocalName: "string"}},
+						Comments: []string{},
+					},
+				},
+			},
+		},
+		"attribute with type literals": {
+			goCode: `
+			package main
+
+			type A struct {
+				c chan int
+				f func() error
+				a [2]int
+			}
+			`,
+			structName: "A",
+			expectedStruct: models.Element{
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+				Attributes: []models.Attribute{
+					{
+						Name: "c",
+						Type: models.Type{
+							Name: "chan int", InternalName: "chan int", LocalName: "chan int",
+							IsChan: true, Elem: &models.Type{Name: "int", InternalName: "int", LocalName: "int"},
+						},
+						Comments: []string{},
+					},
+					{
+						Name:     "f",
+						Type:     models.Type{Name: "func() error", InternalName: "func() error", LocalName: "func() error", IsFunc: true},
+						Comments: []string{},
+					},
+					{
+						Name: "a",
+						Type: models.Type{
+							Name: "[2]int", InternalName: "[2]int", LocalName: "[2]int",
+							IsArray: true, Elem: &models.Type{Name: "int", InternalName: "int", LocalName: "int"},
+						},
 						Comments: []string{},
 					},
 				},
@@ -148,11 +221,41 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "B",
 			expectedStruct: models.Element{
-				Type: models.Type{Name: "main.B", InternalName: "B"},
+				Type: models.Type{Name: "main.B", InternalName: "B", LocalName: "B", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{
 					{
 						Name:     "foo",
-						Type:     models.Type{Name: "main.A", InternalName: "A"},
+						Type:     models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+						Comments: []string{},
+					},
+				},
+			},
+		},
+		"attribute with named basic type": {
+			goCode: `
+			package main
+
+			type Meters float64
+			type B struct {
+				Distance Meters
+				Elapsed  *Meters
+			}
+			`,
+			structName: "B",
+			expectedStruct: models.Element{
+				Type: models.Type{Name: "main.B", InternalName: "B", LocalName: "B", PkgPath: "command-line-arguments"},
+				Attributes: []models.Attribute{
+					{
+						Name:     "Distance",
+						Type:     models.Type{Name: "main.Meters", InternalName: "Meters", LocalName: "Meters", PkgPath: "command-line-arguments", BasicKind: "float64"},
+						Comments: []string{},
+					},
+					{
+						Name: "Elapsed",
+						Type: models.Type{
+							Name: "*main.Meters", InternalName: "*Meters", LocalName: "*Meters", IsPointer: true,
+							Elem: &models.Type{Name: "main.Meters", InternalName: "Meters", LocalName: "Meters", PkgPath: "command-line-arguments", BasicKind: "float64"},
+						},
 						Comments: []string{},
 					},
 				},
@@ -169,11 +272,11 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "B",
 			expectedStruct: models.Element{
-				Type: models.Type{Name: "main.B", InternalName: "B"},
+				Type: models.Type{Name: "main.B", InternalName: "B", LocalName: "B", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{
 					{
 						Name:     "foo",
-						Type:     models.Type{Name: "[]main.A", InternalName: "[]A"},
+						Type:     models.Type{Name: "[]main.A", InternalName: "[]A", LocalName: "[]A", IsSlice: true, Elem: &models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"}},
 						Comments: []string{},
 					},
 				},
@@ -190,11 +293,11 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "B",
 			expectedStruct: models.Element{
-				Type: models.Type{Name: "main.B", InternalName: "B"},
+				Type: models.Type{Name: "main.B", InternalName: "B", LocalName: "B", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{
 					{
 						Name:     "foo",
-						Type:     models.Type{Name: "map[main.A]main.A", InternalName: "map[A]A"},
+						Type:     models.Type{Name: "map[main.A]main.A", InternalName: "map[A]A", LocalName: "map[A]A", IsMap: true, Key: &models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"}, Elem: &models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"}},
 						Comments: []string{},
 					},
 				},
@@ -207,18 +310,36 @@ func TestParseStructSuccess(t *testing.T) {
 			type A struct {}
 			type B struct {
 				foo struct {
+					// bar comment
 					bar []A
-					baz string
+					baz string ` + "`json:\"baz\"`" + `
 				}
 			}
 			`,
 			structName: "B",
 			expectedStruct: models.Element{
-				Type: models.Type{Name: "main.B", InternalName: "B"},
+				Type: models.Type{Name: "main.B", InternalName: "B", LocalName: "B", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{
 					{
-						Name:     "foo",
-						Type:     models.Type{Name: "struct{bar []main.A; baz string}", InternalName: "struct{bar []A; baz string}"},
+						Name: "foo",
+						Type: models.Type{
+							Name: `struct{bar []main.A; baz string "json:\"baz\""}`, InternalName: `struct{bar []A; baz string "json:\"baz\""}`, LocalName: `struct{bar []A; baz string "json:\"baz\""}`,
+							IsStruct: true,
+							Fields: []models.Attribute{
+								{
+									Name:     "bar",
+									Type:     models.Type{Name: "[]main.A", InternalName: "[]A", LocalName: "[]A", IsSlice: true, Elem: &models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"}},
+									Comments: []string{" bar comment"},
+								},
+								{
+									Name:       "baz",
+									Type:       models.Type{Name: "string", InternalName: "string", LocalName: "string"},
+									Comments:   []string{},
+									Tags:       map[string]string{"json": "baz"},
+									ParsedTags: map[string]models.Tag{"json": {Value: "baz", Name: "baz"}},
+								},
+							},
+						},
 						Comments: []string{},
 					},
 				},
@@ -234,15 +355,18 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type:       models.Type{Name: "main.A", InternalName: "A"},
+				Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{},
 				Methods: []models.Method{
 					{
 						Name:              "foo",
 						IsExported:        false,
 						IsPointerReceiver: false,
+						ReceiverName:      "a",
 						Params:            []models.Type{},
 						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
 						Comments:          []string{},
 					},
 				},
@@ -260,20 +384,51 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type:       models.Type{Name: "main.A", InternalName: "A"},
+				Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{},
 				Methods: []models.Method{
 					{
 						Name:              "foo",
 						IsExported:        false,
 						IsPointerReceiver: false,
+						ReceiverName:      "a",
 						Params:            []models.Type{},
 						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
 						Comments:          []string{"comment 1", "comment 2"},
 					},
 				},
 			},
 		},
+		"one empty method, directives": {
+			goCode: `
+			package main
+
+			type A struct {}
+
+			// comment 1
+			//genz:api v1
+			func (a A) foo() {}
+			`,
+			structName: "A",
+			expectedStruct: models.Element{
+				Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+				Attributes: []models.Attribute{},
+				Methods: []models.Method{
+					{
+						Name:         "foo",
+						ReceiverName: "a",
+						Params:       []models.Type{},
+						Returns:      []models.Type{},
+						ParamNames:   []string{},
+						ReturnNames:  []string{},
+						Comments:     []string{"comment 1"},
+						Directives:   map[string]string{"api": "v1"},
+					},
+				},
+			},
+		},
 		"one empty method, pointer receiver": {
 			goCode: `
 			package main
@@ -284,15 +439,18 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type:       models.Type{Name: "main.A", InternalName: "A"},
+				Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{},
 				Methods: []models.Method{
 					{
 						Name:              "foo",
 						IsExported:        false,
 						IsPointerReceiver: true,
+						ReceiverName:      "a",
 						Params:            []models.Type{},
 						Returns:           []models.Type{},
+						ParamNames:        []string{},
+						ReturnNames:       []string{},
 						Comments:          []string{},
 					},
 				},
@@ -310,15 +468,18 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type:       models.Type{Name: "main.A", InternalName: "A"},
+				Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{},
 				Methods: []models.Method{
 					{
 						Name:              "foo",
 						IsExported:        false,
 						IsPointerReceiver: false,
-						Params:            []models.Type{{Name: "string", InternalName: "string"}},
-						Returns:           []models.Type{{Name: "int", InternalName: "int"}},
+						ReceiverName:      "a",
+						Params:            []models.Type{{Name: "string", InternalName: "string", LocalName: "string"}},
+						Returns:           []models.Type{{Name: "int", InternalName: "int", LocalName: "int"}},
+						ParamNames:        []string{"a"},
+						ReturnNames:       []string{""},
 						Comments:          []string{},
 					},
 				},
@@ -337,15 +498,18 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type:       models.Type{Name: "main.A", InternalName: "A"},
+				Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{},
 				Methods: []models.Method{
 					{
 						Name:              "foo",
 						IsExported:        false,
 						IsPointerReceiver: false,
-						Params:            []models.Type{{Name: "main.T", InternalName: "T"}},
-						Returns:           []models.Type{{Name: "main.T", InternalName: "T"}},
+						ReceiverName:      "a",
+						Params:            []models.Type{{Name: "main.T", InternalName: "T", LocalName: "T", PkgPath: "command-line-arguments"}},
+						Returns:           []models.Type{{Name: "main.T", InternalName: "T", LocalName: "T", PkgPath: "command-line-arguments"}},
+						ParamNames:        []string{"a"},
+						ReturnNames:       []string{""},
 						Comments:          []string{},
 					},
 				},
@@ -364,16 +528,24 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type:       models.Type{Name: "main.A", InternalName: "A"},
+				Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{},
 				Methods: []models.Method{
 					{
 						Name:              "foo",
 						IsExported:        false,
 						IsPointerReceiver: false,
-						Params:            []models.Type{{Name: "map[main.T]main.T", InternalName: "map[T]T"}},
-						Returns:           []models.Type{{Name: "struct{name main.T}", InternalName: "struct{name T}"}},
-						Comments:          []string{},
+						ReceiverName:      "a",
+						Params:            []models.Type{{Name: "map[main.T]main.T", InternalName: "map[T]T", LocalName: "map[T]T", IsMap: true, Key: &models.Type{Name: "main.T", InternalName: "T", LocalName: "T", PkgPath: "command-line-arguments"}, Elem: &models.Type{Name: "main.T", InternalName: "T", LocalName: "T", PkgPath: "command-line-arguments"}}},
+						Returns: []models.Type{{
+							Name: "struct{name main.T}", InternalName: "struct{name T}", LocalName: "struct{name T}", IsStruct: true,
+							Fields: []models.Attribute{
+								{Name: "name", Type: models.Type{Name: "main.T", InternalName: "T", LocalName: "T", PkgPath: "command-line-arguments"}, Comments: []string{}},
+							},
+						}},
+						ParamNames:  []string{"a"},
+						ReturnNames: []string{""},
+						Comments:    []string{},
 					},
 				},
 			},
@@ -391,15 +563,19 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type:       models.Type{Name: "main.A", InternalName: "A"},
+				Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{},
 				Methods: []models.Method{
 					{
 						Name:              "Foo",
 						IsExported:        true,
 						IsPointerReceiver: true,
-						Params:            []models.Type{{Name: "string", InternalName: "string"}, {Name: "uint", InternalName: "uint"}},
-						Returns:           []models.Type{{Name: "int", InternalName: "int"}, {Name: "error", InternalName: "error"}},
+						ReceiverName:      "a",
+						ReturnsError:      true,
+						Params:            []models.Type{{Name: "string", InternalName: "string", LocalName: "string"}, {Name: "uint", InternalName: "uint", LocalName: "uint"}},
+						Returns:           []models.Type{{Name: "int", InternalName: "int", LocalName: "int"}, {Name: "error", InternalName: "error", LocalName: "error"}},
+						ParamNames:        []string{"a", "b"},
+						ReturnNames:       []string{"", ""},
 						Comments:          []string{"comment"},
 					},
 				},
@@ -419,13 +595,15 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type:    models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+				Imports: []string{"github.com/google/uuid"},
 				Attributes: []models.Attribute{
 					{
 						Name: "foo",
 						Type: models.Type{
 							Name:         "string",
 							InternalName: "string",
+							LocalName:    "string",
 						},
 						Comments: []string{},
 					},
@@ -434,6 +612,8 @@ func TestParseStructSuccess(t *testing.T) {
 						Type: models.Type{
 							Name:         "uuid.UUID",
 							InternalName: "UUID",
+							LocalName:    "uuid.UUID",
+							PkgPath:      "github.com/google/uuid",
 						},
 						Comments: []string{},
 					},
@@ -442,10 +622,105 @@ func TestParseStructSuccess(t *testing.T) {
 						Type: models.Type{
 							Name:         "map[uuid.UUID]uuid.UUID",
 							InternalName: "map[UUID]UUID",
+							LocalName:    "map[uuid.UUID]uuid.UUID",
+							IsMap:        true,
+							Key:          &models.Type{Name: "uuid.UUID", InternalName: "UUID", LocalName: "uuid.UUID", PkgPath: "github.com/google/uuid"},
+							Elem:         &models.Type{Name: "uuid.UUID", InternalName: "UUID", LocalName: "uuid.UUID", PkgPath: "github.com/google/uuid"},
+						},
+						Comments: []string{},
+					},
+				},
+			},
+		},
+		"generic struct": {
+			goCode: `
+			package main
+
+			type Box[K comparable, V ~int | ~string] struct {
+				key   K
+				value V
+			}
+
+			func (b Box[K, V]) Get() V {
+				return b.value
+			}
+			`,
+			structName: "Box",
+			expectedStruct: models.Element{
+				Type: models.Type{Name: "main.Box", InternalName: "Box", LocalName: "Box", PkgPath: "command-line-arguments"},
+				Attributes: []models.Attribute{
+					{
+						Name:     "key",
+						Type:     models.Type{Name: "K", InternalName: "K", LocalName: "K"},
+						Comments: []string{},
+					},
+					{
+						Name:     "value",
+						Type:     models.Type{Name: "V", InternalName: "V", LocalName: "V"},
+						Comments: []string{},
+					},
+				},
+				Methods: []models.Method{
+					{
+						Name:              "Get",
+						IsExported:        true,
+						IsPointerReceiver: false,
+						ReceiverName:      "b",
+						Params:            []models.Type{},
+						Returns:           []models.Type{{Name: "V", InternalName: "V", LocalName: "V"}},
+						ParamNames:        []string{},
+						ReturnNames:       []string{""},
+						TypeParams: []models.TypeParam{
+							{Name: "K", Constraint: models.Type{Name: "comparable", InternalName: "comparable", LocalName: "comparable"}},
+							{Name: "V", Constraint: models.Type{Name: "~int | ~string", InternalName: "~int | ~string", LocalName: "~int | ~string"}},
 						},
 						Comments: []string{},
 					},
 				},
+				TypeParams: []models.TypeParam{
+					{Name: "K", Constraint: models.Type{Name: "comparable", InternalName: "comparable", LocalName: "comparable"}},
+					{Name: "V", Constraint: models.Type{Name: "~int | ~string", InternalName: "~int | ~string", LocalName: "~int | ~string"}},
+				},
+			},
+		},
+		"struct with embedded fields": {
+			goCode: `
+			package main
+
+			import "time"
+
+			type A struct {
+				foo string
+			}
+			type B struct {
+				A
+				*time.Location
+				bar string
+			}
+			`,
+			structName: "B",
+			expectedStruct: models.Element{
+				Type:    models.Type{Name: "main.B", InternalName: "B", LocalName: "B", PkgPath: "command-line-arguments"},
+				Imports: []string{"time"},
+				Attributes: []models.Attribute{
+					{
+						Name:       "A",
+						Type:       models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+						IsEmbedded: true,
+						Comments:   []string{},
+					},
+					{
+						Name:       "Location",
+						Type:       models.Type{Name: "*time.Location", InternalName: "*Location", LocalName: "*time.Location", IsPointer: true, Elem: &models.Type{Name: "time.Location", InternalName: "Location", LocalName: "time.Location", PkgPath: "time"}},
+						IsEmbedded: true,
+						Comments:   []string{},
+					},
+					{
+						Name:     "bar",
+						Type:     models.Type{Name: "string", InternalName: "string", LocalName: "string"},
+						Comments: []string{},
+					},
+				},
 			},
 		},
 		"struct with tags": {
@@ -459,25 +734,29 @@ func TestParseStructSuccess(t *testing.T) {
 			`,
 			structName: "A",
 			expectedStruct: models.Element{
-				Type: models.Type{Name: "main.A", InternalName: "A"},
+				Type: models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
 				Attributes: []models.Attribute{
 					{
 						Name: "foo",
 						Type: models.Type{
 							Name:         "string",
 							InternalName: "string",
+							LocalName:    "string",
 						},
-						Comments: []string{},
-						Tags:     map[string]string{"json": "foo"},
+						Comments:   []string{},
+						Tags:       map[string]string{"json": "foo"},
+						ParsedTags: map[string]models.Tag{"json": {Value: "foo", Name: "foo"}},
 					},
 					{
 						Name: "bar",
 						Type: models.Type{
 							Name:         "string",
 							InternalName: "string",
+							LocalName:    "string",
 						},
-						Comments: []string{},
-						Tags:     map[string]string{"json": "bar", "xml": "bar"},
+						Comments:   []string{},
+						Tags:       map[string]string{"json": "bar", "xml": "bar"},
+						ParsedTags: map[string]models.Tag{"json": {Value: "bar", Name: "bar"}, "xml": {Value: "bar", Name: "bar"}},
 					},
 				},
 			},
@@ -510,37 +789,47 @@ func TestParseStructSuccess(t *testing.T) {
 func Test_parseTags(t *testing.T) {
 	testCases := map[string]struct {
 		tags    string
-		want    map[string]string
+		want    map[string]models.Tag
 		wantErr bool
 	}{
 		"empty tags": {
 			tags:    "",
-			want:    map[string]string{},
+			want:    map[string]models.Tag{},
 			wantErr: false,
 		},
 		"one tag": {
 			tags:    "`json:\"name\"`",
-			want:    map[string]string{"json": "name"},
+			want:    map[string]models.Tag{"json": {Value: "name", Name: "name"}},
 			wantErr: false,
 		},
 		"two tags": {
 			tags:    "`json:\"name\" xml:\"name\"`",
-			want:    map[string]string{"json": "name", "xml": "name"},
+			want:    map[string]models.Tag{"json": {Value: "name", Name: "name"}, "xml": {Value: "name", Name: "name"}},
 			wantErr: false,
 		},
 		"tag with options": {
 			tags:    "`json:\"name,omitempty\"`",
-			want:    map[string]string{"json": "name,omitempty"},
+			want:    map[string]models.Tag{"json": {Value: "name,omitempty", Name: "name", Options: []string{"omitempty"}}},
+			wantErr: false,
+		},
+		"tag with a colon in its value": {
+			tags:    "`default:\":8080\" env:\"ADDR\"`",
+			want:    map[string]models.Tag{"default": {Value: ":8080", Name: ":8080"}, "env": {Value: "ADDR", Name: "ADDR"}},
 			wantErr: false,
 		},
 		"tag with options and spaces": {
 			tags:    "`json:\"name, omitempty\"`",
-			want:    map[string]string{"json": "name, omitempty"},
+			want:    map[string]models.Tag{"json": {Value: "name, omitempty", Name: "name", Options: []string{"omitempty"}}},
+			wantErr: false,
+		},
+		"tag with several options and no name": {
+			tags:    "`json:\",omitempty,string\"`",
+			want:    map[string]models.Tag{"json": {Value: ",omitempty,string", Name: "", Options: []string{"omitempty", "string"}}},
 			wantErr: false,
 		},
 		"two tags with options": {
 			tags:    "`json:\"name,omitempty\" xml:\"name\"`",
-			want:    map[string]string{"json": "name,omitempty", "xml": "name"},
+			want:    map[string]models.Tag{"json": {Value: "name,omitempty", Name: "name", Options: []string{"omitempty"}}, "xml": {Value: "name", Name: "name"}},
 			wantErr: false,
 		},
 		"malformed tag": {
@@ -551,13 +840,13 @@ func Test_parseTags(t *testing.T) {
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
-			got, err := parseTags(tc.tags)
+			tags, err := parseTags(tc.tags)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("parseTags() error = %v, wantErr %v", err, tc.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tc.want) {
-				t.Errorf("parseTags() = %v, want %v", got, tc.want)
+			if got := parseTagValues(tags); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseTagValues() = %v, want %v", got, tc.want)
 			}
 		})
 	}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
+)
+
+// ParseTarget parses the given type of the given package as the type the elements of the local package are converted
+// to, or as an interface they are generated with. See models.ParsedElement.Target and models.ParsedElement.Interfaces.
+// Declared in another package, the local names of the type, of its attributes and of the params and the returns of its
+// methods are qualified with their package name, e.g. "domain.User" or "[]domain.Role", as written from the local
+// package.
+func ParseTarget(pkg *packages.Package, local *packages.Package, typeName string, options Options) (models.Element, error) {
+	parsed, err := parse(pkg, typeName, options)
+	if err != nil {
+		return models.Element{}, err
+	}
+	element := parsed.Element
+	if pkg.PkgPath == local.PkgPath {
+		return element, nil
+	}
+	qualifyType(&element.Type)
+	attributes := make([]models.Attribute, len(element.Attributes))
+	for i, attribute := range element.Attributes {
+		qualifyType(&attribute.Type)
+		attributes[i] = attribute
+	}
+	element.Attributes = attributes
+	methods := make([]models.Method, len(element.Methods))
+	for i, method := range element.Methods {
+		method.Params = qualifyTypes(method.Params)
+		method.Returns = qualifyTypes(method.Returns)
+		methods[i] = method
+	}
+	element.Methods = methods
+	return element, nil
+}
+
+// qualifyTypes returns a copy of the given types, qualified by qualifyType.
+func qualifyTypes(list []models.Type) []models.Type {
+	qualified := make([]models.Type, len(list))
+	for i, t := range list {
+		qualifyType(&t)
+		qualified[i] = t
+	}
+	return qualified
+}
+
+// qualifyType sets the local names of the given type and of its element and key types to their package-qualified names.
+func qualifyType(t *models.Type) {
+	t.LocalName = t.Name
+	if t.Elem != nil {
+		elem := *t.Elem
+		qualifyType(&elem)
+		t.Elem = &elem
+	}
+	if t.Key != nil {
+		key := *t.Key
+		qualifyType(&key)
+		t.Key = &key
+	}
+}
@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/leorolland/genz/internal/testutils"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestParseTarget(t *testing.T) {
+	local := testutils.CreatePkgWithCode(t, `
+	package main
+
+	type Link struct {
+		Next *Link
+	}
+	`)
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax}, "net/url")
+	if err != nil || len(pkgs) != 1 {
+		t.Fatalf("failed to load net/url: %v", err)
+	}
+
+	element, err := ParseTarget(pkgs[0], local, "URL", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if element.Type.LocalName != "url.URL" {
+		t.Errorf("expected the local name url.URL, got %s", element.Type.LocalName)
+	}
+	for _, attribute := range element.Attributes {
+		if attribute.Name == "User" && (attribute.Type.LocalName != "*url.Userinfo" || attribute.Type.Elem.LocalName != "url.Userinfo") {
+			t.Errorf("expected the local names *url.Userinfo and url.Userinfo, got %s and %s", attribute.Type.LocalName, attribute.Type.Elem.LocalName)
+		}
+	}
+	for _, method := range element.Methods {
+		if method.Name == "ResolveReference" && (method.Params[0].LocalName != "*url.URL" || method.Returns[0].LocalName != "*url.URL") {
+			t.Errorf("expected the local names *url.URL of the method, got %s and %s", method.Params[0].LocalName, method.Returns[0].LocalName)
+		}
+	}
+
+	element, err = ParseTarget(local, local, "Link", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if element.Type.LocalName != "Link" || element.Attributes[0].Type.LocalName != "*Link" {
+		t.Errorf("expected the local names of the local package, got %s and %s", element.Type.LocalName, element.Attributes[0].Type.LocalName)
+	}
+}
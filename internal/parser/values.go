@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/leorolland/genz/pkg/models"
+	"golang.org/x/tools/go/packages"
+)
+
+// parseValues returns the exported constants and variables of the package, in source order.
+func parseValues(pkg *packages.Package) models.Package {
+	var parsed models.Package
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, isGenDecl := decl.(*ast.GenDecl)
+			if !isGenDecl || (genDecl.Tok != token.CONST && genDecl.Tok != token.VAR) {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				doc := valueSpec.Doc
+				if doc == nil && !genDecl.Lparen.IsValid() { // e.g. "// Timeout is...\nconst Timeout = 5"
+					doc = genDecl.Doc
+				}
+				comments := []string{}
+				if doc != nil {
+					for _, comment := range doc.List {
+						comments = append(comments, comment.Text[2:])
+					}
+				}
+				for i, ident := range valueSpec.Names {
+					if !ident.IsExported() {
+						continue
+					}
+					switch object := pkg.TypesInfo.Defs[ident].(type) {
+					case *types.Const:
+						parsed.Constants = append(parsed.Constants, models.Value{
+							Name:     ident.Name,
+							Type:     parseType(types.Default(object.Type()), pkg.Types), // e.g. "int" for an untyped constant
+							Value:    object.Val().ExactString(),
+							Comments: comments,
+						})
+					case *types.Var:
+						value := ""
+						if len(valueSpec.Values) == len(valueSpec.Names) {
+							value = types.ExprString(valueSpec.Values[i])
+						}
+						parsed.Variables = append(parsed.Variables, models.Value{
+							Name:     ident.Name,
+							Type:     parseType(object.Type(), pkg.Types),
+							Value:    value,
+							Comments: comments,
+						})
+					}
+				}
+			}
+		}
+	}
+	return parsed
+}
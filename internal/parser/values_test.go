@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leorolland/genz/internal/testutils"
+	"github.com/leorolland/genz/pkg/models"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseValuesSuccess(t *testing.T) {
+	intType := models.Type{Name: "int", InternalName: "int", LocalName: "int"}
+	testCases := map[string]struct {
+		goCode          string
+		typeName        string
+		expectedPackage models.Package
+	}{
+		"no value": {
+			goCode: `
+			package main
+			`,
+			expectedPackage: models.Package{},
+		},
+		"constants and variables without type": {
+			goCode: `
+			package main
+
+			import "time"
+
+			// Timeout is the timeout.
+			const Timeout = 5 * time.Second
+
+			const (
+				// Name of the app.
+				Name    = "genz"
+				private = 1
+				Max     = 10
+			)
+
+			// Debug enables the debug mode.
+			var Debug = false
+
+			var A, B = pair()
+
+			func pair() (int, int) { return 1, 2 }
+			`,
+			expectedPackage: models.Package{
+				Constants: []models.Value{
+					{
+						Name:     "Timeout",
+						Type:     models.Type{Name: "time.Duration", InternalName: "Duration", LocalName: "time.Duration", PkgPath: "time", BasicKind: "int64"},
+						Value:    "5000000000",
+						Comments: []string{" Timeout is the timeout."},
+					},
+					{
+						Name:     "Name",
+						Type:     models.Type{Name: "string", InternalName: "string", LocalName: "string"},
+						Value:    `"genz"`,
+						Comments: []string{" Name of the app."},
+					},
+					{Name: "Max", Type: intType, Value: "10", Comments: []string{}},
+				},
+				Variables: []models.Value{
+					{
+						Name:     "Debug",
+						Type:     models.Type{Name: "bool", InternalName: "bool", LocalName: "bool"},
+						Value:    "false",
+						Comments: []string{" Debug enables the debug mode."},
+					},
+					{Name: "A", Type: intType, Comments: []string{}},
+					{Name: "B", Type: intType, Comments: []string{}},
+				},
+			},
+		},
+		"values with a type": {
+			goCode: `
+			package main
+
+			type A struct{}
+
+			var Default = A{}
+			`,
+			typeName: "A",
+			expectedPackage: models.Package{
+				Variables: []models.Value{
+					{
+						Name:     "Default",
+						Type:     models.Type{Name: "main.A", InternalName: "A", LocalName: "A", PkgPath: "command-line-arguments"},
+						Value:    "A{}",
+						Comments: []string{},
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			pkg := testutils.CreatePkgWithCode(t, tc.goCode)
+
+			parsed, err := parse(pkg, tc.typeName, Options{Values: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			values := models.Package{Constants: parsed.Package.Constants, Variables: parsed.Package.Variables}
+			if !reflect.DeepEqual(values, tc.expectedPackage) {
+				t.Errorf("output package doesn't match expected:\n%s", cmp.Diff(values, tc.expectedPackage))
+			}
+		})
+	}
+}
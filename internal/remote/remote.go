@@ -0,0 +1,171 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// checksumPrefix starts the fragment of a URL giving the SHA-256 checksum of its content,
+// e.g. "https://example.com/getters.tmpl#sha256=9f86d0...".
+const checksumPrefix = "sha256="
+
+// IsModule returns true if the given location is a file or a directory of a version of a Go module,
+// e.g. "github.com/org/templates/builder.tmpl@v1.2.0": an import path whose first element is a domain name,
+// followed by a version.
+func IsModule(location string) bool {
+	importPath, version, found := splitModule(location)
+	if !found || strings.Contains(location, "://") || strings.HasPrefix(importPath, "/") {
+		return false
+	}
+	domain, _, _ := strings.Cut(importPath, "/")
+	return strings.Contains(domain, ".") && domain != "." && domain != ".." &&
+		strings.HasPrefix(version, "v") && !strings.ContainsAny(version, `/\`)
+}
+
+// TestLocation returns the location of the test template of the given template location: the file with a _test suffix,
+// e.g. "github.com/org/templates/builder.tmpl_test@v1.2.0" for "github.com/org/templates/builder.tmpl@v1.2.0".
+func TestLocation(location string) (string, error) {
+	if IsModule(location) {
+		importPath, version, _ := splitModule(location)
+		return importPath + "_test@" + version, nil
+	}
+	if u, err := url.Parse(location); err == nil && strings.HasPrefix(u.Fragment, checksumPrefix) {
+		return "", fmt.Errorf("the test template of %s cannot be verified by its checksum", location)
+	}
+	return location + "_test", nil
+}
+
+// Module returns the local path of the given module location, see IsModule. The module is downloaded with
+// go mod download: it is resolved through GOPROXY, verified against GOSUMDB and cached in GOMODCACHE, as any
+// dependency. Since the module path is not known, each parent path of the location is tried, from the longest.
+func Module(location string) (string, error) {
+	importPath, version, _ := splitModule(location)
+	var errs []error
+	for modulePath, sub := importPath, ""; modulePath != "."; {
+		dir, err := download(modulePath, version)
+		if err == nil {
+			return filepath.Join(dir, filepath.FromSlash(sub)), nil
+		}
+		errs = append(errs, err)
+		sub = path.Join(path.Base(modulePath), sub)
+		modulePath = path.Dir(modulePath)
+	}
+	return "", fmt.Errorf("no module found for %s:\n%w", location, errors.Join(errs...))
+}
+
+// download downloads the given version of the given module, and returns its directory in the module cache.
+// It runs from a temporary directory, so that the current module, its go.mod and its go.sum are left aside.
+func download(modulePath, version string) (string, error) {
+	dir, err := os.MkdirTemp("", "genz-download")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "mod", "download", "-json", modulePath+"@"+version)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=on")
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	runErr := cmd.Run()
+	var downloaded struct {
+		Dir   string
+		Error string
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &downloaded); err != nil {
+		return "", fmt.Errorf("go mod download %s@%s: %v: %s", modulePath, version, runErr, strings.TrimSpace(stderr.String()))
+	}
+	if downloaded.Error != "" {
+		return "", errors.New(downloaded.Error)
+	}
+	if runErr != nil {
+		return "", fmt.Errorf("go mod download %s@%s: %w", modulePath, version, runErr)
+	}
+	return downloaded.Dir, nil
+}
+
+// splitModule splits the given location at its last @, e.g. "github.com/org/templates/builder.tmpl" and "v1.2.0".
+func splitModule(location string) (string, string, bool) {
+	i := strings.LastIndex(location, "@")
+	if i < 0 {
+		return location, "", false
+	}
+	return location[:i], location[i+1:], true
+}
+
+// Fetch returns the content of the given http(s) URL. A URL with a "#sha256=<hex>" fragment is verified against this
+// checksum, and cached in the given directory by checksum: it is then downloaded only once.
+func Fetch(location, cacheDir string) ([]byte, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	checksum := ""
+	if strings.HasPrefix(u.Fragment, checksumPrefix) {
+		checksum = strings.ToLower(strings.TrimPrefix(u.Fragment, checksumPrefix))
+		if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 checksum %s of %s", checksum, location)
+		}
+	}
+	cached := filepath.Join(cacheDir, checksum)
+	if checksum != "" {
+		if content, err := os.ReadFile(cached); err == nil && sum(content) == checksum {
+			return content, nil
+		}
+	}
+
+	u.Fragment = ""
+	response, err := http.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to make a request to %s: %v", location, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get %s: %s", location, response.Status)
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read body of remote template %s: %v", location, err)
+	}
+	if checksum == "" {
+		return body, nil
+	}
+	if actual := sum(body); actual != checksum {
+		return nil, fmt.Errorf("checksum mismatch for %s: got sha256=%s", location, actual)
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to cache %s: %v", location, err)
+	}
+	if err := os.WriteFile(cached, body, 0644); err != nil {
+		return nil, fmt.Errorf("failed to cache %s: %v", location, err)
+	}
+	return body, nil
+}
+
+// CacheDir returns the directory caching the templates downloaded from URLs with a checksum: genz/templates in the
+// user cache directory, e.g. ~/.cache/genz/templates on Linux.
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "genz", "templates"), nil
+}
+
+// sum returns the hexadecimal SHA-256 checksum of the given content.
+func sum(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
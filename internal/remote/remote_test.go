@@ -0,0 +1,150 @@
+package remote
+
+import (
+	"archive/zip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsModule(t *testing.T) {
+	testCases := map[string]bool{
+		"github.com/org/templates/builder.tmpl@v1.2.0":                    true,
+		"github.com/org/templates/api@v0.0.0-20231010120000-abcdefabcdef": true,
+		"example.com/templates@v1.0.0":                                    true,
+		"github.com/org/templates/builder.tmpl":                           false,
+		"./templates/builder.tmpl@v1.2.0":                                 false,
+		"templates/builder.tmpl@v1.2.0":                                   false,
+		"/abs/example.com/builder.tmpl@v1.2.0":                            false,
+		"https://example.com/builder.tmpl@v1.2.0":                         false,
+		"builtin:getters":                                                 false,
+		"github.com/org/templates/builder.tmpl@latest":                    false,
+		"github.com/org/templates/builder.tmpl@v1.2.0/other.tmpl":         false,
+	}
+	for location, expected := range testCases {
+		if actual := IsModule(location); actual != expected {
+			t.Errorf("IsModule(%s) = %t, expected %t", location, actual, expected)
+		}
+	}
+}
+
+func TestTestLocation(t *testing.T) {
+	testCases := map[string]string{
+		"./getters.tmpl":                      "./getters.tmpl_test",
+		"https://example.com/getters.tmpl":    "https://example.com/getters.tmpl_test",
+		"example.com/templates/x.tmpl@v1.0.0": "example.com/templates/x.tmpl_test@v1.0.0",
+	}
+	for location, expected := range testCases {
+		if actual, err := TestLocation(location); err != nil || actual != expected {
+			t.Errorf("TestLocation(%s) = %s, %v, expected %s", location, actual, err, expected)
+		}
+	}
+	if _, err := TestLocation("https://example.com/getters.tmpl#sha256=00"); err == nil {
+		t.Error("expected an error for a URL with a checksum")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	content := "{{ .Type.Name }}"
+	checksum := sum([]byte(content))
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/getters.tmpl" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+	cacheDir := filepath.Join(t.TempDir(), "templates")
+
+	for i := 0; i < 2; i++ {
+		body, err := Fetch(server.URL+"/getters.tmpl#sha256="+strings.ToUpper(checksum), cacheDir)
+		if err != nil || string(body) != content {
+			t.Fatalf("Fetch() = %s, %v", body, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the verified template to be downloaded once, got %d requests", requests)
+	}
+	if body, err := Fetch(server.URL+"/getters.tmpl", cacheDir); err != nil || string(body) != content || requests != 2 {
+		t.Errorf("Fetch() without checksum = %s, %v after %d requests", body, err, requests)
+	}
+
+	for name, location := range map[string]string{
+		"checksum mismatch": server.URL + "/getters.tmpl#sha256=" + sum([]byte("other")),
+		"invalid checksum":  server.URL + "/getters.tmpl#sha256=xyz",
+		"not found":         server.URL + "/unknown.tmpl",
+	} {
+		if _, err := Fetch(location, cacheDir); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestModule downloads a template directory from a module proxy of the local file system.
+func TestModule(t *testing.T) {
+	proxy := t.TempDir()
+	versions := filepath.Join(proxy, "example.com", "templates", "@v")
+	if err := os.MkdirAll(versions, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"list":        "v1.0.0\n",
+		"v1.0.0.info": `{"Version":"v1.0.0"}`,
+		"v1.0.0.mod":  "module example.com/templates\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(versions, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archive, err := os.Create(filepath.Join(versions, "v1.0.0.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := zip.NewWriter(archive)
+	for name, content := range map[string]string{
+		"go.mod":            "module example.com/templates\n",
+		"api/main.tmpl":     `{{ template "header" }}`,
+		"api/header.tmpl":   `{{ define "header" }}// header{{ end }}`,
+		"builder/main.tmpl": "builder",
+	} {
+		file, err := writer.Create("example.com/templates@v1.0.0/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Write([]byte(content))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive.Close()
+
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(proxy))
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("GOFLAGS", "-modcacherw")
+
+	dir, err := Module("example.com/templates/api@v1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "header.tmpl")); err != nil || !strings.Contains(string(content), "// header") {
+		t.Errorf("unexpected header.tmpl in %s: %s, %v", dir, content, err)
+	}
+	file, err := Module("example.com/templates/builder/main.tmpl@v1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, err := os.ReadFile(file); err != nil || string(content) != "builder" {
+		t.Errorf("unexpected %s: %s, %v", file, content, err)
+	}
+	if _, err := Module("example.com/templates/api@v2.0.0"); err == nil {
+		t.Error("expected an error for an unknown version")
+	}
+}
@@ -0,0 +1,176 @@
+package render
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/parser"
+	"github.com/leorolland/genz/internal/utils"
+	"github.com/leorolland/genz/pkg/models"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ParseFunc parses the model of a type of the package given to the templates, see generator.GenerateWithFuncs.
+type ParseFunc = func(pkg *packages.Package, typeName string) (models.ParsedElement, error)
+
+// Prepare returns the types of the given package selected by the target, "" for the package itself, their output
+// files, and the function parsing their model with the options of the target, the To, Interfaces and compared types
+// it parses once, and its Vars.
+func Prepare(target config.Target, pkg *packages.Package) ([]string, []string, ParseFunc, error) {
+	var err error
+	typeNames := []string{""} // Package only: the template is rendered once for the package.
+	var left, right *models.Element
+	if target.Compares() {
+		left, right, err = parseCompared(target, pkg)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	} else if len(target.Types) != 0 || target.TypeRegex != "" || !target.ParsesPackage() {
+		typeNames, err = parser.SelectTypes(pkg, target.Types, target.TypeRegex)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	outputNames, err := OutputPaths(target, typeNames)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	parseWithOptions := parser.WithOptions(parser.Options{
+		FlattenEmbedded:   target.FlattenEmbedded,
+		Recursive:         target.Recursive,
+		Functions:         target.Functions,
+		Values:            target.Values,
+		Positions:         target.Positions,
+		Implementations:   target.Implementations,
+		IncludeTags:       target.IncludeTags,
+		ExcludeTags:       target.ExcludeTags,
+		ExcludeUnexported: target.ExcludeUnexported,
+	})
+	var to *models.Element
+	if target.To != "" {
+		element, err := ParseTo(target, pkg)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		to = &element
+	}
+	var interfaces []models.Element
+	if len(target.Interfaces) > 0 {
+		interfaces, err = parseInterfaces(target, pkg)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	parse := func(pkg *packages.Package, typeName string) (models.ParsedElement, error) {
+		parsedElement, err := parseWithOptions(pkg, typeName)
+		parsedElement.Target = to
+		parsedElement.Interfaces = interfaces
+		parsedElement.Left, parsedElement.Right = left, right
+		parsedElement.Vars = target.Vars
+		return parsedElement, err
+	}
+	return typeNames, outputNames, parse, nil
+}
+
+// ParseTo parses the To type of the target, declared in the given package, or in the package of the path or of the
+// directory qualifying it, e.g. "./domain.User". See models.ParsedElement.Target.
+func ParseTo(target config.Target, pkg *packages.Package) (models.Element, error) {
+	typePkg, typeName, err := loadQualified(target, pkg, target.To)
+	if err != nil {
+		return models.Element{}, err
+	}
+	return parser.ParseTarget(typePkg, pkg, typeName, targetOptions(target))
+}
+
+// parseCompared parses the two types compared by the target, qualified as its To type. See models.ParsedElement.Left.
+func parseCompared(target config.Target, pkg *packages.Package) (*models.Element, *models.Element, error) {
+	if err := target.CheckCompared(); err != nil {
+		return nil, nil, err
+	}
+	elements := make([]*models.Element, len(target.Types))
+	for i, qualified := range target.Types {
+		typePkg, typeName, err := loadQualified(target, pkg, qualified)
+		if err != nil {
+			return nil, nil, err
+		}
+		element, err := parser.ParseTarget(typePkg, pkg, typeName, targetOptions(target))
+		if err != nil {
+			return nil, nil, err
+		}
+		elements[i] = &element
+	}
+	return elements[0], elements[1], nil
+}
+
+// parseInterfaces parses the Interfaces of the target, qualified as its To type. See models.ParsedElement.Interfaces.
+func parseInterfaces(target config.Target, pkg *packages.Package) ([]models.Element, error) {
+	interfaces := make([]models.Element, 0, len(target.Interfaces))
+	for _, qualified := range target.Interfaces {
+		typePkg, typeName, err := loadQualified(target, pkg, qualified)
+		if err != nil {
+			return nil, err
+		}
+		if object, isTypeName := typePkg.Types.Scope().Lookup(typeName).(*types.TypeName); !isTypeName || !types.IsInterface(object.Type()) {
+			return nil, fmt.Errorf("%s is not an interface of %s", typeName, typePkg.PkgPath)
+		}
+		element, err := parser.ParseTarget(typePkg, pkg, typeName, targetOptions(target))
+		if err != nil {
+			return nil, err
+		}
+		interfaces = append(interfaces, element)
+	}
+	return interfaces, nil
+}
+
+// loadQualified returns the package declaring the given type name and its unqualified name: the given package, or the
+// package of the path or of the directory qualifying it, e.g. "./domain.User".
+func loadQualified(target config.Target, pkg *packages.Package, qualified string) (*packages.Package, string, error) {
+	i := strings.LastIndex(qualified, ".")
+	if i < 0 {
+		return pkg, qualified, nil
+	}
+	pattern, typeName := qualified[:i], qualified[i+1:]
+	pkgs, err := utils.LoadPackages([]string{pattern}, LoadOptions(target))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(pkgs) != 1 || pkgs[0].Types == nil || len(pkgs[0].Errors) != 0 {
+		return nil, "", fmt.Errorf("failed to load the package %s of the type %s", pattern, qualified)
+	}
+	return pkgs[0], typeName, nil
+}
+
+// targetOptions returns the options of the types parsed from another package than the one of the target, see
+// parser.ParseTarget.
+func targetOptions(target config.Target) parser.Options {
+	return parser.Options{
+		FlattenEmbedded:   target.FlattenEmbedded,
+		Positions:         target.Positions,
+		IncludeTags:       target.IncludeTags,
+		ExcludeTags:       target.ExcludeTags,
+		ExcludeUnexported: target.ExcludeUnexported,
+	}
+}
+
+// LoadPackage loads the package of the target with the given loader, which may be nil, or the single file package read
+// from the standard input.
+func LoadPackage(loader *utils.Loader, target config.Target) (*packages.Package, error) {
+	if len(target.Inputs) != 1 || target.Inputs[0] != config.Stdio {
+		return loader.LoadPackage(target.Inputs, LoadOptions(target))
+	}
+	src, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the standard input: %w", err)
+	}
+	return utils.LoadSource(src, LoadOptions(target))
+}
+
+// LoadOptions returns the options loading the packages of the target.
+func LoadOptions(target config.Target) utils.LoadOptions {
+	return utils.LoadOptions{Tags: target.Tags, Tests: target.Tests, BuildFlags: target.BuildFlags, AllowErrors: target.AllowErrors}
+}
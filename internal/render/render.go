@@ -0,0 +1,312 @@
+// Package render renders the templates of the targets into the contents of their outputs, without writing them.
+// It is shared by the genz commands and pkg/genztest, and has no global state: the loader of the packages and the
+// data of the header of the outputs are given by the caller.
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/leorolland/genz/internal/builtin"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/utils"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Output is an output file of a target, rendered as genz writes it.
+type Output struct {
+	// Name is the file of the output, e.g. "models/user.gen.go".
+	Name string
+	// Content is the rendered content, post-processed: with its header, formatted and its imports fixed for a Go file.
+	Content []byte
+}
+
+// Render renders the outputs of the template of the target without writing them, e.g. to compare them to golden
+// files, see pkg/genztest. The outputs are the ones genz would write, in the order it writes them. The test outputs
+// of WithTests are not rendered. An output failing its post-processing is returned as rendered, along with its error.
+// The package is loaded with the given loader, which may be nil, and the header of the Go outputs is given header.
+func Render(target config.Target, loader *utils.Loader, header generator.HeaderData) ([]Output, error) {
+	if target.Plugin != "" {
+		return nil, failure.New(failure.KindUsage, fmt.Errorf("the outputs of the plugin %s cannot be rendered", target.Plugin))
+	}
+	if len(target.Inputs) == 1 && utils.IsPackagePattern(target.Inputs[0]) {
+		return nil, failure.New(failure.KindUsage, fmt.Errorf("the package pattern %s cannot be rendered, select a directory", target.Inputs[0]))
+	}
+	var (
+		template []byte
+		partials []generator.Partial
+		err      error
+	)
+	if target.TemplateDir != "" {
+		template, partials, err = ReadTemplateDir(target.TemplateDir, target.Template)
+	} else {
+		template, err = ReadTemplate(target.Template)
+	}
+	if err != nil {
+		return nil, failure.New(failure.KindTemplate, err)
+	}
+	pkg, err := LoadPackage(loader, target)
+	if err != nil {
+		return nil, err
+	}
+	return Package(target, pkg, template, partials, header)
+}
+
+// Package renders the outputs of the given template for the package, post-processed, see Render.
+func Package(target config.Target, pkg *packages.Package, template []byte, partials []generator.Partial, header generator.HeaderData) ([]Output, error) {
+	funcs, err := generator.LoadFuncs(target.Funcs)
+	if err != nil {
+		return nil, failure.New(failure.KindTemplate, err)
+	}
+	typeNames, outputNames, parse, err := Prepare(target, pkg)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := Outputs(target, pkg, TemplateFile(target), string(template), partials, funcs, typeNames, outputNames, parse)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make([]Output, len(rendered))
+	var errs error
+	for i, output := range rendered {
+		outputs[i] = Output{Name: output.Name, Content: []byte(output.Buf.String())}
+		src, err := PostProcess(target, output.Name, output.Buf, header)
+		if err != nil {
+			errs = errors.Join(errs, &failure.Error{Kind: failure.KindTemplate, File: output.Name, Err: err})
+			continue
+		}
+		outputs[i].Content = src
+	}
+	return outputs, errs
+}
+
+// Rendered is the rendered content of an output file, before its post-processing, see PostProcess.
+type Rendered struct {
+	// Name is the output file.
+	Name string
+	// Buf is the rendered content.
+	Buf bytes.Buffer
+}
+
+// Outputs renders the template for each of the types into their output files, combined with Combine, followed
+// by the additional files emitted by the template, in the order they are written. The additional files emitted by
+// several types under the same name are combined.
+func Outputs(
+	target config.Target,
+	pkg *packages.Package,
+	templateName string,
+	template string,
+	partials []generator.Partial,
+	funcs texttemplate.FuncMap,
+	typeNames []string,
+	outputNames []string,
+	parse ParseFunc,
+) ([]Rendered, error) {
+	bufs := make([]bytes.Buffer, len(typeNames))
+	var files []generator.File
+	for i, typeName := range typeNames {
+		var err error
+		bufs[i], err = generator.GenerateWithFuncs(pkg, template, typeName, parse, funcs, partials...)
+		if err != nil {
+			return nil, TemplateFileError(target, templateName, err)
+		}
+		var typeFiles []generator.File
+		bufs[i], typeFiles, err = generator.SplitFiles(bufs[i])
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, typeFiles...)
+	}
+	if target.Combine {
+		var err error
+		bufs, err = combineBuffers(bufs, OutputExtension(target, outputNames[0]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var outputs []Rendered
+	for i, buf := range bufs {
+		// A template emitting only additional files leaves the main output empty.
+		if len(files) != 0 && len(bytes.TrimSpace(buf.Bytes())) == 0 {
+			continue
+		}
+		outputs = append(outputs, Rendered{Name: outputNames[i], Buf: buf})
+	}
+	names, contents := []string{}, map[string][]bytes.Buffer{}
+	for _, file := range files {
+		name := file.Name
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(outputNames[0]), name)
+		}
+		if _, ok := contents[name]; !ok {
+			names = append(names, name)
+		}
+		contents[name] = append(contents[name], file.Content)
+	}
+	for _, name := range names {
+		bufs, err := combineBuffers(contents[name], filepath.Ext(name))
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, Rendered{Name: name, Buf: bufs[0]})
+	}
+	return outputs, nil
+}
+
+// combineBuffers merges the generated buffers into the single buffer of an output of the given extension:
+// YAML and JSON documents are merged, Go and proto files share a single package clause and imports, GraphQL schemas
+// declare each type once, other files are concatenated.
+func combineBuffers(bufs []bytes.Buffer, extension string) ([]bytes.Buffer, error) {
+	switch extension {
+	case ".yaml", ".yml":
+		buf, err := generator.CombineYAML(bufs)
+		return []bytes.Buffer{buf}, err
+	case ".json":
+		buf, err := generator.CombineJSON(bufs)
+		return []bytes.Buffer{buf}, err
+	case ".proto":
+		buf, err := generator.CombineProto(bufs)
+		return []bytes.Buffer{buf}, err
+	case ".graphql":
+		buf, err := generator.CombineGraphQL(bufs)
+		return []bytes.Buffer{buf}, err
+	default:
+		return []bytes.Buffer{generator.Combine(bufs)}, nil
+	}
+}
+
+// PostProcess adds the header and the build constraint of the target to the generated Go source, gofmts it and fixes its imports, unless
+// disabled by the target. Non-Go outputs (e.g. schema.sql, types.ts) are written as rendered. The header is given the
+// version and the command line of the given data.
+func PostProcess(target config.Target, outputName string, buf bytes.Buffer, data generator.HeaderData) ([]byte, error) {
+	if target.Raw || OutputExtension(target, outputName) != ".go" {
+		return buf.Bytes(), nil
+	}
+	if target.Header != "none" {
+		header := target.Header
+		if header == "" {
+			header = generator.DefaultHeader
+		}
+		var err error
+		buf, err = generator.AddHeader(buf, header, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf, err := generator.AddBuildConstraint(buf, target.Build)
+	if err != nil {
+		return nil, err
+	}
+	src, err := generator.Format(buf)
+	if err != nil {
+		return nil, err
+	}
+	if target.NoImportsFix {
+		return src, nil
+	}
+	if outputName == config.Stdio {
+		outputName = "genz_stdout.go" // A file of the current directory, to resolve the imports from its module.
+	}
+	return generator.FixImports(outputName, src)
+}
+
+// OutputExtension returns the extension of the given output file. The Stdio output is a Go file, unless the template
+// is a built-in template generating another kind of file.
+func OutputExtension(target config.Target, outputName string) string {
+	if outputName != config.Stdio {
+		return filepath.Ext(outputName)
+	}
+	if name, isBuiltin := builtin.FromLocation(target.Template); isBuiltin {
+		return builtin.Extension(name)
+	}
+	return ".go"
+}
+
+// OutputPaths returns the output files of the target: one per type, or a single one when the types are combined.
+// Default: <input directory>/<type>.gen.go, or <input directory>/<type>_<built-in template>.gen.<extension>, ".gen"
+// being replaced by the Suffix of the target if set. The Stdio input is written to the Stdio output by default.
+// A package rendered without type is named after "functions", or "values" with Values only.
+// An Output written as a template is rendered for each type, see config.Target.RenderOutput.
+func OutputPaths(target config.Target, typeNames []string) ([]string, error) {
+	// We accept either one directory or a list of files. Which do we have?
+	var dir string
+	if len(target.Inputs) == 1 && target.Inputs[0] == config.Stdio {
+		dir = "."
+		if target.Output == "" {
+			target.Output = config.Stdio
+		}
+	} else if len(target.Inputs) == 1 && utils.IsDirectory(target.Inputs[0]) {
+		dir = target.Inputs[0]
+	} else {
+		if len(target.Tags) != 0 {
+			return nil, fmt.Errorf("-tags option applies only to directories, not when files are specified")
+		}
+		dir = filepath.Dir(target.Inputs[0])
+	}
+
+	if target.Combine {
+		typeNames = typeNames[:1]
+	}
+	if target.HasOutputTemplate() {
+		outputNames := make([]string, len(typeNames))
+		rendered := map[string]string{}
+		for i, typeName := range typeNames {
+			name, err := target.RenderOutput(typeName)
+			if err != nil {
+				return nil, failure.New(failure.KindUsage, err)
+			}
+			if other, found := rendered[name]; found {
+				return nil, fmt.Errorf("the types %s and %s have the same output %s, see -combine", other, typeName, name)
+			}
+			rendered[name] = typeName
+			outputNames[i] = name
+		}
+		return outputNames, nil
+	}
+	if target.Output != "" {
+		if len(typeNames) > 1 {
+			return nil, fmt.Errorf("-output requires -combine with several types")
+		}
+		return []string{target.Output}, nil
+	}
+	suffix := target.Suffix
+	if suffix == "" {
+		suffix = ".gen"
+	}
+	outputNames := make([]string, len(typeNames))
+	for i, typeName := range typeNames {
+		if typeName == "" && target.Compares() {
+			typeName = comparedName(target)
+		} else if typeName == "" {
+			typeName = target.String()
+		}
+		baseName := fmt.Sprintf("%s%s.go", typeName, suffix)
+		if name, isBuiltin := builtin.FromLocation(target.Template); isBuiltin {
+			baseName = fmt.Sprintf("%s_%s%s%s", typeName, name, suffix, builtin.Extension(name))
+		}
+		if target.Tests && strings.HasSuffix(baseName, ".go") {
+			// The test-only types can only be used by test files.
+			baseName = strings.TrimSuffix(baseName, ".go") + "_test.go"
+		}
+		outputNames[i] = filepath.Join(dir, strings.ToLower(baseName))
+	}
+	return outputNames, nil
+}
+
+// comparedName returns the name of the output of the types compared by the target, without their packages,
+// e.g. "User_UserV2" for ["./v1.User", "UserV2"].
+func comparedName(target config.Target) string {
+	names := make([]string, len(target.Types))
+	for i, qualified := range target.Types {
+		names[i] = qualified[strings.LastIndex(qualified, ".")+1:]
+	}
+	return strings.Join(names, "_")
+}
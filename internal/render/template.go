@@ -0,0 +1,159 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/leorolland/genz/internal/builtin"
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/remote"
+	"github.com/leorolland/genz/internal/utils"
+)
+
+// TemplateFile returns the file of the main template of the target, e.g. "templates/api/main.tmpl" or "builtin:getters".
+func TemplateFile(target config.Target) string {
+	if target.TemplateDir == "" {
+		return target.Template
+	}
+	entrypoint := target.Template
+	if entrypoint == "" {
+		entrypoint = "main.tmpl"
+	}
+	return filepath.Join(target.TemplateDir, entrypoint)
+}
+
+// TemplateFileError names the failing template of a *generator.TemplateError after its file: the given file for
+// the main template, or the file of a partial of the template directory.
+func TemplateFileError(target config.Target, templateName string, err error) error {
+	var templateErr *generator.TemplateError
+	if !errors.As(err, &templateErr) {
+		return err
+	}
+	if templateErr.Name == generator.MainTemplate {
+		templateErr.Name = templateName
+	} else if target.TemplateDir != "" {
+		templateErr.Name = filepath.Join(target.TemplateDir, filepath.FromSlash(templateErr.Name))
+	}
+	return err
+}
+
+// ReadTemplate returns the content of the given local, remote, module or built-in template.
+func ReadTemplate(location string) ([]byte, error) {
+	if name, isBuiltin := builtin.FromLocation(location); isBuiltin {
+		return builtin.Template(name)
+	}
+	if utils.IsRemote(location) {
+		cacheDir, err := remote.CacheDir()
+		if err != nil {
+			return nil, err
+		}
+		return remote.Fetch(location, cacheDir)
+	}
+	file := location
+	if remote.IsModule(location) {
+		var err error
+		if file, err = remote.Module(location); err != nil {
+			return nil, err
+		}
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file %s: %v", location, err)
+	}
+	return content, nil
+}
+
+// LocalTemplateDir returns the given template directory, downloaded first if it is a directory of a module,
+// e.g. "github.com/org/templates/api@v1.2.0".
+func LocalTemplateDir(dir string) (string, error) {
+	if !remote.IsModule(dir) {
+		return dir, nil
+	}
+	return remote.Module(dir)
+}
+
+// ReadTestTemplate returns the content of the test template of the target, rendered with -with-tests:
+// the template location with a _test suffix (e.g. getters.tmpl_test), or the test template of a built-in template.
+func ReadTestTemplate(target config.Target) ([]byte, error) {
+	if target.TemplateDir != "" {
+		entrypoint := target.Template
+		if entrypoint == "" {
+			entrypoint = "main.tmpl"
+		}
+		dir, err := LocalTemplateDir(target.TemplateDir)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, entrypoint+"_test")
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read test template file %s: %v", path, err)
+		}
+		return content, nil
+	}
+	if name, isBuiltin := builtin.FromLocation(target.Template); isBuiltin {
+		return builtin.TemplateTests(name)
+	}
+	location, err := remote.TestLocation(target.Template)
+	if err != nil {
+		return nil, err
+	}
+	return ReadTemplate(location)
+}
+
+// ReadTemplateDir returns the content of the entrypoint template of the given directory, main.tmpl by default,
+// and the other templates of the directory and of its subdirectories as partials.
+func ReadTemplateDir(dir, entrypoint string) ([]byte, []generator.Partial, error) {
+	if entrypoint == "" {
+		entrypoint = "main.tmpl"
+	}
+	dir, err := LocalTemplateDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := TemplateFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	var template []byte
+	var partials []generator.Partial
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read template file %s: %v", file, err)
+		}
+		name, _ := filepath.Rel(dir, file)
+		name = filepath.ToSlash(name)
+		if name == filepath.ToSlash(filepath.Clean(entrypoint)) {
+			template = content
+			continue
+		}
+		partials = append(partials, generator.Partial{Name: name, Content: string(content)})
+	}
+	if template == nil {
+		return nil, nil, fmt.Errorf("template %s not found in template directory %s", entrypoint, dir)
+	}
+	return template, partials, nil
+}
+
+// TemplateFiles returns the *.tmpl files of the given directory and of its subdirectories, sorted.
+func TemplateFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && filepath.Ext(path) == ".tmpl" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template directory %s: %v", dir, err)
+	}
+	return files, nil
+}
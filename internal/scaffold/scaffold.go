@@ -0,0 +1,304 @@
+// Package scaffold renders the template bundles of genz new into the files of a new package, e.g. a service.
+//
+// A bundle is a directory of templates: each file is rendered into the file of the same path, with its path rendered
+// too (e.g. "cmd/{{ .Name }}/main.go.tmpl" into "cmd/user-service/main.go") and its .tmpl suffix removed. A file whose
+// path renders to an empty element is skipped. The optional scaffold.yaml file of the bundle declares its variables,
+// see Manifest.
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/leorolland/genz/internal/generator"
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestName is the name of the manifest of a bundle, at its root. It is not rendered.
+const ManifestName = "scaffold.yaml"
+
+type (
+	// Manifest is the content of the scaffold.yaml file of a bundle.
+	Manifest struct {
+		// Description of the bundle, printed before the prompts.
+		Description string `yaml:"description"`
+		// Vars are the variables of the bundle, available as .Vars in the templates, asked for unless given.
+		Vars []Var `yaml:"vars"`
+	}
+
+	// Var is a variable of a bundle.
+	Var struct {
+		// Name of the variable, e.g. "port" for {{ .Vars.port }}.
+		Name string `yaml:"name"`
+		// Prompt asking for the variable. Default: its name.
+		Prompt string `yaml:"prompt"`
+		// Default is the template of the default value, given the Data and the previous variables,
+		// e.g. "{{ .Name }} service". The variable is required without a default.
+		Default string `yaml:"default"`
+		// Choices are the accepted values, if any.
+		Choices []string `yaml:"choices"`
+	}
+
+	// Data is given to the templates of a bundle.
+	Data struct {
+		// Name is the name of the new package given to genz new, e.g. "user-service".
+		Name string
+		// Package is the Go package name of the new package, e.g. "userservice".
+		Package string
+		// Module is the path of the module of the new package: the one of the go.mod found from its directory,
+		// or its import path, e.g. "github.com/acme/shop".
+		Module string
+		// ImportPath is the import path of the new package, e.g. "github.com/acme/shop/user-service".
+		ImportPath string
+		// Vars are the variables of the bundle.
+		Vars map[string]string
+	}
+
+	// File is a scaffolded file.
+	File struct {
+		// Path of the file, slash-separated and relative to the directory of the new package, e.g. "cmd/main.go".
+		Path string
+		// Content of the file.
+		Content []byte
+	}
+)
+
+// LoadManifest returns the manifest of the given bundle directory, empty if it has none.
+func LoadManifest(dir string) (Manifest, error) {
+	var manifest Manifest
+	content, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return manifest, err
+	}
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return manifest, fmt.Errorf("failed to parse %s: %w", ManifestName, err)
+	}
+	for i, v := range manifest.Vars {
+		if v.Name == "" {
+			return manifest, fmt.Errorf("%s: missing name of variable %d", ManifestName, i)
+		}
+	}
+	return manifest, nil
+}
+
+// NewData returns the data of the new package of the given name, scaffolded into the given directory. Its import path
+// is the one of the directory in the module of the closest go.mod, unless the module path is given, e.g. for a bundle
+// scaffolding a new module: the new package is then at its root.
+func NewData(name, dir, module string) (Data, error) {
+	data := Data{Name: name, Package: packageName(path.Base(filepath.ToSlash(name))), Vars: map[string]string{}}
+	if data.Package == "" {
+		return data, fmt.Errorf("invalid name %q, expected letters or digits for its package name", name)
+	}
+	if module != "" {
+		data.Module, data.ImportPath = module, module
+		return data, nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return data, err
+	}
+	for root := dir; ; {
+		content, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			data.Module = modfile.ModulePath(content)
+			if data.Module == "" {
+				return data, fmt.Errorf("no module path in %s", filepath.Join(root, "go.mod"))
+			}
+			rel, err := filepath.Rel(root, dir)
+			if err != nil {
+				return data, err
+			}
+			data.ImportPath = path.Join(data.Module, filepath.ToSlash(rel))
+			return data, nil
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			break
+		}
+		root = parent
+	}
+	data.Module, data.ImportPath = filepath.ToSlash(name), filepath.ToSlash(name)
+	return data, nil
+}
+
+// packageName returns the Go package name of the given name: its letters and digits, lower-cased, e.g. "userservice"
+// for "user-service".
+func packageName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || (unicode.IsDigit(r) && b.Len() > 0) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// Ask fills the variables of the data: the given ones, then the answers to the prompts of the manifest written to out
+// and read from in, an empty answer keeping the default value. Without input (nil in), the default values are used,
+// and a variable without default must be given.
+func Ask(manifest Manifest, data *Data, given map[string]string, in io.Reader, out io.Writer) error {
+	for name, value := range given {
+		data.Vars[name] = value
+	}
+	var reader *bufio.Reader
+	if in != nil {
+		reader = bufio.NewReader(in)
+	}
+	for _, v := range manifest.Vars {
+		if value, found := given[v.Name]; found {
+			if err := checkChoice(v, value); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := render("default of "+v.Name, v.Default, *data)
+		if err != nil {
+			return err
+		}
+		if reader == nil {
+			if value == "" {
+				return fmt.Errorf("missing variable %s, e.g. -var %s=<value>", v.Name, v.Name)
+			}
+		} else if value, err = prompt(v, value, reader, out); err != nil {
+			return err
+		}
+		if err := checkChoice(v, value); err != nil {
+			return err
+		}
+		data.Vars[v.Name] = value
+	}
+	return nil
+}
+
+// prompt asks for the given variable until the answer is valid, and returns it, or the default value if empty.
+func prompt(v Var, defaultValue string, reader *bufio.Reader, out io.Writer) (string, error) {
+	question := v.Prompt
+	if question == "" {
+		question = v.Name
+	}
+	if len(v.Choices) > 0 {
+		question += " (" + strings.Join(v.Choices, ", ") + ")"
+	}
+	if defaultValue != "" {
+		question += " [" + defaultValue + "]"
+	}
+	for {
+		fmt.Fprintf(out, "%s: ", question)
+		answer, err := reader.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || answer == "") {
+			return "", fmt.Errorf("reading variable %s: %w", v.Name, err)
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = defaultValue
+		}
+		if answer == "" {
+			fmt.Fprintf(out, "%s is required\n", v.Name)
+			continue
+		}
+		if err := checkChoice(v, answer); err != nil {
+			fmt.Fprintln(out, err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// checkChoice returns an error if the variable has choices and the value is not one of them.
+func checkChoice(v Var, value string) error {
+	if len(v.Choices) == 0 {
+		return nil
+	}
+	for _, choice := range v.Choices {
+		if value == choice {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s %q, expected one of %s", v.Name, value, strings.Join(v.Choices, ", "))
+}
+
+// Render renders the files of the bundle of the given directory with the given data. The additional files emitted by
+// a template with {{ file "name" }} ... {{ endfile }} are relative to the directory of its file.
+func Render(dir string, data Data) ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == ManifestName {
+			return nil
+		}
+		name, err := render(rel, rel, data)
+		if err != nil {
+			return err
+		}
+		name = strings.TrimSuffix(name, ".tmpl")
+		for _, element := range strings.Split(name, "/") {
+			if element == "" {
+				return nil // e.g. "{{ if .Vars.docker }}Dockerfile{{ end }}"
+			}
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if !utf8.Valid(content) {
+			files = append(files, File{Path: name, Content: content}) // e.g. an image, copied as is
+			return nil
+		}
+		rendered, err := render(rel, string(content), data)
+		if err != nil {
+			return err
+		}
+		main, extra, err := generator.SplitFiles(*bytes.NewBufferString(rendered))
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		if len(extra) == 0 || len(bytes.TrimSpace(main.Bytes())) > 0 {
+			files = append(files, File{Path: name, Content: main.Bytes()})
+		}
+		for _, f := range extra {
+			files = append(files, File{Path: path.Join(path.Dir(name), f.Name), Content: f.Content.Bytes()})
+		}
+		return nil
+	})
+	return files, err
+}
+
+// render executes the given template with the data, the name identifying it in the errors.
+func render(name, content string, data Data) (string, error) {
+	tmpl, err := generator.Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,157 @@
+package scaffold
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestNewData(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"go.mod": "module github.com/acme/shop\n\ngo 1.20\n"})
+
+	data, err := NewData("user-service", filepath.Join(root, "services", "user-service"), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Data{
+		Name:       "user-service",
+		Package:    "userservice",
+		Module:     "github.com/acme/shop",
+		ImportPath: "github.com/acme/shop/services/user-service",
+		Vars:       map[string]string{},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("data doesn't match expected:\n%s", cmp.Diff(data, expected))
+	}
+
+	data, err = NewData("Billing", filepath.Join(root, "billing"), "github.com/acme/billing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Package != "billing" || data.Module != "github.com/acme/billing" || data.ImportPath != "github.com/acme/billing" {
+		t.Errorf("unexpected data %+v", data)
+	}
+
+	if _, err := NewData("---", root, ""); err == nil {
+		t.Errorf("expected an error for a name without letters")
+	}
+}
+
+func TestAsk(t *testing.T) {
+	manifest := Manifest{Vars: []Var{
+		{Name: "description", Prompt: "Description", Default: "The {{ .Name }} service"},
+		{Name: "db", Choices: []string{"postgres", "mysql"}, Default: "postgres"},
+		{Name: "port"},
+	}}
+
+	data := Data{Name: "users", Vars: map[string]string{}}
+	var out bytes.Buffer
+	err := Ask(manifest, &data, map[string]string{"port": "8080"}, strings.NewReader("\nsqlite\nmysql\n"), &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{"description": "The users service", "db": "mysql", "port": "8080"}
+	if !reflect.DeepEqual(data.Vars, expected) {
+		t.Errorf("vars don't match expected:\n%s", cmp.Diff(data.Vars, expected))
+	}
+	expectedOut := "Description [The users service]: db (postgres, mysql) [postgres]: invalid db \"sqlite\", expected one of postgres, mysql\n" +
+		"db (postgres, mysql) [postgres]: "
+	if out.String() != expectedOut {
+		t.Errorf("expected the prompts %q, got %q", expectedOut, out.String())
+	}
+
+	data = Data{Name: "users", Vars: map[string]string{}}
+	err = Ask(manifest, &data, nil, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "missing variable port") {
+		t.Errorf("expected a missing variable error, got %v", err)
+	}
+
+	data = Data{Name: "users", Vars: map[string]string{}}
+	err = Ask(manifest, &data, map[string]string{"port": "80", "db": "oracle"}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), `invalid db "oracle"`) {
+		t.Errorf("expected an invalid choice error, got %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		ManifestName:  "vars: [{name: docker, default: \"no\"}]",
+		"go.mod.tmpl": "module {{ .Module }}\n",
+		"cmd/{{ .Name }}/main.go.tmpl": "package main\n\n// {{ .Vars.docker }}\n" +
+			"{{ file \"README.md\" }}# {{ .Name }}\n{{ endfile }}",
+		"{{ .Package }}.go": "package {{ .Package }}\n",
+		"{{ if eq .Vars.docker \"yes\" }}Dockerfile{{ end }}": "FROM scratch\n",
+		"logo.png": "\xff\xd8{{",
+	})
+
+	files, err := Render(dir, Data{Name: "user-service", Package: "userservice", Module: "github.com/acme/users", Vars: map[string]string{"docker": "no"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual := map[string]string{}
+	for _, f := range files {
+		actual[f.Path] = string(f.Content)
+	}
+	expected := map[string]string{
+		"go.mod":                     "module github.com/acme/users\n",
+		"cmd/user-service/main.go":   "package main\n\n// no\n",
+		"cmd/user-service/README.md": "# user-service\n",
+		"userservice.go":             "package userservice\n",
+		"logo.png":                   "\xff\xd8{{",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("files don't match expected:\n%s", cmp.Diff(actual, expected))
+	}
+}
+
+func TestRenderError(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"main.go.tmpl": "package {{ .Unknown }}\n"})
+	_, err := Render(dir, Data{Name: "users"})
+	if err == nil || !strings.Contains(err.Error(), "main.go.tmpl") {
+		t.Errorf("expected an error of main.go.tmpl, got %v", err)
+	}
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest, err := LoadManifest(dir)
+	if err != nil || !reflect.DeepEqual(manifest, Manifest{}) {
+		t.Errorf("expected an empty manifest, got %+v, %v", manifest, err)
+	}
+
+	writeFiles(t, dir, map[string]string{ManifestName: "description: A service\nvars:\n  - name: port\n    default: \"8080\"\n"})
+	manifest, err = LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Manifest{Description: "A service", Vars: []Var{{Name: "port", Default: "8080"}}}
+	if !reflect.DeepEqual(manifest, expected) {
+		t.Errorf("manifest doesn't match expected:\n%s", cmp.Diff(manifest, expected))
+	}
+
+	writeFiles(t, dir, map[string]string{ManifestName: "vars: [{prompt: Port}]"})
+	if _, err := LoadManifest(dir); err == nil {
+		t.Errorf("expected an error for a variable without name")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/leorolland/genz/internal/generator"
 	"github.com/leorolland/genz/internal/utils"
 )
 
@@ -85,6 +86,9 @@ func runTest(directory string, verbose bool) error {
 	if err := removeFiles(generatedFiles); err != nil {
 		return err
 	}
+	if !generator.IsGenerated(expected) {
+		actual = withoutHeader(actual)
+	}
 	if err := assertOutputIsEqual("expected.go", generatedFiles[0], expected, actual, verbose); err != nil {
 		return err
 	}
@@ -1,10 +1,13 @@
 package testing
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/google/go-cmp/cmp"
 	"log"
 	"os"
+
+	"github.com/leorolland/genz/internal/generator"
 )
 
 // assertOutputIsEqual compares the expected and actual byte slices and returns an error if they are different.
@@ -33,3 +36,13 @@ func removeFiles(generatedFiles []string) error {
 	}
 	return nil
 }
+
+// withoutHeader removes the header comment prepended by genz to the generated Go files, see generator.AddHeader,
+// so that they can be compared to expected files written without it.
+func withoutHeader(src []byte) []byte {
+	header, rest, found := bytes.Cut(src, []byte("\n\n"))
+	if !found || !bytes.HasPrefix(header, []byte("//")) || !generator.IsGenerated(header) {
+		return src
+	}
+	return rest
+}
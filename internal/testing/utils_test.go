@@ -28,3 +28,30 @@ func TestAssertOutputIsEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestWithoutHeader(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"Header should be removed": {
+			src:  "// Code generated by genz 0.0.1. DO NOT EDIT.\n// genz -type Car\n\npackage test\n",
+			want: "package test\n",
+		},
+		"Package documentation should be kept": {
+			src:  "// Package test is a test.\n\npackage test\n",
+			want: "// Package test is a test.\n\npackage test\n",
+		},
+		"Source without header should be kept": {
+			src:  "package test\n\nfunc A() {}\n",
+			want: "package test\n\nfunc A() {}\n",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := string(withoutHeader([]byte(tc.src))); got != tc.want {
+				t.Errorf("withoutHeader() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -2,28 +2,206 @@ package utils
 
 import (
 	"fmt"
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/logging"
 	"golang.org/x/tools/go/packages"
 	"log"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
-func LoadPackage(patterns []string, tags []string) *packages.Package {
-	cfg := &packages.Config{
-		Mode:       packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
-		Tests:      false,
-		BuildFlags: []string{fmt.Sprintf("-tags=%s", strings.Join(tags, " "))},
+// LoadOptions are the options of the loading of packages.
+type LoadOptions struct {
+	// Tags is the list of build tags to apply.
+	Tags []string
+	// Tests includes the _test.go files of the packages, so that their test-only types can be parsed.
+	// The types of the external test packages, e.g. package foo_test, are not loaded.
+	Tests bool
+	// BuildFlags are passed as is to the build system, e.g. "-mod=vendor", after the build tags. The GOFLAGS
+	// environment variable and the GOPACKAGESDRIVER of Bazel or please builds apply as well.
+	BuildFlags []string
+	// AllowErrors loads the packages having compile errors, e.g. referencing the code not generated yet, instead of
+	// failing. Their errors are logged, and the expressions of the invalid types are kept as written.
+	AllowErrors bool
+}
+
+// LoadPackage loads the single package matching the given patterns.
+func LoadPackage(patterns []string, options LoadOptions) (*packages.Package, error) {
+	pkgs, err := LoadPackages(patterns, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, failure.New(failure.KindUsage, fmt.Errorf("%d packages matching %v", len(pkgs), strings.Join(patterns, " ")))
+	}
+	return pkgs[0], nil
+}
+
+// LoadPackages loads the packages matching the given patterns at once, e.g. "./..." for all the packages of a module.
+func LoadPackages(patterns []string, options LoadOptions) ([]*packages.Package, error) {
+	pkgs, err := packages.Load(loadConfig(options), patterns...)
+	if err != nil {
+		return nil, failure.New(failure.KindParse, err)
+	}
+	if options.Tests {
+		pkgs = testVariants(pkgs)
+	}
+	return pkgs, checkErrors(pkgs, options)
+}
+
+// maxErrors is the maximum number of package errors listed in the message, as a missing declaration often causes many
+// of them.
+const maxErrors = 10
+
+// packageErrors are the errors of the loaded packages, classified as failure.KindParse and located.
+type packageErrors []error
+
+func (p packageErrors) Error() string {
+	messages := make([]string, 0, maxErrors+1)
+	for i, err := range p {
+		if i == maxErrors {
+			messages = append(messages, fmt.Sprintf("and %d more errors", len(p)-maxErrors))
+			break
+		}
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("the packages have errors, see -allow-errors:\n\t%s", strings.Join(messages, "\n\t"))
+}
+
+func (p packageErrors) Unwrap() []error {
+	return p
+}
+
+// newPackageError returns the given error of a package, located at its position, e.g. "foo.go:12:5".
+func newPackageError(pkgErr packages.Error) error {
+	located := &failure.Error{Kind: failure.KindParse, Err: pkgErr}
+	// The file name may contain colons, e.g. on Windows: the line and the column are read from the end.
+	parts := strings.Split(pkgErr.Pos, ":")
+	for len(parts) > 1 {
+		number, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		located.Line, located.Column = number, located.Line
+		parts = parts[:len(parts)-1]
+	}
+	if located.Line != 0 {
+		located.File = strings.Join(parts, ":")
+	}
+	return located
+}
+
+// checkErrors returns the errors of the given packages, or logs them with options.AllowErrors.
+func checkErrors(pkgs []*packages.Package, options LoadOptions) error {
+	var errs packageErrors
+	for _, pkg := range pkgs {
+		// The go command reports the type errors as well: they are kept only once, with their position.
+		hasTypeErrors := false
+		for _, pkgErr := range pkg.Errors {
+			hasTypeErrors = hasTypeErrors || pkgErr.Kind != packages.ListError
+		}
+		for _, pkgErr := range pkg.Errors {
+			if !hasTypeErrors || pkgErr.Kind != packages.ListError {
+				errs = append(errs, newPackageError(pkgErr))
+			}
+		}
 	}
+	if len(errs) == 0 {
+		return nil
+	}
+	if options.AllowErrors {
+		for _, err := range errs {
+			logging.Warn("ignoring error", "error", err)
+		}
+		return nil
+	}
+	return errs
+}
+
+// ListPackages lists the names and the files of the packages matching the given patterns, without loading their syntax
+// nor their types, e.g. to scan the comments of their files.
+func ListPackages(patterns []string, options LoadOptions) ([]*packages.Package, error) {
+	cfg := loadConfig(options)
+	cfg.Mode = packages.NeedName | packages.NeedFiles
 	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil || !options.Tests {
+		return pkgs, err
+	}
+	return testVariants(pkgs), nil
+}
+
+// loadConfig returns the configuration loading the syntax and the types of packages with the given options.
+// The environment is inherited, so that GOFLAGS, e.g. -mod=vendor, and GOPACKAGESDRIVER are respected.
+func loadConfig(options LoadOptions) *packages.Config {
+	return &packages.Config{
+		Mode:       packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax | packages.NeedModule,
+		Tests:      options.Tests,
+		BuildFlags: buildFlags(options),
+	}
+}
+
+// buildFlags returns the flags of the build system for the given options. An empty -tags flag is not given, as it would
+// override the tags of GOFLAGS, and some drivers do not accept it.
+func buildFlags(options LoadOptions) []string {
+	var flags []string
+	if len(options.Tags) > 0 {
+		flags = append(flags, fmt.Sprintf("-tags=%s", strings.Join(options.Tags, ",")))
+	}
+	return append(flags, options.BuildFlags...)
+}
+
+// testVariants replaces the packages having test files by their test variant, e.g. "foo [foo.test]", which includes
+// their _test.go files. The external test packages, e.g. "foo_test [foo.test]", and the test binaries are dropped.
+func testVariants(pkgs []*packages.Package) []*packages.Package {
+	variants := map[string]*packages.Package{}
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.ID, ".test]") && !strings.HasSuffix(pkg.Name, "_test") {
+			variants[pkg.PkgPath] = pkg
+		}
+	}
+	var result []*packages.Package
+	for _, pkg := range pkgs {
+		switch {
+		case strings.Contains(pkg.ID, " ["), strings.HasSuffix(pkg.ID, ".test"), strings.HasSuffix(pkg.Name, "_test"):
+			continue
+		case variants[pkg.PkgPath] != nil:
+			result = append(result, variants[pkg.PkgPath])
+		default:
+			result = append(result, pkg)
+		}
+	}
+	return result
+}
+
+// LoadSource loads the given Go source as a single file package, as if it was a file of the current directory,
+// so that its imports are resolved from the current module. Nothing is written on disk.
+func LoadSource(src []byte, options LoadOptions) (*packages.Package, error) {
+	wd, err := os.Getwd()
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	name := filepath.Join(wd, "genz_stdin.go")
+	options.Tests = false // A single file has no tests.
+	cfg := loadConfig(options)
+	cfg.Overlay = map[string][]byte{name: src}
+	pkgs, err := packages.Load(cfg, name)
+	if err != nil {
+		return nil, err
 	}
 	if len(pkgs) != 1 {
-		log.Fatalf("error: %d packages matching %v", len(pkgs), strings.Join(patterns, " "))
+		return nil, fmt.Errorf("%d packages loaded from the standard input", len(pkgs))
 	}
+	// The errors are tolerated, as the declarations of the other files of the package are not loaded.
+	return pkgs[0], nil
+}
 
-	return pkgs[0]
+// IsPackagePattern returns true if the given input is a package pattern matching several packages, e.g. "./...".
+func IsPackagePattern(input string) bool {
+	return strings.Contains(input, "...")
 }
 
 func IsDirectory(name string) bool {
@@ -34,6 +212,15 @@ func IsDirectory(name string) bool {
 	return info.IsDir()
 }
 
+// IsRemote returns true if the given location is an http(s) URL rather than a local file path.
+func IsRemote(location string) bool {
+	u, err := url.ParseRequestURI(location)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "http" || u.Scheme == "https"
+}
+
 // RunCommand runs a command and returns an error if it fails.
 // If verbose is true, the command output is printed.
 func RunCommand(command []string, verbose bool) error {
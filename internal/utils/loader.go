@@ -0,0 +1,274 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/leorolland/genz/internal/failure"
+	"github.com/leorolland/genz/internal/logging"
+	"golang.org/x/tools/go/packages"
+)
+
+// Loader shares the loading of packages between several targets, e.g. the targets of a genz.yaml: the directories
+// added with the same options and in the same module are loaded and type-checked at once, their common dependencies
+// once, when the first of them is needed. The loaded packages are kept until they change, see Refresh.
+// A nil Loader loads every package on its own, as LoadPackage does.
+type Loader struct {
+	groups map[string]*loadGroup
+}
+
+// loadGroup is a set of directories loaded at once.
+type loadGroup struct {
+	options LoadOptions
+	// dirs are the absolute directories added to the group.
+	dirs map[string]bool
+	// pending are the directories of the group to load, as given, in the order they were added.
+	pending []string
+	// pkgs are the loaded packages, by absolute directory.
+	pkgs map[string]*packages.Package
+	// sums are the checksums of the Go files of the packages when they were loaded, see Loader.Invalidate.
+	sums map[string][sha256.Size]byte
+	// stamps are the stamps of the input directories of the packages when they were loaded, by package directory,
+	// see Loader.Refresh.
+	stamps map[string]string
+}
+
+// NewLoader returns a loader sharing nothing yet, see Loader.Add.
+func NewLoader() *Loader {
+	return &Loader{groups: map[string]*loadGroup{}}
+}
+
+// Add declares the given pattern to be loaded with the given options, along with the other ones added with the same
+// options. Only a directory is shared: a package pattern (e.g. "./..."), a list of files or the standard input is
+// loaded on its own.
+func (l *Loader) Add(pattern string, options LoadOptions) {
+	if l == nil || IsPackagePattern(pattern) || !IsDirectory(pattern) {
+		return
+	}
+	dir, err := filepath.Abs(pattern)
+	if err != nil {
+		return
+	}
+	key := loadKey(dir, options)
+	group := l.groups[key]
+	if group == nil {
+		group = &loadGroup{
+			options: options,
+			dirs:    map[string]bool{},
+			pkgs:    map[string]*packages.Package{},
+			sums:    map[string][sha256.Size]byte{},
+			stamps:  map[string]string{},
+		}
+		l.groups[key] = group
+	}
+	if group.pkgs[dir] != nil {
+		return
+	}
+	for _, pending := range group.pending {
+		if other, err := filepath.Abs(pending); err == nil && other == dir {
+			return
+		}
+	}
+	group.dirs[dir] = true
+	group.pending = append(group.pending, pattern)
+}
+
+// LoadPackage returns the single package matching the given patterns, as LoadPackage does: the package loaded with
+// the other directories of its group if it was added, or the package loaded on its own otherwise.
+// The errors of the package are checked with the given options, e.g. AllowErrors.
+func (l *Loader) LoadPackage(patterns []string, options LoadOptions) (*packages.Package, error) {
+	if l == nil || len(patterns) != 1 {
+		return LoadPackage(patterns, options)
+	}
+	dir, err := filepath.Abs(patterns[0])
+	if err != nil {
+		return LoadPackage(patterns, options)
+	}
+	group := l.groups[loadKey(dir, options)]
+	if group == nil || !group.dirs[dir] {
+		return LoadPackage(patterns, options)
+	}
+	group.load()
+	pkg := group.pkgs[dir]
+	if pkg == nil {
+		// Not loaded with its group, or invalidated since: it is loaded on its own, reporting its own errors.
+		return LoadPackage(patterns, options)
+	}
+	if err := checkErrors([]*packages.Package{pkg}, options); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// Invalidate drops the shared packages whose Go files were changed by the given written files, e.g. the outputs of a
+// target, so that the next targets reading them load them again, with the generated code.
+// An output written with the same content as when its package was loaded keeps the package shared.
+func (l *Loader) Invalidate(files []string) {
+	if l == nil {
+		return
+	}
+	for _, file := range files {
+		if filepath.Ext(file) != ".go" {
+			continue
+		}
+		name, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		dir := filepath.Dir(name)
+		for _, group := range l.groups {
+			if group.pkgs[dir] == nil {
+				continue
+			}
+			sum, known := group.sums[name]
+			if content, err := os.ReadFile(name); err != nil || !known || sha256.Sum256(content) != sum {
+				logging.Debug("reloading the package of a written file", "file", file)
+				delete(group.pkgs, dir)
+			}
+		}
+	}
+}
+
+// Refresh drops the loaded packages whose Go files, or the ones of the packages of their module they import, were
+// added, removed or modified since they were loaded, e.g. by an editor, so that they are loaded again when added again.
+func (l *Loader) Refresh() {
+	if l == nil {
+		return
+	}
+	for _, group := range l.groups {
+		for dir, pkg := range group.pkgs {
+			if stampDirs(InputDirs(pkg)) != group.stamps[dir] {
+				logging.Debug("reloading a changed package", "dir", dir)
+				delete(group.pkgs, dir)
+			}
+		}
+	}
+}
+
+// load loads the pending directories of the group at once. On failure, e.g. a directory without Go files, they are not
+// shared: their packages are loaded on their own, reporting their own errors.
+func (g *loadGroup) load() {
+	if len(g.pending) == 0 {
+		return
+	}
+	patterns := g.pending
+	g.pending = nil
+	pkgs, err := packages.Load(loadConfig(g.options), patterns...)
+	if err != nil {
+		logging.Debug("failed to load the packages at once", "error", failure.New(failure.KindParse, err))
+		return
+	}
+	if g.options.Tests {
+		pkgs = testVariants(pkgs)
+	}
+	loaded := 0
+	for _, pkg := range pkgs {
+		files := pkg.GoFiles
+		if len(files) == 0 {
+			files = pkg.CompiledGoFiles
+		}
+		if len(files) == 0 || !g.dirs[filepath.Dir(files[0])] {
+			continue
+		}
+		dir := filepath.Dir(files[0])
+		g.pkgs[dir] = pkg
+		g.stamps[dir] = stampDirs(InputDirs(pkg))
+		for _, file := range files {
+			if content, err := os.ReadFile(file); err == nil {
+				g.sums[file] = sha256.Sum256(content)
+			}
+		}
+		loaded++
+	}
+	logging.Debug("loaded the packages at once", "directories", strings.Join(patterns, " "), "packages", loaded)
+}
+
+// loadKey returns the key of the group of the given directory: the directories of a group share their options, except
+// AllowErrors which is checked for each package, and their module, since the go command loads a single one at once.
+func loadKey(dir string, options LoadOptions) string {
+	var key bytes.Buffer
+	key.WriteString(moduleRoot(dir))
+	key.WriteString("\x00" + strconv.FormatBool(options.Tests))
+	for _, flag := range buildFlags(options) {
+		key.WriteString("\x00" + flag)
+	}
+	return key.String()
+}
+
+// moduleRoot returns the directory of the closest go.mod of the given directory, or "" if there is none.
+func moduleRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// stampDirs returns a stamp of the Go files of the given directories, test files included: their names, sizes and
+// modification times. Adding, removing or saving a file changes it, without reading the files.
+func stampDirs(dirs []string) string {
+	h := sha256.New()
+	for _, dir := range dirs {
+		entries, _ := os.ReadDir(dir) // A removed directory has an empty stamp.
+		fmt.Fprintf(h, "%s\x00", dir)
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".go" {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(h, "%s:%d:%d\x00", entry.Name(), info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return string(h.Sum(nil))
+}
+
+// InputDirs returns the directory of the package and the directories of the packages of its module it imports,
+// directly or not, e.g. for the structs resolved with Recursive.
+func InputDirs(pkg *packages.Package) []string {
+	dirs := map[string]bool{}
+	for _, file := range pkg.Syntax {
+		dirs[filepath.Dir(pkg.Fset.File(file.Pos()).Name())] = true
+	}
+	if pkg.Module != nil && pkg.Types != nil {
+		seen := map[*types.Package]bool{}
+		var visit func(imported *types.Package)
+		visit = func(imported *types.Package) {
+			if seen[imported] {
+				return
+			}
+			seen[imported] = true
+			path := imported.Path()
+			if path != pkg.Module.Path && !strings.HasPrefix(path, pkg.Module.Path+"/") {
+				return
+			}
+			dirs[filepath.Join(pkg.Module.Dir, filepath.FromSlash(strings.TrimPrefix(path, pkg.Module.Path)))] = true
+			for _, next := range imported.Imports() {
+				visit(next)
+			}
+		}
+		for _, imported := range pkg.Types.Imports() {
+			visit(imported)
+		}
+	}
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
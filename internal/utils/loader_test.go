@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/shared\n\ngo 1.20\n"
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return dir
+}
+
+// chdir changes the working directory to the given module for the test, so that its packages are loaded from it.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestLoader(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"users/user.go":    "package users\n\ntype User struct{ ID int }\n",
+		"orders/order.go":  "package orders\n\nimport \"example.com/shared/users\"\n\ntype Order struct{ User users.User }\n",
+		"broken/broken.go": "package broken\n\nvar x int = \"x\"\n",
+	})
+	users, orders, broken := filepath.Join(dir, "users"), filepath.Join(dir, "orders"), filepath.Join(dir, "broken")
+	chdir(t, dir)
+
+	loader := NewLoader()
+	for _, pattern := range []string{users, orders, broken} {
+		loader.Add(pattern, LoadOptions{})
+	}
+	usersPkg, err := loader.LoadPackage([]string{users}, LoadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ordersPkg, err := loader.LoadPackage([]string{orders}, LoadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ordersPkg.Types.Scope().Lookup("Order") == nil || usersPkg.Types.Scope().Lookup("User") == nil {
+		t.Fatalf("expected the types of the packages")
+	}
+	if again, _ := loader.LoadPackage([]string{users}, LoadOptions{}); again != usersPkg {
+		t.Errorf("expected the shared package")
+	}
+
+	// The errors of a package are checked for its own target only.
+	if _, err := loader.LoadPackage([]string{broken}, LoadOptions{}); err == nil {
+		t.Errorf("expected the errors of the broken package")
+	}
+	if pkg, err := loader.LoadPackage([]string{broken}, LoadOptions{AllowErrors: true}); err != nil || pkg == nil {
+		t.Errorf("expected the broken package with AllowErrors, got %v", err)
+	}
+
+	// A file written with the same content keeps the package shared, a new one reloads it.
+	loader.Invalidate([]string{filepath.Join(users, "user.go")})
+	if again, _ := loader.LoadPackage([]string{users}, LoadOptions{}); again != usersPkg {
+		t.Errorf("expected the shared package after an unchanged file")
+	}
+	generated := filepath.Join(users, "user.gen.go")
+	if err := os.WriteFile(generated, []byte("package users\n\nfunc (u User) Name() string { return \"\" }\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loader.Invalidate([]string{generated, filepath.Join(users, "schema.sql")})
+	reloaded, err := loader.LoadPackage([]string{users}, LoadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reloaded == usersPkg || len(reloaded.GoFiles) != 2 {
+		t.Errorf("expected the package reloaded with the generated file, got %v", reloaded.GoFiles)
+	}
+	if again, _ := loader.LoadPackage([]string{orders}, LoadOptions{}); again != ordersPkg {
+		t.Errorf("expected the other packages kept shared")
+	}
+}
+
+func TestLoaderNil(t *testing.T) {
+	dir := writeModule(t, map[string]string{"users/user.go": "package users\n\ntype User struct{ ID int }\n"})
+	chdir(t, dir)
+
+	var loader *Loader
+	loader.Add("./users", LoadOptions{})
+	loader.Invalidate([]string{"./users/user.go"})
+	pkg, err := loader.LoadPackage([]string{"./users"}, LoadOptions{})
+	if err != nil || pkg.Name != "users" {
+		t.Errorf("expected the package loaded on its own, got %v", err)
+	}
+}
+
+func TestLoaderRefresh(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"users/user.go":   "package users\n\ntype User struct{ ID int }\n",
+		"orders/order.go": "package orders\n\nimport \"example.com/shared/users\"\n\ntype Order struct{ User users.User }\n",
+		"other/other.go":  "package other\n\ntype Other struct{}\n",
+	})
+	users, orders, other := filepath.Join(dir, "users"), filepath.Join(dir, "orders"), filepath.Join(dir, "other")
+	chdir(t, dir)
+
+	loader := NewLoader()
+	loaded := map[string]any{}
+	for _, pattern := range []string{users, orders, other} {
+		loader.Add(pattern, LoadOptions{})
+	}
+	for _, pattern := range []string{users, orders, other} {
+		pkg, err := loader.LoadPackage([]string{pattern}, LoadOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		loaded[pattern] = pkg
+	}
+
+	loader.Refresh()
+	if pkg, _ := loader.LoadPackage([]string{users}, LoadOptions{}); pkg != loaded[users] {
+		t.Errorf("expected the unchanged package kept")
+	}
+
+	// Editing a package reloads it, and the packages importing it.
+	if err := os.WriteFile(filepath.Join(users, "user.go"), []byte("package users\n\ntype User struct{ ID, Age int }\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loader.Refresh()
+	for _, pattern := range []string{users, orders, other} {
+		loader.Add(pattern, LoadOptions{})
+	}
+	for pattern, reloaded := range map[string]bool{users: true, orders: true, other: false} {
+		pkg, err := loader.LoadPackage([]string{pattern}, LoadOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if (pkg != loaded[pattern]) != reloaded {
+			t.Errorf("%s: expected reloaded %t", filepath.Base(pattern), reloaded)
+		}
+	}
+}
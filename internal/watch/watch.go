@@ -0,0 +1,132 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/leorolland/genz/internal/logging"
+)
+
+const (
+	// Interval is the delay between two polls of the watched files.
+	Interval = 500 * time.Millisecond
+	// Debounce is the delay the watched files must stay unchanged before onChange is called.
+	// It avoids regenerating several times when an editor or a VCS writes many files at once.
+	Debounce = time.Second
+)
+
+// fileState is the state of a watched file, compared between two polls to detect changes.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch polls the given files and directories until the context is done,
+// and calls onChange once the watched files stopped changing for the Debounce delay.
+// Directories are not watched recursively and only their .go files are watched.
+// Ignored files (e.g. the generated output) never trigger onChange, nor do the files onChange returns, which are
+// ignored from then on: the outputs it writes, including the ones of a generation failing at first.
+// An error returned by onChange is logged and does not stop the watch.
+// The watched paths must exist when the watch starts. A file missing later, e.g. replaced by an editor saving it, is
+// a change once it is back.
+func Watch(ctx context.Context, paths, ignored []string, onChange func() ([]string, error)) error {
+	ticker := time.NewTicker(Interval)
+	defer ticker.Stop()
+
+	for _, name := range paths {
+		if _, err := os.Stat(name); err != nil {
+			return err
+		}
+	}
+	ignored = append([]string(nil), ignored...)
+	previous, err := snapshot(paths, ignored)
+	if err != nil {
+		return err
+	}
+	var lastChange time.Time
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			current, err := snapshot(paths, ignored)
+			if err != nil {
+				return err
+			}
+			if !equal(previous, current) {
+				previous = current
+				lastChange = now
+				pending = true
+				continue
+			}
+			if pending && now.Sub(lastChange) >= Debounce {
+				pending = false
+				written, err := onChange()
+				if err != nil {
+					logging.Error("generation failed", "error", err)
+				}
+				if len(written) != 0 {
+					ignored = append(ignored, written...)
+					if previous, err = snapshot(paths, ignored); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+}
+
+// snapshot returns the state of every watched file. The missing files are left out.
+func snapshot(paths, ignored []string) (map[string]fileState, error) {
+	ignoredFiles := map[string]bool{}
+	for _, name := range ignored {
+		ignoredFiles[filepath.Clean(name)] = true
+	}
+
+	files := map[string]fileState{}
+	for _, name := range paths {
+		info, err := os.Stat(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // e.g. replaced by an editor, being saved
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files[filepath.Clean(name)] = fileState{modTime: info.ModTime(), size: info.Size()}
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(name, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				continue // removed since the glob
+			}
+			files[filepath.Clean(match)] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	for name := range ignoredFiles {
+		delete(files, name)
+	}
+	return files, nil
+}
+
+func equal(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, state := range a {
+		if other, ok := b[name]; !ok || !other.modTime.Equal(state.modTime) || other.size != state.size {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchCallsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "main.go")
+	output := filepath.Join(dir, "main.gen.go")
+	if err := os.WriteFile(source, []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("failed while writing file: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	changes := make(chan struct{}, 10)
+	go func() {
+		_ = Watch(ctx, []string{dir}, []string{output}, func() ([]string, error) {
+			changes <- struct{}{}
+			return nil, nil
+		})
+	}()
+
+	time.Sleep(Interval)
+	if err := os.WriteFile(output, []byte("package main\n\n"), 0644); err != nil {
+		t.Fatalf("failed while writing file: %v", err)
+	}
+	select {
+	case <-changes:
+		t.Fatalf("onChange called for an ignored file")
+	case <-time.After(Interval + 2*Debounce):
+	}
+
+	if err := os.WriteFile(source, []byte("package main\n\ntype A struct{}\n"), 0644); err != nil {
+		t.Fatalf("failed while writing file: %v", err)
+	}
+	select {
+	case <-changes:
+	case <-ctx.Done():
+		t.Fatalf("onChange not called after a change")
+	}
+}
+
+func TestWatchErrorMissingPath(t *testing.T) {
+	err := Watch(context.Background(), []string{filepath.Join(t.TempDir(), "missing")}, nil, func() ([]string, error) { return nil, nil })
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestWatchIgnoresWrittenFiles(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "main.go")
+	template := filepath.Join(dir, "main.tmpl")
+	output := filepath.Join(dir, "main.gen.go")
+	for _, name := range []string{source, template} {
+		if err := os.WriteFile(name, []byte("package main\n"), 0644); err != nil {
+			t.Fatalf("failed while writing file: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	changes := make(chan struct{}, 10)
+	done := make(chan error)
+	go func() {
+		// The output is first written by a generation, which rewrites it each time.
+		done <- Watch(ctx, []string{dir, template}, nil, func() ([]string, error) {
+			changes <- struct{}{}
+			return []string{output}, os.WriteFile(output, []byte(time.Now().String()), 0644)
+		})
+	}()
+
+	time.Sleep(Interval)
+	// An editor saving the template replaces it.
+	if err := os.Remove(template); err != nil {
+		t.Fatalf("failed while removing file: %v", err)
+	}
+	time.Sleep(2 * Interval)
+	if err := os.WriteFile(template, []byte("package main\n\n"), 0644); err != nil {
+		t.Fatalf("failed while writing file: %v", err)
+	}
+	select {
+	case <-changes:
+	case err := <-done:
+		t.Fatalf("watch stopped: %v", err)
+	case <-ctx.Done():
+		t.Fatalf("onChange not called after a change")
+	}
+	select {
+	case <-changes:
+		t.Fatalf("onChange called for the written output")
+	case err := <-done:
+		t.Fatalf("watch stopped: %v", err)
+	case <-time.After(Interval + 2*Debounce):
+	}
+}
@@ -1,13 +1,13 @@
 package main
 
 import (
-	"log"
+	"os"
 
 	"github.com/leorolland/genz/cmd/genz"
 )
 
 func main() {
 	if err := genz.Execute(); err != nil {
-		log.Fatal(err)
+		os.Exit(genz.Report(err))
 	}
 }
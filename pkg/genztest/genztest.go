@@ -0,0 +1,209 @@
+// Package genztest tests genz templates against golden files. Template authors use it to check the code their
+// templates generate from a few sample sources, and to review how a change to a template changes that code.
+//
+// Each subdirectory of the directory given to RunGolden is a case. A case holds a package of Go sources, its
+// template, and the golden files. A golden file is an expected output, named after the output with a .golden
+// extension, e.g. user.gen.go.golden:
+//
+//	testdata/
+//		getters/
+//			user.go
+//			getters.tmpl
+//			user.gen.go.golden
+//
+// The template is the single .tmpl file of the case. It is rendered for all the types declared by the sources.
+// If the case has a genz.yaml, its targets are rendered instead, from the sources of the case by default. The
+// golden files of a case expected to fail hold its error message in error.golden. The outputs have no genz
+// header, unless their target sets one: it is then given "genztest" as its .Version and .Command, so that the golden
+// files depend neither on the version of genz nor on the flags of go test.
+//
+//	func TestTemplates(t *testing.T) {
+//		genztest.RunGolden(t, "testdata")
+//	}
+//
+// Running go test -genztest.update rewrites the golden files with the current outputs, so that they can be reviewed
+// with git diff. GENZ_UPDATE=1 go test ./... does the same for all the packages, including the ones without genztest.
+// The flag is not named -update, as the golden file helpers of many packages already define one: the test binary
+// would panic with "flag redefined" when both are linked in.
+package genztest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/leorolland/genz/internal/config"
+	"github.com/leorolland/genz/internal/diff"
+	"github.com/leorolland/genz/internal/generator"
+	"github.com/leorolland/genz/internal/render"
+)
+
+const (
+	// goldenExtension is the extension of the golden files, appended to the names of the outputs.
+	goldenExtension = ".golden"
+	// errorFile is the golden file of a case expected to fail, holding its error message.
+	errorFile = "error" + goldenExtension
+)
+
+// header is the data given to the headers set by the targets, independent of the version of genz and of the test.
+var header = generator.HeaderData{Version: "genztest", Command: "genztest"}
+
+// UpdateEnv is the environment variable rewriting the golden files when set to 1, as the -genztest.update flag does.
+const UpdateEnv = "GENZ_UPDATE"
+
+// update is namespaced, so that it does not conflict with the -update flag of the test packages.
+var update = flag.Bool("genztest.update", false, "rewrite the golden files of genztest.RunGolden with the current outputs")
+
+// RunGolden renders each case of the given directory, as a subtest named after it, and compares its outputs to its
+// golden files. With -genztest.update or $GENZ_UPDATE, the golden files are rewritten instead, and the ones without
+// output are removed.
+func RunGolden(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read the cases: %v", err)
+	}
+	cases := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		cases++
+		caseDir := filepath.Join(dir, entry.Name())
+		t.Run(entry.Name(), func(t *testing.T) {
+			for _, err := range runCase(caseDir, *update || os.Getenv(UpdateEnv) == "1") {
+				t.Error(err)
+			}
+		})
+	}
+	if cases == 0 {
+		t.Fatalf("no case in %s", dir)
+	}
+}
+
+// runCase renders the targets of the case in the given directory, and compares their outputs to its golden files,
+// or rewrites them with update. It returns the differences found.
+func runCase(dir string, update bool) []error {
+	dir, err := filepath.Abs(dir) // Not loaded as a package path.
+	if err != nil {
+		return []error{err}
+	}
+	targets, err := caseTargets(dir)
+	if err != nil {
+		return []error{err}
+	}
+	outputs := map[string][]byte{} // By golden file.
+	var renderErr error
+	for _, target := range targets {
+		rendered, err := render.Render(target, nil, header)
+		if err != nil {
+			renderErr = errors.Join(renderErr, err)
+			continue
+		}
+		for _, output := range rendered {
+			name, err := filepath.Rel(dir, output.Name)
+			if err != nil || output.Name == config.Stdio || strings.HasPrefix(name, "..") {
+				return []error{fmt.Errorf("the output %s is not in the case %s", output.Name, dir)}
+			}
+			outputs[filepath.Join(dir, name+goldenExtension)] = output.Content
+		}
+	}
+	if renderErr != nil {
+		// The paths of the case are relative to it, so that the message does not depend on where the tests run.
+		message := strings.ReplaceAll(renderErr.Error(), dir+string(filepath.Separator), "")
+		outputs = map[string][]byte{filepath.Join(dir, errorFile): []byte(message + "\n")}
+	}
+
+	var goldens []string
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && strings.HasSuffix(path, goldenExtension) {
+			goldens = append(goldens, path)
+		}
+		return err
+	})
+	if err != nil {
+		return []error{fmt.Errorf("failed to list the golden files: %w", err)}
+	}
+	var errs []error
+	for _, golden := range goldens {
+		if _, found := outputs[golden]; found {
+			continue
+		}
+		if !update {
+			errs = append(errs, fmt.Errorf("%s has no output, run go test -genztest.update to remove it", golden))
+		} else if err := os.Remove(golden); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, golden := range names {
+		if update {
+			if err := writeGolden(golden, outputs[golden]); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		expected, err := os.ReadFile(golden)
+		if errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("%s is missing, run go test -genztest.update to write it", golden))
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		output := strings.TrimSuffix(golden, goldenExtension)
+		if d := diff.Unified(golden, output, expected, outputs[golden]); d != "" {
+			errs = append(errs, fmt.Errorf("%s differs from the output, run go test -genztest.update to rewrite it:\n%s", golden, d))
+		}
+	}
+	return errs
+}
+
+// caseTargets returns the targets of the case in the given directory: the ones of its genz.yaml, or its single
+// template rendered for all the types of the case. The outputs have no header unless the target sets one.
+func caseTargets(dir string) ([]config.Target, error) {
+	var targets []config.Target
+	path := filepath.Join(dir, config.FileName)
+	if _, err := os.Stat(path); err == nil {
+		cfg, err := config.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		targets = cfg.Targets
+	} else {
+		templates, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+		if len(templates) != 1 {
+			return nil, fmt.Errorf("expected a single .tmpl file or a %s in %s, found %d templates", config.FileName, dir, len(templates))
+		}
+		targets = []config.Target{{Template: templates[0], Inputs: []string{dir}, TypeRegex: "."}}
+	}
+	for i := range targets {
+		if targets[i].Header == "" {
+			targets[i].Header = "none" // The default header of genz is left out of the golden files.
+		}
+	}
+	return targets, nil
+}
+
+// writeGolden writes the given content into the golden file, creating its directory if needed.
+func writeGolden(golden string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(golden, content, 0644)
+}
@@ -0,0 +1,66 @@
+package genztest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The -update flag of a test package importing genztest, which would panic if genztest defined it too.
+var _ = flag.Bool("update", false, "update the golden files of the package")
+
+func TestRunGolden(t *testing.T) {
+	RunGolden(t, "testdata")
+}
+
+func TestRunCase(t *testing.T) {
+	// The case is loaded from the module of the package.
+	dir, err := os.MkdirTemp(".", "_case")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for _, name := range []string{"user.go", "getters.tmpl", "user.gen.go.golden"} {
+		content, err := os.ReadFile(filepath.Join("testdata", "getters", name))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if name == "user.gen.go.golden" {
+			content = []byte(strings.Replace(string(content), "u.email", "u.mail", 1))
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stale.gen.go.golden"), nil, 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errs := runCase(dir, false)
+	if len(errs) != 2 {
+		t.Fatalf("expected the stale and the differing golden files, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "stale.gen.go.golden has no output") {
+		t.Errorf("expected the stale golden file, got %v", errs[0])
+	}
+	if !strings.Contains(errs[1].Error(), "-\treturn u.mail\n+\treturn u.email\n") {
+		t.Errorf("expected the diff of the golden file, got %v", errs[1])
+	}
+
+	if errs := runCase(dir, true); len(errs) != 0 {
+		t.Fatalf("unexpected errors on update: %v", errs)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stale.gen.go.golden")); !os.IsNotExist(err) {
+		t.Errorf("expected the stale golden file to be removed, got %v", err)
+	}
+	if errs := runCase(dir, false); len(errs) != 0 {
+		t.Errorf("unexpected errors after update: %v", errs)
+	}
+
+	os.Remove(filepath.Join(dir, "user.gen.go.golden"))
+	if errs := runCase(dir, false); len(errs) != 1 || !strings.Contains(errs[0].Error(), "user.gen.go.golden is missing") {
+		t.Errorf("expected the missing golden file, got %v", errs)
+	}
+}
@@ -0,0 +1,8 @@
+package config
+
+type Status int
+
+const (
+	Pending Status = iota
+	Shipped
+)
@@ -0,0 +1,5 @@
+package failing
+
+type Item struct {
+	Name string
+}
@@ -0,0 +1,6 @@
+package getters
+
+type User struct {
+	name  string
+	email string
+}
@@ -0,0 +1,43 @@
+package models
+
+import "fmt"
+
+// HasMethod returns true if the parsed type, or a pointer to it, has the given method, e.g. to not generate a method
+// written by hand: {{ if not (.HasMethod "String") }}...{{ end }}
+// Without TypeChecker, it looks for the method in Methods.
+func (e ParsedElement) HasMethod(name string) bool {
+	if e.TypeChecker != nil {
+		return e.TypeChecker.HasMethod(name)
+	}
+	for _, method := range e.Methods {
+		if method.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasField returns true if the parsed struct has the given field, declared or promoted from an embedded struct,
+// e.g. {{ if .HasField "ID" }}...{{ end }}
+// Without TypeChecker, it looks for the field in Attributes.
+func (e ParsedElement) HasField(name string) bool {
+	if e.TypeChecker != nil {
+		return e.TypeChecker.HasField(name)
+	}
+	for _, attribute := range e.Attributes {
+		if attribute.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Implements returns true if the parsed type, or a pointer to it, implements the given interface, e.g.
+// {{ if not (.Implements "fmt.Stringer") }}...{{ end }}; see TypeChecker.Implements for the names of the interfaces.
+// It fails without TypeChecker.
+func (e ParsedElement) Implements(iface string) (bool, error) {
+	if e.TypeChecker == nil {
+		return false, fmt.Errorf("cannot check whether %s implements %s without the type checker", e.Type.InternalName, iface)
+	}
+	return e.TypeChecker.Implements(iface)
+}
@@ -0,0 +1,16 @@
+package models
+
+// String returns the raw value of the tag.
+func (t Tag) String() string {
+	return t.Value
+}
+
+// HasOption returns true if the tag has the given option. e.g. {{ if (index .ParsedTags "json").HasOption "omitempty" }}
+func (t Tag) HasOption(option string) bool {
+	for _, o := range t.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -7,9 +7,39 @@ type (
 		// PackageName is the name of the package of the parsed element.
 		// e.g. "package foo" => "foo"
 		PackageName string
-		// List of the imports of the package of the parsed element.
+		// Sorted list of the imports of the package of the parsed element.
 		// e.g. ["github.com/google/uuid", "time"]
 		PackageImports []string
+		// List of the top-level functions of the package of the parsed element, in source order.
+		// Only filled in functions mode (-functions). See Function for more details.
+		Functions []Function
+		// Package of the parsed element: its name, paths, Go version and type names, and its exported constants and
+		// variables in values mode (-values). See Package for more details.
+		Package Package
+		// List of the structs implementing the parsed interface, sorted by package path and name.
+		// Only filled by the implements command (genz implements). See Implementation for more details.
+		Implementations []Implementation
+		// Type the parsed element is converted to, possibly declared in another package: its local names and the ones
+		// of its attributes are then qualified with their package name, e.g. "domain.User".
+		// Only filled by the "to" option of a target (e.g. genz builtin mapper -to ./domain.User).
+		Target *Element
+		// Left and Right are the two types compared by a target, possibly declared in other packages like Target, e.g.
+		// to generate a migration shim or a mapper between two versions of a struct. The template is then rendered once,
+		// with an empty Element. Only filled when the types of the target are qualified with their package.
+		// e.g. "genz -type ./v1.User -type ./v2.User" => {Type: {LocalName: "v1.User", ...}} and {Type: {LocalName: "v2.User", ...}}
+		Left  *Element
+		Right *Element
+		// Interfaces referenced by the interfaces option of a target, in its order, possibly declared in other packages
+		// like Target, e.g. to generate a facade combining them with the methodUnion template function.
+		// e.g. "interfaces: [Reader, Writer]" => [{Type: {Name: "io.Reader", ...}, Methods: [...]}, ...]
+		Interfaces []Element
+		// Variables given to the template by the caller, by name.
+		// e.g. "genz builtin sql -dialect mysql" => {"dialect": "mysql"}
+		Vars map[string]string
+		// TypeChecker answers the guards of the template (HasMethod, HasField and Implements) with the type checker,
+		// which sees the methods and the fields declared in every file of the package, and the promoted ones.
+		// Nil when the element is not parsed from a package, e.g. decoded by a plugin.
+		TypeChecker TypeChecker `json:"-" yaml:"-"`
 
 		// See Element for more details.
 		// Note: this inlined, so you can access directly to the fields as if it was an
@@ -17,36 +47,208 @@ type (
 		Element
 	}
 
-	// Element represents a struct or an interface.
+	// TypeChecker answers questions about the parsed type with the type checker, see the guards of ParsedElement.
+	TypeChecker interface {
+		// HasMethod returns true if the method set of the type, or of a pointer to it, has the given method.
+		HasMethod(name string) bool
+		// HasField returns true if the struct has the given field, declared or promoted from an embedded struct.
+		HasField(name string) bool
+		// Implements returns true if the type, or a pointer to it, implements the given interface: the name of an
+		// interface of the package (e.g. "Repository"), or qualified with the name or the path of its package
+		// (e.g. "fmt.Stringer", "github.com/acme/app/store.Repository").
+		Implements(iface string) (bool, error)
+	}
+
+	// Element represents a struct, an interface or any other named type (e.g. "type UserID string").
 	Element struct {
 		// See Type for more details.
 		Type Type
 
-		// List of the attributes of the struct. Empty if the parsed element is an interface.
+		// List of the comments of the element, without its directives.
+		// e.g. "// User is a user." => [" User is a user."]
+		Comments []string
+		// Directives written in the comments of the element with the "//genz:" prefix, by name.
+		// e.g. "//genz:table users" => {"table": "users"}
+		Directives map[string]string
+		// Imports are the sorted import paths of the packages referenced by the attributes, the methods and the type
+		// parameters of the element, excluding its own package. e.g. ["github.com/google/uuid", "time"]
+		Imports []string
+
+		// List of the attributes of the struct, in source order. Empty if the parsed element is an interface.
 		// See Attribute for more details.
 		Attributes []Attribute
 
 		// List of the methods of the struct or the interface.
+		// The methods are in source order. The methods of an interface embedded in an interface are sorted by name,
+		// at the position of the embedded interface.
 		// See Method for more details.
 		Methods []Method
+
+		// List of the type parameters of a generic element. Empty if the element is not generic.
+		// e.g. "type Box[T any] struct{}" => [{Name: "T", Constraint: {Name: "any"}}]
+		// See TypeParam for more details.
+		TypeParams []TypeParam
+
+		// Underlying type of a named type that is neither a struct nor an interface.
+		// e.g. "type UserID string" => {Name: "string", InternalName: "string"}
+		// Empty for structs and interfaces.
+		Underlying Type
+
+		// See Position for more details. Filled with the positions option only.
+		// e.g. {{ .File }}:{{ .Line }}
+		Position
+
+		// List of the constants declared with the named type, in source order.
+		// e.g. "const ( Red Color = iota; Green )" => [{Name: "Red", Value: "0"}, {Name: "Green", Value: "1"}]
+		// Empty for structs and interfaces.
+		// See EnumValue for more details.
+		EnumValues []EnumValue
+	}
+
+	// Implementation represents a struct implementing the parsed interface, possibly declared in another package.
+	// Its LocalName is the one of the struct from the package of the interface. e.g. "repo.SQLRepository"
+	Implementation struct {
+		// See Element for more details.
+		Element
+		// PointerReceiver is true if only the pointer to the struct implements the interface, i.e. some of the methods
+		// are declared on the pointer. e.g. "var _ Repository = (*SQLRepository)(nil)"
+		PointerReceiver bool
+	}
+
+	// EnumValue represents a constant declared with the type of the parsed element.
+	EnumValue struct {
+		// Name of the constant. e.g. "Red" for "Red Color = iota"
+		Name string
+		// Value of the constant as a Go literal. e.g. "0" for an iota constant or "\"red\"" for a string constant
+		Value string
+		// IsExported is true if the constant is exported.
+		IsExported bool
+
+		// List of the comments of the constant.
+		// Only upper comments are parsed. No inline comments.
+		Comments []string
+		// Tags written in the inline comment of the constant, with the struct tags syntax.
+		// e.g. map[string]string{"genz": "dark-red"} for "DarkRed Color = iota // genz:"dark-red""
+		Tags map[string]string
+		// ParsedTags are the Tags split into their name and options. See Tag for more details.
+		ParsedTags map[string]Tag
+	}
+
+	// Package represents the package of the parsed element and its package-level declarations, other than its functions.
+	Package struct {
+		// Name of the package. e.g. "package foo" => "foo"
+		Name string
+		// Path is the import path of the package. e.g. "github.com/acme/app/internal/foo"
+		Path string
+		// ModulePath is the path of the module of the package, e.g. "github.com/acme/app".
+		// Empty if the package is not part of a module.
+		ModulePath string
+		// GoVersion is the Go version of the go.mod file of the module of the package, e.g. "1.21".
+		// Empty if the package is not part of a module.
+		GoVersion string
+		// Sorted list of the names of the types declared in the package, aliases included, e.g. to detect that the name
+		// of a generated type collides with an existing one: {{ if has "UserView" .Package.TypeNames }}...{{ end }}
+		TypeNames []string
+
+		// List of the exported constants of the package, in source order. e.g. "const Timeout = 5 * time.Second"
+		// Only filled in values mode (-values).
+		Constants []Value
+		// List of the exported variables of the package, in source order. e.g. "var Debug = flag.Bool(...)"
+		// Only filled in values mode (-values).
+		Variables []Value
+	}
+
+	// Value represents a package-level constant or variable.
+	Value struct {
+		// Name of the constant or of the variable. e.g. "Timeout"
+		Name string
+		// See Type for more details. e.g. {Name: "time.Duration", ...} for "const Timeout = 5 * time.Second"
+		Type Type
+		// Value of a constant as a Go literal. e.g. "5000000000" for "5 * time.Second", "\"red\"" for a string
+		// For a variable, the expression initializing it, e.g. "flag.Bool(\"debug\", false, \"\")", or empty if it has none
+		// or if it is initialized with several others (e.g. "var A, B = f()").
+		Value string
+
+		// List of the comments of the constant or of the variable, or of its declaration if it is declared alone.
+		Comments []string
+	}
+
+	// Function represents a top-level function of a package. e.g. "func NewUser(name string) (*User, error)"
+	Function struct {
+		// Name of the function. e.g. "NewUser"
+		Name string
+		// List of the types of the params of the function. e.g. ["string"]
+		Params []Type
+		// List of the types of the returns of the function. e.g. ["*User", "error"]
+		Returns []Type
+		// Names of the params, in the same order as Params. e.g. ["name"]
+		ParamNames []string
+		// Names of the returns, in the same order as Returns. Empty names for unnamed returns. e.g. ["", ""]
+		ReturnNames []string
+		// List of the type parameters of a generic function. e.g. "func Map[T, U any]()" => [{Name: "T"}, {Name: "U"}]
+		TypeParams []TypeParam
+		// IsExported is true if the function is exported.
+		IsExported bool
+		// Implements are the exported interfaces of the package implemented by the first return of the function, when it
+		// is not an interface itself, sorted by name. The interfaces without method are left aside.
+		// e.g. "func NewSQLStore() *SQLStore" => [{Name: "main.Store", ...}] if *SQLStore implements Store
+		Implements []Type
+
+		// List of the comments of the function.
+		Comments []string
+	}
+
+	// TypeParam represents a type parameter of a generic element.
+	TypeParam struct {
+		// Name of the type parameter. e.g. "T" for "[T any]"
+		Name string
+		// Constraint of the type parameter. e.g. "any", "comparable" or "~int | ~string"
+		// See Type for more details.
+		Constraint Type
 	}
 
 	// Attribute represents a struct's attribute.
 	// It is not used for interfaces.
 	Attribute struct {
 		// Name of the attribute. e.g. "Foo" for "Foo string"
+		// For an embedded field, it is the name of the embedded type. e.g. "Bar" for "*foo.Bar"
 		Name string
 		// See Type for more details.
 		Type Type
+		// IsEmbedded is true if the attribute is an embedded field. e.g. "type B struct { A }"
+		IsEmbedded bool
+		// Resolved is the parsed struct referenced by the attribute type, when parsing recursively.
+		// Pointers, slices, arrays and map values are looked through. e.g. "[]*Foo" => Foo
+		// Nil if parsing is not recursive or if the type is not a struct of the parsed module.
+		Resolved *Element
 
 		// List of the comments of the attribute.
 		// Only upper comments are parsed. No inline comments.
 		Comments []string
 
+		// See Position for more details. Filled with the positions option only.
+		Position
+
 		// Tags of the attribute. e.g. `json:"foo,omitempty"`
 		// The map key is the tag name, the map value is the tag value.
 		// e.g. `json:"foo,omitempty"` => map[string]string{"json": "foo,omitempty"}
 		Tags map[string]string
+		// ParsedTags are the Tags split into their name and options.
+		// e.g. `json:"foo,omitempty"` => map[string]Tag{"json": {Value: "foo,omitempty", Name: "foo", Options: ["omitempty"]}}
+		// See Tag for more details.
+		ParsedTags map[string]Tag
+	}
+
+	// Tag represents the value of a tag key, split into its name and its options.
+	// It prints as its raw value, e.g. {{ index .ParsedTags "json" }} => "foo,omitempty"
+	Tag struct {
+		// Value is the raw value of the tag. e.g. "foo,omitempty" for `json:"foo,omitempty"`
+		Value string
+		// Name is the first comma-separated element of the value. e.g. "foo" for `json:"foo,omitempty"`
+		Name string
+		// Options are the following comma-separated elements of the value. e.g. ["omitempty"] for `json:"foo,omitempty"`
+		// Empty if the tag has no option.
+		Options []string
 	}
 
 	// Method represents a struct's method or an interface's method.
@@ -59,15 +261,61 @@ type (
 		// List of the return values of the method. Empty if the method has no return value.
 		// See Type for more details.
 		Returns []Type
+		// Names of the parameters, in the same order as Params. A name is empty if the parameter is unnamed.
+		// e.g. "Get(ctx context.Context, id string)" => ["ctx", "id"]
+		ParamNames []string
+		// Names of the return values, in the same order as Returns. A name is empty if the return value is unnamed.
+		// e.g. "Get() (user *User, err error)" => ["user", "err"]
+		ReturnNames []string
+		// ResolvedParams are the parsed structs referenced by the types of the parameters, in the same order as Params,
+		// when parsing recursively, see Attribute.Resolved. Nil for the parameters which do not reference a struct, and
+		// empty if none does. e.g. "Save(ctx context.Context, users []User)" => [nil, {Type: {Name: "main.User", ...}, ...}]
+		ResolvedParams []*Element
+		// ResolvedReturns are the parsed structs referenced by the types of the return values, in the same order as
+		// Returns, see ResolvedParams.
+		ResolvedReturns []*Element
+		// List of the type parameters of the receiver of a method of a generic type, as named by the method.
+		// e.g. "func (b Box[K, V]) Get() V" => [{Name: "K", ...}, {Name: "V", ...}]
+		// Empty for the methods of non generic types and for interface methods.
+		TypeParams []TypeParam
 		// IsPointerReceiver is true if the method is a pointer receiver.
 		// Always false for interfaces.
 		IsPointerReceiver bool
+		// ReceiverName is the name of the receiver of the method. e.g. "u" for "func (u *User) Save() error"
+		// Empty for interfaces and for unnamed receivers.
+		ReceiverName string
+		// FirstParamIsContext is true if the first param of the method is a context.Context.
+		FirstParamIsContext bool
+		// ReturnsError is true if the last return value of the method is an error.
+		ReturnsError bool
+		// Panics is true if the body of the method calls panic, outside of the function literals it declares.
+		// Always false for interfaces and for the methods of the types declared outside of the parsed package.
+		Panics bool
 		// IsExported is true if the method is exported.
 		IsExported bool
 
 		// List of the comments of the method.
 		// Only upper comments are parsed. No inline or in the method's body comments.
 		Comments []string
+		// Directives written in the comments of a method of a struct, a named type or an interface with the "//genz:"
+		// prefix, by name.
+		// e.g. "//genz:api" => {"api": ""}
+		Directives map[string]string
+
+		// See Position for more details. Filled with the positions option only.
+		Position
+	}
+
+	// Position is the position of the declaration of an element, an attribute or a method in its source file,
+	// e.g. to write a "//line" directive or a diagnostic pointing to the source. Zero if unknown.
+	Position struct {
+		// File is the absolute path of the source file. e.g. "/home/me/project/models/user.go"
+		// Use {{ base .File }} for an output which does not depend on the location of the project.
+		File string
+		// Line is the 1-based line of the declaration. e.g. 12
+		Line int
+		// Column is the 1-based column of the declared name, in bytes. e.g. 6 for "type User struct"
+		Column int
 	}
 
 	// Type represents a generic type among structs, interfaces, attributes, methods, etc.
@@ -84,5 +332,53 @@ type (
 		// Example `UUID` or `Time`
 		// Use this variable if you generate code inside the package of that type
 		InternalName string
+
+		// Name of the type from inside the package of the parsed element
+		// Example `uuid.UUID` or `Car` (for a type declared in the package of the parsed element)
+		// Use this variable if you generate code inside the package of the parsed element, e.g. with go:generate
+		LocalName string
+
+		// PkgPath is the import path of the package declaring a named type. e.g. "github.com/google/uuid" for `uuid.UUID`
+		// Empty for the predeclared types (e.g. "string", "error") and for the type literals, see Elem and Key.
+		PkgPath string
+
+		// Kind of a type literal. They are all false for a named type, e.g. "type IDs []string".
+		// e.g. "[]*User" => IsSlice, with an Elem "*User" which IsPointer
+		IsPointer bool
+		IsSlice   bool
+		IsArray   bool
+		IsMap     bool
+		IsChan    bool
+		IsFunc    bool
+		IsStruct  bool
+
+		// Fields are the attributes of an anonymous struct type, with their comments when it is declared in the parsed
+		// package. Nil for the other types, named structs included: see Attribute.Resolved.
+		// e.g. "struct { Host string }" => [{Name: "Host", ...}], "[]struct { ... }" => the Fields of its Elem
+		Fields []Attribute
+
+		// Elem is the element type of a pointer, a slice, an array, a map or a channel. Nil otherwise.
+		// e.g. "map[string]int" => {Name: "int", ...}
+		Elem *Type
+		// Key is the key type of a map. Nil otherwise.
+		// e.g. "map[string]int" => {Name: "string", ...}
+		Key *Type
+
+		// IsVariadic is true for the last param of a variadic function or method.
+		// Its names are the ones of the slice type, e.g. "...string" => {Name: "[]string", IsSlice: true, Elem: {Name: "string"}}
+		IsVariadic bool
+
+		// BasicKind is the predeclared type underlying a defined type, e.g. "float64" for "type Meters float64",
+		// or "int64" for `time.Duration`, to serialize or validate it as its basic type. Empty for the predeclared types
+		// themselves, whose Name is the kind, and for the other types.
+		BasicKind string
+
+		// IsAlias is true for a type written with the name of an alias, e.g. "type ID = uuid.UUID".
+		// Its names are the ones of the alias, as written in the source, and Aliased is the type it stands for.
+		// The aliases of the predeclared types (byte, rune and any) are not reported.
+		IsAlias bool
+		// Aliased is the type an alias stands for, with the aliases of its own declaration followed. Nil otherwise.
+		// e.g. "type ID = uuid.UUID" => {Name: "uuid.UUID", ...}
+		Aliased *Type
 	}
 )
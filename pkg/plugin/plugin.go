@@ -0,0 +1,127 @@
+// Package plugin is the protocol between genz and the external generators, or plugins: executables reading a Request
+// encoded in JSON on their standard input, and writing a Response encoded in JSON on their standard output.
+// The messages use the field names of the Go types, as the models printed by genz inspect.
+//
+// A plugin written in Go only needs to call Serve from its main function:
+//
+//	func main() {
+//		plugin.Serve(func(request plugin.Request) ([]plugin.File, error) {
+//			var files []plugin.File
+//			for _, element := range request.Elements {
+//				files = append(files, plugin.File{Name: strings.ToLower(element.Type.Name) + "_foo.gen.go", Content: "..."})
+//			}
+//			return files, nil
+//		})
+//	}
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/leorolland/genz/pkg/models"
+)
+
+// Version is the version of the protocol, incremented on breaking changes. See Request.Version.
+const Version = 1
+
+type (
+	// Request is the message genz writes to the standard input of a plugin.
+	Request struct {
+		// Version is the version of the protocol used by genz. A plugin should fail on a version it does not support.
+		Version int
+		// Elements are the parsed types selected with -type, in the order they were selected.
+		// See models.ParsedElement for more details.
+		Elements []models.ParsedElement
+		// Vars are the variables given to the plugin by the caller, by name.
+		// e.g. "genz plugin foo -var prefix=Foo" => {"prefix": "Foo"}
+		Vars map[string]string
+	}
+
+	// Response is the message a plugin writes to its standard output.
+	Response struct {
+		// Files are the files generated by the plugin.
+		Files []File
+		// Error is the error of the plugin, if any. The files are then ignored.
+		Error string
+	}
+
+	// File is a file generated by a plugin.
+	File struct {
+		// Name is the path of the file, relative to the directory of the parsed package unless it is absolute.
+		// The .go files are formatted and get their imports fixed, as the outputs of a template.
+		Name string
+		// Content is the content of the file.
+		Content string
+	}
+)
+
+// Serve runs a plugin: it reads the request from the standard input, generates the files with the given function,
+// and writes the response to the standard output. It exits with status 1 if the request cannot be read.
+func Serve(generate func(request Request) ([]File, error)) {
+	if err := serve(os.Stdin, os.Stdout, generate); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// serve reads a request from r, and writes the response of the given function to w.
+func serve(r io.Reader, w io.Writer, generate func(request Request) ([]File, error)) error {
+	var request Request
+	if err := json.NewDecoder(r).Decode(&request); err != nil {
+		return fmt.Errorf("failed to read the genz request: %w", err)
+	}
+	var response Response
+	if request.Version != Version {
+		response.Error = fmt.Sprintf("unsupported genz plugin protocol version %d, expected %d", request.Version, Version)
+	} else if files, err := generate(request); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Files = files
+	}
+	return json.NewEncoder(w).Encode(response)
+}
+
+// Call runs the plugin of the given executable with the given request, and returns the files it generated.
+// The standard error of the plugin is forwarded, e.g. for its logs.
+func Call(executable string, request Request) ([]File, error) {
+	request.Version = Version
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	var output bytes.Buffer
+	cmd := exec.Command(executable)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %w", executable, err)
+	}
+	var response Response
+	if err := json.Unmarshal(output.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("invalid response of plugin %s: %w", executable, err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", executable, response.Error)
+	}
+	return response.Files, nil
+}
+
+// Lookup returns the executable of the plugin with the given name: the genz-plugin-<name> executable of the PATH,
+// e.g. genz-plugin-foo for foo, or the given path if it contains a path separator, e.g. ./bin/foo.
+func Lookup(name string) (string, error) {
+	if strings.ContainsAny(name, `/\`) {
+		return name, nil
+	}
+	executable, err := exec.LookPath("genz-plugin-" + name)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s not found: %w", name, err)
+	}
+	return executable, nil
+}
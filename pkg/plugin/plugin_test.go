@@ -0,0 +1,130 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leorolland/genz/pkg/models"
+)
+
+// TestMain runs the test binary as a plugin when GENZ_TEST_PLUGIN is set, see TestCall.
+func TestMain(m *testing.M) {
+	switch os.Getenv("GENZ_TEST_PLUGIN") {
+	case "":
+		os.Exit(m.Run())
+	case "names":
+		Serve(func(request Request) ([]File, error) {
+			var files []File
+			for _, element := range request.Elements {
+				files = append(files, File{Name: element.Type.Name + ".txt", Content: request.Vars["prefix"] + element.Type.Name})
+			}
+			return files, nil
+		})
+	case "error":
+		Serve(func(request Request) ([]File, error) {
+			return nil, errors.New("unsupported type")
+		})
+	case "crash":
+		os.Exit(2)
+	}
+}
+
+func TestServe(t *testing.T) {
+	testCases := map[string]struct {
+		request  Request
+		expected Response
+	}{
+		"files": {
+			request:  Request{Version: Version, Vars: map[string]string{"name": "foo"}},
+			expected: Response{Files: []File{{Name: "foo.go", Content: "package foo"}}},
+		},
+		"unsupported version": {
+			request:  Request{Version: Version + 1},
+			expected: Response{Error: "unsupported genz plugin protocol version 2, expected 1"},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			input, err := json.Marshal(tc.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var output bytes.Buffer
+			err = serve(bytes.NewReader(input), &output, func(request Request) ([]File, error) {
+				return []File{{Name: request.Vars["name"] + ".go", Content: "package " + request.Vars["name"]}}, nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var actual Response
+			if err := json.Unmarshal(output.Bytes(), &actual); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, actual)
+			}
+		})
+	}
+	if err := serve(strings.NewReader("{"), &bytes.Buffer{}, nil); err == nil {
+		t.Error("expected an error for an invalid request")
+	}
+}
+
+func TestCall(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := Request{
+		Elements: []models.ParsedElement{
+			{Element: models.Element{Type: models.Type{Name: "Human"}}},
+			{Element: models.Element{Type: models.Type{Name: "Car"}}},
+		},
+		Vars: map[string]string{"prefix": "type "},
+	}
+
+	t.Setenv("GENZ_TEST_PLUGIN", "names")
+	files, err := Call(executable, request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []File{{Name: "Human.txt", Content: "type Human"}, {Name: "Car.txt", Content: "type Car"}}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %+v, got %+v", expected, files)
+	}
+
+	t.Setenv("GENZ_TEST_PLUGIN", "error")
+	if _, err := Call(executable, request); err == nil || !strings.Contains(err.Error(), "unsupported type") {
+		t.Errorf("expected the error of the plugin, got %v", err)
+	}
+	t.Setenv("GENZ_TEST_PLUGIN", "crash")
+	if _, err := Call(executable, request); err == nil {
+		t.Error("expected an error for a failing plugin")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "genz-plugin-foo"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	if executable, err := Lookup("foo"); err != nil || executable != filepath.Join(dir, "genz-plugin-foo") {
+		t.Errorf("Lookup(foo) = %s, %v", executable, err)
+	}
+	if executable, err := Lookup("./bin/bar"); err != nil || executable != "./bin/bar" {
+		t.Errorf("Lookup(./bin/bar) = %s, %v", executable, err)
+	}
+	if _, err := Lookup("bar"); err == nil {
+		t.Error("expected an error for an unknown plugin")
+	}
+}